@@ -0,0 +1,99 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Migration is a single move of a Subnamespace to be under a new parent
+type Migration struct {
+	// CurrentNamespace is name of the Subnamespace that is being migrated
+	CurrentNamespace string `json:"currentns"`
+
+	// ToNamespace is the name of the Subnamespace that represents the new parent
+	// of the Subnamespace that needs to be migrated
+	ToNamespace string `json:"tons"`
+}
+
+// BatchMigrationSpec defines the desired state of BatchMigration
+type BatchMigrationSpec struct {
+	// Migrations is the list of Subnamespace migrations of the plan. The whole plan is validated
+	// when the BatchMigration is created, and the migrations are then executed one at a time
+	Migrations []Migration `json:"migrations"`
+}
+
+// MigrationStatus defines the observed state of a single migration of a BatchMigration
+type MigrationStatus struct {
+	// CurrentNamespace is name of the Subnamespace that is being migrated
+	CurrentNamespace string `json:"currentns"`
+
+	// ToNamespace is the name of the new parent of the Subnamespace
+	ToNamespace string `json:"tons"`
+
+	// MigrationHierarchy is the name of the MigrationHierarchy which executes the migration
+	MigrationHierarchy string `json:"migrationHierarchy,omitempty"`
+
+	// Phase is the phase of the migration. It is a string and can be one of the following:
+	// "Pending" - state for a migration which was not started yet
+	// "InProgress" - state for a migration which is being executed
+	// "Error" - state for a migration which could not be completed due to an error
+	// "Complete" - state for a migration which completed successfully
+	Phase Phase `json:"phase,omitempty"`
+
+	// Reason is a string explaining why an error occurred if it did; otherwise it’s empty
+	Reason string `json:"reason,omitempty"`
+}
+
+// BatchMigrationStatus defines the observed state of BatchMigration
+type BatchMigrationStatus struct {
+	// Phase acts like a state machine for the BatchMigration.
+	// It is a string and can be one of the following:
+	// "InProgress" - state for a BatchMigration indicating that the migrations are being executed
+	// "Error" - state for a BatchMigration indicating that one of the migrations could not be completed due to an error
+	// "Complete" - state for a BatchMigration indicating that all the migrations completed successfully
+	Phase Phase `json:"phase,omitempty"`
+
+	// Reason is a string explaining why an error occurred if it did; otherwise it’s empty
+	Reason string `json:"reason,omitempty"`
+
+	// Migrations is the status of each of the migrations of the plan, in the order they are executed
+	Migrations []MigrationStatus `json:"migrations,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:scope=Cluster
+
+// BatchMigration is the Schema for the batchmigrations API
+type BatchMigration struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   BatchMigrationSpec   `json:"spec,omitempty"`
+	Status BatchMigrationStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// BatchMigrationList contains a list of BatchMigration
+type BatchMigrationList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []BatchMigration `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&BatchMigration{}, &BatchMigrationList{})
+}
@@ -0,0 +1,76 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// HierarchyRootSpec defines the desired state of HierarchyRoot
+type HierarchyRootSpec struct {
+	// RqDepth is the depth of the hierarchy until which ResourceQuotas are created for
+	// Subnamespaces; ClusterResourceQuotas are created for deeper Subnamespaces
+	// +kubebuilder:validation:Minimum=1
+	RqDepth int `json:"rqDepth"`
+
+	// ResourceQuotaSpec represents the quota of the root namespace, which is the total
+	// quota that can be allocated to the Subnamespaces of the hierarchy
+	ResourceQuotaSpec corev1.ResourceQuotaSpec `json:"resourcequota,omitempty"`
+
+	// SecondaryRoots are the names of the children Subnamespaces of the root namespace which
+	// denote different branches of the hierarchy. Moving resources and migrating Subnamespaces
+	// between secondary roots is not allowed
+	// +optional
+	SecondaryRoots []string `json:"secondaryRoots,omitempty"`
+}
+
+// HierarchyRootStatus defines the observed state of HierarchyRoot
+type HierarchyRootStatus struct {
+	// Phase acts like a state machine for the HierarchyRoot.
+	// It is a string and can be one of the following:
+	// "Error" - state for a HierarchyRoot indicating that the root namespace could not be reconciled due to an error
+	// "Created" - state for a HierarchyRoot whose root namespace exists and is being synced
+	Phase Phase `json:"phase,omitempty"`
+
+	// Reason is a string explaining why an error occurred if it did; otherwise it’s empty
+	Reason string `json:"reason,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:scope=Cluster,shortName=hr
+
+// HierarchyRoot is the Schema for the hierarchyroots API. The name of a HierarchyRoot is
+// the name of the root namespace of the hierarchy it declares
+type HierarchyRoot struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   HierarchyRootSpec   `json:"spec,omitempty"`
+	Status HierarchyRootStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// HierarchyRootList contains a list of HierarchyRoot
+type HierarchyRootList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []HierarchyRoot `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&HierarchyRoot{}, &HierarchyRootList{})
+}
@@ -7,6 +7,23 @@ type HNSConfigSpec struct {
 	PermittedGroups   []string           `json:"permittedGroups"`
 	ObservedResources []string           `json:"observedResources"`
 	LimitRange        LimitRangeSettings `json:"limitRange"`
+
+	// ResourceAliases maps a canonical resource name to the resource names which are aliases of it,
+	// e.g. "cpu" to ["requests.cpu"]. The quota specs of Subnamespaces, UpdateQuotas and quota objects
+	// are normalized to use the canonical resource names.
+	// +optional
+	ResourceAliases map[string][]string `json:"resourceAliases,omitempty"`
+
+	// ExcludedServiceAccounts is a list of names of ServiceAccounts which are not propagated as subjects of
+	// RoleBindings to the descendants of their namespace, since every namespace has its own. Defaults to
+	// "default", "builder" and "deployer".
+	// +optional
+	ExcludedServiceAccounts []string `json:"excludedServiceAccounts,omitempty"`
+
+	// ExcludedGroupPrefixes is a list of prefixes of names of Groups which are not propagated as subjects
+	// of RoleBindings to the descendants of their namespace. Defaults to "system".
+	// +optional
+	ExcludedGroupPrefixes []string `json:"excludedGroupPrefixes,omitempty"`
 }
 
 type LimitRangeSettings struct {
@@ -15,6 +15,7 @@ limitations under the License.
 package v1
 
 import (
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -26,18 +27,130 @@ type MigrationHierarchySpec struct {
 	// ToNamespace is the name of the Subnamespace that represents the new parent
 	// of the Subnamespace that needs to be migrated
 	ToNamespace string `json:"tons"`
+
+	// Revert asks to undo the steps of a migration which failed and to return the Subnamespace
+	// and its quota to the original parent. It can only be set after the migration has failed
+	Revert bool `json:"revert,omitempty"`
+
+	// DryRun asks to compute the impact of the migration and write it to the status
+	// as a preview, without changing anything
+	DryRun bool `json:"dryRun,omitempty"`
 }
 
 // MigrationHierarchyStatus defines the observed state of MigrationHierarchy
 type MigrationHierarchyStatus struct {
 	// Phase acts like a state machine for the Migrationhierarchy.
 	// It is a string and can be one of the following:
+	// "InProgress" - state for a Migrationhierarchy indicating that the operation is running
 	// "Error" - state for a Migrationhierarchy indicating that the operation could not be completed due to an error
 	// "Complete" - state for a Migrationhierarchy indicating that the operation completed successfully
+	// "Reverting" - state for a Migrationhierarchy indicating that a failed operation is being reverted
+	// "Reverted" - state for a Migrationhierarchy indicating that a failed operation was reverted
 	Phase Phase `json:"phase,omitempty"`
 
+	// Step is the last step of the migration which was completed. It can be one of the following, in order:
+	// "QuotaReserved", "NewSNSCreated", "OldSNSDeleted", "RelatedUpdated", "DBUpdated", "QuotaReleased"
+	Step MigrationStep `json:"step,omitempty"`
+
+	// OriginalParent is the name of the parent of the Subnamespace before it was migrated
+	OriginalParent string `json:"originalParent,omitempty"`
+
 	// Reason is a string explaining why an error occurred if it did; otherwise it’s empty
 	Reason string `json:"reason,omitempty"`
+
+	// QuotaTransfer is the transfer of the quota of the migrated Subnamespace from its old parent to its
+	// new parent. It is recorded before the Subnamespace is migrated and is updated as every step of the
+	// transfer is applied, so that a transfer which was interrupted can be resumed
+	QuotaTransfer *QuotaTransfer `json:"quotaTransfer,omitempty"`
+
+	// Preview is the impact the migration would have. It is only computed in dry-run mode
+	Preview *MigrationPreview `json:"preview,omitempty"`
+}
+
+// MigrationPreview defines the impact a MigrationHierarchy would have if it was executed
+type MigrationPreview struct {
+	// Namespaces is the list of the namespaces which would move, which are the migrated
+	// Subnamespace and all its descendants
+	Namespaces []string `json:"namespaces,omitempty"`
+
+	// GainedRoleBindings is the list of the names of the RoleBindings which the
+	// migrated namespaces would inherit from the new parent
+	GainedRoleBindings []string `json:"gainedRoleBindings,omitempty"`
+
+	// LostRoleBindings is the list of the names of the RoleBindings which the
+	// migrated namespaces would no longer inherit from the old parent
+	LostRoleBindings []string `json:"lostRoleBindings,omitempty"`
+
+	// AnnotationChanges is the list of the changes to the ClusterResourceQuota selectors
+	// and pointer of the migrated Subnamespace
+	AnnotationChanges []AnnotationChange `json:"annotationChanges,omitempty"`
+
+	// QuotaTransfer is the transfer of the quota of the migrated Subnamespace which would be applied
+	QuotaTransfer *QuotaTransfer `json:"quotaTransfer,omitempty"`
+
+	// QuotaFits is true if the common ancestor has enough free resources to reserve the quota in the new
+	// branch, and every Subnamespace the quota would be released from can release it
+	QuotaFits bool `json:"quotaFits"`
+
+	// QuotaMessage explains why the quota does not fit, if it does not
+	QuotaMessage string `json:"quotaMessage,omitempty"`
+
+	// NamespaceDBKey is the key of the new parent in the NamespaceDB, if it belongs to one
+	NamespaceDBKey string `json:"namespaceDBKey,omitempty"`
+
+	// NamespaceDBKeyCount is the number of namespaces the key of the new parent would have after the migration
+	NamespaceDBKeyCount int `json:"namespaceDBKeyCount,omitempty"`
+
+	// ExceedsMaxSNS is true if the key of the new parent would exceed the maximum number of namespaces
+	ExceedsMaxSNS bool `json:"exceedsMaxSNS"`
+}
+
+// AnnotationChange defines the change of the value of an annotation
+type AnnotationChange struct {
+	// Key is the key of the annotation
+	Key string `json:"key"`
+
+	// Before is the current value of the annotation
+	Before string `json:"before,omitempty"`
+
+	// After is the value the annotation would have
+	After string `json:"after,omitempty"`
+}
+
+// QuotaTransfer defines the transfer of the quota of a migrated Subnamespace through the common
+// ancestor of its old parent and its new parent
+type QuotaTransfer struct {
+	// Resources is the quota of the migrated Subnamespace which is transferred
+	Resources corev1.ResourceList `json:"resources,omitempty"`
+
+	// Ancestor is the name of the common ancestor of the old parent and the new parent,
+	// whose quota is not changed by the transfer
+	Ancestor string `json:"ancestor"`
+
+	// Release is the list of steps which subtract the resources from the Subnamespaces on the way
+	// from the old parent up to the common ancestor, in the order they are applied
+	Release []QuotaTransferStep `json:"release,omitempty"`
+
+	// Reserve is the list of steps which add the resources to the Subnamespaces on the way
+	// from the common ancestor down to the new parent, in the order they are applied
+	Reserve []QuotaTransferStep `json:"reserve,omitempty"`
+}
+
+// QuotaTransferStep defines the change of the quota of a single Subnamespace in a quota transfer
+type QuotaTransferStep struct {
+	// Subnamespace is the name of the Subnamespace whose quota is changed
+	Subnamespace string `json:"subnamespace"`
+
+	// Parent is the name of the parent of the Subnamespace
+	Parent string `json:"parent"`
+
+	// Marker is the value of the quota transfer annotation which is set on the Subnamespace together with the
+	// change of its quota. It is recorded right before the step is applied or reverted, so that a change which
+	// was already made is not made again after an interruption
+	Marker string `json:"marker,omitempty"`
+
+	// Done is true once the step is applied
+	Done bool `json:"done,omitempty"`
 }
 
 // +kubebuilder:object:root=true
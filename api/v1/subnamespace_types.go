@@ -16,6 +16,7 @@ package v1
 
 import (
 	v1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -42,6 +43,56 @@ type Total struct {
 	Free v1.ResourceList `json:"free,omitempty"`
 }
 
+// OwnerKind is the kind of subject an owner of a Subnamespace is.
+// +kubebuilder:validation:Enum=User;Group;ServiceAccount
+type OwnerKind string
+
+const (
+	UserOwner           OwnerKind = "User"
+	GroupOwner          OwnerKind = "Group"
+	ServiceAccountOwner OwnerKind = "ServiceAccount"
+)
+
+// SubnamespaceOwner is a subject that is granted a role in the namespace bound to a Subnamespace
+type SubnamespaceOwner struct {
+	// Kind is the kind of the subject, which can be a User, a Group or a ServiceAccount
+	Kind OwnerKind `json:"kind"`
+
+	// Name is the name of the subject
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+
+	// Namespace is the namespace of a ServiceAccount subject. If it's empty, the ServiceAccount is
+	// taken from the namespace bound to the Subnamespace
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+
+	// Role is the name of the ClusterRole the subject is granted in the namespace bound to the Subnamespace
+	// +kubebuilder:validation:MinLength=1
+	Role string `json:"role"`
+}
+
+// InheritancePolicy controls which of the RoleBindings of its ancestors the namespace bound to a Subnamespace inherits
+type InheritancePolicy struct {
+	// BlockAll stops the inheritance of all the RoleBindings of the ancestors of the Subnamespace, so that
+	// neither the namespace bound to the Subnamespace nor its descendants inherit any of them
+	// +optional
+	BlockAll bool `json:"blockAll,omitempty"`
+
+	// ExcludedNames is a list of names of RoleBindings of the ancestors of the Subnamespace which are not inherited
+	// +optional
+	ExcludedNames []string `json:"excludedNames,omitempty"`
+
+	// ExcludedSubjects is a list of subjects whose RoleBindings in the ancestors of the Subnamespace are not inherited.
+	// The namespace of a subject is only compared if it's set
+	// +optional
+	ExcludedSubjects []rbacv1.Subject `json:"excludedSubjects,omitempty"`
+
+	// ExcludedRoles is a list of names of roles whose RoleBindings in the ancestors of the Subnamespace are not inherited
+	// +optional
+	ExcludedRoles []string `json:"excludedRoles,omitempty"`
+}
+
 // SubnamespaceSpec defines the desired state of Subnamespace
 type SubnamespaceSpec struct {
 	// ResourceQuotaSpec represents the limitations that are associated with the Subnamespace.
@@ -49,6 +100,25 @@ type SubnamespaceSpec struct {
 	// and the overall maximum quota consumption of the current Subnamespace and its children.
 	ResourceQuotaSpec v1.ResourceQuotaSpec `json:"resourcequota,omitempty"`
 
+	// LimitRangeSpec represents the LimitRange policy of the Subnamespace. The policy is inherited by
+	// all the descendants of the Subnamespace, which may only tighten it and never loosen it. The LimitRange
+	// of the namespace bound to the Subnamespace is the HNSConfig LimitRange tightened by the policies
+	// of all of its ancestors and its own policy.
+	// +optional
+	LimitRangeSpec *v1.LimitRangeSpec `json:"limitrange,omitempty"`
+
+	// Owners is a list of subjects which are granted a role in the namespace bound to the Subnamespace.
+	// HNS creates a RoleBinding for every owner, which is inherited by all the descendants of the Subnamespace
+	// like any other RoleBinding, and keeps the RoleBindings in sync with the list.
+	// +optional
+	Owners []SubnamespaceOwner `json:"owners,omitempty"`
+
+	// InheritancePolicy controls which of the RoleBindings of its ancestors are inherited by the namespace bound to
+	// the Subnamespace, and therefore by its descendants. RoleBindings which are excluded by the policy are removed
+	// from the namespace. Only members of the permitted groups of the HNSConfig may set the policy.
+	// +optional
+	InheritancePolicy *InheritancePolicy `json:"inheritance,omitempty"`
+
 	// The name of the namespace that this Subnamespace is bound to
 	NamespaceRef namespaceRef `json:"namespaceRef,omitempty"`
 }
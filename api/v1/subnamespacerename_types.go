@@ -0,0 +1,82 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// SubnamespaceRenameSpec defines the desired state of SubnamespaceRename
+type SubnamespaceRenameSpec struct {
+	// CurrentNamespace is the name of the Subnamespace that is being renamed
+	CurrentNamespace string `json:"currentns"`
+
+	// NewNamespace is the new name of the Subnamespace
+	NewNamespace string `json:"newns"`
+
+	// Revert asks to undo the steps of a rename which failed and to return the objects and the children
+	// of the Subnamespace to the old namespace. It can only be set after the rename has failed and as long
+	// as the old namespace was not deleted
+	Revert bool `json:"revert,omitempty"`
+}
+
+// SubnamespaceRenameStatus defines the observed state of SubnamespaceRename
+type SubnamespaceRenameStatus struct {
+	// Phase acts like a state machine for the SubnamespaceRename.
+	// It is a string and can be one of the following:
+	// "InProgress" - state for a SubnamespaceRename indicating that the operation is in progress
+	// "Error" - state for a SubnamespaceRename indicating that the operation could not be completed due to an error
+	// "Complete" - state for a SubnamespaceRename indicating that the operation completed successfully
+	// "Reverting" - state for a SubnamespaceRename indicating that a failed operation is being reverted
+	// "Reverted" - state for a SubnamespaceRename indicating that a failed operation was reverted
+	Phase Phase `json:"phase,omitempty"`
+
+	// Step is the last step of the rename which was completed. It can be one of the following, in order:
+	// "NewNSCreated", "ObjectsMoved", "ChildrenMoved", "DBRenamed", "OldNSDeleted"
+	Step RenameStep `json:"step,omitempty"`
+
+	// Reason is a string explaining why an error occurred if it did; otherwise it’s empty
+	Reason string `json:"reason,omitempty"`
+
+	// NotMoved lists the objects of the old namespace, as "<kind>/<name>", which are not moved
+	// to the new namespace by the rename and are deleted along with the old namespace
+	// +optional
+	NotMoved []string `json:"notMoved,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:scope=Cluster
+
+// SubnamespaceRename is the Schema for the subnamespacerenames API
+type SubnamespaceRename struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   SubnamespaceRenameSpec   `json:"spec,omitempty"`
+	Status SubnamespaceRenameStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// SubnamespaceRenameList contains a list of SubnamespaceRename
+type SubnamespaceRenameList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []SubnamespaceRename `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&SubnamespaceRename{}, &SubnamespaceRenameList{})
+}
@@ -17,11 +17,45 @@ const (
 	Created    Phase = "Created"
 	None       Phase = ""
 	Migrated   Phase = "Migrated"
+	Pending    Phase = "Pending"
 	Complete   Phase = "Complete"
 	InProgress Phase = "InProgress"
 	Error      Phase = "Error"
+	Reverting  Phase = "Reverting"
+	Reverted   Phase = "Reverted"
 )
 
+// MigrationStep is the last step of a migration which was completed. The steps are completed in the
+// order they are declared in, and a migration which was interrupted is resumed after its last step.
+type MigrationStep string
+
+const (
+	QuotaReserved  MigrationStep = "QuotaReserved"
+	NewSNSCreated  MigrationStep = "NewSNSCreated"
+	OldSNSDeleted  MigrationStep = "OldSNSDeleted"
+	RelatedUpdated MigrationStep = "RelatedUpdated"
+	DBUpdated      MigrationStep = "DBUpdated"
+	QuotaReleased  MigrationStep = "QuotaReleased"
+)
+
+// MigrationSteps is the list of the steps of a migration, in the order they are completed in
+var MigrationSteps = []MigrationStep{QuotaReserved, NewSNSCreated, OldSNSDeleted, RelatedUpdated, DBUpdated, QuotaReleased}
+
+// RenameStep is the last step of a rename which was completed. The steps are completed in the
+// order they are declared in, and a rename which was interrupted is resumed after its last step.
+type RenameStep string
+
+const (
+	NewNSCreated  RenameStep = "NewNSCreated"
+	ObjectsMoved  RenameStep = "ObjectsMoved"
+	ChildrenMoved RenameStep = "ChildrenMoved"
+	DBRenamed     RenameStep = "DBRenamed"
+	OldNSDeleted  RenameStep = "OldNSDeleted"
+)
+
+// RenameSteps is the list of the steps of a rename, in the order they are completed in
+var RenameSteps = []RenameStep{NewNSCreated, ObjectsMoved, ChildrenMoved, DBRenamed, OldNSDeleted}
+
 const (
 	Root   string = "root"
 	NoRole string = "none"
@@ -42,26 +76,43 @@ const (
 )
 
 const (
-	Hns          = MetaGroup + "subnamespace"
-	Parent       = MetaGroup + "parent"
-	ResourcePool = MetaGroup + "resourcepool"
+	Hns                 = MetaGroup + "subnamespace"
+	Parent              = MetaGroup + "parent"
+	ResourcePool        = MetaGroup + "resourcepool"
+	BatchMigrationLabel = MetaGroup + "batch-migration"
+	InheritedFromUID    = MetaGroup + "inherited-from-uid"
+	OwnerRoleBinding    = MetaGroup + "owner"
+	HNSViewSubject      = MetaGroup + "hns-view-subject"
 )
 
+// TreeLabelSuffix is the suffix of the tree labels of a namespace. A namespace has a
+// "<ancestor>.tree.dana.hns.io/depth" label for itself and for each of its ancestors,
+// whose value is the distance of the namespace from that ancestor.
+const TreeLabelSuffix = ".tree.dana.hns.io/depth"
+
 const (
-	Role                 = MetaGroup + "role"
-	Depth                = MetaGroup + "depth"
-	CrqSelector          = MetaGroup + "crq-selector"
-	RootCrqSelector      = CrqSelector + "-0"
-	SnsPointer           = MetaGroup + "sns-pointer"
-	RqDepth              = MetaGroup + "rq-depth"
-	IsRq                 = MetaGroup + "is-rq"
-	IsSecondaryRoot      = MetaGroup + "is-secondary-root"
-	IsUpperRp            = MetaGroup + "is-upper-rp"
-	UpperRp              = MetaGroup + "upper-rp"
-	CrqPointer           = MetaGroup + "crq-pointer"
-	DisplayName          = MetaGroup + "display-name"
-	Description          = MetaGroup + "description"
-	OpenShiftDisplayName = "openshift.io/display-name"
+	Role                  = MetaGroup + "role"
+	Depth                 = MetaGroup + "depth"
+	CrqSelector           = MetaGroup + "crq-selector"
+	RootCrqSelector       = CrqSelector + "-0"
+	SnsPointer            = MetaGroup + "sns-pointer"
+	RqDepth               = MetaGroup + "rq-depth"
+	IsRq                  = MetaGroup + "is-rq"
+	IsSecondaryRoot       = MetaGroup + "is-secondary-root"
+	IsUpperRp             = MetaGroup + "is-upper-rp"
+	UpperRp               = MetaGroup + "upper-rp"
+	CrqPointer            = MetaGroup + "crq-pointer"
+	DisplayName           = MetaGroup + "display-name"
+	Description           = MetaGroup + "description"
+	PreviousNames         = MetaGroup + "previous-names"
+	InheritedFrom         = MetaGroup + "inherited-from"
+	InheritedFromName     = MetaGroup + "inherited-from-name"
+	OriginalReclaimPolicy = MetaGroup + "original-reclaim-policy"
+	OriginalReplicas      = MetaGroup + "original-replicas"
+	AppliedRootQuota      = MetaGroup + "applied-root-quota"
+	QuotaTransferMarker   = MetaGroup + "quota-transfer"
+	OpenShiftDisplayName  = "openshift.io/display-name"
+	Requester             = "requester"
 )
 
 const (
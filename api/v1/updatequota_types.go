@@ -36,11 +36,13 @@ type UpdatequotaSpec struct {
 type UpdatequotaStatus struct {
 	// Phase acts like a state machine for the Updatequota.
 	// It is a string and can be one of the following:
+	// "Pending" - state for an Updatequota waiting for Updatequotas which share a Subnamespace on its path to complete
 	// "Error" - state for an Updatequota indicating that the operation could not be completed due to an error
 	// "Complete" - state for an Updatequota indicating that the operation completed successfully
 	Phase Phase `json:"phase,omitempty"`
 
-	// Reason is a string explaining why an error occurred if it did; otherwise it’s empty
+	// Reason is a string explaining why an error occurred if it did, or the position of a Pending Updatequota
+	// in the queue of Updatequotas which share a Subnamespace on its path; otherwise it’s empty
 	Reason string `json:"reason,omitempty"`
 }
 
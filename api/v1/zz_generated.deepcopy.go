@@ -22,9 +22,124 @@ package v1
 
 import (
 	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AnnotationChange) DeepCopyInto(out *AnnotationChange) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AnnotationChange.
+func (in *AnnotationChange) DeepCopy() *AnnotationChange {
+	if in == nil {
+		return nil
+	}
+	out := new(AnnotationChange)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BatchMigration) DeepCopyInto(out *BatchMigration) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BatchMigration.
+func (in *BatchMigration) DeepCopy() *BatchMigration {
+	if in == nil {
+		return nil
+	}
+	out := new(BatchMigration)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *BatchMigration) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BatchMigrationList) DeepCopyInto(out *BatchMigrationList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]BatchMigration, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BatchMigrationList.
+func (in *BatchMigrationList) DeepCopy() *BatchMigrationList {
+	if in == nil {
+		return nil
+	}
+	out := new(BatchMigrationList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *BatchMigrationList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BatchMigrationSpec) DeepCopyInto(out *BatchMigrationSpec) {
+	*out = *in
+	if in.Migrations != nil {
+		in, out := &in.Migrations, &out.Migrations
+		*out = make([]Migration, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BatchMigrationSpec.
+func (in *BatchMigrationSpec) DeepCopy() *BatchMigrationSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(BatchMigrationSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BatchMigrationStatus) DeepCopyInto(out *BatchMigrationStatus) {
+	*out = *in
+	if in.Migrations != nil {
+		in, out := &in.Migrations, &out.Migrations
+		*out = make([]MigrationStatus, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BatchMigrationStatus.
+func (in *BatchMigrationStatus) DeepCopy() *BatchMigrationStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(BatchMigrationStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *HNSConfig) DeepCopyInto(out *HNSConfig) {
 	*out = *in
@@ -98,6 +213,32 @@ func (in *HNSConfigSpec) DeepCopyInto(out *HNSConfigSpec) {
 		copy(*out, *in)
 	}
 	in.LimitRange.DeepCopyInto(&out.LimitRange)
+	if in.ResourceAliases != nil {
+		in, out := &in.ResourceAliases, &out.ResourceAliases
+		*out = make(map[string][]string, len(*in))
+		for key, val := range *in {
+			var outVal []string
+			if val == nil {
+				(*out)[key] = nil
+			} else {
+				inVal := (*in)[key]
+				in, out := &inVal, &outVal
+				*out = make([]string, len(*in))
+				copy(*out, *in)
+			}
+			(*out)[key] = outVal
+		}
+	}
+	if in.ExcludedServiceAccounts != nil {
+		in, out := &in.ExcludedServiceAccounts, &out.ExcludedServiceAccounts
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ExcludedGroupPrefixes != nil {
+		in, out := &in.ExcludedGroupPrefixes, &out.ExcludedGroupPrefixes
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HNSConfigSpec.
@@ -125,6 +266,131 @@ func (in *HNSConfigStatus) DeepCopy() *HNSConfigStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HierarchyRoot) DeepCopyInto(out *HierarchyRoot) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	out.Status = in.Status
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HierarchyRoot.
+func (in *HierarchyRoot) DeepCopy() *HierarchyRoot {
+	if in == nil {
+		return nil
+	}
+	out := new(HierarchyRoot)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *HierarchyRoot) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HierarchyRootList) DeepCopyInto(out *HierarchyRootList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]HierarchyRoot, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HierarchyRootList.
+func (in *HierarchyRootList) DeepCopy() *HierarchyRootList {
+	if in == nil {
+		return nil
+	}
+	out := new(HierarchyRootList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *HierarchyRootList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HierarchyRootSpec) DeepCopyInto(out *HierarchyRootSpec) {
+	*out = *in
+	in.ResourceQuotaSpec.DeepCopyInto(&out.ResourceQuotaSpec)
+	if in.SecondaryRoots != nil {
+		in, out := &in.SecondaryRoots, &out.SecondaryRoots
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HierarchyRootSpec.
+func (in *HierarchyRootSpec) DeepCopy() *HierarchyRootSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(HierarchyRootSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HierarchyRootStatus) DeepCopyInto(out *HierarchyRootStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HierarchyRootStatus.
+func (in *HierarchyRootStatus) DeepCopy() *HierarchyRootStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(HierarchyRootStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *InheritancePolicy) DeepCopyInto(out *InheritancePolicy) {
+	*out = *in
+	if in.ExcludedNames != nil {
+		in, out := &in.ExcludedNames, &out.ExcludedNames
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ExcludedSubjects != nil {
+		in, out := &in.ExcludedSubjects, &out.ExcludedSubjects
+		*out = make([]rbacv1.Subject, len(*in))
+		copy(*out, *in)
+	}
+	if in.ExcludedRoles != nil {
+		in, out := &in.ExcludedRoles, &out.ExcludedRoles
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InheritancePolicy.
+func (in *InheritancePolicy) DeepCopy() *InheritancePolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(InheritancePolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *LimitRangeSettings) DeepCopyInto(out *LimitRangeSettings) {
 	*out = *in
@@ -175,13 +441,28 @@ func (in *LimitRangeSettings) DeepCopy() *LimitRangeSettings {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Migration) DeepCopyInto(out *Migration) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Migration.
+func (in *Migration) DeepCopy() *Migration {
+	if in == nil {
+		return nil
+	}
+	out := new(Migration)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *MigrationHierarchy) DeepCopyInto(out *MigrationHierarchy) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	out.Spec = in.Spec
-	out.Status = in.Status
+	in.Status.DeepCopyInto(&out.Status)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MigrationHierarchy.
@@ -252,6 +533,16 @@ func (in *MigrationHierarchySpec) DeepCopy() *MigrationHierarchySpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *MigrationHierarchyStatus) DeepCopyInto(out *MigrationHierarchyStatus) {
 	*out = *in
+	if in.QuotaTransfer != nil {
+		in, out := &in.QuotaTransfer, &out.QuotaTransfer
+		*out = new(QuotaTransfer)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Preview != nil {
+		in, out := &in.Preview, &out.Preview
+		*out = new(MigrationPreview)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MigrationHierarchyStatus.
@@ -264,6 +555,61 @@ func (in *MigrationHierarchyStatus) DeepCopy() *MigrationHierarchyStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MigrationPreview) DeepCopyInto(out *MigrationPreview) {
+	*out = *in
+	if in.Namespaces != nil {
+		in, out := &in.Namespaces, &out.Namespaces
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.GainedRoleBindings != nil {
+		in, out := &in.GainedRoleBindings, &out.GainedRoleBindings
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.LostRoleBindings != nil {
+		in, out := &in.LostRoleBindings, &out.LostRoleBindings
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.AnnotationChanges != nil {
+		in, out := &in.AnnotationChanges, &out.AnnotationChanges
+		*out = make([]AnnotationChange, len(*in))
+		copy(*out, *in)
+	}
+	if in.QuotaTransfer != nil {
+		in, out := &in.QuotaTransfer, &out.QuotaTransfer
+		*out = new(QuotaTransfer)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MigrationPreview.
+func (in *MigrationPreview) DeepCopy() *MigrationPreview {
+	if in == nil {
+		return nil
+	}
+	out := new(MigrationPreview)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MigrationStatus) DeepCopyInto(out *MigrationStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MigrationStatus.
+func (in *MigrationStatus) DeepCopy() *MigrationStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(MigrationStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Namespaces) DeepCopyInto(out *Namespaces) {
 	*out = *in
@@ -280,6 +626,53 @@ func (in *Namespaces) DeepCopy() *Namespaces {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *QuotaTransfer) DeepCopyInto(out *QuotaTransfer) {
+	*out = *in
+	if in.Resources != nil {
+		in, out := &in.Resources, &out.Resources
+		*out = make(corev1.ResourceList, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val.DeepCopy()
+		}
+	}
+	if in.Release != nil {
+		in, out := &in.Release, &out.Release
+		*out = make([]QuotaTransferStep, len(*in))
+		copy(*out, *in)
+	}
+	if in.Reserve != nil {
+		in, out := &in.Reserve, &out.Reserve
+		*out = make([]QuotaTransferStep, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new QuotaTransfer.
+func (in *QuotaTransfer) DeepCopy() *QuotaTransfer {
+	if in == nil {
+		return nil
+	}
+	out := new(QuotaTransfer)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *QuotaTransferStep) DeepCopyInto(out *QuotaTransferStep) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new QuotaTransferStep.
+func (in *QuotaTransferStep) DeepCopy() *QuotaTransferStep {
+	if in == nil {
+		return nil
+	}
+	out := new(QuotaTransferStep)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Subnamespace) DeepCopyInto(out *Subnamespace) {
 	*out = *in
@@ -339,10 +732,134 @@ func (in *SubnamespaceList) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SubnamespaceOwner) DeepCopyInto(out *SubnamespaceOwner) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SubnamespaceOwner.
+func (in *SubnamespaceOwner) DeepCopy() *SubnamespaceOwner {
+	if in == nil {
+		return nil
+	}
+	out := new(SubnamespaceOwner)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SubnamespaceRename) DeepCopyInto(out *SubnamespaceRename) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	out.Spec = in.Spec
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SubnamespaceRename.
+func (in *SubnamespaceRename) DeepCopy() *SubnamespaceRename {
+	if in == nil {
+		return nil
+	}
+	out := new(SubnamespaceRename)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *SubnamespaceRename) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SubnamespaceRenameList) DeepCopyInto(out *SubnamespaceRenameList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]SubnamespaceRename, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SubnamespaceRenameList.
+func (in *SubnamespaceRenameList) DeepCopy() *SubnamespaceRenameList {
+	if in == nil {
+		return nil
+	}
+	out := new(SubnamespaceRenameList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *SubnamespaceRenameList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SubnamespaceRenameSpec) DeepCopyInto(out *SubnamespaceRenameSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SubnamespaceRenameSpec.
+func (in *SubnamespaceRenameSpec) DeepCopy() *SubnamespaceRenameSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(SubnamespaceRenameSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SubnamespaceRenameStatus) DeepCopyInto(out *SubnamespaceRenameStatus) {
+	*out = *in
+	if in.NotMoved != nil {
+		in, out := &in.NotMoved, &out.NotMoved
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SubnamespaceRenameStatus.
+func (in *SubnamespaceRenameStatus) DeepCopy() *SubnamespaceRenameStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(SubnamespaceRenameStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *SubnamespaceSpec) DeepCopyInto(out *SubnamespaceSpec) {
 	*out = *in
 	in.ResourceQuotaSpec.DeepCopyInto(&out.ResourceQuotaSpec)
+	if in.LimitRangeSpec != nil {
+		in, out := &in.LimitRangeSpec, &out.LimitRangeSpec
+		*out = new(corev1.LimitRangeSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Owners != nil {
+		in, out := &in.Owners, &out.Owners
+		*out = make([]SubnamespaceOwner, len(*in))
+		copy(*out, *in)
+	}
+	if in.InheritancePolicy != nil {
+		in, out := &in.InheritancePolicy, &out.InheritancePolicy
+		*out = new(InheritancePolicy)
+		(*in).DeepCopyInto(*out)
+	}
 	out.NamespaceRef = in.NamespaceRef
 }
 
@@ -18,6 +18,7 @@ import (
 	"crypto/tls"
 	"flag"
 	"os"
+	"time"
 
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
 
@@ -55,6 +56,8 @@ var (
 	noWebhooks           bool
 	onlyResourcePool     bool
 	maxSNS               int
+	parentSyncWindow     time.Duration
+	upqMaxConcurrent     int
 	secureMetrics        bool
 	enableHTTP2          bool
 	tlsOpts              []func(*tls.Config)
@@ -154,13 +157,15 @@ func main() {
 	}
 
 	hnsOpts := setup.Options{
-		NoWebhooks:        noWebhooks,
-		OnlyResourcePool:  onlyResourcePool,
-		MaxSNSInHierarchy: maxSNS,
+		NoWebhooks:                 noWebhooks,
+		OnlyResourcePool:           onlyResourcePool,
+		MaxSNSInHierarchy:          maxSNS,
+		ParentSyncWindow:           parentSyncWindow,
+		UPQMaxConcurrentReconciles: upqMaxConcurrent,
 	}
 
 	setupLog.Info("setting up reconcilers")
-	if err := setup.Controllers(mgr, ndb); err != nil {
+	if err := setup.Controllers(mgr, ndb, hnsOpts); err != nil {
 		setupLog.Error(err, "unable to successfully set up controllers")
 		os.Exit(1)
 	}
@@ -199,6 +204,10 @@ func parseFlags() {
 	flag.BoolVar(&noWebhooks, "no-webhooks", false, "Disables webhooks")
 	flag.BoolVar(&onlyResourcePool, "only-resourcepool", false, "Only allow creation of resourcepools")
 	flag.IntVar(&maxSNS, "max-sns", 250, "The maximum number of subnamespaces under a single CRQ")
+	flag.DurationVar(&parentSyncWindow, "parent-sync-window", time.Second,
+		"The window in which the reconciliations of a subnamespace triggered by its children are coalesced")
+	flag.IntVar(&upqMaxConcurrent, "upq-max-concurrent-reconciles", 1,
+		"The maximum number of UpdateQuotas which are reconciled concurrently. UpdateQuotas which share a subnamespace on their path are never reconciled concurrently")
 
 	flag.Parse()
 }
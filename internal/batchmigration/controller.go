@@ -0,0 +1,281 @@
+package batchmigration
+
+import (
+	"context"
+	"fmt"
+
+	danav1 "github.com/dana-team/hns/api/v1"
+	"github.com/dana-team/hns/internal/common"
+	"github.com/dana-team/hns/internal/namespace/nsutils"
+	"github.com/dana-team/hns/internal/objectcontext"
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// BatchMigrationReconciler reconciles a BatchMigration object
+type BatchMigrationReconciler struct {
+	client.Client
+	Scheme *runtime.Scheme
+}
+
+// +kubebuilder:rbac:groups=dana.hns.io,resources=batchmigrations,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=dana.hns.io,resources=batchmigrations/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=dana.hns.io,resources=migrationhierarchies,verbs=get;list;watch;create;update;patch;delete
+
+func (r *BatchMigrationReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&danav1.BatchMigration{}).
+		Owns(&danav1.MigrationHierarchy{}).
+		Complete(r)
+}
+
+func (r *BatchMigrationReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := log.FromContext(ctx).WithName("controllers").WithName("BatchMigration").WithValues("bm", req.NamespacedName)
+	logger.Info("starting to reconcile")
+
+	batchObject, err := objectcontext.New(ctx, r.Client, client.ObjectKey{Name: req.NamespacedName.Name}, &danav1.BatchMigration{})
+	if err != nil {
+		return ctrl.Result{}, fmt.Errorf("failed to get object %q: %v", req.NamespacedName, err.Error())
+	}
+
+	if !batchObject.IsPresent() {
+		logger.Info("resource not found. Ignoring since object must be deleted")
+		return ctrl.Result{}, nil
+	}
+
+	phase := batchObject.Object.(*danav1.BatchMigration).Status.Phase
+	if common.ShouldReconcile(phase) {
+		if err := r.reconcile(batchObject); err != nil {
+			if updateErr := updateBatchStatus(batchObject, danav1.Error, err.Error()); updateErr != nil {
+				return ctrl.Result{}, updateErr
+			}
+			return ctrl.Result{}, err
+		}
+	} else {
+		logger.Info("no need to reconcile, object phase is: ", "phase", phase)
+	}
+
+	return ctrl.Result{}, nil
+}
+
+// reconcile executes the migrations of a BatchMigration one at a time, by creating a MigrationHierarchy
+// owned by the BatchMigration for every migration and waiting for it to complete before moving on to the
+// next one. The BatchMigration is reconciled again whenever one of its MigrationHierarchies changes.
+func (r *BatchMigrationReconciler) reconcile(batchObject *objectcontext.ObjectContext) error {
+	ctx := batchObject.Ctx
+	logger := log.FromContext(ctx)
+
+	if batchObject.Object.(*danav1.BatchMigration).Status.Phase == danav1.None {
+		if err := initBatchStatus(batchObject); err != nil {
+			return err
+		}
+		logger.Info("successfully updated status of BatchMigration object", "phase", danav1.InProgress)
+	}
+
+	migrations := batchObject.Object.(*danav1.BatchMigration).Status.Migrations
+
+	for i, migration := range migrations {
+		if migration.Phase != danav1.InProgress {
+			continue
+		}
+
+		mhObject, err := objectcontext.New(ctx, r.Client, client.ObjectKey{Name: migration.MigrationHierarchy}, &danav1.MigrationHierarchy{})
+		if err != nil {
+			return fmt.Errorf("failed getting MigrationHierarchy object %q: %v", migration.MigrationHierarchy, err.Error())
+		}
+		if !mhObject.IsPresent() {
+			return fmt.Errorf("MigrationHierarchy %q of migration of %q does not exist", migration.MigrationHierarchy, migration.CurrentNamespace)
+		}
+
+		mhStatus := mhObject.Object.(*danav1.MigrationHierarchy).Status
+		switch mhStatus.Phase {
+		case danav1.Complete:
+			if err := updateMigrationStatus(batchObject, i, danav1.Complete, ""); err != nil {
+				return err
+			}
+			logger.Info("successfully completed migration", "subnamespace", migration.CurrentNamespace, "parent", migration.ToNamespace)
+		case danav1.Error:
+			if err := updateMigrationStatus(batchObject, i, danav1.Error, mhStatus.Reason); err != nil {
+				return err
+			}
+			return fmt.Errorf("failed migrating %q to %q: %v", migration.CurrentNamespace, migration.ToNamespace, mhStatus.Reason)
+		default:
+			logger.Info("waiting for migration to complete", "subnamespace", migration.CurrentNamespace, "migrationHierarchy", migration.MigrationHierarchy)
+			return nil
+		}
+	}
+
+	next, err := r.nextMigration(batchObject)
+	if err != nil {
+		return err
+	}
+
+	if next == -1 {
+		if err := updateBatchStatus(batchObject, danav1.Complete, ""); err != nil {
+			return err
+		}
+		logger.Info("successfully updated status of BatchMigration object", "phase", danav1.Complete)
+		return nil
+	}
+
+	if err := r.startMigration(batchObject, next); err != nil {
+		return err
+	}
+	logger.Info("successfully started migration", "subnamespace", migrations[next].CurrentNamespace, "parent", migrations[next].ToNamespace)
+
+	return nil
+}
+
+// nextMigration returns the index of the next pending migration of a BatchMigration which can be safely
+// executed, or -1 if there are no pending migrations. A migration can be safely executed if its new parent
+// is currently not a descendant of the Subnamespace, which may be the case until other migrations of the
+// plan move the new parent out of the branch of the Subnamespace. A pending migration whose MigrationHierarchy
+// was already created, but whose status was not updated, is always returned first.
+func (r *BatchMigrationReconciler) nextMigration(batchObject *objectcontext.ObjectContext) (int, error) {
+	ctx := batchObject.Ctx
+	migrations := batchObject.Object.(*danav1.BatchMigration).Status.Migrations
+
+	for i, migration := range migrations {
+		if migration.Phase != danav1.Pending {
+			continue
+		}
+
+		mhName := migrationHierarchyName(batchObject, i)
+		mhObject, err := objectcontext.New(ctx, r.Client, client.ObjectKey{Name: mhName}, &danav1.MigrationHierarchy{})
+		if err != nil {
+			return -1, fmt.Errorf("failed getting MigrationHierarchy object %q: %v", mhName, err.Error())
+		}
+		if mhObject.IsPresent() {
+			return i, nil
+		}
+	}
+
+	pending := -1
+	for i, migration := range migrations {
+		if migration.Phase != danav1.Pending {
+			continue
+		}
+		if pending == -1 {
+			pending = i
+		}
+
+		toNS, err := objectcontext.New(ctx, r.Client, client.ObjectKey{Name: migration.ToNamespace}, &corev1.Namespace{})
+		if err != nil {
+			return -1, fmt.Errorf("failed getting namespace object %q: %v", migration.ToNamespace, err.Error())
+		}
+		if !toNS.IsPresent() {
+			return -1, fmt.Errorf("namespace %q does not exist", migration.ToNamespace)
+		}
+
+		toNSAncestors, err := nsutils.Ancestors(toNS.Object)
+		if err != nil {
+			return -1, err
+		}
+		if !common.ContainsString(toNSAncestors, migration.CurrentNamespace) {
+			return i, nil
+		}
+	}
+
+	// the plan is validated when the BatchMigration is created, so there is always a migration which
+	// can be executed; if the hierarchy has changed since then, the MigrationHierarchy is denied
+	return pending, nil
+}
+
+// startMigration creates the MigrationHierarchy of a migration of a BatchMigration and marks the migration as InProgress.
+func (r *BatchMigrationReconciler) startMigration(batchObject *objectcontext.ObjectContext, index int) error {
+	migration := batchObject.Object.(*danav1.BatchMigration).Status.Migrations[index]
+	mhName := migrationHierarchyName(batchObject, index)
+
+	composedMH := &danav1.MigrationHierarchy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   mhName,
+			Labels: map[string]string{danav1.BatchMigrationLabel: batchObject.Name()},
+		},
+		Spec: danav1.MigrationHierarchySpec{
+			CurrentNamespace: migration.CurrentNamespace,
+			ToNamespace:      migration.ToNamespace,
+		},
+	}
+	if err := controllerutil.SetControllerReference(batchObject.Object, composedMH, r.Scheme); err != nil {
+		return fmt.Errorf("failed setting owner reference of MigrationHierarchy %q: %v", mhName, err.Error())
+	}
+
+	mhObject, err := objectcontext.New(batchObject.Ctx, r.Client, types.NamespacedName{Name: mhName}, composedMH)
+	if err != nil {
+		return fmt.Errorf("failed getting MigrationHierarchy object %q: %v", mhName, err.Error())
+	}
+	if err := mhObject.EnsureCreate(); err != nil {
+		return fmt.Errorf("failed creating MigrationHierarchy %q: %v", mhName, err.Error())
+	}
+
+	return batchObject.UpdateObject(func(object client.Object, l logr.Logger) (client.Object, logr.Logger) {
+		object.(*danav1.BatchMigration).Status.Migrations[index].MigrationHierarchy = mhName
+		object.(*danav1.BatchMigration).Status.Migrations[index].Phase = danav1.InProgress
+		return object, l
+	})
+}
+
+// migrationHierarchyName returns the name of the MigrationHierarchy of a migration of a BatchMigration.
+func migrationHierarchyName(batchObject *objectcontext.ObjectContext, index int) string {
+	return fmt.Sprintf("%s-%d", batchObject.Name(), index)
+}
+
+// initBatchStatus sets the phase of the BatchMigration object to InProgress and all its migrations to Pending.
+func initBatchStatus(batchObject *objectcontext.ObjectContext) error {
+	err := batchObject.UpdateObject(func(object client.Object, l logr.Logger) (client.Object, logr.Logger) {
+		batch := object.(*danav1.BatchMigration)
+		batch.Status.Phase = danav1.InProgress
+		batch.Status.Migrations = make([]danav1.MigrationStatus, 0, len(batch.Spec.Migrations))
+		for _, migration := range batch.Spec.Migrations {
+			batch.Status.Migrations = append(batch.Status.Migrations, danav1.MigrationStatus{
+				CurrentNamespace: migration.CurrentNamespace,
+				ToNamespace:      migration.ToNamespace,
+				Phase:            danav1.Pending,
+			})
+		}
+		return object, l
+	})
+
+	if err != nil {
+		return fmt.Errorf("failed updating the status of object %q: %v", batchObject.Name(), err.Error())
+	}
+
+	return nil
+}
+
+// updateMigrationStatus updates the status of a single migration of the BatchMigration object.
+func updateMigrationStatus(batchObject *objectcontext.ObjectContext, index int, phase danav1.Phase, reason string) error {
+	err := batchObject.UpdateObject(func(object client.Object, l logr.Logger) (client.Object, logr.Logger) {
+		object.(*danav1.BatchMigration).Status.Migrations[index].Phase = phase
+		object.(*danav1.BatchMigration).Status.Migrations[index].Reason = reason
+		return object, l
+	})
+
+	if err != nil {
+		return fmt.Errorf("failed updating the status of object %q: %v", batchObject.Name(), err.Error())
+	}
+
+	return nil
+}
+
+// updateBatchStatus updates the status of the BatchMigration object.
+func updateBatchStatus(batchObject *objectcontext.ObjectContext, phase danav1.Phase, reason string) error {
+	err := batchObject.UpdateObject(func(object client.Object, l logr.Logger) (client.Object, logr.Logger) {
+		object.(*danav1.BatchMigration).Status.Phase = phase
+		object.(*danav1.BatchMigration).Status.Reason = reason
+		return object, l
+	})
+
+	if err != nil {
+		return fmt.Errorf("failed updating the status of object %q: %v", batchObject.Name(), err.Error())
+	}
+
+	return nil
+}
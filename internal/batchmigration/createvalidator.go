@@ -0,0 +1,162 @@
+package batchmigration
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	danav1 "github.com/dana-team/hns/api/v1"
+	"github.com/dana-team/hns/internal/migrationhierarchy"
+	"github.com/dana-team/hns/internal/namespace/nsutils"
+	"github.com/dana-team/hns/internal/objectcontext"
+	"github.com/dana-team/hns/internal/subnamespace/resourcepool"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+// handleCreate validates the whole plan of a BatchMigration before any of its migrations is executed.
+func (v *BatchMigrationValidator) handleCreate(batchObject *objectcontext.ObjectContext, userInfo authenticationv1.UserInfo) admission.Response {
+	ctx := batchObject.Ctx
+	migrations := batchObject.Object.(*danav1.BatchMigration).Spec.Migrations
+
+	if response := validateMigrationsUnique(migrations); !response.Allowed {
+		return response
+	}
+
+	// each migration is validated on its own against the current hierarchy, except for loops
+	// which depend on the other migrations of the plan and are validated against the planned hierarchy
+	mhValidator := migrationhierarchy.MigrationHierarchyValidator{
+		Client:      v.Client,
+		Decoder:     v.Decoder,
+		NamespaceDB: v.NamespaceDB,
+		MaxSNS:      v.MaxSNS,
+		Authorizer:  v.Authorizer,
+	}
+	for _, migration := range migrations {
+		response := mhValidator.ValidateMigration(ctx, migration.CurrentNamespace, migration.ToNamespace, userInfo, false)
+		if !response.Allowed {
+			return migrationDenied(migration, response)
+		}
+	}
+
+	plan, err := v.newPlan(ctx, migrations)
+	if err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+
+	if response := plan.validateLoops(); !response.Allowed {
+		return response
+	}
+
+	if response := v.validateKeyCounts(ctx, plan); !response.Allowed {
+		return response
+	}
+
+	if response := v.validateResources(ctx, plan); !response.Allowed {
+		return response
+	}
+
+	return admission.Allowed("")
+}
+
+// validateMigrationsUnique validates that the plan is not empty and that every Subnamespace is migrated at most once.
+func validateMigrationsUnique(migrations []danav1.Migration) admission.Response {
+	if len(migrations) == 0 {
+		return admission.Denied("it's forbidden to create a BatchMigration without migrations")
+	}
+
+	migrated := map[string]bool{}
+	for _, migration := range migrations {
+		if migrated[migration.CurrentNamespace] {
+			message := fmt.Sprintf("it's forbidden to migrate Subnamespace %q more than once in the same BatchMigration", migration.CurrentNamespace)
+			return admission.Denied(message)
+		}
+		migrated[migration.CurrentNamespace] = true
+	}
+
+	return admission.Allowed("")
+}
+
+// validateKeyCounts validates that the migrations of the plan will not cause any of the new parents
+// to exceed the maximum limit of namespaces in its hierarchy, counting all the migrations to it together.
+func (v *BatchMigrationValidator) validateKeyCounts(ctx context.Context, plan *plan) admission.Response {
+	addedToKey := map[string]int{}
+
+	for _, migration := range plan.migrations {
+		isCurrentNSResourcePool, err := resourcepool.IsNSResourcePool(plan.namespaces[migration.CurrentNamespace])
+		if err != nil {
+			return admission.Errored(http.StatusBadRequest, err)
+		}
+		isToNSResourcePool, err := resourcepool.IsNSResourcePool(plan.namespaces[migration.ToNamespace])
+		if err != nil {
+			return admission.Errored(http.StatusBadRequest, err)
+		}
+		if isCurrentNSResourcePool || isToNSResourcePool {
+			continue
+		}
+
+		// every migration is looked up in the hierarchy of its own root, since the migrations of a
+		// BatchMigration may span the hierarchies of several roots
+		currentRoot, err := nsutils.Root(plan.namespaces[migration.CurrentNamespace].Object)
+		if err != nil {
+			return admission.Errored(http.StatusBadRequest, err)
+		}
+		currentNSKey := v.NamespaceDB.Key(currentRoot, migration.CurrentNamespace)
+
+		toRoot, err := nsutils.Root(plan.namespaces[migration.ToNamespace].Object)
+		if err != nil {
+			return admission.Errored(http.StatusBadRequest, err)
+		}
+		toNSKey := v.NamespaceDB.Key(toRoot, migration.ToNamespace)
+		if currentNSKey == "" || toNSKey == "" {
+			continue
+		}
+
+		childrenNum, err := migrationhierarchy.GetNSChildrenNum(ctx, v.Client, migration.CurrentNamespace)
+		if err != nil {
+			return admission.Denied(err.Error())
+		}
+		addedToKey[toNSKey] += childrenNum
+
+		if v.NamespaceDB.KeyCount(toRoot, toNSKey)+addedToKey[toNSKey] >= v.MaxSNS {
+			message := fmt.Sprintf("it's forbidden to create more than %v namespaces under hierarchy %q", v.MaxSNS, toNSKey)
+			return admission.Denied(message)
+		}
+	}
+
+	return admission.Allowed("")
+}
+
+// validateResources validates that the common ancestor of the current and new parents of every migrated
+// Subnamespace has enough free resources for its quota, and that every Subnamespace on the way from a current
+// parent up to the common ancestor has enough resources for the quota of all the Subnamespaces which are
+// migrated out of its branch by the plan, once the resources used by them no longer count towards its usage.
+func (v *BatchMigrationValidator) validateResources(ctx context.Context, plan *plan) admission.Response {
+	quotaPlan := migrationhierarchy.NewQuotaPlan()
+	for _, migration := range plan.migrations {
+		if err := quotaPlan.AddMigration(plan.namespaces[migration.CurrentNamespace], plan.namespaces[migration.ToNamespace]); err != nil {
+			return admission.Errored(http.StatusBadRequest, err)
+		}
+	}
+
+	message, err := quotaPlan.Validate(ctx, v.Client)
+	if err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+	if message != "" {
+		return admission.Denied("it's forbidden to execute the migrations of the BatchMigration since " + message)
+	}
+
+	return admission.Allowed("")
+}
+
+// migrationDenied returns the response of a migration of the plan which was denied.
+func migrationDenied(migration danav1.Migration, response admission.Response) admission.Response {
+	message := fmt.Sprintf("migration of %q to %q is not allowed: %s", migration.CurrentNamespace, migration.ToNamespace, response.Result.Message)
+	if response.Result.Reason != metav1.StatusReasonForbidden {
+		return admission.Errored(response.Result.Code, fmt.Errorf("%s", message))
+	}
+
+	return admission.Denied(message)
+}
@@ -0,0 +1,81 @@
+package batchmigration
+
+import (
+	"context"
+	"fmt"
+
+	danav1 "github.com/dana-team/hns/api/v1"
+	"github.com/dana-team/hns/internal/namespace/nsutils"
+	"github.com/dana-team/hns/internal/objectcontext"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+// plan is the hierarchy of the namespaces which take part in a BatchMigration, as it is before
+// and after all the migrations of the BatchMigration are executed.
+type plan struct {
+	migrations []danav1.Migration
+	namespaces map[string]*objectcontext.ObjectContext
+
+	// parents maps every namespace on the way from the namespaces of the plan up to
+	// the root to its parent, after all the migrations of the plan are executed
+	parents map[string]string
+}
+
+// newPlan returns the plan of the given migrations, based on the current hierarchy.
+func (v *BatchMigrationValidator) newPlan(ctx context.Context, migrations []danav1.Migration) (*plan, error) {
+	p := &plan{
+		migrations: migrations,
+		namespaces: map[string]*objectcontext.ObjectContext{},
+		parents:    map[string]string{},
+	}
+
+	for _, migration := range migrations {
+		for _, nsName := range []string{migration.CurrentNamespace, migration.ToNamespace} {
+			if _, ok := p.namespaces[nsName]; ok {
+				continue
+			}
+
+			ns, err := objectcontext.New(ctx, v.Client, client.ObjectKey{Name: nsName}, &corev1.Namespace{})
+			if err != nil {
+				return nil, fmt.Errorf("failed to get namespace %q: %v", nsName, err.Error())
+			}
+			p.namespaces[nsName] = ns
+
+			ancestors, err := nsutils.Ancestors(ns.Object)
+			if err != nil {
+				return nil, err
+			}
+			for i := 1; i < len(ancestors); i++ {
+				p.parents[ancestors[i]] = ancestors[i-1]
+			}
+		}
+	}
+
+	for _, migration := range migrations {
+		p.parents[migration.CurrentNamespace] = migration.ToNamespace
+	}
+
+	return p, nil
+}
+
+// validateLoops validates that none of the migrations of the plan moves a Subnamespace to be under
+// one of its own descendants, once all the migrations of the plan are executed.
+func (p *plan) validateLoops() admission.Response {
+	for _, migration := range p.migrations {
+		// every namespace is visited at most once on the way up, unless there is a loop
+		nsName := migration.ToNamespace
+		for steps := 0; nsName != "" && steps <= len(p.parents); steps++ {
+			if nsName == migration.CurrentNamespace {
+				message := fmt.Sprintf("it's forbidden to migrate %q to %q, since %q would be a descendant of %q "+
+					"after all the migrations of the BatchMigration", migration.CurrentNamespace, migration.ToNamespace,
+					migration.ToNamespace, migration.CurrentNamespace)
+				return admission.Denied(message)
+			}
+			nsName = p.parents[nsName]
+		}
+	}
+
+	return admission.Allowed("")
+}
@@ -0,0 +1,66 @@
+package batchmigration
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"reflect"
+
+	danav1 "github.com/dana-team/hns/api/v1"
+	"github.com/dana-team/hns/internal/common"
+	"github.com/dana-team/hns/internal/namespacedb"
+	"github.com/dana-team/hns/internal/objectcontext"
+	admissionv1 "k8s.io/api/admission/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+type BatchMigrationValidator struct {
+	Client      client.Client
+	Decoder     admission.Decoder
+	NamespaceDB *namespacedb.NamespaceDB
+	MaxSNS      int
+	Authorizer  common.Authorizer
+}
+
+// +kubebuilder:webhook:path=/validate-v1-batchmigration,mutating=false,sideEffects=NoneOnDryRun,failurePolicy=fail,groups="dana.hns.io",resources=batchmigrations,verbs=create;update,versions=v1,name=batchmigration.dana.io,admissionReviewVersions=v1;v1beta1
+
+// Handle implements the validation webhook.
+func (v *BatchMigrationValidator) Handle(ctx context.Context, req admission.Request) admission.Response {
+	logger := log.FromContext(ctx).WithValues("webhook", "BatchMigration Webhook", "Name", req.Name)
+	logger.Info("webhook request received")
+
+	batchObject, err := objectcontext.New(ctx, v.Client, types.NamespacedName{}, &danav1.BatchMigration{})
+	if err != nil {
+		logger.Error(err, "failed to create object context")
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+
+	if err := v.Decoder.DecodeRaw(req.Object, batchObject.Object); err != nil {
+		logger.Error(err, "failed to decode object", "request object", req.Object)
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+
+	if req.Operation == admissionv1.Create {
+		if response := v.handleCreate(batchObject, req.UserInfo); !response.Allowed {
+			return response
+		}
+	}
+
+	// deny update of the plan of a BatchMigration object after it's already been created
+	if req.Operation == admissionv1.Update {
+		oldBatch := &danav1.BatchMigration{}
+		if err := v.Decoder.DecodeRaw(req.OldObject, oldBatch); err != nil {
+			logger.Error(err, "could not decode object")
+			return admission.Errored(http.StatusBadRequest, err)
+		}
+		if !reflect.DeepEqual(batchObject.Object.(*danav1.BatchMigration).Spec, oldBatch.Spec) {
+			message := fmt.Sprintf("it is forbidden to update an object of type %q", oldBatch.TypeMeta.Kind)
+			return admission.Denied(message)
+		}
+	}
+
+	return admission.Allowed("all validations passed")
+}
@@ -0,0 +1,147 @@
+package common
+
+import (
+	"context"
+	"strings"
+
+	danav1 "github.com/dana-team/hns/api/v1"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	authv1 "k8s.io/api/authorization/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// The virtual verbs on subnamespaces which HNS authorizes. They do not correspond to any request to the API server,
+// and only exist so that the permission to perform an HNS operation in a namespace can be granted on its own, e.g.
+// allowing a user to move quota from and to a namespace without allowing them to deploy workload in it.
+const (
+	TransferQuotaVerb = "transferquota"
+	MigrateVerb       = "migrate"
+	CreateChildVerb   = "createchild"
+)
+
+const (
+	subnamespacesResource = "subnamespaces"
+	clusterRolesResource  = "clusterroles"
+	bindVerb              = "bind"
+)
+
+// Authorizer decides whether a user is allowed to perform an HNS operation in a namespace.
+type Authorizer interface {
+	Authorize(ctx context.Context, userInfo authenticationv1.UserInfo, verb, namespace string) (bool, error)
+
+	// AuthorizeBind returns whether a user is allowed to grant a ClusterRole in a namespace.
+	AuthorizeBind(ctx context.Context, userInfo authenticationv1.UserInfo, clusterRole, namespace string) (bool, error)
+}
+
+// SubjectAccessReviewAuthorizer is an Authorizer which asks the API server whether a user is allowed to
+// perform an HNS operation in a namespace, using a SubjectAccessReview on the virtual verb of the operation.
+type SubjectAccessReviewAuthorizer struct {
+	Client client.Client
+}
+
+// NewSubjectAccessReviewAuthorizer returns a new SubjectAccessReviewAuthorizer.
+func NewSubjectAccessReviewAuthorizer(k8sClient client.Client) *SubjectAccessReviewAuthorizer {
+	return &SubjectAccessReviewAuthorizer{Client: k8sClient}
+}
+
+// Authorize returns true if the user is allowed to use the verb on the subnamespaces of the namespace.
+func (a *SubjectAccessReviewAuthorizer) Authorize(ctx context.Context, userInfo authenticationv1.UserInfo, verb, namespace string) (bool, error) {
+	return a.review(ctx, userInfo, authv1.ResourceAttributes{
+		Namespace: namespace,
+		Verb:      verb,
+		Group:     danav1.GroupVersion.Group,
+		Resource:  subnamespacesResource,
+	})
+}
+
+// AuthorizeBind returns true if the user is allowed to grant the ClusterRole in the namespace, following the
+// same rules the API server uses to prevent privilege escalation through RoleBindings: the user must either
+// be allowed to bind the ClusterRole, or already hold all the permissions the ClusterRole grants in the namespace.
+func (a *SubjectAccessReviewAuthorizer) AuthorizeBind(ctx context.Context, userInfo authenticationv1.UserInfo, clusterRole, namespace string) (bool, error) {
+	allowed, err := a.review(ctx, userInfo, authv1.ResourceAttributes{
+		Namespace: namespace,
+		Verb:      bindVerb,
+		Group:     rbacv1.GroupName,
+		Resource:  clusterRolesResource,
+		Name:      clusterRole,
+	})
+	if err != nil || allowed {
+		return allowed, err
+	}
+
+	role := &rbacv1.ClusterRole{}
+	if err := a.Client.Get(ctx, types.NamespacedName{Name: clusterRole}, role); err != nil {
+		if apierrors.IsNotFound(err) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	// rules of non-resource URLs are ignored, since they can't be granted by a RoleBinding
+	for _, rule := range role.Rules {
+		for _, attributes := range ruleAttributes(rule, namespace) {
+			allowed, err := a.review(ctx, userInfo, attributes)
+			if err != nil || !allowed {
+				return false, err
+			}
+		}
+	}
+
+	return true, nil
+}
+
+// ruleAttributes returns the attributes of every request that a rule of a role allows in a namespace.
+func ruleAttributes(rule rbacv1.PolicyRule, namespace string) []authv1.ResourceAttributes {
+	names := rule.ResourceNames
+	if len(names) == 0 {
+		names = []string{""}
+	}
+
+	var attributes []authv1.ResourceAttributes
+	for _, group := range rule.APIGroups {
+		for _, resource := range rule.Resources {
+			resource, subresource, _ := strings.Cut(resource, "/")
+			for _, verb := range rule.Verbs {
+				for _, name := range names {
+					attributes = append(attributes, authv1.ResourceAttributes{
+						Namespace:   namespace,
+						Verb:        verb,
+						Group:       group,
+						Resource:    resource,
+						Subresource: subresource,
+						Name:        name,
+					})
+				}
+			}
+		}
+	}
+
+	return attributes
+}
+
+// review sends a SubjectAccessReview for the user and the attributes, and returns whether the request is allowed.
+func (a *SubjectAccessReviewAuthorizer) review(ctx context.Context, userInfo authenticationv1.UserInfo, attributes authv1.ResourceAttributes) (bool, error) {
+	extra := map[string]authv1.ExtraValue{}
+	for key, value := range userInfo.Extra {
+		extra[key] = authv1.ExtraValue(value)
+	}
+
+	review := &authv1.SubjectAccessReview{
+		Spec: authv1.SubjectAccessReviewSpec{
+			ResourceAttributes: &attributes,
+			User:               userInfo.Username,
+			Groups:             userInfo.Groups,
+			UID:                userInfo.UID,
+			Extra:              extra,
+		},
+	}
+
+	if err := a.Client.Create(ctx, review); err != nil {
+		return false, err
+	}
+
+	return review.Status.Allowed && !review.Status.Denied, nil
+}
@@ -35,7 +35,7 @@ func ContainsString(slice []string, s string) bool {
 }
 
 // ShouldReconcile returns true if the Phase given as argument is
-// not Complete or Error; meaning that reconciliation needs to take place.
+// not Complete, Error or Reverted; meaning that reconciliation needs to take place.
 func ShouldReconcile(phase danav1.Phase) bool {
-	return phase != danav1.Complete && phase != danav1.Error
+	return phase != danav1.Complete && phase != danav1.Error && phase != danav1.Reverted
 }
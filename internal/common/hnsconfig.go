@@ -35,6 +35,18 @@ const (
 	hnsConfigName = "hns-config"
 )
 
+// SubjectExclusions are the subjects of RoleBindings which are not propagated to the descendants of their namespace.
+type SubjectExclusions struct {
+	ServiceAccounts []string
+	GroupPrefixes   []string
+}
+
+// DefaultSubjectExclusions are the subjects which are excluded unless the HNSConfig sets other exclusions.
+var DefaultSubjectExclusions = SubjectExclusions{
+	ServiceAccounts: []string{"default", "builder", "deployer"},
+	GroupPrefixes:   []string{"system"},
+}
+
 // GetHNSConfigData retrieves the HNSConfig data from the cluster.
 func GetHNSConfigData(ctx context.Context, k8sClient client.Client) (*hnsv1.HNSConfig, error) {
 	HNSConfig := &hnsv1.HNSConfig{}
@@ -54,10 +66,66 @@ func GetObservedResources(ctx context.Context, k8sClient client.Client) (corev1.
 		return corev1.ResourceQuotaSpec{}, fmt.Errorf("failed to get HNSconfig %q: %v", resourcesConfig.Name, err)
 	}
 
+	aliases := resourceAliases(resourcesConfig)
+
 	observedResources := corev1.ResourceList{}
 	for _, resourceName := range resourcesConfig.Spec.ObservedResources {
-		observedResources[corev1.ResourceName(resourceName)] = *ZeroDecimal
+		observedResources[aliases.Canonical(corev1.ResourceName(resourceName))] = *ZeroDecimal
 	}
 
 	return corev1.ResourceQuotaSpec{Hard: observedResources}, nil
 }
+
+// ResourceAliases maps every resource alias to its canonical resource name.
+type ResourceAliases map[corev1.ResourceName]corev1.ResourceName
+
+// Canonical returns the canonical name of a resource. A resource which is not an alias is its own canonical name.
+func (a ResourceAliases) Canonical(resourceName corev1.ResourceName) corev1.ResourceName {
+	if canonicalName, ok := a[resourceName]; ok {
+		return canonicalName
+	}
+
+	return resourceName
+}
+
+// GetResourceAliases returns the resource aliases defined in the HNSConfig.
+func GetResourceAliases(ctx context.Context, k8sClient client.Client) (ResourceAliases, error) {
+	HNSConfig, err := GetHNSConfigData(ctx, k8sClient)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get HNSconfig %q: %v", hnsConfigName, err)
+	}
+
+	return resourceAliases(HNSConfig), nil
+}
+
+// GetSubjectExclusions returns the subjects of RoleBindings which are excluded from propagation according to the
+// HNSConfig, falling back to the default exclusions for the kinds of subjects it doesn't set exclusions for.
+func GetSubjectExclusions(ctx context.Context, k8sClient client.Client) (SubjectExclusions, error) {
+	HNSConfig, err := GetHNSConfigData(ctx, k8sClient)
+	if err != nil {
+		return SubjectExclusions{}, fmt.Errorf("failed to get HNSconfig %q: %v", hnsConfigName, err)
+	}
+
+	exclusions := DefaultSubjectExclusions
+	if HNSConfig.Spec.ExcludedServiceAccounts != nil {
+		exclusions.ServiceAccounts = HNSConfig.Spec.ExcludedServiceAccounts
+	}
+	if HNSConfig.Spec.ExcludedGroupPrefixes != nil {
+		exclusions.GroupPrefixes = HNSConfig.Spec.ExcludedGroupPrefixes
+	}
+
+	return exclusions, nil
+}
+
+// resourceAliases returns the resource aliases defined in the given HNSConfig.
+func resourceAliases(HNSConfig *hnsv1.HNSConfig) ResourceAliases {
+	aliases := ResourceAliases{}
+
+	for canonicalName, aliasNames := range HNSConfig.Spec.ResourceAliases {
+		for _, aliasName := range aliasNames {
+			aliases[corev1.ResourceName(aliasName)] = corev1.ResourceName(canonicalName)
+		}
+	}
+
+	return aliases
+}
@@ -9,10 +9,7 @@ import (
 	"k8s.io/apimachinery/pkg/api/errors"
 
 	danav1 "github.com/dana-team/hns/api/v1"
-	authv1 "k8s.io/api/authorization/v1"
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/client-go/kubernetes"
-	"k8s.io/client-go/rest"
+	authenticationv1 "k8s.io/api/authentication/v1"
 
 	"github.com/dana-team/hns/internal/namespace/nsutils"
 	"github.com/dana-team/hns/internal/objectcontext"
@@ -26,6 +23,21 @@ import (
 
 const PermittedGroups = "PERMITTED_GROUPS"
 
+// IsHNSServiceAccount returns whether a user is the service account of the HNS operator.
+func IsHNSServiceAccount(userName string) bool {
+	return userName == fmt.Sprintf("system:serviceaccount:%s:%s", danav1.HNSNamespace, danav1.HNSServiceAccount)
+}
+
+// CanManageHNSObjects returns whether a user is allowed to change objects which are managed by HNS,
+// which is the case for the service account of the HNS operator and for users in a permitted group.
+func CanManageHNSObjects(ctx context.Context, userName string, k8sClient client.Client) (bool, error) {
+	if IsHNSServiceAccount(userName) {
+		return true, nil
+	}
+
+	return ValidatePermittedGroups(ctx, userName, k8sClient)
+}
+
 // ValidateNamespaceExist validates that a namespace exists.
 func ValidateNamespaceExist(ns *objectcontext.ObjectContext) admission.Response {
 	if !(ns.IsPresent()) {
@@ -49,6 +61,26 @@ func ValidateToNamespaceName(ns *objectcontext.ObjectContext, toNSName string) a
 	return admission.Allowed("")
 }
 
+// ValidateSameRoot denies if trying to perform operations involving namespaces from the hierarchies
+// of different root namespaces, since each root namespace has an independent hierarchy and quota.
+func ValidateSameRoot(aNSArray, bNSArray []string) admission.Response {
+	aNSRootName := aNSArray[0]
+	bNSRootName := bNSArray[0]
+
+	if aNSRootName == "" || bNSRootName == "" {
+		message := "it is forbidden to do operations on subnamespaces without a set display-name"
+		return admission.Denied(message)
+	}
+
+	if aNSRootName != bNSRootName {
+		message := fmt.Sprintf("it is forbidden to perform operations between subnamespaces from root namespace %q and "+
+			"subnamespaces from root namespace %q", aNSRootName, bNSRootName)
+		return admission.Denied(message)
+	}
+
+	return admission.Allowed("")
+}
+
 // ValidateSecondaryRoot denies if trying to perform UpdateQuota involving namesapces from different secondary root namespaces
 // a secondary root is the first subnamespace after the root namespace in the hierarchy of a subnamespace.
 func ValidateSecondaryRoot(ctx context.Context, c client.Client, aNSArray, bNSArray []string) admission.Response {
@@ -85,13 +117,13 @@ func ValidateSecondaryRoot(ctx context.Context, c client.Client, aNSArray, bNSAr
 	return admission.Allowed("")
 }
 
-// ValidatePermissions checks if a registered user has the needed permissions on the namespaces and denies otherwise
-// there are 4 scenarios in which things are allowed: if the user is in a permitted group; if the user has the needed permissions on the Ancestor
-// of the two namespaces; if the user has the needed permissions on both namespaces; if the user has the needed
-// permissions on the namespace from which resources are moved and both namespaces are in the same branch
-// (only checked when the branch flag is true).
-func ValidatePermissions(ctx context.Context, aNS []string, aNSName, bNSName, ancestorNSName, reqUser string, branch bool, k8sClient client.Client) admission.Response {
-	inGroup, err := ValidatePermittedGroups(ctx, reqUser, k8sClient)
+// ValidatePermissions checks if a registered user is allowed to perform an HNS operation, which is identified by its
+// verb, on the namespaces and denies otherwise. There are 4 scenarios in which things are allowed: if the user is in a
+// permitted group; if the user is allowed to perform the operation on the Ancestor of the two namespaces; if the user
+// is allowed to perform it on both namespaces; if the user is allowed to perform it on the namespace from which
+// resources are moved and both namespaces are in the same branch (only checked when the branch flag is true).
+func ValidatePermissions(ctx context.Context, aNS []string, aNSName, bNSName, ancestorNSName string, userInfo authenticationv1.UserInfo, verb string, branch bool, k8sClient client.Client, authorizer Authorizer) admission.Response {
+	inGroup, err := ValidatePermittedGroups(ctx, userInfo.Username, k8sClient)
 	if err != nil {
 		return admission.Errored(http.StatusInternalServerError, err)
 	}
@@ -99,15 +131,15 @@ func ValidatePermissions(ctx context.Context, aNS []string, aNSName, bNSName, an
 		return admission.Allowed("")
 	}
 
-	hasSourcePermissions, err := permissionsExist(ctx, reqUser, aNSName)
+	hasSourcePermissions, err := permissionsExist(ctx, authorizer, userInfo, verb, aNSName)
 	if err != nil {
 		return admission.Errored(http.StatusInternalServerError, err)
 	}
-	hasDestPermissions, err := permissionsExist(ctx, reqUser, bNSName)
+	hasDestPermissions, err := permissionsExist(ctx, authorizer, userInfo, verb, bNSName)
 	if err != nil {
 		return admission.Errored(http.StatusInternalServerError, err)
 	}
-	hasAncestorPermissions, err := permissionsExist(ctx, reqUser, ancestorNSName)
+	hasAncestorPermissions, err := permissionsExist(ctx, authorizer, userInfo, verb, ancestorNSName)
 	if err != nil {
 		return admission.Errored(http.StatusInternalServerError, err)
 	}
@@ -116,15 +148,15 @@ func ValidatePermissions(ctx context.Context, aNS []string, aNSName, bNSName, an
 
 	if branch {
 		if !hasAncestorPermissions && !(hasSourcePermissions && hasDestPermissions) && !(hasSourcePermissions && inBranch) {
-			message := fmt.Sprintf("you must have permissions on: %q and %q, or permissions on %q, to perform "+
-				"this operation. Having permissions only on %q, is enough just when resources are moved in the same branch of the hierarchy",
-				aNSName, bNSName, ancestorNSName, aNSName)
+			message := fmt.Sprintf("you must be allowed to %q subnamespaces in: %q and %q, or in %q, to perform "+
+				"this operation. Being allowed only in %q, is enough just when resources are moved in the same branch of the hierarchy",
+				verb, aNSName, bNSName, ancestorNSName, aNSName)
 			return admission.Denied(message)
 		}
 	} else {
 		if !hasAncestorPermissions && !(hasSourcePermissions && hasDestPermissions) {
-			message := fmt.Sprintf("you must have permissions on: %q and %q, or permissions on %q, to perform "+
-				"this operation", aNSName, bNSName, ancestorNSName)
+			message := fmt.Sprintf("you must be allowed to %q subnamespaces in: %q and %q, or in %q, to perform "+
+				"this operation", verb, aNSName, bNSName, ancestorNSName)
 			return admission.Denied(message)
 		}
 	}
@@ -132,58 +164,14 @@ func ValidatePermissions(ctx context.Context, aNS []string, aNSName, bNSName, an
 	return admission.Allowed("")
 }
 
-// permissionsExist checks if a user has permission to create a pod in a given namespace.
-// It impersonates the reqUser and uses SelfSubjectAccessReview API to check if the action is allowed or denied.
-// It returns a boolean value indicating whether the user has permission to create the pod or not.
-func permissionsExist(ctx context.Context, reqUser, namespace string) (bool, error) {
-
-	if reqUser == fmt.Sprintf("system:serviceaccount:%s:%s", danav1.HNSNamespace, danav1.HNSServiceAccount) {
+// permissionsExist checks if a user is allowed to use the virtual verb of an HNS operation in a given namespace.
+// The service account of the HNS operator is always allowed.
+func permissionsExist(ctx context.Context, authorizer Authorizer, userInfo authenticationv1.UserInfo, verb, namespace string) (bool, error) {
+	if IsHNSServiceAccount(userInfo.Username) {
 		return true, nil
 	}
 
-	config, err := rest.InClusterConfig()
-	if err != nil {
-		return false, err
-	}
-
-	// set the user to impersonate in the configuration
-	config.Impersonate = rest.ImpersonationConfig{
-		UserName: reqUser,
-	}
-
-	// create a new Kubernetes client using the configuration
-	clientSet, err := kubernetes.NewForConfig(config)
-	if err != nil {
-		return false, err
-	}
-
-	// create a new SelfSubjectAccessReview API object for checking permissions
-	action := authv1.ResourceAttributes{
-		Namespace: namespace,
-		Verb:      "create",
-		Resource:  "pods",
-	}
-
-	selfCheck := authv1.SelfSubjectAccessReview{
-		Spec: authv1.SelfSubjectAccessReviewSpec{
-			ResourceAttributes: &action,
-		},
-	}
-
-	// check the permissions for the user
-	resp, err := clientSet.AuthorizationV1().SelfSubjectAccessReviews().Create(ctx, &selfCheck, metav1.CreateOptions{})
-	if err != nil {
-		return false, err
-	}
-
-	// check the response status to determine whether the user has permission to create the pod or not
-	if resp.Status.Denied {
-		return false, nil
-	}
-	if resp.Status.Allowed {
-		return true, nil
-	}
-	return false, nil
+	return authorizer.Authorize(ctx, userInfo, verb, namespace)
 }
 
 // IsUserInGroup returns true if given user is in give group
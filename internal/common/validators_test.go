@@ -0,0 +1,87 @@
+package common
+
+import (
+	"context"
+	"testing"
+
+	danav1 "github.com/dana-team/hns/api/v1"
+	"github.com/dana-team/hns/internal/testutils"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// fakeAuthorizer is an Authorizer which allows a verb in the namespaces it was given for it.
+type fakeAuthorizer map[string][]string
+
+func (a fakeAuthorizer) Authorize(_ context.Context, _ authenticationv1.UserInfo, verb, namespace string) (bool, error) {
+	return ContainsString(a[verb], namespace), nil
+}
+
+func (a fakeAuthorizer) AuthorizeBind(_ context.Context, _ authenticationv1.UserInfo, _, namespace string) (bool, error) {
+	return ContainsString(a["bind"], namespace), nil
+}
+
+func TestValidatePermissions(t *testing.T) {
+	hnsConfig := &danav1.HNSConfig{ObjectMeta: metav1.ObjectMeta{Name: hnsConfigName, Namespace: danav1.HNSNamespace}}
+	k8sClient := testutils.NewFakeClient(t, hnsConfig)
+
+	// the hierarchy is root -> a -> b, and root -> c
+	source := []string{"root", "a", "b"}
+	user := authenticationv1.UserInfo{Username: "user"}
+
+	tests := []struct {
+		name       string
+		authorizer fakeAuthorizer
+		dest       string
+		verb       string
+		branch     bool
+		allowed    bool
+	}{
+		{
+			name:       "allowed on the ancestor",
+			authorizer: fakeAuthorizer{TransferQuotaVerb: {"root"}},
+			dest:       "c",
+			verb:       TransferQuotaVerb,
+			allowed:    true,
+		},
+		{
+			name:       "allowed on the source and the destination",
+			authorizer: fakeAuthorizer{MigrateVerb: {"b", "c"}},
+			dest:       "c",
+			verb:       MigrateVerb,
+			allowed:    true,
+		},
+		{
+			name:       "allowed only on the source when moving in the same branch",
+			authorizer: fakeAuthorizer{TransferQuotaVerb: {"b"}},
+			dest:       "a",
+			verb:       TransferQuotaVerb,
+			branch:     true,
+			allowed:    true,
+		},
+		{
+			name:       "allowed only on the source when moving to another branch",
+			authorizer: fakeAuthorizer{TransferQuotaVerb: {"b"}},
+			dest:       "c",
+			verb:       TransferQuotaVerb,
+			branch:     true,
+			allowed:    false,
+		},
+		{
+			name:       "allowed another verb on the ancestor",
+			authorizer: fakeAuthorizer{MigrateVerb: {"root"}},
+			dest:       "c",
+			verb:       TransferQuotaVerb,
+			allowed:    false,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			response := ValidatePermissions(context.Background(), source, "b", test.dest, "root", user, test.verb, test.branch, k8sClient, test.authorizer)
+			if response.Allowed != test.allowed {
+				t.Errorf("expected allowed to be %v, got %v: %v", test.allowed, response.Allowed, response.Result)
+			}
+		})
+	}
+}
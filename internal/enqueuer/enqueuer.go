@@ -0,0 +1,66 @@
+package enqueuer
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/dana-team/hns/internal/metrics"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// Enqueuer enqueues requests to reconcile objects directly in the workqueue of a controller. Enqueueing
+// never blocks the caller, and a request which is already waiting in the workqueue is not added again,
+// so reconcilers may enqueue each other, and large subtrees, without stalling their workers.
+// An Enqueuer is used as a source of the controller it enqueues to.
+type Enqueuer struct {
+	kind    string
+	mu      sync.Mutex
+	queue   workqueue.TypedRateLimitingInterface[reconcile.Request]
+	pending map[reconcile.Request]bool
+}
+
+// New returns an Enqueuer of requests to reconcile objects of the given kind.
+func New(kind string) *Enqueuer {
+	return &Enqueuer{kind: kind, pending: map[reconcile.Request]bool{}}
+}
+
+// Start is called by the controller the Enqueuer is a source of, and adds the requests which were
+// enqueued before the controller was started to its workqueue.
+func (e *Enqueuer) Start(_ context.Context, queue workqueue.TypedRateLimitingInterface[reconcile.Request]) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.queue = queue
+	for request := range e.pending {
+		queue.Add(request)
+	}
+	e.pending = nil
+
+	return nil
+}
+
+// Enqueue enqueues a request to reconcile the object with the given name and namespace.
+func (e *Enqueuer) Enqueue(name, namespace string) {
+	e.EnqueueAfter(name, namespace, 0)
+}
+
+// EnqueueAfter enqueues a request to reconcile the object with the given name and namespace once the given
+// delay passes. Requests for the same object which are enqueued during the delay are coalesced into the first
+// one, so that a burst of requests results in a single reconciliation of the object.
+func (e *Enqueuer) EnqueueAfter(name, namespace string, delay time.Duration) {
+	request := reconcile.Request{NamespacedName: types.NamespacedName{Name: name, Namespace: namespace}}
+	metrics.ObserveEnqueuedRequest(e.kind)
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.queue == nil {
+		e.pending[request] = true
+		return
+	}
+
+	e.queue.AddAfter(request, delay)
+}
@@ -0,0 +1,69 @@
+package enqueuer
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+func TestEnqueue(t *testing.T) {
+	queue := workqueue.NewTypedRateLimitingQueue(workqueue.DefaultTypedControllerRateLimiter[reconcile.Request]())
+	defer queue.ShutDown()
+
+	enqueuer := New("Subnamespace")
+
+	enqueuer.Enqueue("a", "root")
+	enqueuer.Enqueue("a", "root")
+	if err := enqueuer.Start(context.Background(), queue); err != nil {
+		t.Fatalf("failed to start enqueuer: %v", err)
+	}
+	if queue.Len() != 1 {
+		t.Errorf("expected the requests enqueued before start to be added once, got %d requests", queue.Len())
+	}
+
+	enqueuer.Enqueue("b", "root")
+	enqueuer.Enqueue("b", "root")
+	enqueuer.Enqueue("a", "root")
+	if queue.Len() != 2 {
+		t.Errorf("expected requests which are already waiting not to be added again, got %d requests", queue.Len())
+	}
+}
+
+func TestEnqueueAfter(t *testing.T) {
+	queue := workqueue.NewTypedRateLimitingQueue(workqueue.DefaultTypedControllerRateLimiter[reconcile.Request]())
+	defer queue.ShutDown()
+
+	enqueuer := New("Subnamespace")
+	if err := enqueuer.Start(context.Background(), queue); err != nil {
+		t.Fatalf("failed to start enqueuer: %v", err)
+	}
+
+	for i := 0; i < 3; i++ {
+		enqueuer.EnqueueAfter("parent", "root", 50*time.Millisecond)
+	}
+	if queue.Len() != 0 {
+		t.Errorf("expected the request not to be added before the delay passes, got %d requests", queue.Len())
+	}
+
+	// the request is added by the workqueue once the delay passes, so wait for it to be added, and
+	// then for the requests which would have been added if they were not coalesced
+	deadline := time.Now().Add(5 * time.Second)
+	for queue.Len() == 0 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if queue.Len() == 0 {
+		t.Fatalf("expected the request to be added once the delay passes")
+	}
+
+	request, _ := queue.Get()
+	queue.Done(request)
+	if request.Name != "parent" || request.Namespace != "root" {
+		t.Errorf("expected a request for root/parent, got %v", request)
+	}
+	if queue.Len() != 0 {
+		t.Errorf("expected the requests enqueued during the delay to be coalesced, got %d more requests", queue.Len())
+	}
+}
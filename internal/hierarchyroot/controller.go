@@ -0,0 +1,254 @@
+package hierarchyroot
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+
+	danav1 "github.com/dana-team/hns/api/v1"
+	"github.com/dana-team/hns/internal/namespace/nsutils"
+	"github.com/dana-team/hns/internal/objectcontext"
+	"github.com/dana-team/hns/internal/quota"
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// HierarchyRootReconciler reconciles a HierarchyRoot object
+type HierarchyRootReconciler struct {
+	client.Client
+	Scheme *runtime.Scheme
+}
+
+// +kubebuilder:rbac:groups=dana.hns.io,resources=hierarchyroots,verbs=get;list;watch;update;patch
+// +kubebuilder:rbac:groups=dana.hns.io,resources=hierarchyroots/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=core,resources=namespaces,verbs=get;list;watch;create;update;patch
+// +kubebuilder:rbac:groups="",resources=resourcequotas,verbs=get;list;watch;create;update;patch
+
+// SetupWithManager sets up the controller by specifying the following: controller is managing the reconciliation
+// of HierarchyRoot objects and is watching for changes to namespaces and ResourceQuotas, which are mapped to
+// the HierarchyRoot of the hierarchy they belong to.
+func (r *HierarchyRootReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&danav1.HierarchyRoot{}).
+		Watches(&corev1.Namespace{}, handler.EnqueueRequestsFromMapFunc(mapNamespaceToHierarchyRoot),
+			builder.WithPredicates(predicate.NewPredicateFuncs(isRootOrRootChildNamespace))).
+		Watches(&corev1.ResourceQuota{}, handler.EnqueueRequestsFromMapFunc(r.mapResourceQuotaToHierarchyRoot)).
+		Complete(r)
+}
+
+// isRootOrRootChildNamespace returns true if a namespace is a root namespace or a child of a root namespace,
+// which are the only namespaces a HierarchyRoot syncs.
+func isRootOrRootChildNamespace(object client.Object) bool {
+	if object.GetLabels()[danav1.Hns] != "true" {
+		return false
+	}
+
+	ancestors, err := nsutils.Ancestors(object)
+	return err == nil && len(ancestors) <= 2
+}
+
+func (r *HierarchyRootReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := log.FromContext(ctx).WithName("controllers").WithName("HierarchyRoot").WithValues("hr", req.Name)
+	logger.Info("starting to reconcile")
+
+	hrObject, err := objectcontext.New(ctx, r.Client, req.NamespacedName, &danav1.HierarchyRoot{})
+	if err != nil {
+		return ctrl.Result{}, fmt.Errorf("failed to get object %q: %v", req.Name, err.Error())
+	}
+
+	if !hrObject.IsPresent() {
+		logger.Info("resource not found. Ignoring since object must be deleted")
+		return ctrl.Result{}, nil
+	}
+
+	if err := r.sync(hrObject); err != nil {
+		if statusErr := updateHRStatus(hrObject, danav1.Error, err.Error()); statusErr != nil {
+			return ctrl.Result{}, fmt.Errorf("failed to update status for hierarchyroot %q: %v", req.Name, statusErr.Error())
+		}
+		return ctrl.Result{}, err
+	}
+
+	if err := updateHRStatus(hrObject, danav1.Created, ""); err != nil {
+		return ctrl.Result{}, fmt.Errorf("failed to update status for hierarchyroot %q: %v", req.Name, err.Error())
+	}
+	logger.Info("successfully set status for hierarchyroot", "phase", danav1.Created)
+
+	return ctrl.Result{}, nil
+}
+
+// sync makes sure the root namespace declared by the HierarchyRoot exists with the needed labels and
+// annotations, that its ResourceQuota matches the declared quota and that the secondary roots are annotated.
+func (r *HierarchyRootReconciler) sync(hrObject *objectcontext.ObjectContext) error {
+	logger := log.FromContext(hrObject.Ctx)
+	rootName := hrObject.Name()
+
+	rootNS, err := ensureRootNamespace(hrObject)
+	if err != nil {
+		return fmt.Errorf("failed to ensure root namespace %q: %v", rootName, err.Error())
+	}
+	logger.Info("successfully ensured root namespace", "namespace", rootName)
+
+	quotaSpec := hrObject.Object.(*danav1.HierarchyRoot).Spec.ResourceQuotaSpec
+	if err := quota.EnsureRootNSObject(rootNS, quotaSpec); err != nil {
+		return fmt.Errorf("failed to ensure quota object of root namespace %q: %v", rootName, err.Error())
+	}
+	logger.Info("successfully ensured quota object of root namespace", "namespace", rootName)
+
+	if err := syncSecondaryRoots(hrObject); err != nil {
+		return fmt.Errorf("failed to sync secondary roots of root namespace %q: %v", rootName, err.Error())
+	}
+	logger.Info("successfully synced secondary roots of root namespace", "namespace", rootName)
+
+	return nil
+}
+
+// ensureRootNamespace creates the root namespace if it does not exist, and makes sure it has the
+// labels and annotations of a root namespace. It refuses to turn a namespace which is already part
+// of a hierarchy into a root namespace.
+func ensureRootNamespace(hrObject *objectcontext.ObjectContext) (*objectcontext.ObjectContext, error) {
+	rootName := hrObject.Name()
+	labels, annotations := rootNamespaceLabelsAndAnnotations(hrObject)
+
+	composedRootNS := &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        rootName,
+			Labels:      labels,
+			Annotations: annotations,
+		},
+	}
+
+	rootNS, err := objectcontext.New(hrObject.Ctx, hrObject.Client, types.NamespacedName{Name: rootName}, composedRootNS)
+	if err != nil {
+		return nil, err
+	}
+
+	if !rootNS.IsPresent() {
+		return rootNS, rootNS.EnsureCreate()
+	}
+
+	if parent := nsutils.Parent(rootNS.Object); parent != "" {
+		return nil, fmt.Errorf("namespace %q is part of the hierarchy of %q and can't be a root namespace", rootName, parent)
+	}
+
+	if err := rootNS.AppendLabels(labels); err != nil {
+		return nil, err
+	}
+
+	if err := rootNS.AppendAnnotations(annotations); err != nil {
+		return nil, err
+	}
+
+	return rootNS, nil
+}
+
+// rootNamespaceLabelsAndAnnotations returns the labels and annotations of a root namespace.
+func rootNamespaceLabelsAndAnnotations(hrObject *objectcontext.ObjectContext) (map[string]string, map[string]string) {
+	rootName := hrObject.Name()
+	rqDepth := hrObject.Object.(*danav1.HierarchyRoot).Spec.RqDepth
+
+	labels := map[string]string{
+		danav1.Hns:                  "true",
+		nsutils.TreeLabel(rootName): "0",
+	}
+
+	annotations := map[string]string{
+		danav1.Role:                 danav1.Root,
+		danav1.DisplayName:          rootName,
+		danav1.OpenShiftDisplayName: rootName,
+		danav1.RootCrqSelector:      rootName,
+		danav1.Depth:                "0",
+		danav1.RqDepth:              strconv.Itoa(rqDepth),
+	}
+
+	return labels, annotations
+}
+
+// syncSecondaryRoots makes sure that exactly the children namespaces of the root namespace
+// which are declared as secondary roots are annotated as secondary roots.
+func syncSecondaryRoots(hrObject *objectcontext.ObjectContext) error {
+	rootName := hrObject.Name()
+	secondaryRoots := hrObject.Object.(*danav1.HierarchyRoot).Spec.SecondaryRoots
+
+	children, err := objectcontext.NewList(hrObject.Ctx, hrObject.Client, &corev1.NamespaceList{}, client.MatchingLabels{danav1.Parent: rootName})
+	if err != nil {
+		return err
+	}
+
+	for _, child := range children.Objects.(*corev1.NamespaceList).Items {
+		childNS, err := objectcontext.New(hrObject.Ctx, hrObject.Client, types.NamespacedName{Name: child.Name}, &corev1.Namespace{})
+		if err != nil {
+			return err
+		}
+
+		isDeclared := slices.Contains(secondaryRoots, child.Name)
+		isAnnotated := nsutils.IsSecondaryRoot(childNS.Object)
+
+		if isDeclared && !isAnnotated {
+			if err := childNS.AppendAnnotations(map[string]string{danav1.IsSecondaryRoot: danav1.True}); err != nil {
+				return err
+			}
+		} else if !isDeclared && isAnnotated {
+			if err := childNS.DeleteAnnotations([]string{danav1.IsSecondaryRoot}); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// updateHRStatus updates the status of the HierarchyRoot object if it changed.
+func updateHRStatus(hrObject *objectcontext.ObjectContext, phase danav1.Phase, reason string) error {
+	status := hrObject.Object.(*danav1.HierarchyRoot).Status
+	if status.Phase == phase && status.Reason == reason {
+		return nil
+	}
+
+	return hrObject.UpdateObject(func(object client.Object, l logr.Logger) (client.Object, logr.Logger) {
+		object.(*danav1.HierarchyRoot).Status.Phase = phase
+		object.(*danav1.HierarchyRoot).Status.Reason = reason
+		l = l.WithValues("phase", phase, "reason", reason)
+		return object, l
+	})
+}
+
+// mapNamespaceToHierarchyRoot maps a namespace to the HierarchyRoot of the hierarchy it belongs to,
+// which is named after the root namespace of the hierarchy.
+func mapNamespaceToHierarchyRoot(_ context.Context, object client.Object) []reconcile.Request {
+	if object.GetLabels()[danav1.Hns] != "true" {
+		return nil
+	}
+
+	rootName, err := nsutils.Root(object)
+	if err != nil {
+		return nil
+	}
+
+	return []reconcile.Request{{NamespacedName: types.NamespacedName{Name: rootName}}}
+}
+
+// mapResourceQuotaToHierarchyRoot maps the ResourceQuota of a root namespace to its HierarchyRoot. Only
+// a ResourceQuota which is named after a namespace which has a HierarchyRoot is the quota object of a root
+// namespace, so changes to all the other ResourceQuotas don't trigger a reconciliation.
+func (r *HierarchyRootReconciler) mapResourceQuotaToHierarchyRoot(ctx context.Context, object client.Object) []reconcile.Request {
+	if object.GetName() != object.GetNamespace() {
+		return nil
+	}
+
+	if err := r.Get(ctx, types.NamespacedName{Name: object.GetNamespace()}, &danav1.HierarchyRoot{}); err != nil {
+		return nil
+	}
+
+	return []reconcile.Request{{NamespacedName: types.NamespacedName{Name: object.GetNamespace()}}}
+}
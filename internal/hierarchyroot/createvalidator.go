@@ -0,0 +1,183 @@
+package hierarchyroot
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	danav1 "github.com/dana-team/hns/api/v1"
+	"github.com/dana-team/hns/internal/namespace/nsutils"
+	"github.com/dana-team/hns/internal/objectcontext"
+	"github.com/dana-team/hns/internal/quota"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+// handleCreate implements the logic for the creation of a HierarchyRoot.
+func (v *HierarchyRootValidator) handleCreate(hrObject *objectcontext.ObjectContext) admission.Response {
+	ctx := hrObject.Ctx
+	logger := log.FromContext(ctx)
+
+	rootNS, err := objectcontext.New(ctx, v.Client, types.NamespacedName{Name: hrObject.Name()}, &corev1.Namespace{})
+	if err != nil {
+		logger.Error(err, "failed to create object", "rootNS", hrObject.Name())
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+
+	if response := v.validateNotPartOfHierarchy(rootNS); !response.Allowed {
+		return response
+	}
+
+	return v.validateHierarchy(hrObject, rootNS)
+}
+
+// validateHierarchy validates that the spec of the HierarchyRoot does not invalidate the
+// hierarchy which already exists under the root namespace.
+func (v *HierarchyRootValidator) validateHierarchy(hrObject, rootNS *objectcontext.ObjectContext) admission.Response {
+	if !rootNS.IsPresent() {
+		return admission.Allowed("")
+	}
+
+	children, err := childrenSubnamespaces(rootNS)
+	if err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+
+	if response := v.validateRqDepth(hrObject, rootNS, children); !response.Allowed {
+		return response
+	}
+
+	if response := v.validateSecondaryRoots(hrObject, children); !response.Allowed {
+		return response
+	}
+
+	return v.validateEnoughResourcesForChildren(hrObject, children)
+}
+
+// validateNotPartOfHierarchy validates that the namespace is not already part of the
+// hierarchy of another root namespace.
+func (v *HierarchyRootValidator) validateNotPartOfHierarchy(rootNS *objectcontext.ObjectContext) admission.Response {
+	if !rootNS.IsPresent() {
+		return admission.Allowed("")
+	}
+
+	if parent := nsutils.Parent(rootNS.Object); parent != "" {
+		message := fmt.Sprintf("namespace %q is a subnamespace of %q and can't be a root namespace", rootNS.Name(), parent)
+		return admission.Denied(message)
+	}
+
+	return admission.Allowed("")
+}
+
+// validateRqDepth validates that the rqDepth of a root namespace which already has
+// children is not changed, since that would change the type of quota objects in the hierarchy.
+func (v *HierarchyRootValidator) validateRqDepth(hrObject, rootNS *objectcontext.ObjectContext, children []danav1.Subnamespace) admission.Response {
+	if len(children) == 0 {
+		return admission.Allowed("")
+	}
+
+	currentRqDepth, ok := rootNS.Object.GetAnnotations()[danav1.RqDepth]
+	if !ok {
+		return admission.Allowed("")
+	}
+
+	rqDepth := strconv.Itoa(hrObject.Object.(*danav1.HierarchyRoot).Spec.RqDepth)
+	if currentRqDepth != rqDepth {
+		message := fmt.Sprintf("it is forbidden to change the rqDepth of root namespace %q from %q to %q while it has subnamespaces",
+			rootNS.Name(), currentRqDepth, rqDepth)
+		return admission.Denied(message)
+	}
+
+	return admission.Allowed("")
+}
+
+// validateSecondaryRoots validates that the secondary roots are direct children of the root namespace
+// and that the rqDepth is deep enough for secondary roots to exist.
+func (v *HierarchyRootValidator) validateSecondaryRoots(hrObject *objectcontext.ObjectContext, children []danav1.Subnamespace) admission.Response {
+	hrSpec := hrObject.Object.(*danav1.HierarchyRoot).Spec
+	if len(hrSpec.SecondaryRoots) == 0 {
+		return admission.Allowed("")
+	}
+
+	if hrSpec.RqDepth < 2 {
+		message := fmt.Sprintf("rqDepth must be at least 2 when secondary roots exist, got %v", hrSpec.RqDepth)
+		return admission.Denied(message)
+	}
+
+	for _, secondaryRoot := range hrSpec.SecondaryRoots {
+		ns, err := objectcontext.New(hrObject.Ctx, v.Client, types.NamespacedName{Name: secondaryRoot}, &corev1.Namespace{})
+		if err != nil {
+			return admission.Errored(http.StatusBadRequest, err)
+		}
+
+		if !ns.IsPresent() {
+			continue
+		}
+
+		if !isChild(secondaryRoot, children) {
+			message := fmt.Sprintf("secondary root %q is not a direct child of root namespace %q", secondaryRoot, hrObject.Name())
+			return admission.Denied(message)
+		}
+	}
+
+	return admission.Allowed("")
+}
+
+// validateEnoughResourcesForChildren validates that the quota of the root namespace is enough
+// to cover the resources allocated to its children.
+func (v *HierarchyRootValidator) validateEnoughResourcesForChildren(hrObject *objectcontext.ObjectContext, children []danav1.Subnamespace) admission.Response {
+	if len(children) == 0 {
+		return admission.Allowed("")
+	}
+
+	allocated := corev1.ResourceList{}
+	for _, child := range children {
+		allocated = quota.AddResourceLists(allocated, quota.SubnamespaceSpec(&child).Hard)
+	}
+
+	rootQuota := hrObject.Object.(*danav1.HierarchyRoot).Spec.ResourceQuotaSpec.Hard
+	for resourceName, allocatedQuantity := range allocated {
+		if allocatedQuantity.IsZero() {
+			continue
+		}
+
+		rootQuantity, ok := rootQuota[resourceName]
+		if !ok {
+			message := fmt.Sprintf("resource %q is allocated to the subnamespaces of root namespace %q and can't be removed from its quota",
+				resourceName, hrObject.Name())
+			return admission.Denied(message)
+		}
+
+		if rootQuantity.Cmp(allocatedQuantity) < 0 {
+			message := fmt.Sprintf("it is forbidden to set %q of root namespace %q to %v, since %v is allocated to its subnamespaces",
+				resourceName, hrObject.Name(), rootQuantity.String(), allocatedQuantity.String())
+			return admission.Denied(message)
+		}
+	}
+
+	return admission.Allowed("")
+}
+
+// childrenSubnamespaces returns the subnamespaces which are direct children of the root namespace.
+func childrenSubnamespaces(rootNS *objectcontext.ObjectContext) ([]danav1.Subnamespace, error) {
+	snsList, err := objectcontext.NewList(rootNS.Ctx, rootNS.Client, &danav1.SubnamespaceList{}, client.InNamespace(rootNS.Name()))
+	if err != nil {
+		return nil, err
+	}
+
+	return snsList.Objects.(*danav1.SubnamespaceList).Items, nil
+}
+
+// isChild returns whether a subnamespace with the given name exists in children.
+func isChild(name string, children []danav1.Subnamespace) bool {
+	for _, child := range children {
+		if child.Name == name {
+			return true
+		}
+	}
+
+	return false
+}
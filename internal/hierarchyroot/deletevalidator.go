@@ -0,0 +1,47 @@
+package hierarchyroot
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/dana-team/hns/internal/objectcontext"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+// handleDelete implements the logic for the deletion of a HierarchyRoot.
+func (v *HierarchyRootValidator) handleDelete(hrObject *objectcontext.ObjectContext) admission.Response {
+	ctx := hrObject.Ctx
+	logger := log.FromContext(ctx)
+
+	rootNS, err := objectcontext.New(ctx, v.Client, types.NamespacedName{Name: hrObject.Name()}, &corev1.Namespace{})
+	if err != nil {
+		logger.Error(err, "failed to create object", "rootNS", hrObject.Name())
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+
+	return v.validateNoChildren(rootNS)
+}
+
+// validateNoChildren validates that the root namespace has no subnamespaces, since the
+// HierarchyRoot can't be deleted while the hierarchy under it still exists, unless the
+// root namespace itself is being deleted.
+func (v *HierarchyRootValidator) validateNoChildren(rootNS *objectcontext.ObjectContext) admission.Response {
+	if !rootNS.IsPresent() || !rootNS.Object.GetDeletionTimestamp().IsZero() {
+		return admission.Allowed("")
+	}
+
+	children, err := childrenSubnamespaces(rootNS)
+	if err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+
+	if len(children) > 0 {
+		message := fmt.Sprintf("it is forbidden to delete the HierarchyRoot of %q while it has subnamespaces", rootNS.Name())
+		return admission.Denied(message)
+	}
+
+	return admission.Allowed("")
+}
@@ -0,0 +1,25 @@
+package hierarchyroot
+
+import (
+	"net/http"
+
+	"github.com/dana-team/hns/internal/objectcontext"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+// handleUpdate implements the logic for the update of a HierarchyRoot.
+func (v *HierarchyRootValidator) handleUpdate(hrObject *objectcontext.ObjectContext) admission.Response {
+	ctx := hrObject.Ctx
+	logger := log.FromContext(ctx)
+
+	rootNS, err := objectcontext.New(ctx, v.Client, types.NamespacedName{Name: hrObject.Name()}, &corev1.Namespace{})
+	if err != nil {
+		logger.Error(err, "failed to create object", "rootNS", hrObject.Name())
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+
+	return v.validateHierarchy(hrObject, rootNS)
+}
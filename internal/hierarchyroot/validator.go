@@ -0,0 +1,60 @@
+package hierarchyroot
+
+import (
+	"context"
+	"net/http"
+
+	danav1 "github.com/dana-team/hns/api/v1"
+	"github.com/dana-team/hns/internal/objectcontext"
+	admissionv1 "k8s.io/api/admission/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+type HierarchyRootValidator struct {
+	Client  client.Client
+	Decoder admission.Decoder
+}
+
+// +kubebuilder:webhook:path=/validate-v1-hierarchyroot,mutating=false,sideEffects=NoneOnDryRun,failurePolicy=fail,groups="dana.hns.io",resources=hierarchyroots,verbs=create;update;delete,versions=v1,name=hierarchyroot.dana.io,admissionReviewVersions=v1;v1beta1
+
+// Handle implements the validation webhook.
+func (v *HierarchyRootValidator) Handle(ctx context.Context, req admission.Request) admission.Response {
+	logger := log.FromContext(ctx).WithValues("webhook", "HierarchyRoot Webhook", "Name", req.Name)
+	logger.Info("webhook request received")
+
+	hrObject, err := objectcontext.New(ctx, v.Client, types.NamespacedName{}, &danav1.HierarchyRoot{})
+	if err != nil {
+		logger.Error(err, "failed to create object context")
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+
+	if req.Operation == admissionv1.Delete {
+		if err := v.Decoder.DecodeRaw(req.OldObject, hrObject.Object); err != nil {
+			logger.Error(err, "failed to decode object", "request object", req.OldObject)
+			return admission.Errored(http.StatusBadRequest, err)
+		}
+		return v.handleDelete(hrObject)
+	}
+
+	if err := v.Decoder.DecodeRaw(req.Object, hrObject.Object); err != nil {
+		logger.Error(err, "failed to decode object", "request object", req.Object)
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+
+	if req.Operation == admissionv1.Create {
+		if response := v.handleCreate(hrObject); !response.Allowed {
+			return response
+		}
+	}
+
+	if req.Operation == admissionv1.Update {
+		if response := v.handleUpdate(hrObject); !response.Allowed {
+			return response
+		}
+	}
+
+	return admission.Allowed("all validations passed")
+}
@@ -11,6 +11,7 @@ func InitializeHNSMetrics() {
 		snsAllocatedResources,
 		snsFreeResources,
 		snsTotalResources,
+		enqueuedRequests,
 	)
 }
 
@@ -19,7 +20,7 @@ var (
 		prometheus.GaugeOpts{
 			Name: "sns_allocated_resources",
 			Help: "Indication of the quantity of an allocated subnamespace resource",
-		}, []string{"name", "namespace", "resource"},
+		}, []string{"name", "namespace", "root", "resource"},
 	)
 )
 
@@ -28,7 +29,7 @@ var (
 		prometheus.GaugeOpts{
 			Name: "sns_free_resources",
 			Help: "Indication of the quantity of a free subnamespace resource",
-		}, []string{"name", "namespace", "resource"},
+		}, []string{"name", "namespace", "root", "resource"},
 	)
 )
 
@@ -37,33 +38,52 @@ var (
 		prometheus.GaugeOpts{
 			Name: "sns_total_resources",
 			Help: "Indication of the total quantity of a subnamespace resource",
-		}, []string{"name", "namespace", "resource"},
+		}, []string{"name", "namespace", "root", "resource"},
+	)
+)
+
+var (
+	enqueuedRequests = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "hns_enqueued_requests_total",
+			Help: "Number of requests to reconcile an object enqueued by the HNS controllers",
+		}, []string{"kind"},
 	)
 )
 
 // ObserveSNSAllocatedResource sets the allocated metric as per the quantity.
-func ObserveSNSAllocatedResource(name, namespace, resource string, quantity float64) {
+func ObserveSNSAllocatedResource(name, namespace, root, resource string, quantity float64) {
 	snsAllocatedResources.With(prometheus.Labels{
 		"name":      name,
 		"namespace": namespace,
+		"root":      root,
 		"resource":  resource,
 	}).Set(quantity)
 }
 
 // ObserveSNSFreeResource sets the allocatable metric as per the quantity.
-func ObserveSNSFreeResource(name, namespace, resource string, quantity float64) {
+func ObserveSNSFreeResource(name, namespace, root, resource string, quantity float64) {
 	snsFreeResources.With(prometheus.Labels{
 		"name":      name,
 		"namespace": namespace,
+		"root":      root,
 		"resource":  resource,
 	}).Set(quantity)
 }
 
 // ObserveSNSTotalResource sets the total metric as per the quantity.
-func ObserveSNSTotalResource(name, namespace, resource string, quantity float64) {
+func ObserveSNSTotalResource(name, namespace, root, resource string, quantity float64) {
 	snsTotalResources.With(prometheus.Labels{
 		"name":      name,
 		"namespace": namespace,
+		"root":      root,
 		"resource":  resource,
 	}).Set(quantity)
 }
+
+// ObserveEnqueuedRequest increments the enqueued requests metric of a kind.
+func ObserveEnqueuedRequest(kind string) {
+	enqueuedRequests.With(prometheus.Labels{
+		"kind": kind,
+	}).Inc()
+}
@@ -3,14 +3,17 @@ package migrationhierarchy
 import (
 	"context"
 	"fmt"
+	"slices"
 	"time"
 
 	"github.com/dana-team/hns/internal/common"
+	"github.com/dana-team/hns/internal/enqueuer"
 	"github.com/dana-team/hns/internal/namespace/nsutils"
 	"github.com/dana-team/hns/internal/namespacedb"
 	"github.com/dana-team/hns/internal/objectcontext"
 	"github.com/dana-team/hns/internal/quota"
 	"github.com/dana-team/hns/internal/subnamespace/snsutils"
+	"github.com/dana-team/hns/internal/updatequota"
 	"github.com/go-logr/logr"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 
@@ -21,7 +24,6 @@ import (
 	"k8s.io/apimachinery/pkg/types"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
-	"sigs.k8s.io/controller-runtime/pkg/event"
 )
 
 // MigrationHierarchyReconciler reconciles a MigrationHierarchy object
@@ -29,17 +31,19 @@ type MigrationHierarchyReconciler struct {
 	client.Client
 	Scheme      *runtime.Scheme
 	NamespaceDB *namespacedb.NamespaceDB
-	SnsEvents   chan event.GenericEvent
+	SnsEvents   *enqueuer.Enqueuer
+	MHEvents    *enqueuer.Enqueuer
+	Scheduler   *updatequota.Scheduler
+	MaxSNS      int
 }
 
 // +kubebuilder:rbac:groups=dana.hns.io,resources=migrationhierarchies,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=dana.hns.io,resources=migrationhierarchies/status,verbs=get;update;patch
-// +kubebuilder:rbac:groups="",resources=users,verbs=impersonate
-// +kubebuilder:rbac:groups="",resources=serviceaccounts,verbs=impersonate
 
 func (r *MigrationHierarchyReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&danav1.MigrationHierarchy{}).
+		WatchesRawSource(r.MHEvents).
 		Complete(r)
 }
 
@@ -54,11 +58,16 @@ func (r *MigrationHierarchyReconciler) Reconcile(ctx context.Context, req ctrl.R
 
 	if !mhObject.IsPresent() {
 		logger.Info("resource not found. Ignoring since object must be deleted")
+		r.Scheduler.Release(req.NamespacedName)
 		return ctrl.Result{}, nil
 	}
 
 	phase := mhObject.Object.(*danav1.MigrationHierarchy).Status.Phase
-	if common.ShouldReconcile(phase) {
+	if shouldRevert(mhObject) {
+		return r.revert(mhObject)
+	} else if mhObject.Object.(*danav1.MigrationHierarchy).Spec.DryRun && common.ShouldReconcile(phase) {
+		return r.preview(mhObject)
+	} else if common.ShouldReconcile(phase) {
 		return r.reconcile(mhObject)
 	} else {
 		logger.Info("no need to reconcile, object phase is: ", "phase", phase)
@@ -67,6 +76,10 @@ func (r *MigrationHierarchyReconciler) Reconcile(ctx context.Context, req ctrl.R
 	return ctrl.Result{}, nil
 }
 
+// reconcile executes the steps of a migration which were not completed yet, so that a migration which was
+// interrupted is resumed after its last completed step. The step is recorded in the status of the
+// MigrationHierarchy after every step is completed, and every step can be safely executed again in case
+// the controller stopped before the step was recorded.
 func (r *MigrationHierarchyReconciler) reconcile(mhObject *objectcontext.ObjectContext) (ctrl.Result, error) {
 	ctx := mhObject.Ctx
 	logger := log.FromContext(ctx)
@@ -84,171 +97,158 @@ func (r *MigrationHierarchyReconciler) reconcile(mhObject *objectcontext.ObjectC
 		return ctrl.Result{}, fmt.Errorf("failed getting namespace object %q: %v", currentNamespace, err.Error())
 	}
 
-	sourceSNSParentName := nsutils.Parent(ns.Object)
-	oldSNS, err := objectcontext.New(ctx, r.Client, client.ObjectKey{Name: currentNamespace, Namespace: sourceSNSParentName}, &danav1.Subnamespace{})
+	toNS, err := objectcontext.New(ctx, r.Client, client.ObjectKey{Name: toNamespace}, &corev1.Namespace{})
 	if err != nil {
 		updateErr := updateMHStatus(mhObject, danav1.Error, err.Error())
 		if updateErr != nil {
 			return ctrl.Result{}, updateErr
 		}
-		return ctrl.Result{}, fmt.Errorf("failed getting subnamespace object %q: %v", currentNamespace, err.Error())
+		return ctrl.Result{}, fmt.Errorf("failed to get namespace %q: %v", toNamespace, err.Error())
 	}
 
-	sourceQuotaObj, err := quota.NamespaceObject(ns)
-	if err != nil {
-		updateErr := updateMHStatus(mhObject, danav1.Error, err.Error())
-		if updateErr != nil {
-			return ctrl.Result{}, updateErr
+	if phase == danav1.None {
+		transfer, err := composeMigrationQuotaTransfer(ns, toNS)
+		if err != nil {
+			updateErr := updateMHStatus(mhObject, danav1.Error, err.Error())
+			if updateErr != nil {
+				return ctrl.Result{}, updateErr
+			}
+			return ctrl.Result{}, fmt.Errorf("failed composing quota transfer for migration %q: %v", mhObject.Name(), err.Error())
 		}
-		return ctrl.Result{}, fmt.Errorf("failed getting quota object %q: %v", currentNamespace, err.Error())
-	}
-
-	sourceResources := quota.GetQuotaObjectSpec(sourceQuotaObj.Object)
-	rootNSName := nsutils.DisplayNameSlice(ns)[0]
-
-	// add the resources that are allocated to the migrated subnamespace to the new parent using UpdateQuota API
-	sourceQuotaObjExists, _, _ := quota.DoesSubnamespaceObjectExist(oldSNS)
 
-	toNS, err := objectcontext.New(ctx, r.Client, client.ObjectKey{Name: toNamespace}, &corev1.Namespace{})
-	if err != nil {
-		updateErr := updateMHStatus(mhObject, danav1.Error, err.Error())
-		if updateErr != nil {
+		// record the original parent and the quota transfer together with the phase of the Migration Hierarchy, so
+		// that in case of an error or a requeue the migration is resumed instead of being composed again from the new hierarchy
+		if updateErr := startMigration(mhObject, nsutils.Parent(ns.Object), transfer); updateErr != nil {
 			return ctrl.Result{}, updateErr
 		}
-		return ctrl.Result{}, fmt.Errorf("failed to get namespace %q: %v", toNamespace, err.Error())
+		logger.Info("successfully updated status of MigrationHierarchy object", "phase", danav1.InProgress)
 	}
 
-	toSNSParentName := nsutils.Parent(toNS.Object)
-	toSNS, err := objectcontext.New(ctx, r.Client, client.ObjectKey{Name: toNamespace, Namespace: toSNSParentName}, &danav1.Subnamespace{})
+	sourceSNSParentName := mhObject.Object.(*danav1.MigrationHierarchy).Status.OriginalParent
+	oldSNS, err := objectcontext.New(ctx, r.Client, client.ObjectKey{Name: currentNamespace, Namespace: sourceSNSParentName}, &danav1.Subnamespace{})
 	if err != nil {
 		updateErr := updateMHStatus(mhObject, danav1.Error, err.Error())
 		if updateErr != nil {
 			return ctrl.Result{}, updateErr
 		}
-		return ctrl.Result{}, fmt.Errorf("failed getting subnamespace object %q: %v", toNamespace, err.Error())
+		return ctrl.Result{}, fmt.Errorf("failed getting subnamespace object %q: %v", currentNamespace, err.Error())
 	}
 
-	if phase == danav1.None {
-		if sourceQuotaObjExists {
-			// temporarily increase the quota of the root namespace to make
-			// sure the migrationUPQ doesn't result in an error due to insufficient resources
-			if err := increaseRootResources(mhObject, rootNSName, sourceResources); err != nil {
-				updateErr := updateMHStatus(mhObject, danav1.Error, err.Error())
-				if updateErr != nil {
-					return ctrl.Result{}, updateErr
-				}
-				return ctrl.Result{}, fmt.Errorf("failed increasing root resources for migration %q: %v", mhObject.Name(), err.Error())
+	// reserve the quota of the migrated subnamespace in its new branch before the subnamespace is created there,
+	// so that no subnamespace on the new branch holds more than its quota while the subnamespace is moved
+	if !isStepCompleted(mhObject, danav1.QuotaReserved) {
+		acquired, err := r.scheduleQuotaTransfer(mhObject, func() error {
+			if message, err := validateQuotaTransfer(mhObject, ns); err != nil {
+				return err
+			} else if message != "" {
+				return fmt.Errorf("it's forbidden to migrate %q to %q since %s", currentNamespace, toNamespace, message)
 			}
 
-			toSNSCRQPointer := toSNS.Object.(*danav1.Subnamespace).Annotations[danav1.CrqPointer]
-			if err := createMigrationUPQ(mhObject, sourceResources, rootNSName, toSNSCRQPointer); err != nil {
-				updateErr := updateMHStatus(mhObject, danav1.Error, err.Error())
-				if updateErr != nil {
-					return ctrl.Result{}, updateErr
-				}
-				return ctrl.Result{}, fmt.Errorf("failed create updateQuota for migration %q: %v", mhObject.Name(), err.Error())
+			if err := applyQuotaTransfer(mhObject, false); err != nil {
+				return fmt.Errorf("failed reserving quota for migration %q: %v", mhObject.Name(), err.Error())
 			}
+			return nil
+		})
+		if err != nil {
+			updateErr := updateMHStatus(mhObject, danav1.Error, err.Error())
+			if updateErr != nil {
+				return ctrl.Result{}, updateErr
+			}
+			return ctrl.Result{}, err
 		}
-
-		// update the phase of the Migration Hierarchy to make sure that in case of an error or a requeue, the resources
-		// that have been added for the migration will not be added again
-		if updateErr := updateMHStatus(mhObject, danav1.InProgress, ""); updateErr != nil {
-			return ctrl.Result{}, updateErr
+		if !acquired {
+			return ctrl.Result{}, nil
 		}
-		logger.Info("successfully updated status of MigrationHierarchy object", "phase", danav1.InProgress)
+		if err := completeStep(mhObject, danav1.QuotaReserved); err != nil {
+			return ctrl.Result{}, err
+		}
+		logger.Info("successfully reserved quota of subnamespace in new parent", "subnamespace", currentNamespace, "new parent", toNamespace)
 	}
 
-	// requeue until the updateQuota is Complete to make sure resources are updated
-	// before moving forward with the migration process
-	if sourceQuotaObjExists {
-		if res, err := monitorMigrationUPQ(mhObject, rootNSName); err != nil {
+	if !isStepCompleted(mhObject, danav1.NewSNSCreated) {
+		if _, err := r.createNewSNS(oldSNS, currentNamespace, toNamespace); err != nil {
 			updateErr := updateMHStatus(mhObject, danav1.Error, err.Error())
 			if updateErr != nil {
 				return ctrl.Result{}, updateErr
 			}
-			return ctrl.Result{}, fmt.Errorf("failed to add migration resources: %v", err.Error())
-		} else if !res.IsZero() {
-			return res, nil
+			return ctrl.Result{}, fmt.Errorf("failed creating subnamespace %q under namespace %q: %v", currentNamespace, toNamespace, err.Error())
 		}
-		logger.Info("successfully added resources for migration", "new parent", toNamespace)
+		if err := completeStep(mhObject, danav1.NewSNSCreated); err != nil {
+			return ctrl.Result{}, err
+		}
+		logger.Info("successfully created new subnamespace under new parent", "subnamespace", currentNamespace, "new parent", toNamespace)
 	}
 
-	// resources have been added to the new parent to complete the migration, so continue with migration
-	newSNS, err := r.createNewSNS(oldSNS, currentNamespace, toNamespace)
+	newSNS, err := objectcontext.New(ctx, r.Client, client.ObjectKey{Name: currentNamespace, Namespace: toNamespace}, &danav1.Subnamespace{})
 	if err != nil {
 		updateErr := updateMHStatus(mhObject, danav1.Error, err.Error())
 		if updateErr != nil {
 			return ctrl.Result{}, updateErr
 		}
-		return ctrl.Result{}, fmt.Errorf("failed creating subnamespace %q under namespace %q: %v", currentNamespace, toNamespace, err.Error())
+		return ctrl.Result{}, fmt.Errorf("failed getting subnamespace object %q: %v", currentNamespace, err.Error())
 	}
 
-	logger.Info("successfully created new subnamespace under new parent", "subnamespace", currentNamespace, "new parent", toNamespace)
-
-	if err := r.deleteOldSNS(oldSNS, newSNS, toNamespace); err != nil {
-		updateErr := updateMHStatus(mhObject, danav1.Error, err.Error())
-		if updateErr != nil {
-			return ctrl.Result{}, updateErr
+	if !isStepCompleted(mhObject, danav1.OldSNSDeleted) {
+		if err := r.deleteOldSNS(oldSNS, newSNS, toNamespace); err != nil {
+			updateErr := updateMHStatus(mhObject, danav1.Error, err.Error())
+			if updateErr != nil {
+				return ctrl.Result{}, updateErr
+			}
+			return ctrl.Result{}, fmt.Errorf("failed deleting old subnamespace %q: %v", oldSNS.Name(), err.Error())
 		}
-		return ctrl.Result{}, fmt.Errorf("failed deleting old subnamespace %q: %v", oldSNS.Name(), err.Error())
-	}
-
-	logger.Info("successfully deleted old subnamespace from old parent", "subnamesapce", currentNamespace, "old parent", sourceSNSParentName)
-
-	if err := r.updateRelatedObjects(mhObject, toNS, ns); err != nil {
-		updateErr := updateMHStatus(mhObject, danav1.Error, err.Error())
-		if updateErr != nil {
-			return ctrl.Result{}, updateErr
+		if err := completeStep(mhObject, danav1.OldSNSDeleted); err != nil {
+			return ctrl.Result{}, err
 		}
-		return ctrl.Result{}, fmt.Errorf("failed to update related objects: %v", err.Error())
+		logger.Info("successfully deleted old subnamespace from old parent", "subnamesapce", currentNamespace, "old parent", sourceSNSParentName)
 	}
-	logger.Info("successfully updated related objects of subnamespace", "subnamespace", currentNamespace)
 
-	// after the migration is completed, we need to update the db to account for the new parent
-	// MigrateNsHierarchy updates the namespace and its children hierarchy to be under the new parent in the DB
-	if err := namespacedb.MigrateNSHierarchy(ctx, r.NamespaceDB, r.Client, ns.Name(), toNS.Name()); err != nil {
-		updateErr := updateMHStatus(mhObject, danav1.Error, err.Error())
-		if updateErr != nil {
-			return ctrl.Result{}, updateErr
+	if !isStepCompleted(mhObject, danav1.RelatedUpdated) {
+		if err := r.updateRelatedObjects(mhObject, toNS, ns); err != nil {
+			updateErr := updateMHStatus(mhObject, danav1.Error, err.Error())
+			if updateErr != nil {
+				return ctrl.Result{}, updateErr
+			}
+			return ctrl.Result{}, fmt.Errorf("failed to update related objects: %v", err.Error())
 		}
-		return ctrl.Result{}, fmt.Errorf("failed migrating subnamespace %q in namespacedb: %v", ns.Name(), err.Error())
-	}
-	logger.Info("successfully migrated subnamespace in namespacedb", "subnamespace", currentNamespace)
-
-	// enqueue for reconciliation the original parent of the subnamespace that should be migrated in order for
-	// the old parent's status to show the now-changed list of child subnamespaces
-	if err := r.enqueueOriginalParent(ctx, sourceSNSParentName); err != nil {
-		updateErr := updateMHStatus(mhObject, danav1.Error, err.Error())
-		if updateErr != nil {
-			return ctrl.Result{}, updateErr
+		if err := completeStep(mhObject, danav1.RelatedUpdated); err != nil {
+			return ctrl.Result{}, err
 		}
-		return ctrl.Result{}, fmt.Errorf("failed to enqueue %q: %v", sourceSNSParentName, err.Error())
+		logger.Info("successfully updated related objects of subnamespace", "subnamespace", currentNamespace)
 	}
-	logger.Info("successfully enqueued original parent namespace", "oldParent", sourceSNSParentName)
-
-	// enqueue for reconciliation the descendants of the subnamespace so that their labels and annotations
-	// are updated properly
-	r.enqueueSNSDescendants(newSNS)
-	logger.Info("successfully enqueued descendants of subnamespace", "subnamespace", currentNamespace)
 
-	// subtract the resources that were allocated to the migrated subnamespace from the old parent using UpdateQuota API,
-	// don't wait for it to finish since it shouldn't block
-	if sourceQuotaObjExists {
-		if err := createMigrationUPQ(mhObject, sourceResources, sourceSNSParentName, rootNSName); err != nil {
+	if !isStepCompleted(mhObject, danav1.DBUpdated) {
+		if err := r.updateDB(ns, toNS, newSNS, sourceSNSParentName); err != nil {
 			updateErr := updateMHStatus(mhObject, danav1.Error, err.Error())
 			if updateErr != nil {
 				return ctrl.Result{}, updateErr
 			}
-			return ctrl.Result{}, fmt.Errorf("failed to create updateQuota for migration %q: %v", mhObject.Name(), err.Error())
+			return ctrl.Result{}, err
 		}
-		// decrease the quota of the root namespace which was temporarily added
-		if err := decreaseRootResources(mhObject, rootNSName, sourceResources); err != nil {
+		if err := completeStep(mhObject, danav1.DBUpdated); err != nil {
+			return ctrl.Result{}, err
+		}
+		logger.Info("successfully migrated subnamespace in namespacedb", "subnamespace", currentNamespace)
+	}
+
+	// release the quota of the migrated subnamespace from its old branch, which no longer holds it
+	if !isStepCompleted(mhObject, danav1.QuotaReleased) {
+		acquired, err := r.scheduleQuotaTransfer(mhObject, func() error {
+			return applyQuotaTransfer(mhObject, true)
+		})
+		if err != nil {
 			updateErr := updateMHStatus(mhObject, danav1.Error, err.Error())
 			if updateErr != nil {
 				return ctrl.Result{}, updateErr
 			}
-			return ctrl.Result{}, fmt.Errorf("failed decreasing root resources for migration: %v", err.Error())
+			return ctrl.Result{}, fmt.Errorf("failed releasing quota for migration %q: %v", mhObject.Name(), err.Error())
+		}
+		if !acquired {
+			return ctrl.Result{}, nil
 		}
+		if err := completeStep(mhObject, danav1.QuotaReleased); err != nil {
+			return ctrl.Result{}, err
+		}
+		logger.Info("successfully released quota of subnamespace from old parent", "subnamespace", currentNamespace, "old parent", sourceSNSParentName)
 	}
 
 	if err := updateMHStatus(mhObject, danav1.Complete, ""); err != nil {
@@ -263,6 +263,76 @@ func (r *MigrationHierarchyReconciler) reconcile(mhObject *objectcontext.ObjectC
 	return ctrl.Result{}, nil
 }
 
+// composeMigrationQuotaTransfer returns the quota transfer of a migrated subnamespace, or nil if the
+// subnamespace does not have its own quota object and therefore has no quota to transfer.
+func composeMigrationQuotaTransfer(ns, toNS *objectcontext.ObjectContext) (*danav1.QuotaTransfer, error) {
+	ctx := ns.Ctx
+
+	oldSNS, err := objectcontext.New(ctx, ns.Client, client.ObjectKey{Name: ns.Name(), Namespace: nsutils.Parent(ns.Object)}, &danav1.Subnamespace{})
+	if err != nil {
+		return nil, fmt.Errorf("failed getting subnamespace object %q: %v", ns.Name(), err.Error())
+	}
+
+	if sourceQuotaObjExists, _, _ := quota.DoesSubnamespaceObjectExist(oldSNS); !sourceQuotaObjExists {
+		return nil, nil
+	}
+
+	sourceQuotaObj, err := quota.NamespaceObject(ns)
+	if err != nil {
+		return nil, fmt.Errorf("failed getting quota object %q: %v", ns.Name(), err.Error())
+	}
+	sourceResources := quota.GetQuotaObjectSpec(sourceQuotaObj.Object)
+
+	toSNS, err := objectcontext.New(ctx, ns.Client, client.ObjectKey{Name: toNS.Name(), Namespace: nsutils.Parent(toNS.Object)}, &danav1.Subnamespace{})
+	if err != nil {
+		return nil, fmt.Errorf("failed getting subnamespace object %q: %v", toNS.Name(), err.Error())
+	}
+
+	// the quota is transferred to the namespace which holds the quota of the new parent, which
+	// is the new parent itself or the upper ResourcePool the new parent belongs to
+	quotaOwnerName := toSNS.Object.(*danav1.Subnamespace).Annotations[danav1.CrqPointer]
+	if quotaOwnerName == "" {
+		quotaOwnerName = toNS.Name()
+	}
+	quotaOwnerNS, err := objectcontext.New(ctx, ns.Client, client.ObjectKey{Name: quotaOwnerName}, &corev1.Namespace{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get namespace %q: %v", quotaOwnerName, err.Error())
+	}
+
+	return composeQuotaTransfer(ns, quotaOwnerNS, sourceResources.Hard)
+}
+
+// updateDB updates the namespacedb to account for the new parent of a migrated subnamespace, and enqueues
+// the original parent and the descendants of the subnamespace so that they are updated as well.
+func (r *MigrationHierarchyReconciler) updateDB(ns, toNS, newSNS *objectcontext.ObjectContext, sourceSNSParentName string) error {
+	ctx := ns.Ctx
+	logger := log.FromContext(ctx)
+
+	root, err := nsutils.Root(ns.Object)
+	if err != nil {
+		return fmt.Errorf("failed to get root namespace of %q: %v", ns.Name(), err.Error())
+	}
+
+	// MigrateNsHierarchy updates the namespace and its children hierarchy to be under the new parent in the DB
+	if err := namespacedb.MigrateNSHierarchy(ctx, r.NamespaceDB, r.Client, root, ns.Name(), toNS.Name()); err != nil {
+		return fmt.Errorf("failed migrating subnamespace %q in namespacedb: %v", ns.Name(), err.Error())
+	}
+
+	// enqueue for reconciliation the original parent of the subnamespace that should be migrated in order for
+	// the old parent's status to show the now-changed list of child subnamespaces
+	if err := r.enqueueOriginalParent(ctx, sourceSNSParentName); err != nil {
+		return fmt.Errorf("failed to enqueue %q: %v", sourceSNSParentName, err.Error())
+	}
+	logger.Info("successfully enqueued original parent namespace", "oldParent", sourceSNSParentName)
+
+	// enqueue for reconciliation the descendants of the subnamespace so that their labels and annotations
+	// are updated properly
+	r.enqueueSNSDescendants(newSNS)
+	logger.Info("successfully enqueued descendants of subnamespace", "subnamespace", ns.Name())
+
+	return nil
+}
+
 // createNewSNS handles the creation of the migrated subnamespace under a new parent.
 func (r *MigrationHierarchyReconciler) createNewSNS(sns *objectcontext.ObjectContext, currentNamespace, toNamespace string) (*objectcontext.ObjectContext, error) {
 	newSNS, err := r.createSNS(sns, currentNamespace, toNamespace)
@@ -300,6 +370,8 @@ func (r *MigrationHierarchyReconciler) createSNS(sns *objectcontext.ObjectContex
 	labels[danav1.ResourcePool] = sns.Object.GetLabels()[danav1.ResourcePool]
 
 	composedNewSNS := ComposeSNS(currentNamespace, toNamespace, resources, labels)
+	composedNewSNS.Spec.Owners = sns.Object.(*danav1.Subnamespace).Spec.Owners
+	composedNewSNS.Spec.InheritancePolicy = sns.Object.(*danav1.Subnamespace).Spec.InheritancePolicy
 	composedNewSNS.Status.Phase = danav1.Migrated
 
 	newSNS, err := objectcontext.New(sns.Ctx, r.Client, types.NamespacedName{Name: snsName}, composedNewSNS)
@@ -359,14 +431,9 @@ func (r *MigrationHierarchyReconciler) enqueueSNSDescendants(sns *objectcontext.
 }
 
 // addSnsToSnsEvent takes two parameters: snsName and snsNamespace
-// then adds the sns to the sns event channel to trigger new re-sync for the sns.
+// then enqueues the sns to trigger new re-sync for the sns.
 func (r *MigrationHierarchyReconciler) addSnsToSnsEvent(snsName string, snsNamespace string) {
-	r.SnsEvents <- event.GenericEvent{Object: &danav1.Subnamespace{
-		ObjectMeta: metav1.ObjectMeta{
-			Name:      snsName,
-			Namespace: snsNamespace,
-		},
-	}}
+	r.SnsEvents.Enqueue(snsName, snsNamespace)
 }
 
 // ensureSnsEqualAnnotations makes sure that the annotations of a namespace are equal to the given annotations.
@@ -413,6 +480,43 @@ func ensureSnsEqualLabels(ctx context.Context, parentNS, childNS *objectcontext.
 	return nil
 }
 
+// startMigration sets the phase of the MigrationHierarchy object to InProgress and records the original
+// parent of the migrated subnamespace and its quota transfer.
+func startMigration(mhObject *objectcontext.ObjectContext, originalParent string, transfer *danav1.QuotaTransfer) error {
+	err := mhObject.EnsureUpdateObject(func(object client.Object, l logr.Logger) (client.Object, logr.Logger, error) {
+		object.(*danav1.MigrationHierarchy).Status.Phase = danav1.InProgress
+		object.(*danav1.MigrationHierarchy).Status.OriginalParent = originalParent
+		object.(*danav1.MigrationHierarchy).Status.QuotaTransfer = transfer
+		return object, l, nil
+	}, false)
+
+	if err != nil {
+		return fmt.Errorf("failed updating the status of object %q: %v", mhObject.Name(), err.Error())
+	}
+
+	return nil
+}
+
+// isStepCompleted returns true if a step of the migration of a MigrationHierarchy was already completed.
+func isStepCompleted(mhObject *objectcontext.ObjectContext, step danav1.MigrationStep) bool {
+	completed := mhObject.Object.(*danav1.MigrationHierarchy).Status.Step
+	return completed != "" && slices.Index(danav1.MigrationSteps, completed) >= slices.Index(danav1.MigrationSteps, step)
+}
+
+// completeStep records a completed step of the migration in the status of the MigrationHierarchy object.
+func completeStep(mhObject *objectcontext.ObjectContext, step danav1.MigrationStep) error {
+	err := mhObject.EnsureUpdateObject(func(object client.Object, l logr.Logger) (client.Object, logr.Logger, error) {
+		object.(*danav1.MigrationHierarchy).Status.Step = step
+		return object, l, nil
+	}, false)
+
+	if err != nil {
+		return fmt.Errorf("failed updating the status of object %q: %v", mhObject.Name(), err.Error())
+	}
+
+	return nil
+}
+
 // updateMHStatus updates the status of the MH object.
 func updateMHStatus(mhObject *objectcontext.ObjectContext, phase danav1.Phase, reason string) error {
 	err := mhObject.UpdateObject(func(object client.Object, l logr.Logger) (client.Object, logr.Logger) {
@@ -12,6 +12,7 @@ import (
 	"github.com/dana-team/hns/internal/subnamespace/resourcepool"
 	"github.com/dana-team/hns/internal/subnamespace/snsutils"
 	quotav1 "github.com/openshift/api/quota/v1"
+	authenticationv1 "k8s.io/api/authentication/v1"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -19,18 +20,39 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
 )
 
-func (v *MigrationHierarchyValidator) handleCreate(mhObject *objectcontext.ObjectContext, reqUser string) admission.Response {
-	ctx := mhObject.Ctx
+func (v *MigrationHierarchyValidator) handleCreate(mhObject *objectcontext.ObjectContext, userInfo authenticationv1.UserInfo) admission.Response {
+	currentNSName := mhObject.Object.(*danav1.MigrationHierarchy).Spec.CurrentNamespace
+	toNSName := mhObject.Object.(*danav1.MigrationHierarchy).Spec.ToNamespace
+
+	if mhObject.Object.(*danav1.MigrationHierarchy).Spec.Revert {
+		message := "it's forbidden to create a MigrationHierarchy which is reverted, only a failed migration can be reverted"
+		return admission.Denied(message)
+	}
+
+	if response := v.ValidateMigration(mhObject.Ctx, currentNSName, toNSName, userInfo, true); !response.Allowed {
+		return response
+	}
+
+	// the number of namespaces under the new parent is only reported by a dry-run, which does not migrate anything
+	if mhObject.Object.(*danav1.MigrationHierarchy).Spec.DryRun {
+		return admission.Allowed("")
+	}
+
+	return v.validateKeyCount(mhObject.Ctx, currentNSName, toNSName)
+}
+
+// ValidateMigration validates that a user may migrate a Subnamespace to be under a new parent. The migration
+// loop validation can be skipped by callers which validate loops against a planned hierarchy instead of the
+// current one.
+func (v *MigrationHierarchyValidator) ValidateMigration(ctx context.Context, currentNSName, toNSName string, userInfo authenticationv1.UserInfo, validateLoop bool) admission.Response {
 	logger := log.FromContext(ctx)
 
-	currentNSName := mhObject.Object.(*danav1.MigrationHierarchy).Spec.CurrentNamespace
 	currentNS, err := objectcontext.New(ctx, v.Client, client.ObjectKey{Name: currentNSName}, &corev1.Namespace{})
 	if err != nil {
 		logger.Error(err, "failed to create object", "currentNS", currentNSName)
 		return admission.Errored(http.StatusBadRequest, err)
 	}
 
-	toNSName := mhObject.Object.(*danav1.MigrationHierarchy).Spec.ToNamespace
 	toNS, err := objectcontext.New(ctx, v.Client, client.ObjectKey{Name: toNSName}, &corev1.Namespace{})
 	if err != nil {
 		logger.Error(err, "failed to create object", "toNS", toNSName)
@@ -53,16 +75,29 @@ func (v *MigrationHierarchyValidator) handleCreate(mhObject *objectcontext.Objec
 		return response
 	}
 
-	currentNSSliced := nsutils.DisplayNameSlice(currentNS)
-	toNSSliced := nsutils.DisplayNameSlice(toNS)
+	currentNSSliced, err := nsutils.Ancestors(currentNS.Object)
+	if err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+
+	toNSSliced, err := nsutils.Ancestors(toNS.Object)
+	if err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+	if response := common.ValidateSameRoot(currentNSSliced, toNSSliced); !response.Allowed {
+		return response
+	}
+
 	ancestorNSName, isAncestorRoot, err := snsutils.GetAncestor(currentNSSliced, toNSSliced)
 	if err != nil {
 		logger.Error(err, "failed to get ancestor", "source namespace", currentNSSliced, "destination namespace", toNSSliced)
 		return admission.Errored(http.StatusBadRequest, err)
 	}
 
-	if response := v.validateMigrationLoop(toNSSliced, currentNSName); !response.Allowed {
-		return response
+	if validateLoop {
+		if response := v.validateMigrationLoop(toNSSliced, currentNSName); !response.Allowed {
+			return response
+		}
 	}
 
 	// validate the source and destination namespaces are under the same secondary root only
@@ -73,7 +108,10 @@ func (v *MigrationHierarchyValidator) handleCreate(mhObject *objectcontext.Objec
 		}
 	}
 
-	if response := common.ValidatePermissions(ctx, currentNSSliced, currentNSName, toNSName, ancestorNSName, reqUser, false, v.Client); !response.Allowed {
+	// the user needs permissions on both the current parent and the new parent of the subnamespace,
+	// or on their common ancestor, since the migration changes the children of both
+	currentParentNSName := nsutils.Parent(currentNS.Object)
+	if response := common.ValidatePermissions(ctx, currentNSSliced, currentParentNSName, toNSName, ancestorNSName, userInfo, common.MigrateVerb, false, v.Client, v.Authorizer); !response.Allowed {
 		return response
 	}
 
@@ -102,14 +140,45 @@ func (v *MigrationHierarchyValidator) handleCreate(mhObject *objectcontext.Objec
 		}
 	}
 
+	return admission.Allowed("")
+}
+
+// validateKeyCount validates that migrating a Subnamespace will not cause its new parent to exceed
+// the maximum limit of namespaces in its hierarchy, unless one of them is a ResourcePool.
+func (v *MigrationHierarchyValidator) validateKeyCount(ctx context.Context, currentNSName, toNSName string) admission.Response {
+	currentNS, err := objectcontext.New(ctx, v.Client, client.ObjectKey{Name: currentNSName}, &corev1.Namespace{})
+	if err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+
+	toNS, err := objectcontext.New(ctx, v.Client, client.ObjectKey{Name: toNSName}, &corev1.Namespace{})
+	if err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+
+	isCurrentNSResourcePool, err := resourcepool.IsNSResourcePool(currentNS)
+	if err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+
+	isToNSResourcePool, err := resourcepool.IsNSResourcePool(toNS)
+	if err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+
 	if !isCurrentNSResourcePool && !isToNSResourcePool {
-		currentNSKey := v.NamespaceDB.Key(currentNSName)
-		toNSKey := v.NamespaceDB.Key(toNSName)
+		root, err := nsutils.Root(currentNS.Object)
+		if err != nil {
+			return admission.Errored(http.StatusBadRequest, err)
+		}
+
+		currentNSKey := v.NamespaceDB.Key(root, currentNSName)
+		toNSKey := v.NamespaceDB.Key(root, toNSName)
 
 		if currentNSKey != "" && toNSKey != "" {
 			// validate that the new requested parent doesn't already have too many subnamespaces in its branch
 			// the maximum number a subnamespace can have in its branch is called by the MaxSNS cli flag
-			if response := v.validateKeyCountInDB(ctx, toNSKey, currentNSName); !response.Allowed {
+			if response := v.validateKeyCountInDB(ctx, root, toNSKey, currentNSName); !response.Allowed {
 				return response
 			}
 		}
@@ -163,15 +232,15 @@ func (v *MigrationHierarchyValidator) validateNonUpperRPToSNSMigration(isCurrent
 // validateKeyCountInDB validates that migrating a subnamespace and all its children
 // to the new parent subnamespace will not cause the new parent to exceed the maximum
 // limit of namespaces in its hierarchy.
-func (v *MigrationHierarchyValidator) validateKeyCountInDB(ctx context.Context, toNSKey, currentNSName string) admission.Response {
+func (v *MigrationHierarchyValidator) validateKeyCountInDB(ctx context.Context, root, toNSKey, currentNSName string) admission.Response {
 	logger := log.FromContext(ctx)
-	childrenNum, err := getNSChildrenNum(ctx, v.Client, currentNSName)
+	childrenNum, err := GetNSChildrenNum(ctx, v.Client, currentNSName)
 	if err != nil {
 		logger.Error(err, "failed to compute number of children", "currentNS", currentNSName)
 		return admission.Denied(err.Error())
 	}
 
-	if (v.NamespaceDB.KeyCount(toNSKey) + childrenNum) >= v.MaxSNS {
+	if (v.NamespaceDB.KeyCount(root, toNSKey) + childrenNum) >= v.MaxSNS {
 		message := fmt.Sprintf("it's forbidden to create more than %v namespaces under hierarchy %q", v.MaxSNS, toNSKey)
 		return admission.Denied(message)
 	}
@@ -179,8 +248,8 @@ func (v *MigrationHierarchyValidator) validateKeyCountInDB(ctx context.Context,
 	return admission.Allowed("")
 }
 
-// getNSChildrenNum returns the number of children of a subnamespace by looking at its CRQ.
-func getNSChildrenNum(ctx context.Context, c client.Client, nsname string) (int, error) {
+// GetNSChildrenNum returns the number of children of a subnamespace by looking at its CRQ.
+func GetNSChildrenNum(ctx context.Context, c client.Client, nsname string) (int, error) {
 	crq := quotav1.ClusterResourceQuota{}
 	if err := c.Get(ctx, types.NamespacedName{Name: nsname}, &crq); err != nil {
 		return 0, err
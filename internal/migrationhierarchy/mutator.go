@@ -42,7 +42,7 @@ func (m *MigrationHierarchyMutator) UpdateRequester(migrationHierarchyObject dan
 	if migrationHierarchyObject.Annotations == nil {
 		migrationHierarchyObject.Annotations = make(map[string]string)
 	}
-	migrationHierarchyObject.Annotations["requester"] = requester
+	migrationHierarchyObject.Annotations[danav1.Requester] = requester
 	marshalUpdateQuota, err := json.Marshal(migrationHierarchyObject)
 	if err != nil {
 		return nil, err
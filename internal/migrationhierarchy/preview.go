@@ -0,0 +1,268 @@
+package migrationhierarchy
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	danav1 "github.com/dana-team/hns/api/v1"
+	"github.com/dana-team/hns/internal/common"
+	"github.com/dana-team/hns/internal/namespace/nsutils"
+	"github.com/dana-team/hns/internal/objectcontext"
+	"github.com/dana-team/hns/internal/quota"
+	"github.com/dana-team/hns/internal/rolebinding/rbutils"
+	"github.com/dana-team/hns/internal/subnamespace/resourcepool"
+	"github.com/dana-team/hns/internal/subnamespace/snsutils"
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// preview computes the impact a MigrationHierarchy would have if it was executed and writes it to its
+// status, without changing anything else.
+func (r *MigrationHierarchyReconciler) preview(mhObject *objectcontext.ObjectContext) (ctrl.Result, error) {
+	logger := log.FromContext(mhObject.Ctx)
+
+	preview, err := r.composePreview(mhObject)
+	if err != nil {
+		updateErr := updateMHStatus(mhObject, danav1.Error, err.Error())
+		if updateErr != nil {
+			return ctrl.Result{}, updateErr
+		}
+		return ctrl.Result{}, fmt.Errorf("failed computing preview of migration %q: %v", mhObject.Name(), err.Error())
+	}
+
+	if err := updateMHPreview(mhObject, preview); err != nil {
+		return ctrl.Result{}, err
+	}
+	logger.Info("successfully updated status of MigrationHierarchy object", "phase", danav1.Complete, "preview", preview)
+
+	return ctrl.Result{}, nil
+}
+
+// composePreview returns the impact a MigrationHierarchy would have if it was executed.
+func (r *MigrationHierarchyReconciler) composePreview(mhObject *objectcontext.ObjectContext) (*danav1.MigrationPreview, error) {
+	ctx := mhObject.Ctx
+	currentNamespace := mhObject.Object.(*danav1.MigrationHierarchy).Spec.CurrentNamespace
+	toNamespace := mhObject.Object.(*danav1.MigrationHierarchy).Spec.ToNamespace
+
+	ns, err := objectcontext.New(ctx, r.Client, client.ObjectKey{Name: currentNamespace}, &corev1.Namespace{})
+	if err != nil {
+		return nil, fmt.Errorf("failed getting namespace object %q: %v", currentNamespace, err.Error())
+	}
+
+	toNS, err := objectcontext.New(ctx, r.Client, client.ObjectKey{Name: toNamespace}, &corev1.Namespace{})
+	if err != nil {
+		return nil, fmt.Errorf("failed getting namespace object %q: %v", toNamespace, err.Error())
+	}
+
+	preview := &danav1.MigrationPreview{}
+
+	for _, descendant := range snsutils.GetAllChildren(ns) {
+		preview.Namespaces = append(preview.Namespaces, descendant.Name())
+	}
+
+	if preview.GainedRoleBindings, preview.LostRoleBindings, err = r.previewRoleBindings(ns, toNS); err != nil {
+		return nil, err
+	}
+
+	if preview.AnnotationChanges, err = previewAnnotationChanges(ns, toNS); err != nil {
+		return nil, err
+	}
+
+	if preview.QuotaTransfer, err = composeMigrationQuotaTransfer(ns, toNS); err != nil {
+		return nil, err
+	}
+
+	if preview.QuotaFits, preview.QuotaMessage, err = previewQuotaFits(ns, preview.QuotaTransfer); err != nil {
+		return nil, err
+	}
+
+	if err := r.previewKeyCount(ns, toNS, preview); err != nil {
+		return nil, err
+	}
+
+	return preview, nil
+}
+
+// previewRoleBindings returns the names of the RoleBindings the migrated namespaces would gain and lose by
+// inheritance. The RoleBindings of a namespace are inherited by all its descendants, so the inherited RoleBindings
+// of the migrated namespaces change from the ones of the old parent to the ones of the new parent.
+func (r *MigrationHierarchyReconciler) previewRoleBindings(ns, toNS *objectcontext.ObjectContext) ([]string, []string, error) {
+	oldParentRoleBindings, err := r.inheritedRoleBindings(ns, nsutils.Parent(ns.Object))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	newParentRoleBindings, err := r.inheritedRoleBindings(ns, toNS.Name())
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var gained, lost []string
+	for _, name := range newParentRoleBindings {
+		if !slices.Contains(oldParentRoleBindings, name) {
+			gained = append(gained, name)
+		}
+	}
+	for _, name := range oldParentRoleBindings {
+		if !slices.Contains(newParentRoleBindings, name) {
+			lost = append(lost, name)
+		}
+	}
+
+	return gained, lost, nil
+}
+
+// inheritedRoleBindings returns the sorted names of the RoleBindings of a namespace which are inherited by its children.
+func (r *MigrationHierarchyReconciler) inheritedRoleBindings(ns *objectcontext.ObjectContext, nsName string) ([]string, error) {
+	roleBindings, err := objectcontext.NewList(ns.Ctx, r.Client, &rbacv1.RoleBindingList{}, client.InNamespace(nsName))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list rolebindings in namespace %q: %v", nsName, err.Error())
+	}
+
+	exclusions, err := common.GetSubjectExclusions(ns.Ctx, r.Client)
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	for _, roleBinding := range roleBindings.Objects.(*rbacv1.RoleBindingList).Items {
+		if rbutils.IsHNSRelated(&roleBinding, exclusions) {
+			names = append(names, roleBinding.Name)
+		}
+	}
+	sort.Strings(names)
+
+	return names, nil
+}
+
+// previewAnnotationChanges returns the changes the migration would make to the ClusterResourceQuota
+// selectors of the migrated namespace and to the ClusterResourceQuota pointer of its Subnamespace.
+func previewAnnotationChanges(ns, toNS *objectcontext.ObjectContext) ([]danav1.AnnotationChange, error) {
+	var changes []danav1.AnnotationChange
+
+	annotations := ns.Object.GetAnnotations()
+	newAnnotations := nsutils.AnnotationsBasedOnParent(toNS, ns.Name())
+
+	var keys []string
+	for key := range newAnnotations {
+		if strings.HasPrefix(key, danav1.CrqSelector) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		if annotations[key] != newAnnotations[key] {
+			changes = append(changes, danav1.AnnotationChange{Key: key, Before: annotations[key], After: newAnnotations[key]})
+		}
+	}
+
+	sns, err := nsutils.SNSFromNamespace(ns)
+	if err != nil {
+		return nil, fmt.Errorf("failed getting subnamespace object %q: %v", ns.Name(), err.Error())
+	}
+
+	// a Subnamespace which does not point to its own ClusterResourceQuota points to the
+	// one of the upper ResourcePool it belongs to, which would be the one of the new parent
+	crqPointer := quota.GetCrqPointer(sns.Object)
+	newCrqPointer := crqPointer
+	if crqPointer != sns.Name() {
+		toSNS, err := nsutils.SNSFromNamespace(toNS)
+		if err != nil {
+			return nil, fmt.Errorf("failed getting subnamespace object %q: %v", toNS.Name(), err.Error())
+		}
+
+		isToNSResourcePool, err := resourcepool.IsNSResourcePool(toNS)
+		if err != nil {
+			return nil, err
+		}
+
+		if toSNS != nil && isToNSResourcePool {
+			newCrqPointer = quota.GetCrqPointer(toSNS.Object)
+		} else {
+			newCrqPointer = sns.Name()
+		}
+	}
+
+	if crqPointer != newCrqPointer {
+		changes = append(changes, danav1.AnnotationChange{Key: danav1.CrqPointer, Before: crqPointer, After: newCrqPointer})
+	}
+
+	return changes, nil
+}
+
+// previewQuotaFits returns true if the common ancestor has enough free resources to reserve the quota of the
+// migrated Subnamespace in the new branch, and every Subnamespace of the old branch can release it without having
+// less quota than its workloads use. Otherwise, it returns false together with a message which explains why.
+func previewQuotaFits(ns *objectcontext.ObjectContext, transfer *danav1.QuotaTransfer) (bool, string, error) {
+	if transfer == nil {
+		return true, "", nil
+	}
+
+	message, err := validateMigrationQuotaTransfer(ns, transfer)
+	if err != nil {
+		return false, "", err
+	}
+
+	return message == "", message, nil
+}
+
+// previewKeyCount sets the key of the new parent in the NamespaceDB in the preview, together with the number
+// of namespaces the key would have after the migration and whether it would exceed the maximum.
+func (r *MigrationHierarchyReconciler) previewKeyCount(ns, toNS *objectcontext.ObjectContext, preview *danav1.MigrationPreview) error {
+	isCurrentNSResourcePool, err := resourcepool.IsNSResourcePool(ns)
+	if err != nil {
+		return err
+	}
+
+	isToNSResourcePool, err := resourcepool.IsNSResourcePool(toNS)
+	if err != nil {
+		return err
+	}
+
+	if isCurrentNSResourcePool || isToNSResourcePool {
+		return nil
+	}
+
+	root, err := nsutils.Root(ns.Object)
+	if err != nil {
+		return err
+	}
+
+	toNSKey := r.NamespaceDB.Key(root, toNS.Name())
+	if r.NamespaceDB.Key(root, ns.Name()) == "" || toNSKey == "" {
+		return nil
+	}
+
+	childrenNum, err := GetNSChildrenNum(ns.Ctx, r.Client, ns.Name())
+	if err != nil {
+		return fmt.Errorf("failed to compute number of children of %q: %v", ns.Name(), err.Error())
+	}
+
+	preview.NamespaceDBKey = toNSKey
+	preview.NamespaceDBKeyCount = r.NamespaceDB.KeyCount(root, toNSKey) + childrenNum
+	preview.ExceedsMaxSNS = preview.NamespaceDBKeyCount >= r.MaxSNS
+
+	return nil
+}
+
+// updateMHPreview sets the preview of the MigrationHierarchy object and sets its phase to Complete.
+func updateMHPreview(mhObject *objectcontext.ObjectContext, preview *danav1.MigrationPreview) error {
+	err := mhObject.EnsureUpdateObject(func(object client.Object, l logr.Logger) (client.Object, logr.Logger, error) {
+		object.(*danav1.MigrationHierarchy).Status.Phase = danav1.Complete
+		object.(*danav1.MigrationHierarchy).Status.Preview = preview
+		return object, l, nil
+	}, false)
+
+	if err != nil {
+		return fmt.Errorf("failed updating the status of object %q: %v", mhObject.Name(), err.Error())
+	}
+
+	return nil
+}
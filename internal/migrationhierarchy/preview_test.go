@@ -0,0 +1,78 @@
+package migrationhierarchy
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	danav1 "github.com/dana-team/hns/api/v1"
+	"github.com/dana-team/hns/internal/objectcontext"
+	"github.com/dana-team/hns/internal/testutils"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func TestPreviewQuotaFits(t *testing.T) {
+	tests := []struct {
+		name            string
+		rootHard        corev1.ResourceList
+		oldParentUsed   corev1.ResourceList
+		expectedFits    bool
+		expectedMessage string
+	}{
+		{
+			name:          "ancestor has enough free resources",
+			rootHard:      cpu("40"),
+			oldParentUsed: cpu("4"),
+			expectedFits:  true,
+		},
+		{
+			name:            "ancestor does not have enough free resources",
+			rootHard:        cpu("32"),
+			oldParentUsed:   cpu("4"),
+			expectedMessage: `not enough free resources of type "cpu" in "root"`,
+		},
+		{
+			name:            "old parent uses more than it would keep",
+			rootHard:        cpu("40"),
+			oldParentUsed:   cpu("12"),
+			expectedMessage: `active workloads in the hierarchy of "a" use more resources of type "cpu"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// subnamespace d, with a quota of 5 CPUs, would be migrated from a to b. The quota of d would be reserved
+			// in b from the free resources of root, and a would be left with 5 CPUs, of which d uses 2
+			fakeClient := testutils.NewFakeClient(t,
+				testNamespace("root", "", "0"), testNamespace("a", "root", "1"), testNamespace("b", "root", "1"), testNamespace("d", "a", "2"),
+				testSubnamespace("a", "root", cpu("10")), testSubnamespace("b", "root", cpu("20")), testSubnamespace("d", "a", cpu("5")),
+				testResourceQuota("root", tt.rootHard, nil), testResourceQuota("a", cpu("10"), tt.oldParentUsed),
+				testResourceQuota("b", cpu("20"), nil), testResourceQuota("d", cpu("5"), cpu("2")),
+			)
+
+			ns, err := objectcontext.New(context.Background(), fakeClient, types.NamespacedName{Name: "d"}, &corev1.Namespace{})
+			if err != nil {
+				t.Fatalf("failed to get namespace: %v", err)
+			}
+
+			transfer := &danav1.QuotaTransfer{
+				Resources: cpu("5"),
+				Ancestor:  "root",
+				Reserve:   []danav1.QuotaTransferStep{{Subnamespace: "b", Parent: "root"}},
+				Release:   []danav1.QuotaTransferStep{{Subnamespace: "a", Parent: "root"}},
+			}
+
+			fits, message, err := previewQuotaFits(ns, transfer)
+			if err != nil {
+				t.Fatalf("failed to preview quota: %v", err)
+			}
+			if fits != tt.expectedFits {
+				t.Errorf("expected quota fits to be %v, got %v with message %q", tt.expectedFits, fits, message)
+			}
+			if !strings.Contains(message, tt.expectedMessage) || (tt.expectedMessage == "" && message != "") {
+				t.Errorf("expected message to contain %q, got %q", tt.expectedMessage, message)
+			}
+		})
+	}
+}
@@ -0,0 +1,216 @@
+package migrationhierarchy
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	danav1 "github.com/dana-team/hns/api/v1"
+	"github.com/dana-team/hns/internal/namespace/nsutils"
+	"github.com/dana-team/hns/internal/objectcontext"
+	"github.com/dana-team/hns/internal/quota"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// QuotaPlan holds the quota transfers of one or more migrations which are executed one after the other, and
+// validates that the namespaces on their paths have enough free resources for them. A migrated subnamespace
+// holds its quota in its new branch before the quota is released from its old branch, so the common ancestor
+// of every migration must have enough free resources for the quota of the migrated subnamespace on its own.
+// Since a migration does not change the free resources of any namespace, every reservation is validated against
+// the current free resources of its common ancestor, while the quota released from a subnamespace by all the
+// migrations is validated together against the resources the subnamespace keeps using.
+type QuotaPlan struct {
+	reservations []quotaReservation
+	releases     map[types.NamespacedName]*quotaRelease
+}
+
+// quotaReservation is the quota a migration reserves from the free resources of a common ancestor.
+type quotaReservation struct {
+	subnamespace string
+	ancestor     string
+	resources    corev1.ResourceList
+}
+
+// quotaRelease is the quota released from a subnamespace, together with the resources
+// used by the migrated subnamespaces which no longer count towards its usage.
+type quotaRelease struct {
+	resources corev1.ResourceList
+	used      corev1.ResourceList
+}
+
+// NewQuotaPlan returns an empty QuotaPlan.
+func NewQuotaPlan() *QuotaPlan {
+	return &QuotaPlan{releases: map[types.NamespacedName]*quotaRelease{}}
+}
+
+// AddMigration adds the quota transfer of the migration of the subnamespace of namespace ns to namespace toNS.
+func (p *QuotaPlan) AddMigration(ns, toNS *objectcontext.ObjectContext) error {
+	transfer, err := composeMigrationQuotaTransfer(ns, toNS)
+	if err != nil {
+		return err
+	}
+	if transfer == nil {
+		return nil
+	}
+
+	quotaObject, err := quota.NamespaceObject(ns)
+	if err != nil {
+		return fmt.Errorf("failed getting quota object %q: %v", ns.Name(), err.Error())
+	}
+
+	p.AddTransfer(ns.Name(), transfer, quota.GetQuotaUsed(quotaObject.Object))
+	return nil
+}
+
+// AddTransfer adds a quota transfer of the migration of a subnamespace, which uses the given resources.
+func (p *QuotaPlan) AddTransfer(snsName string, transfer *danav1.QuotaTransfer, used corev1.ResourceList) {
+	p.reservations = append(p.reservations, quotaReservation{subnamespace: snsName, ancestor: transfer.Ancestor, resources: transfer.Resources})
+
+	for _, step := range transfer.Release {
+		key := types.NamespacedName{Name: step.Subnamespace, Namespace: step.Parent}
+		if p.releases[key] == nil {
+			p.releases[key] = &quotaRelease{}
+		}
+		p.releases[key].resources = quota.AddResourceLists(p.releases[key].resources, transfer.Resources)
+		p.releases[key].used = quota.AddResourceLists(p.releases[key].used, used)
+	}
+}
+
+// Validate returns a message which explains why the namespaces on the paths of the quota transfers of the plan do
+// not have enough free resources for them, or an empty string if they do. A resource which is transferred must be
+// part of the quota of every namespace on the path of the transfer.
+func (p *QuotaPlan) Validate(ctx context.Context, k8sClient client.Client) (string, error) {
+	for _, reservation := range p.reservations {
+		ancestorNS, err := objectcontext.New(ctx, k8sClient, client.ObjectKey{Name: reservation.ancestor}, &corev1.Namespace{})
+		if err != nil {
+			return "", fmt.Errorf("failed getting namespace object %q: %v", reservation.ancestor, err.Error())
+		}
+
+		free, ok, err := FreeQuota(ancestorNS)
+		if err != nil {
+			return "", err
+		}
+		if !ok {
+			continue
+		}
+
+		if resourceName, missing := missingResource(free, reservation.resources); missing {
+			return fmt.Sprintf("there are not enough free resources of type %q in %q for the quota of %q",
+				resourceName.String(), reservation.ancestor, reservation.subnamespace), nil
+		}
+	}
+
+	keys := make([]types.NamespacedName, 0, len(p.releases))
+	for key := range p.releases {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i].Name < keys[j].Name })
+
+	for _, key := range keys {
+		sns, err := objectcontext.New(ctx, k8sClient, key, &danav1.Subnamespace{})
+		if err != nil {
+			return "", fmt.Errorf("failed getting subnamespace object %q: %v", key.Name, err.Error())
+		}
+		if !sns.IsPresent() {
+			return "", fmt.Errorf("subnamespace %q does not exist in namespace %q", key.Name, key.Namespace)
+		}
+
+		hard := quota.SubnamespaceSpec(sns.Object).Hard
+		if resourceName, missing := missingResource(hard, p.releases[key].resources); missing {
+			return fmt.Sprintf("there are not enough resources of type %q in %q to release the quota of the migrated "+
+				"subnamespaces", resourceName.String(), key.Name), nil
+		}
+
+		quotaObject, err := quota.SubnamespaceObject(sns)
+		if err != nil {
+			return "", fmt.Errorf("failed getting quota object %q: %v", key.Name, err.Error())
+		}
+		if !quotaObject.IsPresent() {
+			continue
+		}
+
+		remaining := quota.SubResourceLists(hard, p.releases[key].resources)
+		used := quota.SubResourceLists(quota.GetQuotaUsed(quotaObject.Object), p.releases[key].used)
+		if resourceName, missing := missingResource(remaining, usedResources(used, remaining)); missing {
+			return fmt.Sprintf("active workloads in the hierarchy of %q use more resources of type %q than it would "+
+				"have after the quota of the migrated subnamespaces is released", key.Name, resourceName.String()), nil
+		}
+	}
+
+	return "", nil
+}
+
+// FreeQuota returns the resources of a namespace which are free to allocate, which are its quota minus the quota
+// allocated to its children, or minus the resources it uses if it has no children. It returns false if the
+// namespace does not have a quota object of its own.
+func FreeQuota(ns *objectcontext.ObjectContext) (corev1.ResourceList, bool, error) {
+	var quotaObject *objectcontext.ObjectContext
+	var err error
+	if nsutils.IsRoot(ns.Object) {
+		quotaObject, err = quota.RootNSObject(ns)
+	} else {
+		quotaObject, err = quota.NamespaceObject(ns)
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("failed getting quota object %q: %v", ns.Name(), err.Error())
+	}
+	if !quotaObject.IsPresent() {
+		return nil, false, nil
+	}
+
+	children, err := objectcontext.NewList(ns.Ctx, ns.Client, &danav1.SubnamespaceList{}, client.InNamespace(ns.Name()))
+	if err != nil {
+		return nil, false, fmt.Errorf("failed getting children of namespace %q: %v", ns.Name(), err.Error())
+	}
+
+	allocated := corev1.ResourceList{}
+	for _, child := range children.Objects.(*danav1.SubnamespaceList).Items {
+		allocated = quota.AddResourceLists(allocated, child.Spec.ResourceQuotaSpec.Hard)
+	}
+	if len(children.Objects.(*danav1.SubnamespaceList).Items) == 0 {
+		allocated = quota.GetQuotaUsed(quotaObject.Object)
+	}
+
+	hard := quota.GetQuotaObjectSpec(quotaObject.Object).Hard
+	free := corev1.ResourceList{}
+	for resourceName, quantity := range hard {
+		available := quantity.DeepCopy()
+		available.Sub(allocated[resourceName])
+		free[resourceName] = available
+	}
+
+	return free, true, nil
+}
+
+// missingResource returns the first resource, by name, of the required resources which is missing
+// from the available resources or which is more than the available quantity of the resource.
+func missingResource(available, required corev1.ResourceList) (corev1.ResourceName, bool) {
+	resourceNames := make([]string, 0, len(required))
+	for resourceName := range required {
+		resourceNames = append(resourceNames, resourceName.String())
+	}
+	sort.Strings(resourceNames)
+
+	for _, resourceName := range resourceNames {
+		quantity, ok := available[corev1.ResourceName(resourceName)]
+		if !ok || quantity.Cmp(required[corev1.ResourceName(resourceName)]) < 0 {
+			return corev1.ResourceName(resourceName), true
+		}
+	}
+
+	return "", false
+}
+
+// usedResources returns the used resources which are part of a quota and whose quantity is more than zero.
+func usedResources(used, hard corev1.ResourceList) corev1.ResourceList {
+	resources := corev1.ResourceList{}
+	for resourceName, quantity := range used {
+		if _, ok := hard[resourceName]; ok && quantity.Sign() > 0 {
+			resources[resourceName] = quantity
+		}
+	}
+
+	return resources
+}
@@ -8,7 +8,9 @@ import (
 	danav1 "github.com/dana-team/hns/api/v1"
 	"github.com/dana-team/hns/internal/namespace/nsutils"
 	"github.com/dana-team/hns/internal/objectcontext"
+	"github.com/dana-team/hns/internal/rolebinding/rbutils"
 	"github.com/dana-team/hns/internal/subnamespace/resourcepool"
+	"github.com/dana-team/hns/internal/subnamespace/snsutils"
 	"github.com/go-logr/logr"
 	quotav1 "github.com/openshift/api/quota/v1"
 	corev1 "k8s.io/api/core/v1"
@@ -23,11 +25,11 @@ import (
 func (r *MigrationHierarchyReconciler) updateRelatedObjects(mhObject, toNS, ns *objectcontext.ObjectContext) error {
 	ctx := mhObject.Ctx
 
-	if err := r.UpdateNSBasedOnParent(ctx, toNS, ns); err != nil {
+	if err := UpdateNSBasedOnParent(ctx, toNS, ns); err != nil {
 		return fmt.Errorf("failed updating the labels and annotations of namespace %q according to its parent %q: %v", ns.Name(), toNS.Name(), err.Error())
 	}
 
-	if err := r.UpdateAllNSChildrenOfNs(ctx, ns); err != nil {
+	if err := UpdateAllNSChildrenOfNs(ctx, ns); err != nil {
 		return fmt.Errorf("failed updating labels and annotations of child namespaces of sunamespace %q: %v", ns.Name(), err.Error())
 	}
 
@@ -35,11 +37,24 @@ func (r *MigrationHierarchyReconciler) updateRelatedObjects(mhObject, toNS, ns *
 		return fmt.Errorf("failed updating role of subnamespace %q: %v", toNS.Name(), err.Error())
 	}
 
+	updateInheritedRoleBindings(mhObject, ns)
+
 	return nil
 }
 
+// updateInheritedRoleBindings syncs the inherited RoleBindings of the migrated namespace and of
+// all its descendants with their new ancestors. Failing to sync them does not fail the migration,
+// since the inherited RoleBindings of every namespace are also synced when the namespace is reconciled.
+func updateInheritedRoleBindings(mhObject, ns *objectcontext.ObjectContext) {
+	for _, descendant := range snsutils.GetAllChildren(ns) {
+		if err := rbutils.EnsureInheritedRoleBindings(descendant); err != nil {
+			mhObject.Log.Info("failed syncing inherited roleBindings of namespace, it will be synced when the namespace is reconciled", "namespace", descendant.Name(), "error", err.Error())
+		}
+	}
+}
+
 // UpdateAllNSChildrenOfNs updates all the children namespaces of a parent namespace recursively.
-func (r *MigrationHierarchyReconciler) UpdateAllNSChildrenOfNs(ctx context.Context, parentNS *objectcontext.ObjectContext) error {
+func UpdateAllNSChildrenOfNs(ctx context.Context, parentNS *objectcontext.ObjectContext) error {
 	snsChildren, err := objectcontext.NewList(ctx, parentNS.Client, &danav1.SubnamespaceList{}, client.InNamespace(parentNS.Name()))
 	if err != nil {
 		return err
@@ -47,11 +62,11 @@ func (r *MigrationHierarchyReconciler) UpdateAllNSChildrenOfNs(ctx context.Conte
 
 	for _, sns := range snsChildren.Objects.(*danav1.SubnamespaceList).Items {
 		ns, _ := objectcontext.New(ctx, parentNS.Client, types.NamespacedName{Name: sns.GetName()}, &corev1.Namespace{})
-		if err := r.UpdateNSBasedOnParent(ctx, parentNS, ns); err != nil {
+		if err := UpdateNSBasedOnParent(ctx, parentNS, ns); err != nil {
 			return err
 		}
 
-		if err = r.UpdateAllNSChildrenOfNs(ctx, ns); err != nil {
+		if err = UpdateAllNSChildrenOfNs(ctx, ns); err != nil {
 			return err
 		}
 	}
@@ -61,9 +76,12 @@ func (r *MigrationHierarchyReconciler) UpdateAllNSChildrenOfNs(ctx context.Conte
 
 // UpdateNSBasedOnParent updates the labels and annotations of a namespace
 // based on its parent labels and annotations.
-func (r *MigrationHierarchyReconciler) UpdateNSBasedOnParent(ctx context.Context, parentNS, childNS *objectcontext.ObjectContext) error {
+func UpdateNSBasedOnParent(ctx context.Context, parentNS, childNS *objectcontext.ObjectContext) error {
 	nsName := childNS.Name()
-	labels := nsutils.LabelsBasedOnParent(parentNS, nsName)
+	labels, err := nsutils.LabelsBasedOnParent(parentNS, nsName)
+	if err != nil {
+		return err
+	}
 	annotations := nsutils.AnnotationsBasedOnParent(parentNS, nsName)
 
 	if err := childNS.AppendAnnotations(annotations); err != nil {
@@ -86,7 +104,7 @@ func (r *MigrationHierarchyReconciler) UpdateNSBasedOnParent(ctx context.Context
 	}
 
 	if !isChildNSResourcePool || isChildNSUpperResourcePool {
-		if err := r.updateCRQSelector(childNS, parentNS, nsName); err != nil {
+		if err := updateCRQSelector(childNS, parentNS, nsName); err != nil {
 			return err
 		}
 	}
@@ -104,7 +122,7 @@ func (r *MigrationHierarchyReconciler) UpdateNSBasedOnParent(ctx context.Context
 }
 
 // updateCRQSelector updates the ClusterResourceQuota selector of a namespace.
-func (r *MigrationHierarchyReconciler) updateCRQSelector(childNS, parentNS *objectcontext.ObjectContext, nsName string) error {
+func updateCRQSelector(childNS, parentNS *objectcontext.ObjectContext, nsName string) error {
 	ctx := childNS.Ctx
 
 	crq := quotav1.ClusterResourceQuota{}
@@ -114,7 +132,7 @@ func (r *MigrationHierarchyReconciler) updateCRQSelector(childNS, parentNS *obje
 
 	// use retry on conflict to update the CRQ
 	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
-		if err := r.Client.Get(ctx, types.NamespacedName{Name: childNS.Name()}, &crq); err != nil {
+		if err := childNS.Client.Get(ctx, types.NamespacedName{Name: childNS.Name()}, &crq); err != nil {
 			if errors.IsNotFound(err) {
 				return nil
 			}
@@ -122,7 +140,7 @@ func (r *MigrationHierarchyReconciler) updateCRQSelector(childNS, parentNS *obje
 		}
 
 		crq.Spec.Selector.AnnotationSelector = crqAnnotation
-		if err := r.Client.Update(ctx, &crq); err != nil {
+		if err := childNS.Client.Update(ctx, &crq); err != nil {
 			return err
 		}
 
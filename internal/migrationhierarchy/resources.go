@@ -4,133 +4,319 @@ import (
 	"fmt"
 
 	danav1 "github.com/dana-team/hns/api/v1"
+	"github.com/dana-team/hns/internal/common"
+	"github.com/dana-team/hns/internal/namespace/nsutils"
 	"github.com/dana-team/hns/internal/objectcontext"
 	"github.com/dana-team/hns/internal/quota"
-	"github.com/dana-team/hns/internal/updatequota/upqutils"
+	"github.com/dana-team/hns/internal/subnamespace/snsutils"
 	"github.com/go-logr/logr"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/types"
-	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
-// increaseRootResources increases the resources of the root namespace quota object.
-func increaseRootResources(mhObject *objectcontext.ObjectContext, rootNSName string, sourceResources corev1.ResourceQuotaSpec) error {
-	rootNSQuotaObj, err := quota.RootNSObjectFromName(mhObject, rootNSName)
+// composeQuotaTransfer returns the transfer of the quota of a migrated subnamespace from its old parent to the
+// quota owner of its new parent, which is the new parent itself or the upper ResourcePool it belongs to. The
+// resources are subtracted from the subnamespaces on the way from the old parent up to the common ancestor and
+// added to the subnamespaces on the way from the common ancestor down to the new quota owner, so the quota of
+// the common ancestor, and of the root namespace, is never changed.
+func composeQuotaTransfer(ns, toNS *objectcontext.ObjectContext, resources corev1.ResourceList) (*danav1.QuotaTransfer, error) {
+	nsSliced, err := nsutils.Ancestors(ns.Object)
 	if err != nil {
-		return fmt.Errorf("failed to get root namespace quota object: %v", err.Error())
+		return nil, err
 	}
+	oldParentSliced := nsSliced[:len(nsSliced)-1]
 
-	if err := addRootQuota(rootNSQuotaObj, sourceResources); err != nil {
-		return fmt.Errorf("failed to add resources to root namespace quota object: %v", err.Error())
+	toNSSliced, err := nsutils.Ancestors(toNS.Object)
+	if err != nil {
+		return nil, err
 	}
 
-	return nil
+	ancestorNSName, _, err := snsutils.GetAncestor(oldParentSliced, toNSSliced)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find ancestor namespace of %q and %q: %v", oldParentSliced, toNSSliced, err.Error())
+	}
+
+	transfer := &danav1.QuotaTransfer{
+		Resources: resources,
+		Ancestor:  ancestorNSName,
+	}
 
+	for i := len(oldParentSliced) - 1; i > 0 && oldParentSliced[i] != ancestorNSName; i-- {
+		transfer.Release = append(transfer.Release, danav1.QuotaTransferStep{Subnamespace: oldParentSliced[i], Parent: oldParentSliced[i-1]})
+	}
+
+	index, err := common.IndexOf(ancestorNSName, toNSSliced)
+	if err != nil {
+		return nil, err
+	}
+	for i := index + 1; i < len(toNSSliced); i++ {
+		transfer.Reserve = append(transfer.Reserve, danav1.QuotaTransferStep{Subnamespace: toNSSliced[i], Parent: toNSSliced[i-1]})
+	}
+
+	return transfer, nil
+}
+
+// validateQuotaTransfer returns a message which explains why the namespaces on the path of the quota transfer of a
+// MigrationHierarchy do not have enough free resources for it, or an empty string if they do. A transfer whose
+// reservation has already started is not validated again, since the resources it reserved are no longer free.
+func validateQuotaTransfer(mhObject, ns *objectcontext.ObjectContext) (string, error) {
+	transfer := mhObject.Object.(*danav1.MigrationHierarchy).Status.QuotaTransfer
+	if transfer == nil {
+		return "", nil
+	}
+
+	for _, step := range transfer.Reserve {
+		if step.Done || step.Marker != "" {
+			return "", nil
+		}
+	}
+
+	return validateMigrationQuotaTransfer(ns, transfer)
 }
 
-// decreaseRootResources decreases the resources of the root namespace quota object.
-func decreaseRootResources(mhObject *objectcontext.ObjectContext, rootNSName string, sourceResources corev1.ResourceQuotaSpec) error {
-	rootNSQuotaObj, err := quota.RootNSObjectFromName(mhObject, rootNSName)
+// validateMigrationQuotaTransfer returns a message which explains why the free resources of the common ancestor
+// and the subnamespaces of the old branch are not enough for the quota transfer of the migration of the
+// subnamespace of namespace ns, or an empty string if they are.
+func validateMigrationQuotaTransfer(ns *objectcontext.ObjectContext, transfer *danav1.QuotaTransfer) (string, error) {
+	quotaObject, err := quota.NamespaceObject(ns)
 	if err != nil {
-		return fmt.Errorf("failed to get root namespace quota object: %v", err.Error())
+		return "", fmt.Errorf("failed getting quota object %q: %v", ns.Name(), err.Error())
 	}
 
-	if err := subRootQuota(rootNSQuotaObj, sourceResources); err != nil {
-		return fmt.Errorf("failed to subtract resources from root namespace quota object: %v", err.Error())
+	quotaPlan := NewQuotaPlan()
+	quotaPlan.AddTransfer(ns.Name(), transfer, quota.GetQuotaUsed(quotaObject.Object))
+	return quotaPlan.Validate(ns.Ctx, ns.Client)
+}
+
+// scheduleQuotaTransfer runs the given function, which changes the quota of the subnamespaces of the quota transfer of
+// a MigrationHierarchy, once the Scheduler allows it. The quota transfer is scheduled together with the UpdateQuota
+// operations and the quota transfers of other MigrationHierarchies, so that it does not run in parallel to operations
+// which change the quota of the same subnamespaces or draw from the free resources of the same common ancestor. It
+// returns false if the quota transfer has to wait, in which case the MigrationHierarchy is enqueued once it may run.
+func (r *MigrationHierarchyReconciler) scheduleQuotaTransfer(mhObject *objectcontext.ObjectContext, transfer func() error) (bool, error) {
+	quotaTransfer := mhObject.Object.(*danav1.MigrationHierarchy).Status.QuotaTransfer
+	if quotaTransfer == nil {
+		return true, transfer()
 	}
 
-	return nil
+	path := []string{quotaTransfer.Ancestor}
+	for _, step := range quotaTransfer.Reserve {
+		path = append(path, step.Subnamespace)
+	}
+	for _, step := range quotaTransfer.Release {
+		path = append(path, step.Subnamespace)
+	}
+
+	key := types.NamespacedName{Name: mhObject.Name()}
+	if acquired, position := r.Scheduler.Acquire(key, path, r.MHEvents); !acquired {
+		mhObject.Log.Info("waiting for operations on overlapping subnamespaces", "position", position)
+		return false, nil
+	}
+	defer r.Scheduler.Release(key)
 
+	return true, transfer()
 }
 
-// addRootQuota updates the resource quota for a root namespace by adding the quota specified
-// in quotaSpec to the existing quota.
-func addRootQuota(rootNSQuotaObj *objectcontext.ObjectContext, quotaSpec corev1.ResourceQuotaSpec) error {
-	err := rootNSQuotaObj.EnsureUpdateObject(func(object client.Object, l logr.Logger) (client.Object, logr.Logger, error) {
-		for resourceName := range quotaSpec.Hard {
-			var (
-				before, _  = object.(*corev1.ResourceQuota).Spec.Hard[resourceName]
-				request, _ = quotaSpec.Hard[resourceName]
-			)
-			before.Set(before.Value() + request.Value())
-			object.(*corev1.ResourceQuota).Spec.Hard[resourceName] = before
+// applyQuotaTransfer applies the release steps or the reserve steps of the quota transfer of a MigrationHierarchy
+// which were not applied yet. The resources are reserved in the new branch, from the free resources of the common
+// ancestor, before the subnamespace is moved, and are released from the old branch once it no longer holds the
+// subnamespace, so that no subnamespace holds more than its quota while the subnamespace is moved.
+func applyQuotaTransfer(mhObject *objectcontext.ObjectContext, release bool) error {
+	transfer := mhObject.Object.(*danav1.MigrationHierarchy).Status.QuotaTransfer
+	if transfer == nil {
+		return nil
+	}
+
+	for i := range transferSteps(transfer, release) {
+		if err := applyQuotaTransferStep(mhObject, release, i); err != nil {
+			return err
 		}
-		return object, l, nil
-	}, false)
+	}
 
-	return err
+	return nil
 }
 
-// subRootQuota updates the resource quota for a root namespace by subtracting the quota specified
-// in quotaSpec to the existing quota.
-func subRootQuota(rootNSQuotaObj *objectcontext.ObjectContext, quotaSpec corev1.ResourceQuotaSpec) error {
-	err := rootNSQuotaObj.EnsureUpdateObject(func(object client.Object, l logr.Logger) (client.Object, logr.Logger, error) {
-		for resourceName := range quotaSpec.Hard {
-			var (
-				before, _  = object.(*corev1.ResourceQuota).Spec.Hard[resourceName]
-				request, _ = quotaSpec.Hard[resourceName]
-			)
-			before.Set(before.Value() - request.Value())
-			object.(*corev1.ResourceQuota).Spec.Hard[resourceName] = before
+// revertQuotaTransfer reverts the release steps or the reserve steps of the quota transfer of a MigrationHierarchy
+// which were applied, in the opposite order to the one they were applied in.
+func revertQuotaTransfer(mhObject *objectcontext.ObjectContext, release bool) error {
+	transfer := mhObject.Object.(*danav1.MigrationHierarchy).Status.QuotaTransfer
+	if transfer == nil {
+		return nil
+	}
+
+	for i := len(transferSteps(transfer, release)) - 1; i >= 0; i-- {
+		if err := revertQuotaTransferStep(mhObject, release, i); err != nil {
+			return err
 		}
-		return object, l, nil
-	}, false)
+	}
 
-	return err
+	return nil
 }
 
-// createMigrationUPQ performs an UpdateQuota with the resources given.
-func createMigrationUPQ(mhObject *objectcontext.ObjectContext, sourceResources corev1.ResourceQuotaSpec, sourceNS, destNS string) error {
-	mhName := mhObject.Name()
+// transferSteps returns the release steps or the reserve steps of a quota transfer.
+func transferSteps(transfer *danav1.QuotaTransfer, release bool) []danav1.QuotaTransferStep {
+	if release {
+		return transfer.Release
+	}
+
+	return transfer.Reserve
+}
 
-	if err := createUpdateQuota(mhObject, mhName, sourceNS, destNS, sourceResources); err != nil {
-		return fmt.Errorf("failed to create updateQuota for migration %q: %v", mhObject.Name(), err.Error())
+// applyQuotaTransferStep applies a single step of the quota transfer of a MigrationHierarchy, by adding the resources
+// to the current quota of the subnamespace of a reserve step, or subtracting them from the current quota of the
+// subnamespace of a release step. Changes made to the quota of the subnamespace by other operations are therefore kept.
+func applyQuotaTransferStep(mhObject *objectcontext.ObjectContext, release bool, index int) error {
+	step := transferSteps(mhObject.Object.(*danav1.MigrationHierarchy).Status.QuotaTransfer, release)[index]
+	if step.Done {
+		return nil
 	}
 
-	return nil
+	marker := quotaTransferMarker(mhObject, release, index, false)
+	return changeQuotaTransferStepQuota(mhObject, release, index, marker, !release, func(step *danav1.QuotaTransferStep) {
+		step.Done = true
+	})
+}
+
+// revertQuotaTransferStep reverts a single step of the quota transfer of a MigrationHierarchy which was applied, by
+// applying the opposite change to the current quota of the subnamespace: the resources are added back to a subnamespace
+// they were released from and are subtracted from a subnamespace they were reserved in. Changes made to the quota of the
+// subnamespace after the step was applied are therefore kept.
+func revertQuotaTransferStep(mhObject *objectcontext.ObjectContext, release bool, index int) error {
+	step := transferSteps(mhObject.Object.(*danav1.MigrationHierarchy).Status.QuotaTransfer, release)[index]
+	if !step.Done {
+		return nil
+	}
+
+	marker := quotaTransferMarker(mhObject, release, index, true)
+	return changeQuotaTransferStepQuota(mhObject, release, index, marker, release, func(step *danav1.QuotaTransferStep) {
+		step.Done = false
+	})
 }
 
-// monitorMigrationUPQ requeues the MigrationHierarchy object until the UpdateQuota is in Complete Phase,
-// it checks the status of the UpdateQuota object created to provide resources for the migration.
-func monitorMigrationUPQ(mhObject *objectcontext.ObjectContext, ns string) (ctrl.Result, error) {
-	upqPhase, err := getUpdateQuotaStatus(mhObject, ns)
+// changeQuotaTransferStepQuota adds the resources of the quota transfer of a MigrationHierarchy to the current quota of
+// the subnamespace of one of its steps, or subtracts them from it, and then updates the step. The quota is changed in
+// the same update which sets the given marker annotation on the subnamespace, and the marker is recorded in the step
+// right before, so a change which was made before an interruption is recognized by the marker and is not made again.
+func changeQuotaTransferStepQuota(mhObject *objectcontext.ObjectContext, release bool, index int, marker string, add bool, update func(step *danav1.QuotaTransferStep)) error {
+	step := transferSteps(mhObject.Object.(*danav1.MigrationHierarchy).Status.QuotaTransfer, release)[index]
+
+	sns, err := quotaTransferStepSNS(mhObject, step)
 	if err != nil {
-		return ctrl.Result{}, fmt.Errorf("failed getting updateQuota object status %q: %v", mhObject.Name(), err.Error())
+		return err
+	}
+
+	if step.Marker != marker {
+		if err := updateQuotaTransferStep(mhObject, release, index, func(step *danav1.QuotaTransferStep) {
+			step.Marker = marker
+		}); err != nil {
+			return err
+		}
+	}
+
+	resources := mhObject.Object.(*danav1.MigrationHierarchy).Status.QuotaTransfer.Resources
+	if err := sns.EnsureUpdateObject(func(object client.Object, l logr.Logger) (client.Object, logr.Logger, error) {
+		if object.GetAnnotations()[danav1.QuotaTransferMarker] == marker {
+			return object, l, nil
+		}
+
+		hard := quota.SubnamespaceSpec(object).Hard
+		if add {
+			hard = quota.AddResourceLists(hard, resources)
+		} else {
+			hard = quota.SubResourceLists(hard, resources)
+		}
+		object.(*danav1.Subnamespace).Spec.ResourceQuotaSpec.Hard = hard
+
+		annotations := object.GetAnnotations()
+		if annotations == nil {
+			annotations = map[string]string{}
+		}
+		annotations[danav1.QuotaTransferMarker] = marker
+		object.SetAnnotations(annotations)
+
+		l = l.WithValues("resources", hard)
+		return object, l, nil
+	}, false); err != nil {
+		return fmt.Errorf("failed updating the quota of subnamespace %q: %v", sns.Name(), err.Error())
+	}
+
+	// the quota object of the subnamespace must be updated before the quota of the next subnamespace
+	// is changed, since the quota objects are used to validate that there are enough resources for it
+	if err := quota.EnsureSubnamespaceObjectEqual(sns); err != nil {
+		return err
+	}
+	mhObject.Log.Info("successfully updated quota of subnamespace for migration", "subnamespace", sns.Name(), "marker", marker)
+
+	if err := updateQuotaTransferStep(mhObject, release, index, func(step *danav1.QuotaTransferStep) {
+		update(step)
+		step.Marker = ""
+	}); err != nil {
+		return err
 	}
 
-	if upqPhase == danav1.Error {
-		return ctrl.Result{}, fmt.Errorf("failed to do updateQuota for migration %q, phase Error", mhObject.Name())
+	return removeQuotaTransferMarker(sns, marker)
+}
+
+// removeQuotaTransferMarker removes the given marker annotation from a subnamespace once the step which set it
+// is recorded as changed in the MigrationHierarchy, unless the annotation was already replaced by another marker.
+func removeQuotaTransferMarker(sns *objectcontext.ObjectContext, marker string) error {
+	if sns.Object.GetAnnotations()[danav1.QuotaTransferMarker] != marker {
+		return nil
 	}
 
-	if upqPhase != danav1.Complete {
-		return ctrl.Result{Requeue: true}, nil
+	if err := sns.EnsureUpdateObject(func(object client.Object, l logr.Logger) (client.Object, logr.Logger, error) {
+		if annotations := object.GetAnnotations(); annotations[danav1.QuotaTransferMarker] == marker {
+			delete(annotations, danav1.QuotaTransferMarker)
+			object.SetAnnotations(annotations)
+		}
+		return object, l, nil
+	}, false); err != nil {
+		return fmt.Errorf("failed removing the quota transfer marker of subnamespace %q: %v", sns.Name(), err.Error())
 	}
 
-	return ctrl.Result{}, nil
+	return nil
 }
 
-// createUpdateQuota creates an UpdateQuota object.
-func createUpdateQuota(mhObject *objectcontext.ObjectContext, upqName, sourceNS, destNS string, resources corev1.ResourceQuotaSpec) error {
-	description := "Automatically created by migration. Migration name: " + mhObject.Name()
-	upq := upqutils.Compose(upqName, sourceNS, destNS, description, resources)
+// quotaTransferMarker returns the marker of applying or reverting a step of the quota transfer of a MigrationHierarchy.
+func quotaTransferMarker(mhObject *objectcontext.ObjectContext, release bool, index int, revert bool) string {
+	steps := "reserve"
+	if release {
+		steps = "release"
+	}
+
+	action := "apply"
+	if revert {
+		action = "revert"
+	}
+
+	return fmt.Sprintf("%s/%s/%d/%s", mhObject.Name(), steps, index, action)
+}
 
-	upqObject, err := objectcontext.New(mhObject.Ctx, mhObject.Client, types.NamespacedName{}, upq)
+// quotaTransferStepSNS returns the subnamespace whose quota is changed by a step of a quota transfer.
+func quotaTransferStepSNS(mhObject *objectcontext.ObjectContext, step danav1.QuotaTransferStep) (*objectcontext.ObjectContext, error) {
+	sns, err := objectcontext.New(mhObject.Ctx, mhObject.Client, types.NamespacedName{Name: step.Subnamespace, Namespace: step.Parent}, &danav1.Subnamespace{})
 	if err != nil {
-		return err
+		return nil, fmt.Errorf("failed getting subnamespace object %q: %v", step.Subnamespace, err.Error())
+	}
+	if !sns.IsPresent() {
+		return nil, fmt.Errorf("subnamespace %q does not exist in namespace %q", step.Subnamespace, step.Parent)
 	}
 
-	return upqObject.CreateObject()
+	return sns, nil
 }
 
-// getUpdateQuotaStatus requests an UpdateQuota object from the API Server and returns its phase.
-func getUpdateQuotaStatus(mhObject *objectcontext.ObjectContext, upqNamespace string) (danav1.Phase, error) {
-	upqName := mhObject.Name()
-	upqObj, err := objectcontext.New(mhObject.Ctx, mhObject.Client, client.ObjectKey{Name: upqName, Namespace: upqNamespace}, &danav1.Updatequota{})
+// updateQuotaTransferStep updates a step of the quota transfer in the status of the MigrationHierarchy object.
+func updateQuotaTransferStep(mhObject *objectcontext.ObjectContext, release bool, index int, update func(step *danav1.QuotaTransferStep)) error {
+	err := mhObject.EnsureUpdateObject(func(object client.Object, l logr.Logger) (client.Object, logr.Logger, error) {
+		transfer := object.(*danav1.MigrationHierarchy).Status.QuotaTransfer
+		update(&transferSteps(transfer, release)[index])
+		return object, l, nil
+	}, false)
 
 	if err != nil {
-		return "", fmt.Errorf("failed getting updatequota object %q: %v", upqName, err.Error())
+		return fmt.Errorf("failed updating the quota transfer of object %q: %v", mhObject.Name(), err.Error())
 	}
 
-	return upqObj.Object.(*danav1.Updatequota).Status.Phase, nil
+	return nil
 }
@@ -0,0 +1,90 @@
+package migrationhierarchy
+
+import (
+	"context"
+	"testing"
+
+	danav1 "github.com/dana-team/hns/api/v1"
+	"github.com/dana-team/hns/internal/objectcontext"
+	"github.com/dana-team/hns/internal/testutils"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func TestApplyQuotaTransferStep(t *testing.T) {
+	tests := []struct {
+		name         string
+		marker       string
+		hard         corev1.ResourceList
+		expectedHard corev1.ResourceList
+	}{
+		{
+			name:         "the quota is added to the current quota of the subnamespace",
+			hard:         cpu("22"),
+			expectedHard: cpu("27"),
+		},
+		{
+			name:         "a step which was interrupted after the quota was changed is not applied twice",
+			marker:       "migrate-d/reserve/0/apply",
+			hard:         cpu("25"),
+			expectedHard: cpu("25"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// subnamespace d, with a quota of 5 CPUs, is being migrated from a to b, and its quota is reserved in b
+			mh := &danav1.MigrationHierarchy{
+				ObjectMeta: metav1.ObjectMeta{Name: "migrate-d"},
+				Spec:       danav1.MigrationHierarchySpec{CurrentNamespace: "d", ToNamespace: "b"},
+				Status: danav1.MigrationHierarchyStatus{
+					Phase:          danav1.InProgress,
+					OriginalParent: "a",
+					QuotaTransfer: &danav1.QuotaTransfer{
+						Resources: cpu("5"),
+						Ancestor:  "root",
+						Reserve:   []danav1.QuotaTransferStep{{Subnamespace: "b", Parent: "root", Marker: tt.marker}},
+					},
+				},
+			}
+
+			sns := testSubnamespace("b", "root", tt.hard)
+			if tt.marker != "" {
+				sns.Annotations = map[string]string{danav1.QuotaTransferMarker: tt.marker}
+			}
+
+			// the ResourceQuota of b is not synced by a fake client, so it already has the quota which b is expected to have
+			fakeClient := testutils.NewFakeClient(t,
+				testNamespace("root", "", "0"), testNamespace("b", "root", "1"),
+				sns, testResourceQuota("b", tt.expectedHard, nil), mh,
+			)
+
+			ctx := context.Background()
+			mhObject, err := objectcontext.New(ctx, fakeClient, types.NamespacedName{Name: mh.Name}, &danav1.MigrationHierarchy{})
+			if err != nil {
+				t.Fatalf("failed to get migrationhierarchy: %v", err)
+			}
+
+			if err := applyQuotaTransfer(mhObject, false); err != nil {
+				t.Fatalf("failed to apply quota transfer: %v", err)
+			}
+
+			got := &danav1.Subnamespace{}
+			if err := fakeClient.Get(ctx, client.ObjectKey{Name: "b", Namespace: "root"}, got); err != nil {
+				t.Fatalf("failed to get subnamespace: %v", err)
+			}
+			if hard := got.Spec.ResourceQuotaSpec.Hard[corev1.ResourceCPU]; hard.Cmp(tt.expectedHard[corev1.ResourceCPU]) != 0 {
+				t.Errorf("expected the quota of %q to be %v, got %v", got.Name, tt.expectedHard, got.Spec.ResourceQuotaSpec.Hard)
+			}
+			if marker, ok := got.Annotations[danav1.QuotaTransferMarker]; ok {
+				t.Errorf("expected the quota transfer marker to be removed, got %q", marker)
+			}
+
+			if step := mhObject.Object.(*danav1.MigrationHierarchy).Status.QuotaTransfer.Reserve[0]; !step.Done || step.Marker != "" {
+				t.Errorf("expected the reserve step to be done, got %+v", step)
+			}
+		})
+	}
+}
@@ -0,0 +1,158 @@
+package migrationhierarchy
+
+import (
+	"fmt"
+
+	danav1 "github.com/dana-team/hns/api/v1"
+	"github.com/dana-team/hns/internal/namespace/nsutils"
+	"github.com/dana-team/hns/internal/namespacedb"
+	"github.com/dana-team/hns/internal/objectcontext"
+	corev1 "k8s.io/api/core/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// shouldRevert returns true if a failed migration was asked to be reverted and was not reverted yet.
+func shouldRevert(mhObject *objectcontext.ObjectContext) bool {
+	mh := mhObject.Object.(*danav1.MigrationHierarchy)
+	return mh.Spec.Revert && (mh.Status.Phase == danav1.Error || mh.Status.Phase == danav1.Reverting)
+}
+
+// revert undoes the completed steps of a failed migration in the opposite order to the one they were
+// completed in. The quota released from the old branch is first returned to it, the Subnamespace is then
+// returned to its original parent if it was already moved, and the quota reserved in the new branch is
+// finally released. The quota of every Subnamespace is changed back by the quota of the migrated Subnamespace,
+// so changes made to the quota of the Subnamespaces while the migration was in progress are kept. Every step
+// of the revert can be safely executed again, so a revert which was interrupted is resumed by executing it again.
+func (r *MigrationHierarchyReconciler) revert(mhObject *objectcontext.ObjectContext) (ctrl.Result, error) {
+	ctx := mhObject.Ctx
+	logger := log.FromContext(ctx)
+
+	if mhObject.Object.(*danav1.MigrationHierarchy).Status.Phase == danav1.Error {
+		if err := updateMHStatus(mhObject, danav1.Reverting, ""); err != nil {
+			return ctrl.Result{}, err
+		}
+		logger.Info("successfully updated status of MigrationHierarchy object", "phase", danav1.Reverting)
+	}
+
+	// the original parent is recorded when the migration starts, so a migration which failed before
+	// it has started has nothing to revert
+	if mhObject.Object.(*danav1.MigrationHierarchy).Status.OriginalParent != "" {
+		if acquired, err := r.scheduleQuotaTransfer(mhObject, func() error {
+			return revertQuotaTransfer(mhObject, true)
+		}); err != nil {
+			return ctrl.Result{}, fmt.Errorf("failed reverting released quota of migration %q: %v", mhObject.Name(), err.Error())
+		} else if !acquired {
+			return ctrl.Result{}, nil
+		}
+
+		if err := r.revertMigration(mhObject); err != nil {
+			return ctrl.Result{}, fmt.Errorf("failed reverting migration %q: %v", mhObject.Name(), err.Error())
+		}
+
+		if acquired, err := r.scheduleQuotaTransfer(mhObject, func() error {
+			return revertQuotaTransfer(mhObject, false)
+		}); err != nil {
+			return ctrl.Result{}, fmt.Errorf("failed reverting reserved quota of migration %q: %v", mhObject.Name(), err.Error())
+		} else if !acquired {
+			return ctrl.Result{}, nil
+		}
+		logger.Info("successfully reverted quota transfer of migration", "mh", mhObject.Name())
+	}
+
+	if err := updateMHStatus(mhObject, danav1.Reverted, ""); err != nil {
+		return ctrl.Result{}, err
+	}
+	logger.Info("successfully updated status of MigrationHierarchy object", "phase", danav1.Reverted)
+
+	return ctrl.Result{}, nil
+}
+
+// revertMigration returns the migrated Subnamespace to its original parent. If the old Subnamespace was not deleted
+// yet, the Subnamespace was not moved, and only the Subnamespace created under the new parent is deleted. Otherwise,
+// the Subnamespace is migrated back to its original parent by the same steps it was migrated by. The completed
+// step is cleared once the Subnamespace is back under its original parent.
+func (r *MigrationHierarchyReconciler) revertMigration(mhObject *objectcontext.ObjectContext) error {
+	ctx := mhObject.Ctx
+	logger := log.FromContext(ctx)
+	mh := mhObject.Object.(*danav1.MigrationHierarchy)
+
+	currentNamespace := mh.Spec.CurrentNamespace
+	toNamespace := mh.Spec.ToNamespace
+	originalParent := mh.Status.OriginalParent
+
+	oldSNS, err := objectcontext.New(ctx, r.Client, client.ObjectKey{Name: currentNamespace, Namespace: originalParent}, &danav1.Subnamespace{})
+	if err != nil {
+		return fmt.Errorf("failed getting subnamespace object %q: %v", currentNamespace, err.Error())
+	}
+
+	newSNS, err := objectcontext.New(ctx, r.Client, client.ObjectKey{Name: currentNamespace, Namespace: toNamespace}, &danav1.Subnamespace{})
+	if err != nil {
+		return fmt.Errorf("failed getting subnamespace object %q: %v", currentNamespace, err.Error())
+	}
+
+	if oldSNS.IsPresent() && !isStepCompleted(mhObject, danav1.OldSNSDeleted) {
+		if err := r.deleteSNS(newSNS); err != nil {
+			return fmt.Errorf("failed deleting new subnamespace %q: %v", newSNS.Name(), err.Error())
+		}
+		logger.Info("successfully deleted new subnamespace from new parent", "subnamespace", currentNamespace, "new parent", toNamespace)
+
+		// no step of the migration is in effect anymore
+		return completeStep(mhObject, "")
+	}
+
+	if !oldSNS.IsPresent() && !newSNS.IsPresent() {
+		return fmt.Errorf("subnamespace %q does not exist in namespace %q or in namespace %q", currentNamespace, originalParent, toNamespace)
+	}
+
+	if newSNS.IsPresent() {
+		restoredSNS, err := r.createNewSNS(newSNS, currentNamespace, originalParent)
+		if err != nil {
+			return fmt.Errorf("failed creating subnamespace %q under namespace %q: %v", currentNamespace, originalParent, err.Error())
+		}
+
+		if err := r.deleteOldSNS(newSNS, restoredSNS, originalParent); err != nil {
+			return fmt.Errorf("failed deleting new subnamespace %q: %v", newSNS.Name(), err.Error())
+		}
+		logger.Info("successfully moved subnamespace back to original parent", "subnamespace", currentNamespace, "original parent", originalParent)
+	}
+
+	ns, err := objectcontext.New(ctx, r.Client, client.ObjectKey{Name: currentNamespace}, &corev1.Namespace{})
+	if err != nil {
+		return fmt.Errorf("failed getting namespace object %q: %v", currentNamespace, err.Error())
+	}
+
+	originalParentNS, err := objectcontext.New(ctx, r.Client, client.ObjectKey{Name: originalParent}, &corev1.Namespace{})
+	if err != nil {
+		return fmt.Errorf("failed getting namespace object %q: %v", originalParent, err.Error())
+	}
+
+	if err := r.updateRelatedObjects(mhObject, originalParentNS, ns); err != nil {
+		return fmt.Errorf("failed to update related objects: %v", err.Error())
+	}
+	logger.Info("successfully updated related objects of subnamespace", "subnamespace", currentNamespace)
+
+	root, err := nsutils.Root(ns.Object)
+	if err != nil {
+		return fmt.Errorf("failed to get root namespace of %q: %v", ns.Name(), err.Error())
+	}
+
+	if err := namespacedb.MigrateNSHierarchy(ctx, r.NamespaceDB, r.Client, root, ns.Name(), originalParent); err != nil {
+		return fmt.Errorf("failed migrating subnamespace %q in namespacedb: %v", ns.Name(), err.Error())
+	}
+
+	if err := r.enqueueOriginalParent(ctx, toNamespace); err != nil {
+		return fmt.Errorf("failed to enqueue %q: %v", toNamespace, err.Error())
+	}
+
+	restoredSNS, err := objectcontext.New(ctx, r.Client, client.ObjectKey{Name: currentNamespace, Namespace: originalParent}, &danav1.Subnamespace{})
+	if err != nil {
+		return fmt.Errorf("failed getting subnamespace object %q: %v", currentNamespace, err.Error())
+	}
+	r.enqueueSNSDescendants(restoredSNS)
+	logger.Info("successfully migrated subnamespace back to original parent", "subnamespace", currentNamespace, "original parent", originalParent)
+
+	// no step of the migration is in effect anymore
+	return completeStep(mhObject, "")
+}
@@ -0,0 +1,111 @@
+package migrationhierarchy
+
+import (
+	"context"
+	"testing"
+
+	danav1 "github.com/dana-team/hns/api/v1"
+	"github.com/dana-team/hns/internal/objectcontext"
+	"github.com/dana-team/hns/internal/testutils"
+	"github.com/dana-team/hns/internal/updatequota"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func cpu(quantity string) corev1.ResourceList {
+	return corev1.ResourceList{corev1.ResourceCPU: resource.MustParse(quantity)}
+}
+
+// testNamespace returns a namespace of the hierarchy of root, in which namespaces up to depth 2 have ResourceQuotas.
+func testNamespace(name, parent, depth string) *corev1.Namespace {
+	namespace := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: name, Annotations: map[string]string{
+		danav1.Depth:           depth,
+		danav1.RootCrqSelector: "root",
+		danav1.RqDepth:         "2",
+	}}}
+	if parent == "" {
+		namespace.Annotations[danav1.Role] = danav1.Root
+	} else {
+		namespace.Labels = map[string]string{danav1.Parent: parent}
+	}
+
+	return namespace
+}
+
+func testSubnamespace(name, parent string, hard corev1.ResourceList) *danav1.Subnamespace {
+	return &danav1.Subnamespace{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: parent, Labels: map[string]string{danav1.ResourcePool: "false"}},
+		Spec:       danav1.SubnamespaceSpec{ResourceQuotaSpec: corev1.ResourceQuotaSpec{Hard: hard}},
+	}
+}
+
+func testResourceQuota(name string, hard, used corev1.ResourceList) *corev1.ResourceQuota {
+	return &corev1.ResourceQuota{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: name},
+		Spec:       corev1.ResourceQuotaSpec{Hard: hard},
+		Status:     corev1.ResourceQuotaStatus{Hard: hard, Used: used},
+	}
+}
+
+func TestRevertKeepsQuotaChangesMadeAfterTransfer(t *testing.T) {
+	// subnamespace d, with a quota of 5 CPUs, was being migrated from a to b. The quota was reserved in b, which
+	// had 20 CPUs before the migration, and the quota of b was then increased by 2 CPUs by another operation
+	mh := &danav1.MigrationHierarchy{
+		ObjectMeta: metav1.ObjectMeta{Name: "migrate-d"},
+		Spec:       danav1.MigrationHierarchySpec{CurrentNamespace: "d", ToNamespace: "b", Revert: true},
+		Status: danav1.MigrationHierarchyStatus{
+			Phase:          danav1.Error,
+			OriginalParent: "a",
+			Step:           danav1.QuotaReserved,
+			QuotaTransfer: &danav1.QuotaTransfer{
+				Resources: cpu("5"),
+				Ancestor:  "root",
+				Reserve:   []danav1.QuotaTransferStep{{Subnamespace: "b", Parent: "root", Done: true}},
+				Release:   []danav1.QuotaTransferStep{{Subnamespace: "a", Parent: "root"}},
+			},
+		},
+	}
+
+	fakeClient := testutils.NewFakeClient(t,
+		testNamespace("root", "", "0"), testNamespace("a", "root", "1"), testNamespace("b", "root", "1"), testNamespace("d", "a", "2"),
+		testSubnamespace("a", "root", cpu("10")), testSubnamespace("b", "root", cpu("27")), testSubnamespace("d", "a", cpu("5")),
+		testResourceQuota("a", cpu("10"), nil), testResourceQuota("b", cpu("22"), nil),
+		mh,
+	)
+
+	ctx := context.Background()
+	mhObject, err := objectcontext.New(ctx, fakeClient, types.NamespacedName{Name: mh.Name}, &danav1.MigrationHierarchy{})
+	if err != nil {
+		t.Fatalf("failed to get migrationhierarchy: %v", err)
+	}
+
+	r := &MigrationHierarchyReconciler{Client: fakeClient, Scheme: fakeClient.Scheme(), Scheduler: updatequota.NewScheduler()}
+	if _, err := r.revert(mhObject); err != nil {
+		t.Fatalf("failed to revert migration: %v", err)
+	}
+
+	expectedHard := map[string]corev1.ResourceList{"a": cpu("10"), "b": cpu("22")}
+	for name, hard := range expectedHard {
+		sns := &danav1.Subnamespace{}
+		if err := fakeClient.Get(ctx, client.ObjectKey{Name: name, Namespace: "root"}, sns); err != nil {
+			t.Fatalf("failed to get subnamespace: %v", err)
+		}
+		if got := sns.Spec.ResourceQuotaSpec.Hard[corev1.ResourceCPU]; got.Cmp(hard[corev1.ResourceCPU]) != 0 {
+			t.Errorf("expected the quota of %q to be %v, got %v", name, hard, sns.Spec.ResourceQuotaSpec.Hard)
+		}
+	}
+
+	got := &danav1.MigrationHierarchy{}
+	if err := fakeClient.Get(ctx, types.NamespacedName{Name: mh.Name}, got); err != nil {
+		t.Fatalf("failed to get migrationhierarchy: %v", err)
+	}
+	if got.Status.Phase != danav1.Reverted {
+		t.Errorf("expected phase %q, got %q", danav1.Reverted, got.Status.Phase)
+	}
+	if step := got.Status.QuotaTransfer.Reserve[0]; step.Done || step.Marker != "" {
+		t.Errorf("expected the reserve step to be reverted, got %+v", step)
+	}
+}
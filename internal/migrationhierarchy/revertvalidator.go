@@ -0,0 +1,64 @@
+package migrationhierarchy
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	danav1 "github.com/dana-team/hns/api/v1"
+	"github.com/dana-team/hns/internal/common"
+	"github.com/dana-team/hns/internal/namespace/nsutils"
+	"github.com/dana-team/hns/internal/objectcontext"
+	"github.com/dana-team/hns/internal/subnamespace/snsutils"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+// handleRevert validates that a MigrationHierarchy may be reverted by a user. Only a failed migration can be
+// reverted, and the user needs the same permissions needed to migrate the Subnamespace back to its original parent.
+func (v *MigrationHierarchyValidator) handleRevert(ctx context.Context, mh *danav1.MigrationHierarchy, userInfo authenticationv1.UserInfo) admission.Response {
+	if mh.Status.Phase != danav1.Error {
+		message := fmt.Sprintf("it's forbidden to revert a MigrationHierarchy whose phase is not %q", danav1.Error)
+		return admission.Denied(message)
+	}
+
+	// a migration which failed before it has started has nothing to revert
+	originalParent := mh.Status.OriginalParent
+	if originalParent == "" {
+		return admission.Allowed("")
+	}
+
+	originalParentNS, err := objectcontext.New(ctx, v.Client, client.ObjectKey{Name: originalParent}, &corev1.Namespace{})
+	if err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+	if response := common.ValidateNamespaceExist(originalParentNS); !response.Allowed {
+		return response
+	}
+
+	toNS, err := objectcontext.New(ctx, v.Client, client.ObjectKey{Name: mh.Spec.ToNamespace}, &corev1.Namespace{})
+	if err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+	if response := common.ValidateNamespaceExist(toNS); !response.Allowed {
+		return response
+	}
+
+	toNSSliced, err := nsutils.Ancestors(toNS.Object)
+	if err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+
+	originalParentNSSliced, err := nsutils.Ancestors(originalParentNS.Object)
+	if err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+	ancestorNSName, _, err := snsutils.GetAncestor(toNSSliced, originalParentNSSliced)
+	if err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+
+	return common.ValidatePermissions(ctx, toNSSliced, mh.Spec.ToNamespace, originalParent, ancestorNSName, userInfo, common.MigrateVerb, false, v.Client, v.Authorizer)
+}
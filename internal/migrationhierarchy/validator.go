@@ -6,6 +6,7 @@ import (
 	"net/http"
 	"reflect"
 
+	"github.com/dana-team/hns/internal/common"
 	"github.com/dana-team/hns/internal/namespacedb"
 	"github.com/dana-team/hns/internal/objectcontext"
 	"sigs.k8s.io/controller-runtime/pkg/log"
@@ -22,6 +23,7 @@ type MigrationHierarchyValidator struct {
 	Decoder     admission.Decoder
 	NamespaceDB *namespacedb.NamespaceDB
 	MaxSNS      int
+	Authorizer  common.Authorizer
 }
 
 // +kubebuilder:webhook:path=/validate-v1-migrationhierarchy,mutating=false,sideEffects=NoneOnDryRun,failurePolicy=fail,groups="dana.hns.io",resources=migrationhierarchies,verbs=create;update,versions=v1,name=migrationhierarchy.dana.io,admissionReviewVersions=v1;v1beta1
@@ -43,8 +45,7 @@ func (v *MigrationHierarchyValidator) Handle(ctx context.Context, req admission.
 	}
 
 	if req.Operation == admissionv1.Create {
-		reqUser := req.UserInfo.Username
-		if response := v.handleCreate(mhObject, reqUser); !response.Allowed {
+		if response := v.handleCreate(mhObject, req.UserInfo); !response.Allowed {
 			return response
 		}
 	}
@@ -57,10 +58,24 @@ func (v *MigrationHierarchyValidator) Handle(ctx context.Context, req admission.
 			logger.Error(err, "could not decode object")
 			return admission.Errored(http.StatusBadRequest, err)
 		}
-		if !reflect.DeepEqual(mhObject.Object.(*danav1.MigrationHierarchy).Spec, oldMH.Spec) {
+		// a failed migration may be asked to be reverted, which is the only change allowed to the spec
+		spec := mhObject.Object.(*danav1.MigrationHierarchy).Spec
+		if spec.Revert && !oldMH.Spec.Revert {
+			if response := v.handleRevert(ctx, oldMH, req.UserInfo); !response.Allowed {
+				return response
+			}
+			spec.Revert = false
+		}
+		if !reflect.DeepEqual(spec, oldMH.Spec) {
 			message := fmt.Sprintf("it is forbidden to update an object of type %q", oldMH.TypeMeta.Kind)
 			return admission.Denied(message)
 		}
+
+		// the requester is used to audit who migrated a subnamespace, so it's not allowed to change it
+		if mhObject.Object.GetAnnotations()[danav1.Requester] != oldMH.Annotations[danav1.Requester] {
+			message := fmt.Sprintf("it is forbidden to change the %q annotation of an object of type %q", danav1.Requester, oldMH.TypeMeta.Kind)
+			return admission.Denied(message)
+		}
 	}
 
 	return admission.Allowed("all validations passed")
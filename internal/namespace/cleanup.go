@@ -12,11 +12,9 @@ import (
 	"github.com/dana-team/hns/internal/subnamespace/snsutils"
 	"github.com/go-logr/logr"
 	corev1 "k8s.io/api/core/v1"
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
-	"sigs.k8s.io/controller-runtime/pkg/event"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 )
 
@@ -27,10 +25,15 @@ func (r *NamespaceReconciler) cleanUp(ctx context.Context, nsObject *objectconte
 	logger.Info("cleaning up namespace")
 	nsName := nsObject.Name()
 
-	if err := r.deleteNamespaceFromNamespaceDB(nsName); err != nil {
-		return fmt.Errorf("failed to delete namespace %q from namespacedb: %v", nsName, err.Error())
+	// a namespace which has no tree labels was never reconciled, so it was never added to the namespacedb
+	if root, err := nsutils.Root(nsObject.Object); err != nil {
+		logger.Info("skipping deleting namespace from namespacedb", "namespace", nsName, "reason", err.Error())
+	} else {
+		if err := r.deleteNamespaceFromNamespaceDB(root, nsName); err != nil {
+			return fmt.Errorf("failed to delete namespace %q from namespacedb: %v", nsName, err.Error())
+		}
+		logger.Info("successfully deleted namespace from namespacedb", "namespace", nsName)
 	}
-	logger.Info("successfully deleted namespace from namespacedb", "namespace", nsName)
 
 	if err := deleteNamespaceQuotaObject(nsObject); err != nil {
 		return fmt.Errorf("failed to delete quota object of namespace %q: %v", nsName, err.Error())
@@ -42,10 +45,10 @@ func (r *NamespaceReconciler) cleanUp(ctx context.Context, nsObject *objectconte
 	}
 	logger.Info("successfully deleted subnamespace object from parent namespace", "namespace", nsName)
 
-	if err := deleteNSHnsView(nsObject); err != nil {
-		return fmt.Errorf("failed to delete role and rolebinding objects associated with namespace %q: %v", nsName, err.Error())
+	if err := rbutils.DeleteLegacyHNSView(nsObject); err != nil {
+		return fmt.Errorf("failed to delete legacy HNS view objects of namespace %q: %v", nsName, err.Error())
 	}
-	logger.Info("successfully deleted role and rolebinding objects associated with namespace", "namespace", nsName)
+	logger.Info("successfully deleted legacy HNS view objects of namespace", "namespace", nsName)
 
 	// trigger reconciliation for parent subnamespace so that it can be aware of
 	// potential changes in one of its children
@@ -62,21 +65,6 @@ func (r *NamespaceReconciler) cleanUp(ctx context.Context, nsObject *objectconte
 	return nil
 }
 
-// deleteNSHnsView deletes the cluster role and cluster role binding HNS objects
-// associated with the namespace.
-func deleteNSHnsView(nsObject *objectcontext.ObjectContext) error {
-	nsHnsViewClusterRoleObj, nsHnsViewClusterRoleBindingObj, err := rbutils.NamespaceHNSView(nsObject)
-	if err != nil {
-		return err
-	}
-
-	if err := nsHnsViewClusterRoleObj.EnsureDelete(); err != nil {
-		return err
-	}
-
-	return nsHnsViewClusterRoleBindingObj.EnsureDelete()
-}
-
 // deleteNamespaceQuotaObject deletes the quota object corresponding to the subnamespace which
 // exists for a given namespace.
 func deleteNamespaceQuotaObject(ns *objectcontext.ObjectContext) error {
@@ -100,14 +88,14 @@ func deleteNamespaceQuotaObject(ns *objectcontext.ObjectContext) error {
 // deleteNamespaceFromNamespaceDB deletes the given namespace from the namespacedb
 // if the namespace is a key, then remove the key from the DB; otherwise remove
 // only the namespace from the list of namespaces under a particular key.
-func (r *NamespaceReconciler) deleteNamespaceFromNamespaceDB(nsName string) error {
-	keyNS := r.NamespaceDB.Key(nsName)
+func (r *NamespaceReconciler) deleteNamespaceFromNamespaceDB(root, nsName string) error {
+	keyNS := r.NamespaceDB.Key(root, nsName)
 
 	if keyNS != "" {
 		if keyNS == nsName {
-			r.NamespaceDB.DeleteKey(keyNS)
+			r.NamespaceDB.DeleteKey(root, keyNS)
 		} else {
-			if err := r.NamespaceDB.RemoveNS(nsName, keyNS); err != nil {
+			if err := r.NamespaceDB.RemoveNS(root, nsName, keyNS); err != nil {
 				return fmt.Errorf("failed to remove namespace %q from key in DB: %v", nsName, err.Error())
 			}
 		}
@@ -127,12 +115,7 @@ func (r *NamespaceReconciler) enqueueParentSNSEvent(nsObject *objectcontext.Obje
 
 	nsGrandparentName := nsutils.Parent(nsParentNSObj.Object)
 
-	r.SNSEvents <- event.GenericEvent{Object: &danav1.Subnamespace{
-		ObjectMeta: metav1.ObjectMeta{
-			Name:      nsParentName,
-			Namespace: nsGrandparentName,
-		},
-	}}
+	r.SNSEvents.EnqueueAfter(nsParentName, nsGrandparentName, r.ParentSyncWindow)
 
 	return nil
 }
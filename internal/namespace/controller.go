@@ -4,31 +4,35 @@ import (
 	"context"
 	"fmt"
 	"reflect"
+	"time"
 
 	danav1 "github.com/dana-team/hns/api/v1"
 	"github.com/dana-team/hns/internal/common"
+	"github.com/dana-team/hns/internal/enqueuer"
 	"github.com/dana-team/hns/internal/namespace/nsutils"
 	"github.com/dana-team/hns/internal/namespacedb"
 	"github.com/dana-team/hns/internal/objectcontext"
+	"github.com/dana-team/hns/internal/quota"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
-	"sigs.k8s.io/controller-runtime/pkg/event"
-	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/predicate"
-	"sigs.k8s.io/controller-runtime/pkg/source"
 )
 
 // NamespaceReconciler reconciles a Namespace object
 type NamespaceReconciler struct {
 	client.Client
 	Scheme      *runtime.Scheme
-	NSEvents    chan event.GenericEvent
-	SNSEvents   chan event.GenericEvent
+	NSEvents    *enqueuer.Enqueuer
+	SNSEvents   *enqueuer.Enqueuer
 	NamespaceDB *namespacedb.NamespaceDB
+
+	// ParentSyncWindow is the window in which the reconciliations of a subnamespace triggered by its children
+	// are coalesced into a single reconciliation
+	ParentSyncWindow time.Duration
 }
 
 // +kubebuilder:rbac:groups=core,resources=namespaces,verbs=get;list;watch;create;update;patch;delete
@@ -37,13 +41,12 @@ type NamespaceReconciler struct {
 // +kubebuilder:rbac:groups="rbac.authorization.k8s.io",resources=clusterroles,verbs=get;list;watch;create;update;patch;delete;escalate;bind
 
 // SetupWithManager sets up the controller by specifying the following: controller is managing the reconciliation
-// of Namespace objects, it is watching for changes to the NSEvents channel and enqueues requests for the
-// associated object. NamespacePredicate is used as an event filter, the predicate function checks if the object
+// of Namespace objects and of the namespaces enqueued to NSEvents by the HNS controllers. NamespacePredicate is used as an event filter, the predicate function checks if the object
 // being watched is an SNS object or has a specific label. The controller also owns SNS objects.
 func (r *NamespaceReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&corev1.Namespace{}).
-		WatchesRawSource(source.Channel(r.NSEvents, &handler.EnqueueRequestForObject{})).
+		WatchesRawSource(r.NSEvents).
 		WithEventFilter(predicate.NewPredicateFuncs(func(object client.Object) bool {
 			if reflect.TypeOf(object) == reflect.TypeOf(&danav1.Subnamespace{}) {
 				return true
@@ -75,9 +78,16 @@ func (r *NamespaceReconciler) Reconcile(ctx context.Context, req ctrl.Request) (
 		return ctrl.Result{}, nil
 	}
 
-	// skip namespace reconciliation for a root namespace, since there is no need to do
-	// anything with the root namespace as it's usually created manually by the cluster admin
+	// skip namespace reconciliation for a root namespace, since there is no need to do anything with the
+	// root namespace as it's usually created manually by the cluster admin, other than migrating its quota
+	// object to use canonical resource names and setting its tree label
 	if nsutils.IsRoot(nsObject.Object) {
+		if err := quota.NormalizeRootNSObject(nsObject); err != nil {
+			return ctrl.Result{}, fmt.Errorf("failed to normalize quota object of root namespace %q: %v", nsObject.Name(), err.Error())
+		}
+		if err := r.ensureRootTreeLabel(nsObject); err != nil {
+			return ctrl.Result{}, fmt.Errorf("failed to set tree label of root namespace %q: %v", nsObject.Name(), err.Error())
+		}
 		logger.Info("no need to reconcile the root namespace, skip")
 		return ctrl.Result{}, nil
 	}
@@ -99,3 +109,20 @@ func (r *NamespaceReconciler) Reconcile(ctx context.Context, req ctrl.Request) (
 func doesNamespaceFinalizerExist(namespace client.Object) bool {
 	return controllerutil.ContainsFinalizer(namespace, danav1.NsFinalizer)
 }
+
+// ensureRootTreeLabel makes sure that the only tree label of a root namespace is its own tree label,
+// and enqueues its children namespaces if it changed.
+func (r *NamespaceReconciler) ensureRootTreeLabel(nsObject *objectcontext.ObjectContext) error {
+	treeLabels := map[string]string{nsutils.TreeLabel(nsObject.Name()): "0"}
+
+	treeLabelsChanged, err := ensureTreeLabels(nsObject, nil, treeLabels)
+	if err != nil {
+		return err
+	}
+
+	if treeLabelsChanged {
+		return r.enqueueChildrenNSEvents(nsObject)
+	}
+
+	return nil
+}
@@ -0,0 +1,52 @@
+package namespace
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	danav1 "github.com/dana-team/hns/api/v1"
+	"github.com/dana-team/hns/internal/common"
+	"github.com/dana-team/hns/internal/namespace/nsutils"
+	"github.com/dana-team/hns/internal/objectcontext"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+// handleCreate implements the logic for the creation of a namespace.
+func (v *NamespaceValidator) handleCreate(nsObject *objectcontext.ObjectContext, userName string) admission.Response {
+	canManage, err := common.CanManageHNSObjects(nsObject.Ctx, userName, v.Client)
+	if err != nil {
+		return admission.Errored(http.StatusInternalServerError, err)
+	}
+	if canManage {
+		return admission.Allowed("user is allowed to set the tree labels of namespaces")
+	}
+
+	if response := v.validateNoTreeLabels(nsObject); !response.Allowed {
+		return response
+	}
+
+	return admission.Allowed("")
+}
+
+// validateNoTreeLabels validates that a namespace which is not created by HNS does not have tree labels,
+// other than the tree label of itself which may be set on a root namespace.
+func (v *NamespaceValidator) validateNoTreeLabels(nsObject *objectcontext.ObjectContext) admission.Response {
+	nsName := nsObject.Name()
+	ownTreeLabel := nsutils.TreeLabel(nsName)
+
+	for key, value := range nsObject.Object.GetLabels() {
+		if !strings.HasSuffix(key, danav1.TreeLabelSuffix) {
+			continue
+		}
+		if key == ownTreeLabel && value == "0" {
+			continue
+		}
+
+		message := fmt.Sprintf("it's forbidden to set the tree label %q on namespace %q, tree labels are "+
+			"managed by HNS", key, nsName)
+		return admission.Denied(message)
+	}
+
+	return admission.Allowed("")
+}
@@ -1,7 +1,10 @@
 package nsutils
 
 import (
+	"context"
+	"fmt"
 	"reflect"
+	"sort"
 	"strconv"
 	"strings"
 
@@ -57,31 +60,125 @@ func IsSecondaryRoot(namespace client.Object) bool {
 	return namespace.GetAnnotations()[danav1.IsSecondaryRoot] == danav1.True
 }
 
-// DisplayNameSlice returns a slice of strings that contains the hierarchy
-// of a namespace in accordance to its display-name.
-func DisplayNameSlice(ns *objectcontext.ObjectContext) []string {
-	displayName := ns.Object.GetAnnotations()[danav1.DisplayName]
-	nsArr := strings.Split(displayName, "/")
+// TreeLabel returns the tree label of a namespace, which is set on the namespace itself and on all its descendants.
+func TreeLabel(nsName string) string {
+	return nsName + danav1.TreeLabelSuffix
+}
+
+// TreeLabels returns the tree labels of a namespace, mapping the name of each of its ancestors
+// (including the namespace itself) to the distance of the namespace from that ancestor.
+func TreeLabels(namespace client.Object) map[string]int {
+	treeLabels := map[string]int{}
+
+	for key, value := range namespace.GetLabels() {
+		if !strings.HasSuffix(key, danav1.TreeLabelSuffix) {
+			continue
+		}
 
-	return nsArr
+		distance, err := strconv.Atoi(value)
+		if err != nil {
+			continue
+		}
+		treeLabels[strings.TrimSuffix(key, danav1.TreeLabelSuffix)] = distance
+	}
+
+	return treeLabels
 }
 
-// LabelsBasedOnParent returns labels for a namespace based on the ones of the namespace of the parent.
-func LabelsBasedOnParent(parentNS *objectcontext.ObjectContext, nsName string) map[string]string {
-	parentDisplayNameSliced := DisplayNameSlice(parentNS)
+// Ancestors returns a slice of strings that contains the hierarchy of a namespace, from the
+// root namespace to the namespace itself, in accordance to its tree labels. A namespace which
+// doesn't have tree labels was not reconciled yet, so its hierarchy is not known.
+func Ancestors(namespace client.Object) ([]string, error) {
+	treeLabels := TreeLabels(namespace)
+	if len(treeLabels) == 0 {
+		return nil, fmt.Errorf("namespace %q has no tree labels, it was not reconciled yet", namespace.GetName())
+	}
+
+	ancestors := make([]string, 0, len(treeLabels))
+	for ancestor := range treeLabels {
+		ancestors = append(ancestors, ancestor)
+	}
+
+	sort.Slice(ancestors, func(i, j int) bool {
+		return treeLabels[ancestors[i]] > treeLabels[ancestors[j]]
+	})
+
+	return ancestors, nil
+}
+
+// SNSAncestors returns a slice of strings that contains the hierarchy of a subnamespace, from the
+// root namespace to the subnamespace itself, in accordance to the tree labels of its namespace.
+func SNSAncestors(ctx context.Context, k8sClient client.Client, sns client.Object) ([]string, error) {
+	namespace := &corev1.Namespace{}
+	if err := k8sClient.Get(ctx, types.NamespacedName{Name: sns.GetName()}, namespace); err != nil {
+		return nil, fmt.Errorf("failed to get namespace of subnamespace %q: %v", sns.GetName(), err.Error())
+	}
+
+	return Ancestors(namespace)
+}
+
+// Root returns the name of the root namespace of the hierarchy a namespace belongs to.
+func Root(namespace client.Object) (string, error) {
+	ancestors, err := Ancestors(namespace)
+	if err != nil {
+		return "", err
+	}
 
+	return ancestors[0], nil
+}
+
+// SNSRoot returns the name of the root namespace of the hierarchy a subnamespace belongs to.
+func SNSRoot(ctx context.Context, k8sClient client.Client, sns client.Object) (string, error) {
+	ancestors, err := SNSAncestors(ctx, k8sClient, sns)
+	if err != nil {
+		return "", err
+	}
+
+	return ancestors[0], nil
+}
+
+// TreeLabelsBasedOnParent returns the tree labels of a namespace based on the ones of the namespace of its parent.
+func TreeLabelsBasedOnParent(parentNS client.Object, nsName string) (map[string]string, error) {
+	labels := map[string]string{}
+
+	parentAncestors, err := Ancestors(parentNS)
+	if err != nil {
+		return nil, err
+	}
+	for i, ancestor := range parentAncestors {
+		labels[TreeLabel(ancestor)] = strconv.Itoa(len(parentAncestors) - i)
+	}
+	labels[TreeLabel(nsName)] = "0"
+
+	return labels, nil
+}
+
+// LabelsBasedOnParent returns labels for a namespace based on the ones of the namespace of the parent.
+// The hierarchy of the namespace is recorded in its tree labels; namespaces used to be labeled with
+// "<ancestor>: true" for each of their ancestors, and these legacy labels are removed when they are synced.
+func LabelsBasedOnParent(parentNS *objectcontext.ObjectContext, nsName string) (map[string]string, error) {
 	labels := make(map[string]string)
 	defaultLabels(parentNS, labels)
 
 	labels[danav1.Parent] = parentNS.Object.(*corev1.Namespace).Name
 	labels[danav1.Hns] = trueString
 
-	for _, ns := range parentDisplayNameSliced {
-		labels[ns] = trueString
+	treeLabels, err := TreeLabelsBasedOnParent(parentNS.Object, nsName)
+	if err != nil {
+		return nil, err
+	}
+	for key, value := range treeLabels {
+		labels[key] = value
 	}
-	labels[nsName] = trueString
 
-	return labels
+	return labels, nil
+}
+
+// IsLegacyAncestorLabel returns true if a label of a namespace is a legacy "<ancestor>: true" label of one
+// of the given ancestors, which recorded the hierarchy of the namespace before it had tree labels.
+func IsLegacyAncestorLabel(key, value string, ancestors map[string]int) bool {
+	_, ok := ancestors[key]
+	return ok && value == trueString
 }
 
 // AnnotationsBasedOnParent returns labels for a namespace based on the ones of the namespace of the parent.
@@ -3,6 +3,7 @@ package namespace
 import (
 	"fmt"
 	"strconv"
+	"strings"
 
 	"k8s.io/apimachinery/pkg/api/errors"
 
@@ -26,10 +27,12 @@ func (r *NamespaceReconciler) sync(nsObject *objectcontext.ObjectContext) error
 
 	nsName := nsObject.Name()
 
-	if err := rbutils.CreateHNSView(nsObject); err != nil {
-		return fmt.Errorf("failed to create role and roleBinding objects associated with namespace %q: %v", nsName, err.Error())
+	// the HNS view objects are created per subject by the roleBinding controller, so the objects which
+	// were created for every namespace by earlier versions are garbage collected when it is synced
+	if err := rbutils.DeleteLegacyHNSView(nsObject); err != nil {
+		return fmt.Errorf("failed to delete legacy HNS view objects of namespace %q: %v", nsName, err.Error())
 	}
-	logger.Info("successfully created role and roleBinding objects associated with namespace", "namespace", nsName)
+	logger.Info("successfully deleted legacy HNS view objects of namespace", "namespace", nsName)
 
 	if nsutils.IsChildless(nsObject) {
 		if err := updateNSRole(nsObject, danav1.Leaf); err != nil {
@@ -40,11 +43,28 @@ func (r *NamespaceReconciler) sync(nsObject *objectcontext.ObjectContext) error
 	}
 	logger.Info("successfully updated role of namespace", "namespace", nsName)
 
-	if err := ensureHierarchyLabels(nsObject); err != nil {
+	treeLabelsChanged, err := ensureHierarchyLabels(nsObject)
+	if err != nil {
 		return fmt.Errorf("failed to set hierarchy labels of namespace %q: %v", nsName, err.Error())
 	}
 	logger.Info("successfully set hierarchy labels of namespace", "namespace", nsName)
 
+	// the tree labels of the children namespaces are based on the ones of the namespace,
+	// so they have to be synced as well whenever the tree labels of the namespace change
+	if treeLabelsChanged {
+		if err := r.enqueueChildrenNSEvents(nsObject); err != nil {
+			return fmt.Errorf("failed to enqueue children of namespace %q: %v", nsName, err.Error())
+		}
+		logger.Info("successfully enqueued children namespaces for reconciliation", "namespace", nsName)
+	}
+
+	// the inherited roleBindings are based on the ancestors of the namespace, so they are
+	// synced after the hierarchy labels, which change when the namespace is migrated
+	if err := rbutils.EnsureInheritedRoleBindings(nsObject); err != nil {
+		return fmt.Errorf("failed to sync inherited roleBindings of namespace %q: %v", nsName, err.Error())
+	}
+	logger.Info("successfully synced inherited roleBindings of namespace", "namespace", nsName)
+
 	if err := ensureChildrenSNSResourcePoolLabel(nsObject); err != nil {
 		return fmt.Errorf("failed to set ResourcePool labels of children subnamespaces of namespace %q: %v", nsName, err.Error())
 	}
@@ -91,8 +111,12 @@ func ensureNSResourcePoolLabelsAndAnnotations(nsObject *objectcontext.ObjectCont
 	return nil
 }
 
-// updateNSRole updates the role of a namespace.
+// updateNSRole updates the role of a namespace, unless the namespace already has the role.
 func updateNSRole(namespace *objectcontext.ObjectContext, role string) error {
+	if namespace.Object.GetLabels()[danav1.Role] == role && namespace.Object.GetAnnotations()[danav1.Role] == role {
+		return nil
+	}
+
 	return namespace.UpdateObject(func(object client.Object, log logr.Logger) (client.Object, logr.Logger) {
 		log = log.WithValues("updated role annotation", role)
 		object.(*corev1.Namespace).Labels[danav1.Role] = role
@@ -101,20 +125,99 @@ func updateNSRole(namespace *objectcontext.ObjectContext, role string) error {
 	})
 }
 
-// ensureHierarchyLabels makes sure that the hierarchy labels of a namespace are set correctly
-func ensureHierarchyLabels(nsObject *objectcontext.ObjectContext) error {
+// ensureHierarchyLabels makes sure that the hierarchy labels of a namespace are set correctly,
+// and returns whether the tree labels of the namespace changed.
+func ensureHierarchyLabels(nsObject *objectcontext.ObjectContext) (bool, error) {
 	snsParentName := nsObject.Object.GetLabels()[danav1.Parent]
 
 	parentNS, err := objectcontext.New(nsObject.Ctx, nsObject.Client, types.NamespacedName{Name: snsParentName}, &corev1.Namespace{})
 	if err != nil {
-		return err
+		return false, err
+	}
+
+	labels, err := nsutils.LabelsBasedOnParent(parentNS, nsObject.Name())
+	if err != nil {
+		return false, err
+	}
+
+	treeLabels, err := nsutils.TreeLabelsBasedOnParent(parentNS.Object, nsObject.Name())
+	if err != nil {
+		return false, err
 	}
 
-	labels := nsutils.LabelsBasedOnParent(parentNS, nsObject.Name())
-	if err := nsObject.AppendLabels(labels); err != nil {
+	return ensureTreeLabels(nsObject, labels, treeLabels)
+}
+
+// ensureTreeLabels sets the given labels on a namespace, and makes sure that the tree labels of the
+// namespace are exactly the given tree labels, removing tree labels of namespaces which are no longer
+// its ancestors, as well as the legacy ancestor labels of its current and former ancestors. It returns
+// whether the tree labels of the namespace changed.
+func ensureTreeLabels(nsObject *objectcontext.ObjectContext, labels, treeLabels map[string]string) (bool, error) {
+	currentLabels := nsObject.Object.GetLabels()
+
+	ancestors := nsutils.TreeLabels(nsObject.Object)
+	for key := range treeLabels {
+		ancestors[strings.TrimSuffix(key, danav1.TreeLabelSuffix)] = 0
+	}
+
+	treeLabelsChanged := false
+	for key := range currentLabels {
+		if _, ok := treeLabels[key]; strings.HasSuffix(key, danav1.TreeLabelSuffix) && !ok {
+			treeLabelsChanged = true
+		}
+	}
+	for key, value := range treeLabels {
+		if currentLabels[key] != value {
+			treeLabelsChanged = true
+		}
+	}
+
+	labelsChanged := false
+	for key, value := range labels {
+		if currentLabels[key] != value {
+			labelsChanged = true
+		}
+	}
+	for key, value := range currentLabels {
+		if nsutils.IsLegacyAncestorLabel(key, value, ancestors) {
+			labelsChanged = true
+		}
+	}
+
+	if !treeLabelsChanged && !labelsChanged {
+		return false, nil
+	}
+
+	return treeLabelsChanged, nsObject.UpdateObject(func(object client.Object, log logr.Logger) (client.Object, logr.Logger) {
+		log = log.WithValues("updated", "hierarchy labels")
+		newLabels := map[string]string{}
+		for key, value := range object.GetLabels() {
+			if !strings.HasSuffix(key, danav1.TreeLabelSuffix) && !nsutils.IsLegacyAncestorLabel(key, value, ancestors) {
+				newLabels[key] = value
+			}
+		}
+		for key, value := range labels {
+			newLabels[key] = value
+		}
+		for key, value := range treeLabels {
+			newLabels[key] = value
+		}
+		object.SetLabels(newLabels)
+		return object, log
+	})
+}
+
+// enqueueChildrenNSEvents enqueues namespace events for the children namespaces of a namespace.
+func (r *NamespaceReconciler) enqueueChildrenNSEvents(nsObject *objectcontext.ObjectContext) error {
+	children, err := objectcontext.NewList(nsObject.Ctx, r.Client, &corev1.NamespaceList{}, client.MatchingLabels{danav1.Parent: nsObject.Name()})
+	if err != nil {
 		return err
 	}
 
+	for _, child := range children.Objects.(*corev1.NamespaceList).Items {
+		r.NSEvents.Enqueue(child.Name, "")
+	}
+
 	return nil
 }
 
@@ -165,11 +268,7 @@ func updateChildResourcePoolLabel(nsObject *objectcontext.ObjectContext, sns dan
 	}
 
 	if isOldSNSResourcePool != isNSResourcePool && !isSNSUpperResourcePool {
-		if err := snsObj.UpdateObject(func(object client.Object, log logr.Logger) (client.Object, logr.Logger) {
-			log = log.WithValues(danav1.ResourcePool, isNSResourcePool)
-			object.SetLabels(map[string]string{danav1.ResourcePool: strconv.FormatBool(isNSResourcePool)})
-			return object, log
-		}); err != nil {
+		if err := snsObj.AppendLabels(map[string]string{danav1.ResourcePool: strconv.FormatBool(isNSResourcePool)}); err != nil {
 			return err
 		}
 	}
@@ -0,0 +1,118 @@
+package namespace
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+	"sort"
+	"strings"
+
+	danav1 "github.com/dana-team/hns/api/v1"
+	"github.com/dana-team/hns/internal/common"
+	"github.com/dana-team/hns/internal/objectcontext"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+// userManagedKeys are the HNS annotations of a namespace which are set by cluster admins
+// and not by HNS, and therefore are not protected by the webhook.
+var userManagedKeys = []string{danav1.IsSecondaryRoot}
+
+// handleUpdate implements the logic for the update of a namespace.
+func (v *NamespaceValidator) handleUpdate(oldNS *corev1.Namespace, nsObject *objectcontext.ObjectContext, userName string) admission.Response {
+	if oldNS.Labels[danav1.Hns] == "" {
+		return validateUnmanagedNSUpdate(oldNS, nsObject, userName)
+	}
+
+	changedLabels := changedHNSKeys(oldNS.Labels, nsObject.Object.GetLabels())
+	changedAnnotations := changedHNSKeys(oldNS.Annotations, nsObject.Object.GetAnnotations())
+	if len(changedLabels) == 0 && len(changedAnnotations) == 0 {
+		return admission.Allowed("no HNS labels or annotations were changed")
+	}
+
+	canManage, err := common.CanManageHNSObjects(nsObject.Ctx, userName, v.Client)
+	if err != nil {
+		return admission.Errored(http.StatusInternalServerError, err)
+	}
+	if canManage {
+		return admission.Allowed("user is allowed to change the HNS labels and annotations of namespaces")
+	}
+
+	if len(changedLabels) > 0 {
+		message := fmt.Sprintf("it's forbidden to change the labels %q of namespace %q, they are managed by HNS",
+			changedLabels, nsObject.Name())
+		return admission.Denied(message)
+	}
+
+	message := fmt.Sprintf("it's forbidden to change the annotations %q of namespace %q, they are managed by HNS",
+		changedAnnotations, nsObject.Name())
+	return admission.Denied(message)
+}
+
+// validateUnmanagedNSUpdate validates that only HNS adds the labels and annotations it manages to a namespace
+// which is not managed by HNS, so that a namespace can't join a hierarchy without a subnamespace being created for it.
+func validateUnmanagedNSUpdate(oldNS *corev1.Namespace, nsObject *objectcontext.ObjectContext, userName string) admission.Response {
+	if common.IsHNSServiceAccount(userName) {
+		return admission.Allowed("HNS is allowed to add its labels and annotations to namespaces")
+	}
+
+	addedLabels := addedHNSKeys(oldNS.Labels, nsObject.Object.GetLabels())
+	if len(addedLabels) > 0 {
+		message := fmt.Sprintf("it's forbidden to add the labels %q to namespace %q, they are managed by HNS",
+			addedLabels, nsObject.Name())
+		return admission.Denied(message)
+	}
+
+	addedAnnotations := addedHNSKeys(oldNS.Annotations, nsObject.Object.GetAnnotations())
+	if len(addedAnnotations) > 0 {
+		message := fmt.Sprintf("it's forbidden to add the annotations %q to namespace %q, they are managed by HNS",
+			addedAnnotations, nsObject.Name())
+		return admission.Denied(message)
+	}
+
+	return admission.Allowed("namespaces not managed by HNS are only validated for added HNS labels and annotations")
+}
+
+// addedHNSKeys returns the sorted keys managed by HNS which were added or changed between
+// the old and the new labels or annotations of a namespace.
+func addedHNSKeys(oldMap, newMap map[string]string) []string {
+	var added []string
+
+	for key, newValue := range newMap {
+		if oldValue, ok := oldMap[key]; isHNSKey(key) && (!ok || newValue != oldValue) {
+			added = append(added, key)
+		}
+	}
+
+	sort.Strings(added)
+	return added
+}
+
+// changedHNSKeys returns the sorted keys managed by HNS which were added, changed or removed between
+// the old and the new labels or annotations of a namespace.
+func changedHNSKeys(oldMap, newMap map[string]string) []string {
+	var changed []string
+
+	for key, oldValue := range oldMap {
+		if newValue, ok := newMap[key]; isHNSKey(key) && (!ok || newValue != oldValue) {
+			changed = append(changed, key)
+		}
+	}
+	for key := range newMap {
+		if _, ok := oldMap[key]; isHNSKey(key) && !ok {
+			changed = append(changed, key)
+		}
+	}
+
+	sort.Strings(changed)
+	return changed
+}
+
+// isHNSKey returns whether a label or annotation key is managed by HNS.
+func isHNSKey(key string) bool {
+	if slices.Contains(userManagedKeys, key) {
+		return false
+	}
+
+	return strings.HasPrefix(key, danav1.MetaGroup) || strings.HasSuffix(key, danav1.TreeLabelSuffix)
+}
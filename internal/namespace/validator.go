@@ -18,7 +18,7 @@ type NamespaceValidator struct {
 	Decoder admission.Decoder
 }
 
-// +kubebuilder:webhook:path=/validate-v1-namespace,mutating=false,sideEffects=NoneOnDryRun,failurePolicy=fail,groups="core",resources=namespaces,verbs=delete,versions=v1,name=namespace.dana.io,admissionReviewVersions=v1;v1beta1
+// +kubebuilder:webhook:path=/validate-v1-namespace,mutating=false,sideEffects=NoneOnDryRun,failurePolicy=fail,groups="core",resources=namespaces,verbs=create;update;delete,versions=v1,name=namespace.dana.io,admissionReviewVersions=v1;v1beta1
 
 // Handle implements the validation webhook.
 func (v *NamespaceValidator) Handle(ctx context.Context, req admission.Request) admission.Response {
@@ -31,13 +31,37 @@ func (v *NamespaceValidator) Handle(ctx context.Context, req admission.Request)
 		return admission.Errored(http.StatusBadRequest, err)
 	}
 
-	if err := v.Decoder.DecodeRaw(req.OldObject, nsObject.Object); err != nil {
+	if req.Operation == admissionv1.Delete {
+		if err := v.Decoder.DecodeRaw(req.OldObject, nsObject.Object); err != nil {
+			logger.Error(err, "failed to decode object", "request object", req.OldObject)
+			return admission.Errored(http.StatusBadRequest, err)
+		}
+
+		if response := v.handleDelete(nsObject); !response.Allowed {
+			return response
+		}
+		return admission.Allowed("all validations passed")
+	}
+
+	if err := v.Decoder.DecodeRaw(req.Object, nsObject.Object); err != nil {
 		logger.Error(err, "failed to decode object", "request object", req.Object)
 		return admission.Errored(http.StatusBadRequest, err)
 	}
 
-	if req.Operation == admissionv1.Delete {
-		if response := v.handleDelete(nsObject); !response.Allowed {
+	if req.Operation == admissionv1.Create {
+		if response := v.handleCreate(nsObject, req.UserInfo.Username); !response.Allowed {
+			return response
+		}
+	}
+
+	if req.Operation == admissionv1.Update {
+		oldNS := &corev1.Namespace{}
+		if err := v.Decoder.DecodeRaw(req.OldObject, oldNS); err != nil {
+			logger.Error(err, "failed to decode object", "request object", req.OldObject)
+			return admission.Errored(http.StatusBadRequest, err)
+		}
+
+		if response := v.handleUpdate(oldNS, nsObject, req.UserInfo.Username); !response.Allowed {
 			return response
 		}
 	}
@@ -5,11 +5,11 @@ import (
 	"fmt"
 	"sort"
 	"strconv"
-	"strings"
 	"sync"
 
 	danav1 "github.com/dana-team/hns/api/v1"
 	"github.com/dana-team/hns/internal/common"
+	"github.com/dana-team/hns/internal/namespace/nsutils"
 	"github.com/dana-team/hns/internal/objectcontext"
 	"github.com/dana-team/hns/internal/quota"
 	"github.com/dana-team/hns/internal/subnamespace/snsutils"
@@ -24,12 +24,16 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/log"
 )
 
-// NamespaceDB is an in-memory DB that contains a map with a key that is a string representing the first
-// namespace in a hierarchy that is bound to a CRQ and not RQ, and a value that is a slice of all namespaces
-// which are under this particular key in the hierarchy.
+// crqForest is a map with a key that is a string representing the first namespace in a hierarchy that
+// is bound to a CRQ and not RQ, and a value that is a slice of all namespaces which are under this
+// particular key in the hierarchy.
+type crqForest map[string][]string
+
+// NamespaceDB is an in-memory DB that contains a crqForest for every root namespace in the cluster,
+// keyed by the name of the root namespace.
 type NamespaceDB struct {
-	crqForest map[string][]string
-	mutex     *sync.RWMutex
+	crqForests map[string]crqForest
+	mutex      *sync.RWMutex
 }
 
 // createClient returns a new client.
@@ -44,8 +48,7 @@ func createClient(scheme *runtime.Scheme) (client.Client, error) {
 func Init(scheme *runtime.Scheme, logger logr.Logger) (*NamespaceDB, error) {
 	logger.Info("initializing namespacedb")
 
-	nDB := &NamespaceDB{crqForest: make(map[string][]string), mutex: &sync.RWMutex{}}
-	rootNS := &corev1.Namespace{}
+	nDB := &NamespaceDB{crqForests: make(map[string]crqForest), mutex: &sync.RWMutex{}}
 	nsList := corev1.NamespaceList{}
 	snsList := danav1.SubnamespaceList{}
 	nsWithSns := corev1.NamespaceList{}
@@ -70,16 +73,24 @@ func Init(scheme *runtime.Scheme, logger logr.Logger) (*NamespaceDB, error) {
 		}
 	}
 
-	if len(nsWithSns.Items) > 0 {
-		rootNS = LocateNS(nsList, nsWithSns.Items[0].Annotations[danav1.RootCrqSelector])
-	}
-
 	for _, ns := range nsWithSns.Items {
 		if ok := ns.Annotations[danav1.Role] == danav1.Leaf; ok {
+			rootName, err := nsutils.Root(&ns)
+			if err != nil {
+				logger.Info("skipping namespace whose hierarchy is not known", "namespace", ns.Name, "reason", err.Error())
+				continue
+			}
+
+			rootNS := LocateNS(nsList, rootName)
+			if rootNS == nil {
+				logger.Info("skipping namespace whose root namespace does not exist", "namespace", ns.Name)
+				continue
+			}
+
 			if err := addHierarchy(ns, nsList, nDB, rootNS); err != nil {
 				return nDB, fmt.Errorf("failed to add hierarchy for %q: %v", ns.Name, err.Error())
 			}
-			logger.Info("successfully added hierarchy", "namespace", ns.Name)
+			logger.Info("successfully added hierarchy", "namespace", ns.Name, "root", rootNS.Name)
 		}
 	}
 
@@ -115,21 +126,22 @@ func addHierarchy(ns corev1.Namespace, nsList corev1.NamespaceList, ndb *Namespa
 	})
 
 	rqDepth, _ := strconv.Atoi(rootNS.Annotations[danav1.RqDepth])
+	root := rootNS.Name
 
 	// if the namespace is at a depth greater than the rqDepth, then it means
 	// that the namespace has a CRQ bound to it, and so add it to the appropriate key in the NamespaceDB
 	if len(nsListUp) > rqDepth {
 		keyName := nsListUp[rqDepth].GetName()
 
-		if !ndb.doesKeyExist(keyName) {
-			if err := ndb.addNSToKey(keyName, keyName); err != nil {
+		if !ndb.doesKeyExist(root, keyName) {
+			if err := ndb.addNSToKey(root, keyName, keyName); err != nil {
 				return fmt.Errorf("failed to add namespace %q to key %q: %v", keyName, keyName, err.Error())
 			}
 		}
 
 		for _, namespace := range nsListUp[rqDepth+1:] {
-			if !ndb.valInKeyExist(keyName, namespace.GetName()) {
-				if err := ndb.addNSToKey(keyName, namespace.GetName()); err != nil {
+			if !ndb.valInKeyExist(root, keyName, namespace.GetName()) {
+				if err := ndb.addNSToKey(root, keyName, namespace.GetName()); err != nil {
 					return fmt.Errorf("failed to add namespace %q to key %q: %v", namespace.GetName(), keyName, err.Error())
 				}
 			}
@@ -141,8 +153,10 @@ func addHierarchy(ns corev1.Namespace, nsList corev1.NamespaceList, ndb *Namespa
 
 // NSListUp creates a slice of all namespaces in the hierarchy from ns to rootNS.
 func NSListUp(ns corev1.Namespace, rootNS string, nsList corev1.NamespaceList) ([]corev1.Namespace, error) {
-	displayName := ns.GetAnnotations()[danav1.DisplayName]
-	nsArray := strings.Split(displayName, "/")
+	nsArray, err := nsutils.Ancestors(&ns)
+	if err != nil {
+		return nil, err
+	}
 
 	index, err := common.IndexOf(rootNS, nsArray)
 	if err != nil {
@@ -159,23 +173,23 @@ func NSListUp(ns corev1.Namespace, rootNS string, nsList corev1.NamespaceList) (
 	return nsListUp, nil
 }
 
-// doesKeyExist checks whether a key with a specific name exists in the db.
-func (ndb *NamespaceDB) doesKeyExist(key string) bool {
+// doesKeyExist checks whether a key with a specific name exists in the db of a root.
+func (ndb *NamespaceDB) doesKeyExist(root, key string) bool {
 	ndb.mutex.RLock()
 	defer ndb.mutex.RUnlock()
 
-	if _, ok := ndb.crqForest[key]; ok {
+	if _, ok := ndb.crqForests[root][key]; ok {
 		return true
 	}
 	return false
 }
 
-// valInKeyExist checks if a value exists in a key's slice of values.
-func (ndb *NamespaceDB) valInKeyExist(key string, value string) bool {
+// valInKeyExist checks if a value exists in a key's slice of values in the db of a root.
+func (ndb *NamespaceDB) valInKeyExist(root, key string, value string) bool {
 	ndb.mutex.RLock()
 	defer ndb.mutex.RUnlock()
 
-	for _, val := range ndb.crqForest[key] {
+	for _, val := range ndb.crqForests[root][key] {
 		if val == value {
 			return true
 		}
@@ -183,25 +197,31 @@ func (ndb *NamespaceDB) valInKeyExist(key string, value string) bool {
 	return false
 }
 
-// addNSToKey adds namespace to its key namespace in the db.
-func (ndb *NamespaceDB) addNSToKey(key string, ns string) error {
+// addNSToKey adds namespace to its key namespace in the db of a root.
+func (ndb *NamespaceDB) addNSToKey(root, key string, ns string) error {
 	ndb.mutex.Lock()
 	defer ndb.mutex.Unlock()
 
+	forest, ok := ndb.crqForests[root]
+	if !ok {
+		forest = crqForest{}
+		ndb.crqForests[root] = forest
+	}
+
 	if key == ns {
-		ndb.crqForest[key] = []string{}
+		forest[key] = []string{}
 	} else {
-		if nsList, ok := ndb.crqForest[key]; ok {
+		if nsList, ok := forest[key]; ok {
 			nsList = append(nsList, ns)
-			ndb.crqForest[key] = nsList
+			forest[key] = nsList
 		} else {
 			nsList := []string{ns}
-			ndb.crqForest[key] = nsList
+			forest[key] = nsList
 		}
 	}
 
-	if _, ok := ndb.crqForest[key]; !ok {
-		return fmt.Errorf("key %q does not exist in NamespaceDB", key)
+	if _, ok := forest[key]; !ok {
+		return fmt.Errorf("key %q does not exist in NamespaceDB of root %q", key, root)
 	}
 
 	return nil
@@ -211,13 +231,17 @@ func (ndb *NamespaceDB) addNSToKey(key string, ns string) error {
 // should be the key itself and adds it to the DB.
 func AddNS(ctx context.Context, nDB *NamespaceDB, client client.Client, sns *danav1.Subnamespace) error {
 	logger := log.FromContext(ctx)
-	keyNS := nDB.Key(sns.Namespace)
+	root, err := nsutils.SNSRoot(ctx, client, sns)
+	if err != nil {
+		return err
+	}
+	keyNS := nDB.Key(root, sns.Namespace)
 
 	if !isKeyEmpty(keyNS) {
-		if err := nDB.addNSToKey(keyNS, sns.Name); err != nil {
+		if err := nDB.addNSToKey(root, keyNS, sns.Name); err != nil {
 			return fmt.Errorf("failed to add namespace %q to key %q: %v", sns.Name, keyNS, err.Error())
 		}
-		logger.Info("added namespace under key in namespacedb", "namespace", sns.Name, "key", keyNS)
+		logger.Info("added namespace under key in namespacedb", "namespace", sns.Name, "key", keyNS, "root", root)
 		return nil
 	}
 
@@ -231,18 +255,19 @@ func AddNS(ctx context.Context, nDB *NamespaceDB, client client.Client, sns *dan
 
 	// if a key does not already exist for the namespace, but the namespace has a CRQ then it means
 	// that the namespace itself should be the key in the DB.
-	if err := nDB.addNSToKey(sns.Name, sns.Name); err != nil {
+	if err := nDB.addNSToKey(root, sns.Name, sns.Name); err != nil {
 		return fmt.Errorf("failed to add namespace %q to key %q: %v", sns.Name, sns.Name, err.Error())
 	}
-	logger.Info("added namespace under key in namespacedb", "namespace", sns.Name, "key", sns.Name)
+	logger.Info("added namespace under key in namespacedb", "namespace", sns.Name, "key", sns.Name, "root", root)
 
 	return nil
 }
 
-// MigrateNSHierarchy migrates namespace and its children hierarchy from one key to another key.
-func MigrateNSHierarchy(ctx context.Context, ndb *NamespaceDB, client client.Client, snsName string, destNSName string) error {
-	oldKeyNS := ndb.Key(snsName)
-	newKeyNS := ndb.Key(destNSName)
+// MigrateNSHierarchy migrates namespace and its children hierarchy from one key to another key
+// in the db of a root.
+func MigrateNSHierarchy(ctx context.Context, ndb *NamespaceDB, client client.Client, root, snsName string, destNSName string) error {
+	oldKeyNS := ndb.Key(root, snsName)
+	newKeyNS := ndb.Key(root, destNSName)
 
 	ns, err := objectcontext.New(ctx, client, types.NamespacedName{Name: snsName}, &corev1.Namespace{})
 	if err != nil {
@@ -256,13 +281,13 @@ func MigrateNSHierarchy(ctx context.Context, ndb *NamespaceDB, client client.Cli
 		childName := child.Name()
 
 		if !isKeyEmpty(oldKeyNS) && oldKeyNS != snsName {
-			if err := ndb.RemoveNS(childName, oldKeyNS); err != nil {
+			if err := ndb.RemoveNS(root, childName, oldKeyNS); err != nil {
 				return fmt.Errorf("removing namespace %q from key %q failed: %v", childName, oldKeyNS, err.Error())
 			}
 		}
 
 		if !isKeyEmpty(newKeyNS) {
-			if err := ndb.addNSToKey(newKeyNS, childName); err != nil {
+			if err := ndb.addNSToKey(root, newKeyNS, childName); err != nil {
 				return fmt.Errorf("adding namespace %q to key %q failed: %v", childName, newKeyNS, err.Error())
 			}
 		}
@@ -270,30 +295,58 @@ func MigrateNSHierarchy(ctx context.Context, ndb *NamespaceDB, client client.Cli
 	return nil
 }
 
-// RemoveNS removes a namespace from the slice of namespaces that belongs to a key.
-func (ndb *NamespaceDB) RemoveNS(nsname string, key string) error {
+// RenameNS replaces a namespace with its new name in the db of a root, whether the namespace is
+// a key in the db or a namespace which belongs to a key.
+func (ndb *NamespaceDB) RenameNS(root, oldName, newName string) {
+	ndb.mutex.Lock()
+	defer ndb.mutex.Unlock()
+
+	forest := ndb.crqForests[root]
+
+	if namespaces, ok := forest[oldName]; ok {
+		forest[newName] = append(forest[newName], namespaces...)
+		delete(forest, oldName)
+		return
+	}
+
+	for key, namespaces := range forest {
+		renamed := []string{}
+		for _, namespace := range namespaces {
+			if namespace != oldName && namespace != newName {
+				renamed = append(renamed, namespace)
+			} else if namespace == oldName {
+				renamed = append(renamed, newName)
+			}
+		}
+		forest[key] = renamed
+	}
+}
+
+// RemoveNS removes a namespace from the slice of namespaces that belongs to a key in the db of a root.
+func (ndb *NamespaceDB) RemoveNS(root, nsname string, key string) error {
 	ndb.mutex.Lock()
 	defer ndb.mutex.Unlock()
 
-	if _, ok := ndb.crqForest[key]; !ok {
-		return fmt.Errorf("key %q does not exist in NamespaceDB", key)
+	forest := ndb.crqForests[root]
+	if _, ok := forest[key]; !ok {
+		return fmt.Errorf("key %q does not exist in NamespaceDB of root %q", key, root)
 	}
 
-	for i, namespace := range ndb.crqForest[key] {
+	for i, namespace := range forest[key] {
 		if namespace == nsname {
-			ndb.crqForest[key] = append(ndb.crqForest[key][:i], ndb.crqForest[key][i+1:]...)
+			forest[key] = append(forest[key][:i], forest[key][i+1:]...)
 		}
 	}
 
 	return nil
 }
 
-// Key retrieves the key that the provided namespace belongs to.
-func (ndb *NamespaceDB) Key(ns string) string {
+// Key retrieves the key that the provided namespace belongs to in the db of a root.
+func (ndb *NamespaceDB) Key(root, ns string) string {
 	ndb.mutex.RLock()
 	defer ndb.mutex.RUnlock()
 
-	for key, namespaces := range ndb.crqForest {
+	for key, namespaces := range ndb.crqForests[root] {
 		if key == ns {
 			return key
 		}
@@ -309,20 +362,23 @@ func (ndb *NamespaceDB) Key(ns string) string {
 	return ""
 }
 
-// DeleteKey deletes a key from the database.
-func (ndb *NamespaceDB) DeleteKey(key string) {
-	ndb.mutex.RLock()
-	defer ndb.mutex.RUnlock()
+// DeleteKey deletes a key from the db of a root, and deletes the db of the root if it's left empty.
+func (ndb *NamespaceDB) DeleteKey(root, key string) {
+	ndb.mutex.Lock()
+	defer ndb.mutex.Unlock()
 
-	delete(ndb.crqForest, key)
+	delete(ndb.crqForests[root], key)
+	if len(ndb.crqForests[root]) == 0 {
+		delete(ndb.crqForests, root)
+	}
 }
 
-// KeyCount returns the number of namespaces that belong to a specific key.
-func (ndb *NamespaceDB) KeyCount(key string) int {
+// KeyCount returns the number of namespaces that belong to a specific key in the db of a root.
+func (ndb *NamespaceDB) KeyCount(root, key string) int {
 	ndb.mutex.RLock()
 	defer ndb.mutex.RUnlock()
 
-	if ns, ok := ndb.crqForest[key]; ok {
+	if ns, ok := ndb.crqForests[root][key]; ok {
 		return len(ns)
 	}
 
@@ -336,7 +392,12 @@ func isKeyEmpty(key string) bool {
 
 // EnsureSNSInDB ensures subnamespace in db if it should be.
 func EnsureSNSInDB(ctx context.Context, sns *objectcontext.ObjectContext, nDB *NamespaceDB) error {
-	key := nDB.Key(sns.Name())
+	root, err := nsutils.SNSRoot(ctx, sns.Client, sns.Object)
+	if err != nil {
+		return err
+	}
+
+	key := nDB.Key(root, sns.Name())
 	if key != "" {
 		return nil
 	}
@@ -14,7 +14,7 @@ import (
 // CreateObject creates the objectContext.object in the cluster.
 func (r *ObjectContext) CreateObject() error {
 	logger := r.Log.WithName("objectContext.CreateObject")
-	if err := r.Create(r.Ctx, r.Object); err != nil {
+	if err := r.Create(r.Ctx, r.Object, client.FieldOwner(FieldManager)); err != nil {
 		if apierrors.IsAlreadyExists(err) {
 			logger.Info(fmt.Sprintf("%s %s already exists", r.Object.GetObjectKind().GroupVersionKind().Kind, r.Name()))
 			r.present = true
@@ -24,26 +24,27 @@ func (r *ObjectContext) CreateObject() error {
 		return err
 	}
 	r.present = true
+	r.snapshot()
 	logger.Info(fmt.Sprintf("%s %s created", r.Object.GetObjectKind().GroupVersionKind().Kind, r.Name()))
 	return nil
 }
 
-// UpdateObject updates the objectContext.object in the cluster.
+// UpdateObject updates the objectContext.object in the cluster. Only the fields which were changed
+// since the object was last read from the cluster are written, so changes which were made to other
+// fields of the object in the meantime are kept. The changes are written with an optimistic lock, since
+// a merge patch replaces lists such as finalizers as a whole; if the object was changed in the cluster
+// in the meantime, it is read again and the update is applied to it again. An object which does not
+// exist in the cluster is only updated locally.
 func (r *ObjectContext) UpdateObject(update func(object client.Object, log logr.Logger) (client.Object, logr.Logger)) error {
-	logger := r.Log.WithName("objectContext.UpdateObject")
-	r.Object, logger = update(r.Object, logger)
-	if r.present {
-		if err := r.Update(r.Ctx, r.Object); err != nil {
-			if apierrors.IsConflict(err) {
-				logger.Info(fmt.Sprintf("newer resource version exists for %s %s ", r.Object.GetObjectKind().GroupVersionKind().Kind, r.Name()))
-				return nil
-			}
-			logger.Error(err, fmt.Sprintf("unable to update %s %s ", r.Object.GetObjectKind().GroupVersionKind().Kind, r.Name()))
-			return err
-		}
+	if !r.present {
+		r.Object, _ = update(r.Object, r.Log.WithName("objectContext.UpdateObject"))
+		return nil
 	}
-	logger.Info(fmt.Sprintf("%s %s updated", r.Object.GetObjectKind().GroupVersionKind().Kind, r.Name()))
-	return nil
+
+	return r.EnsureUpdateObject(func(object client.Object, log logr.Logger) (client.Object, logr.Logger, error) {
+		object, log = update(object, log)
+		return object, log, nil
+	}, false)
 }
 
 // DeleteObject deletes the objectContext.object from the cluster.
@@ -124,12 +125,7 @@ func (r *ObjectContext) update(ctx context.Context, update func(object client.Ob
 		if err != nil {
 			return err
 		}
-		if isStatusUpdate {
-			err = r.Status().Update(ctx, r.Object)
-		} else {
-			err = r.Update(ctx, r.Object)
-		}
-		if err != nil {
+		if err = r.patch(ctx, isStatusUpdate, true); err != nil {
 			if apierrors.IsConflict(err) {
 				localLogger.Info(fmt.Sprintf("conflict while updating %s %s: %s", r.GetKindName(), r.Name(), err))
 			} else {
@@ -157,6 +153,47 @@ func (r *ObjectContext) refresh() error {
 		logger.Info(fmt.Sprintf("unable to refresh %s %s", r.Object.GetObjectKind().GroupVersionKind().Kind, r.Name()))
 		return err
 	}
+	r.snapshot()
+
+	return nil
+}
+
+// patch writes the changes which were made to the object since it was last read from or written to the cluster
+// as a merge patch owned by the HNS field manager, and does nothing if there are no changes. With an optimistic
+// lock, the patch fails with a conflict if the object was changed in the cluster in the meantime.
+func (r *ObjectContext) patch(ctx context.Context, isStatusUpdate, optimisticLock bool) error {
+	// the object was not read from the cluster, e.g. when it already existed when creating it,
+	// so there is nothing to compute the changes from and the whole object is written
+	if r.original == nil {
+		if err := r.Update(ctx, r.Object, client.FieldOwner(FieldManager)); err != nil {
+			return err
+		}
+		r.snapshot()
+		return nil
+	}
+
+	data, err := client.MergeFrom(r.original).Data(r.Object)
+	if err != nil {
+		return err
+	}
+	if string(data) == "{}" {
+		return nil
+	}
+
+	patch := client.MergeFrom(r.original)
+	if optimisticLock {
+		patch = client.MergeFromWithOptions(r.original, client.MergeFromWithOptimisticLock{})
+	}
+
+	if isStatusUpdate {
+		err = r.Status().Patch(ctx, r.Object, patch, client.FieldOwner(FieldManager))
+	} else {
+		err = r.Patch(ctx, r.Object, patch, client.FieldOwner(FieldManager))
+	}
+	if err != nil {
+		return err
+	}
+	r.snapshot()
 
 	return nil
 }
@@ -173,57 +210,77 @@ func (r *ObjectContext) EnsureUpdateObject(update func(object client.Object, log
 }
 
 // AppendAnnotations appends the received annotations to objectContext.object annotations.
+// The object is not updated if it already has all the annotations.
 func (r *ObjectContext) AppendAnnotations(annotationsToAppend map[string]string) error {
-	newAnnotations := r.Object.GetAnnotations()
-	if newAnnotations == nil {
-		newAnnotations = annotationsToAppend
-	} else {
-		for key, value := range annotationsToAppend {
-			newAnnotations[key] = value
-		}
+	if hasEntries(r.Object.GetAnnotations(), annotationsToAppend) {
+		return nil
 	}
-	r.Object.SetAnnotations(newAnnotations)
 
-	if err := r.UpdateObject(func(object client.Object, log logr.Logger) (client.Object, logr.Logger) {
+	return r.UpdateObject(func(object client.Object, log logr.Logger) (client.Object, logr.Logger) {
 		log = log.WithValues("updated", "annotations")
+		object.SetAnnotations(appendToMap(object.GetAnnotations(), annotationsToAppend))
 		return object, log
-	}); err != nil {
-		return err
-	}
-	return nil
+	})
 }
 
 // DeleteAnnotations gets a slice of strings and deletes all the annotations with keys in the slice.
+// The object is not updated if it has none of the annotations.
 func (r *ObjectContext) DeleteAnnotations(annotationsToDelete []string) error {
-	ann := r.Object.GetAnnotations()
+	changed := false
 	for _, key := range annotationsToDelete {
-		delete(ann, key)
+		if _, ok := r.Object.GetAnnotations()[key]; ok {
+			changed = true
+		}
+	}
+	if !changed {
+		return nil
 	}
-	r.Object.SetAnnotations(ann)
 
-	if err := r.UpdateObject(func(object client.Object, log logr.Logger) (client.Object, logr.Logger) {
+	return r.UpdateObject(func(object client.Object, log logr.Logger) (client.Object, logr.Logger) {
 		log = log.WithValues("updated", "annotations")
+		annotations := object.GetAnnotations()
+		for _, key := range annotationsToDelete {
+			delete(annotations, key)
+		}
+		object.SetAnnotations(annotations)
 		return object, log
-	}); err != nil {
-		return err
-	}
-	return nil
+	})
 }
 
 // AppendLabels appends the received labels to objectContext.object labels.
+// The object is not updated if it already has all the labels.
 func (r *ObjectContext) AppendLabels(labelsToAppend map[string]string) error {
-	newLabels := r.Object.GetLabels()
-
-	for key, value := range labelsToAppend {
-		newLabels[key] = value
+	if hasEntries(r.Object.GetLabels(), labelsToAppend) {
+		return nil
 	}
-	r.Object.SetLabels(newLabels)
 
-	if err := r.UpdateObject(func(object client.Object, log logr.Logger) (client.Object, logr.Logger) {
+	return r.UpdateObject(func(object client.Object, log logr.Logger) (client.Object, logr.Logger) {
 		log = log.WithValues("updated", "labels")
+		object.SetLabels(appendToMap(object.GetLabels(), labelsToAppend))
 		return object, log
-	}); err != nil {
-		return err
+	})
+}
+
+// hasEntries returns whether a map has all the given entries with the same values.
+func hasEntries(m, entries map[string]string) bool {
+	for key, value := range entries {
+		if current, ok := m[key]; !ok || current != value {
+			return false
+		}
 	}
-	return nil
+
+	return true
+}
+
+// appendToMap appends the given entries to a map, creating it if it's nil, and returns the map.
+func appendToMap(m, entries map[string]string) map[string]string {
+	if m == nil {
+		m = map[string]string{}
+	}
+
+	for key, value := range entries {
+		m[key] = value
+	}
+
+	return m
 }
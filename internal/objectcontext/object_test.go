@@ -0,0 +1,100 @@
+package objectcontext
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	danav1 "github.com/dana-team/hns/api/v1"
+	"github.com/dana-team/hns/internal/testutils"
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+// newTestSubnamespace returns a fake client holding a subnamespace, along with its key and object context.
+func newTestSubnamespace(t *testing.T) (client.Client, types.NamespacedName, *ObjectContext) {
+	key := types.NamespacedName{Name: "a", Namespace: "root"}
+	fakeClient := testutils.NewFakeClient(t, &danav1.Subnamespace{
+		ObjectMeta: metav1.ObjectMeta{Name: key.Name, Namespace: key.Namespace},
+	})
+
+	snsObject, err := New(context.Background(), fakeClient, key, &danav1.Subnamespace{})
+	if err != nil {
+		t.Fatalf("failed to get subnamespace: %v", err)
+	}
+
+	return fakeClient, key, snsObject
+}
+
+func TestUpdateObjectKeepsOtherChanges(t *testing.T) {
+	ctx := context.Background()
+	fakeClient, key, snsObject := newTestSubnamespace(t)
+
+	// the subnamespace is changed in the cluster after it was read
+	userSNS := &danav1.Subnamespace{}
+	if err := fakeClient.Get(ctx, key, userSNS); err != nil {
+		t.Fatalf("failed to get subnamespace: %v", err)
+	}
+	userSNS.SetLabels(map[string]string{"team": "test"})
+	if err := fakeClient.Update(ctx, userSNS); err != nil {
+		t.Fatalf("failed to update subnamespace: %v", err)
+	}
+
+	if err := snsObject.AppendLabels(map[string]string{danav1.ResourcePool: "false"}); err != nil {
+		t.Fatalf("failed to append labels: %v", err)
+	}
+	if err := snsObject.EnsureUpdateObject(func(object client.Object, l logr.Logger) (client.Object, logr.Logger, error) {
+		object.(*danav1.Subnamespace).Spec.ResourceQuotaSpec.Hard = corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("1")}
+		return object, l, nil
+	}, false); err != nil {
+		t.Fatalf("failed to update subnamespace: %v", err)
+	}
+
+	got := &danav1.Subnamespace{}
+	if err := fakeClient.Get(ctx, key, got); err != nil {
+		t.Fatalf("failed to get subnamespace: %v", err)
+	}
+	if got.Labels["team"] != "test" || got.Labels[danav1.ResourcePool] != "false" {
+		t.Errorf("expected the labels of both updates to be kept, got %v", got.Labels)
+	}
+	if cpu := got.Spec.ResourceQuotaSpec.Hard[corev1.ResourceCPU]; cpu.Cmp(resource.MustParse("1")) != 0 {
+		t.Errorf("expected the quota to be updated, got %v", got.Spec.ResourceQuotaSpec.Hard)
+	}
+}
+
+func TestUpdateObjectKeepsConcurrentListChanges(t *testing.T) {
+	ctx := context.Background()
+	fakeClient, key, snsObject := newTestSubnamespace(t)
+
+	// a finalizer is added in the cluster after the subnamespace was read. A merge patch replaces the
+	// whole list of finalizers, so writing the list from the stale object would remove this finalizer
+	otherSNS := &danav1.Subnamespace{}
+	if err := fakeClient.Get(ctx, key, otherSNS); err != nil {
+		t.Fatalf("failed to get subnamespace: %v", err)
+	}
+	controllerutil.AddFinalizer(otherSNS, "other.io/finalizer")
+	if err := fakeClient.Update(ctx, otherSNS); err != nil {
+		t.Fatalf("failed to update subnamespace: %v", err)
+	}
+
+	if err := snsObject.UpdateObject(func(object client.Object, l logr.Logger) (client.Object, logr.Logger) {
+		controllerutil.AddFinalizer(object, danav1.RbFinalizer)
+		return object, l
+	}); err != nil {
+		t.Fatalf("failed to update subnamespace: %v", err)
+	}
+
+	got := &danav1.Subnamespace{}
+	if err := fakeClient.Get(ctx, key, got); err != nil {
+		t.Fatalf("failed to get subnamespace: %v", err)
+	}
+	want := []string{"other.io/finalizer", danav1.RbFinalizer}
+	if !reflect.DeepEqual(got.Finalizers, want) {
+		t.Errorf("expected finalizers %v, got %v", want, got.Finalizers)
+	}
+}
@@ -10,12 +10,20 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/log"
 )
 
+// FieldManager is the name of the field manager HNS creates and updates objects with.
+const FieldManager = "hns"
+
 type ObjectContext struct {
 	client.Client
 	Ctx     context.Context
 	Log     logr.Logger
 	Object  client.Object
 	present bool
+
+	// original is a copy of the object as it was last read from or written to the cluster. Updates are sent as
+	// merge patches from it, so that only the fields which HNS changed are written and changes which were
+	// made to other fields of the object in the meantime, such as labels added by users, are kept
+	original client.Object
 }
 
 type ObjectContextList struct {
@@ -40,6 +48,7 @@ func New(ctx context.Context, Client client.Client, req types.NamespacedName, ob
 	}
 	objectContext.present = true
 	objectContext.Object = object
+	objectContext.snapshot()
 
 	return &objectContext, nil
 }
@@ -59,6 +68,11 @@ func NewList(ctx context.Context, Client client.Client, objects client.ObjectLis
 	return &objectContextList, nil
 }
 
+// snapshot records the current state of the object as the state it has in the cluster.
+func (r *ObjectContext) snapshot() {
+	r.original = r.Object.DeepCopyObject().(client.Object)
+}
+
 // Name returns the object name.
 func (r *ObjectContext) Name() string {
 	return r.Object.GetName()
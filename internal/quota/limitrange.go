@@ -3,6 +3,11 @@ package quota
 import (
 	"context"
 	"fmt"
+	"sort"
+
+	danav1 "github.com/dana-team/hns/api/v1"
+	"github.com/go-logr/logr"
+	"k8s.io/apimachinery/pkg/api/equality"
 
 	"github.com/dana-team/hns/internal/common"
 
@@ -71,12 +76,12 @@ func getLimits(ctx context.Context, k8sClient client.Client) ([]corev1.LimitRang
 	return []corev1.LimitRangeItem{ContainerLimits, PVCLimits}, nil
 }
 
-// CreateDefaultSNSLimitRange creates a limit range object with some default values
-// that we would like to limit and are not set by the user.
+// CreateDefaultSNSLimitRange creates a limit range object with the limits inherited by the subnamespace,
+// i.e. the default values set in the HNSConfig tightened by the policies of the subnamespace and its ancestors.
 func CreateDefaultSNSLimitRange(snsObject *objectcontext.ObjectContext) error {
 	snsName := snsObject.Name()
 
-	limits, err := getLimits(snsObject.Ctx, snsObject.Client)
+	limits, err := SubnamespaceLimits(snsObject)
 	if err != nil {
 		return fmt.Errorf("error getting default limits: %w", err)
 	}
@@ -93,6 +98,286 @@ func CreateDefaultSNSLimitRange(snsObject *objectcontext.ObjectContext) error {
 	return err
 }
 
+// SyncSNSLimitRange makes sure the limit range object of a subnamespace matches the limits inherited
+// by the subnamespace. It returns whether the limit range object was changed.
+func SyncSNSLimitRange(snsObject *objectcontext.ObjectContext) (bool, error) {
+	snsName := snsObject.Name()
+
+	limits, err := SubnamespaceLimits(snsObject)
+	if err != nil {
+		return false, fmt.Errorf("error getting limits: %w", err)
+	}
+
+	limitRange, err := objectcontext.New(snsObject.Ctx, snsObject.Client, types.NamespacedName{Name: snsName, Namespace: snsName}, &corev1.LimitRange{})
+	if err != nil {
+		return false, err
+	}
+
+	if !limitRange.IsPresent() {
+		return true, CreateDefaultSNSLimitRange(snsObject)
+	}
+
+	if isLimitRangeUpToDate(limitRange.Object.(*corev1.LimitRange), limits) {
+		return false, nil
+	}
+
+	err = limitRange.UpdateObject(func(object client.Object, log logr.Logger) (client.Object, logr.Logger) {
+		log = log.WithValues("updated LimitRange", snsName)
+		object.(*corev1.LimitRange).Spec.Limits = limits
+		return object, log
+	})
+
+	return true, err
+}
+
+// isLimitRangeUpToDate returns true if a LimitRange already has the given limits. The limits are compared after
+// applying the defaults the API server sets on LimitRanges, since otherwise limits without explicit defaults
+// never match the LimitRange and it would be updated on every sync.
+func isLimitRangeUpToDate(limitRange *corev1.LimitRange, limits []corev1.LimitRangeItem) bool {
+	return equality.Semantic.DeepEqual(limitRange.Spec.Limits, defaultLimits(limits))
+}
+
+// defaultLimits returns a copy of the limits with the defaults the API server sets on LimitRanges: for
+// containers, a missing default limit is set to the maximum, and a missing default request is set to
+// the default limit, or to the minimum if there is no default limit.
+func defaultLimits(limits []corev1.LimitRangeItem) []corev1.LimitRangeItem {
+	var defaulted []corev1.LimitRangeItem
+
+	for _, limit := range limits {
+		item := *limit.DeepCopy()
+		if item.Type == corev1.LimitTypeContainer {
+			if item.Default == nil {
+				item.Default = corev1.ResourceList{}
+			}
+			if item.DefaultRequest == nil {
+				item.DefaultRequest = corev1.ResourceList{}
+			}
+			for _, fallback := range []struct{ from, to corev1.ResourceList }{
+				{item.Max, item.Default},
+				{item.Default, item.DefaultRequest},
+				{item.Min, item.DefaultRequest},
+			} {
+				for resourceName, quantity := range fallback.from {
+					if _, ok := fallback.to[resourceName]; !ok {
+						fallback.to[resourceName] = quantity.DeepCopy()
+					}
+				}
+			}
+		}
+		defaulted = append(defaulted, item)
+	}
+
+	return defaulted
+}
+
+// SubnamespaceLimitRangePolicy returns the LimitRange policy of a subnamespace.
+func SubnamespaceLimitRangePolicy(sns client.Object) []corev1.LimitRangeItem {
+	limitRangeSpec := sns.(*danav1.Subnamespace).Spec.LimitRangeSpec
+	if limitRangeSpec == nil {
+		return nil
+	}
+
+	return limitRangeSpec.Limits
+}
+
+// SubnamespaceLimits returns the limits that apply to the namespace of a subnamespace.
+func SubnamespaceLimits(snsObject *objectcontext.ObjectContext) ([]corev1.LimitRangeItem, error) {
+	inherited, err := SubnamespaceInheritedLimits(snsObject)
+	if err != nil {
+		return nil, err
+	}
+
+	return tightenLimits(inherited, SubnamespaceLimitRangePolicy(snsObject.Object)), nil
+}
+
+// SubnamespaceInheritedLimits returns the limits a subnamespace inherits from its ancestors, i.e. the
+// limits set in the HNSConfig tightened by the LimitRange policies of all the ancestors of the subnamespace.
+func SubnamespaceInheritedLimits(snsObject *objectcontext.ObjectContext) ([]corev1.LimitRangeItem, error) {
+	limits, err := getLimits(snsObject.Ctx, snsObject.Client)
+	if err != nil {
+		return nil, err
+	}
+
+	ancestorsPolicies, err := ancestorsLimitRangePolicies(snsObject)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := len(ancestorsPolicies) - 1; i >= 0; i-- {
+		limits = tightenLimits(limits, ancestorsPolicies[i])
+	}
+
+	return limits, nil
+}
+
+// ancestorsLimitRangePolicies returns the LimitRange policies of the ancestors of a subnamespace,
+// ordered from the parent of the subnamespace up to the root.
+func ancestorsLimitRangePolicies(snsObject *objectcontext.ObjectContext) ([][]corev1.LimitRangeItem, error) {
+	var policies [][]corev1.LimitRangeItem
+
+	nsName := snsObject.Namespace()
+	for nsName != "" {
+		ns, err := objectcontext.New(snsObject.Ctx, snsObject.Client, types.NamespacedName{Name: nsName}, &corev1.Namespace{})
+		if err != nil {
+			return nil, err
+		}
+
+		parentName := ns.Object.GetLabels()[danav1.Parent]
+		if !ns.IsPresent() || parentName == "" {
+			break
+		}
+
+		sns, err := objectcontext.New(snsObject.Ctx, snsObject.Client, types.NamespacedName{Name: nsName, Namespace: parentName}, &danav1.Subnamespace{})
+		if err != nil {
+			return nil, err
+		}
+
+		if sns.IsPresent() {
+			policies = append(policies, SubnamespaceLimitRangePolicy(sns.Object))
+		}
+
+		nsName = parentName
+	}
+
+	return policies, nil
+}
+
+// LoosenedLimits returns a description of every limit in policy which is looser than
+// the matching limit in inherited. A policy is looser if it lowers a minimum or raises
+// a maximum or a max limit/request ratio, or if it sets a minimum above an inherited maximum.
+func LoosenedLimits(inherited, policy []corev1.LimitRangeItem) []string {
+	var loosened []string
+
+	for _, item := range policy {
+		inheritedItem := limitRangeItem(inherited, item.Type)
+		if inheritedItem == nil {
+			continue
+		}
+
+		for resourceName, minimum := range item.Min {
+			if inheritedMin, ok := inheritedItem.Min[resourceName]; ok && minimum.Cmp(inheritedMin) < 0 {
+				loosened = append(loosened, fmt.Sprintf("minimum %s of type %s (%s) is lower than the inherited minimum (%s)",
+					resourceName, item.Type, minimum.String(), inheritedMin.String()))
+			}
+			if inheritedMax, ok := inheritedItem.Max[resourceName]; ok && minimum.Cmp(inheritedMax) > 0 {
+				loosened = append(loosened, fmt.Sprintf("minimum %s of type %s (%s) is higher than the inherited maximum (%s)",
+					resourceName, item.Type, minimum.String(), inheritedMax.String()))
+			}
+		}
+
+		for resourceName, maximum := range item.Max {
+			if inheritedMax, ok := inheritedItem.Max[resourceName]; ok && maximum.Cmp(inheritedMax) > 0 {
+				loosened = append(loosened, fmt.Sprintf("maximum %s of type %s (%s) is higher than the inherited maximum (%s)",
+					resourceName, item.Type, maximum.String(), inheritedMax.String()))
+			}
+		}
+
+		for resourceName, ratio := range item.MaxLimitRequestRatio {
+			if inheritedRatio, ok := inheritedItem.MaxLimitRequestRatio[resourceName]; ok && ratio.Cmp(inheritedRatio) > 0 {
+				loosened = append(loosened, fmt.Sprintf("max limit/request ratio of %s of type %s (%s) is higher than the inherited ratio (%s)",
+					resourceName, item.Type, ratio.String(), inheritedRatio.String()))
+			}
+		}
+	}
+
+	sort.Strings(loosened)
+	return loosened
+}
+
+// tightenLimits returns the limits that result from applying a policy on top of inherited limits.
+// Minimums can only be raised and maximums and ratios can only be lowered. Defaults are taken from
+// the policy when set and are kept within the resulting minimum and maximum.
+func tightenLimits(inherited, policy []corev1.LimitRangeItem) []corev1.LimitRangeItem {
+	var limits []corev1.LimitRangeItem
+
+	for _, item := range inherited {
+		limits = append(limits, *item.DeepCopy())
+	}
+
+	for _, item := range policy {
+		limitItem := limitRangeItem(limits, item.Type)
+		if limitItem == nil {
+			limits = append(limits, *item.DeepCopy())
+			continue
+		}
+
+		limitItem.Min = mergeResourceList(limitItem.Min, item.Min, func(inherited, policy resource.Quantity) bool {
+			return policy.Cmp(inherited) > 0
+		})
+		limitItem.Max = mergeResourceList(limitItem.Max, item.Max, func(inherited, policy resource.Quantity) bool {
+			return policy.Cmp(inherited) < 0
+		})
+		limitItem.MaxLimitRequestRatio = mergeResourceList(limitItem.MaxLimitRequestRatio, item.MaxLimitRequestRatio, func(inherited, policy resource.Quantity) bool {
+			return policy.Cmp(inherited) < 0
+		})
+		limitItem.Default = mergeResourceList(limitItem.Default, item.Default, func(_, _ resource.Quantity) bool {
+			return true
+		})
+		limitItem.DefaultRequest = mergeResourceList(limitItem.DefaultRequest, item.DefaultRequest, func(_, _ resource.Quantity) bool {
+			return true
+		})
+	}
+
+	for i := range limits {
+		clampDefaults(&limits[i])
+	}
+
+	return limits
+}
+
+// mergeResourceList returns a copy of inherited in which every resource of policy
+// replaces the inherited resource if it is missing or if replace returns true.
+func mergeResourceList(inherited, policy corev1.ResourceList, replace func(inherited, policy resource.Quantity) bool) corev1.ResourceList {
+	if len(policy) == 0 {
+		return inherited
+	}
+
+	merged := inherited.DeepCopy()
+	if merged == nil {
+		merged = corev1.ResourceList{}
+	}
+
+	for resourceName, quantity := range policy {
+		if inheritedQuantity, ok := merged[resourceName]; !ok || replace(inheritedQuantity, quantity) {
+			merged[resourceName] = quantity.DeepCopy()
+		}
+	}
+
+	return merged
+}
+
+// clampDefaults keeps the default limits and default requests of a LimitRangeItem between its
+// minimum and maximum, and keeps the default requests lower than the default limits.
+func clampDefaults(item *corev1.LimitRangeItem) {
+	for _, defaults := range []corev1.ResourceList{item.Default, item.DefaultRequest} {
+		for resourceName, quantity := range defaults {
+			if minimum, ok := item.Min[resourceName]; ok && quantity.Cmp(minimum) < 0 {
+				defaults[resourceName] = minimum.DeepCopy()
+			}
+			if maximum, ok := item.Max[resourceName]; ok && quantity.Cmp(maximum) > 0 {
+				defaults[resourceName] = maximum.DeepCopy()
+			}
+		}
+	}
+
+	for resourceName, request := range item.DefaultRequest {
+		if limit, ok := item.Default[resourceName]; ok && request.Cmp(limit) > 0 {
+			item.DefaultRequest[resourceName] = limit.DeepCopy()
+		}
+	}
+}
+
+// limitRangeItem returns a pointer to the LimitRangeItem of the given type, or nil if there is none.
+func limitRangeItem(limits []corev1.LimitRangeItem, limitType corev1.LimitType) *corev1.LimitRangeItem {
+	for i := range limits {
+		if limits[i].Type == limitType {
+			return &limits[i]
+		}
+	}
+
+	return nil
+}
+
 // composeLimitRange returns a LimitRange object based on the given parameters.
 func composeLimitRange(name string, namespace string, limits []corev1.LimitRangeItem) *corev1.LimitRange {
 	return &corev1.LimitRange{
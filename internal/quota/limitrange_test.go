@@ -0,0 +1,69 @@
+package quota
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+func TestIsLimitRangeUpToDate(t *testing.T) {
+	limits := []corev1.LimitRangeItem{
+		{
+			Type:           corev1.LimitTypeContainer,
+			Min:            corev1.ResourceList{cpu: resource.MustParse("10m"), memory: resource.MustParse("10Mi")},
+			Max:            corev1.ResourceList{cpu: resource.MustParse("4"), "ephemeral-storage": resource.MustParse("10Gi"), "nvidia.com/gpu": resource.MustParse("2")},
+			Default:        corev1.ResourceList{cpu: resource.MustParse("500m"), memory: resource.MustParse("512Mi")},
+			DefaultRequest: corev1.ResourceList{cpu: resource.MustParse("100m")},
+		},
+		{
+			Type: corev1.LimitTypePersistentVolumeClaim,
+			Min:  corev1.ResourceList{storage: resource.MustParse("1Gi")},
+		},
+	}
+
+	// the LimitRange as it is returned by the API server after it is created with the limits
+	apiDefaulted := &corev1.LimitRange{
+		Spec: corev1.LimitRangeSpec{
+			Limits: []corev1.LimitRangeItem{
+				{
+					Type: corev1.LimitTypeContainer,
+					Min:  corev1.ResourceList{cpu: resource.MustParse("10m"), memory: resource.MustParse("10Mi")},
+					Max:  corev1.ResourceList{cpu: resource.MustParse("4"), "ephemeral-storage": resource.MustParse("10Gi"), "nvidia.com/gpu": resource.MustParse("2")},
+					Default: corev1.ResourceList{
+						cpu:                 resource.MustParse("500m"),
+						memory:              resource.MustParse("512Mi"),
+						"ephemeral-storage": resource.MustParse("10Gi"),
+						"nvidia.com/gpu":    resource.MustParse("2"),
+					},
+					DefaultRequest: corev1.ResourceList{
+						cpu:                 resource.MustParse("100m"),
+						memory:              resource.MustParse("512Mi"),
+						"ephemeral-storage": resource.MustParse("10Gi"),
+						"nvidia.com/gpu":    resource.MustParse("2"),
+					},
+				},
+				{
+					Type: corev1.LimitTypePersistentVolumeClaim,
+					Min:  corev1.ResourceList{storage: resource.MustParse("1Gi")},
+				},
+			},
+		},
+	}
+
+	if !isLimitRangeUpToDate(apiDefaulted, limits) {
+		t.Errorf("expected a LimitRange defaulted by the API server to be up to date with the limits it was created with")
+	}
+
+	tightened := apiDefaulted.DeepCopy()
+	tightened.Spec.Limits[0].Max["nvidia.com/gpu"] = resource.MustParse("1")
+	if isLimitRangeUpToDate(tightened, limits) {
+		t.Errorf("expected a LimitRange with a different maximum not to be up to date")
+	}
+
+	for _, item := range limits {
+		if _, ok := item.Default["ephemeral-storage"]; ok {
+			t.Errorf("expected the limits not to be changed by the comparison")
+		}
+	}
+}
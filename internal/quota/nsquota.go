@@ -1,8 +1,17 @@
 package quota
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strings"
+
+	danav1 "github.com/dana-team/hns/api/v1"
+	"github.com/dana-team/hns/internal/common"
 	"github.com/dana-team/hns/internal/namespace/nsutils"
 	"github.com/dana-team/hns/internal/objectcontext"
+	"github.com/go-logr/logr"
 	corev1 "k8s.io/api/core/v1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
@@ -41,3 +50,93 @@ func RootNSObjectFromName(obj *objectcontext.ObjectContext, rootNSName string) (
 
 	return rootNSQuotaObj, nil
 }
+
+// NormalizeRootNSObject replaces the resource aliases in the quota object of a root namespace
+// with their canonical names.
+func NormalizeRootNSObject(ns *objectcontext.ObjectContext) error {
+	quotaObj, err := RootNSObject(ns)
+	if err != nil {
+		return err
+	}
+
+	if !quotaObj.IsPresent() {
+		return nil
+	}
+
+	aliases, err := common.GetResourceAliases(ns.Ctx, ns.Client)
+	if err != nil {
+		return err
+	}
+
+	quotaSpec := GetQuotaObjectSpec(quotaObj.Object)
+	if IsResourceListNormalized(quotaSpec.Hard, aliases) {
+		return nil
+	}
+
+	normalizedQuotaSpec, err := NormalizeResourceQuotaSpec(quotaSpec, aliases)
+	if err != nil {
+		return err
+	}
+
+	return quotaObj.UpdateObject(func(object client.Object, log logr.Logger) (client.Object, logr.Logger) {
+		log = log.WithValues("updated root quota object", "normalized spec")
+		object.(*corev1.ResourceQuota).Spec = normalizedQuotaSpec
+		return object, log
+	})
+}
+
+// EnsureRootNSObject makes sure the quota object of a root namespace exists and that the given spec
+// was applied to it. The spec is applied again only when it changes, so that changes which HNS makes to
+// the quota of the root namespace after the spec was applied are kept.
+func EnsureRootNSObject(ns *objectcontext.ObjectContext, resources corev1.ResourceQuotaSpec) error {
+	aliases, err := common.GetResourceAliases(ns.Ctx, ns.Client)
+	if err != nil {
+		return err
+	}
+
+	normalizedResources, err := NormalizeResourceQuotaSpec(resources, aliases)
+	if err != nil {
+		return err
+	}
+
+	appliedQuota := resourceListHash(normalizedResources.Hard)
+	composedRQ := composeRQ(ns.Name(), ns.Name(), normalizedResources)
+	composedRQ.SetAnnotations(map[string]string{danav1.AppliedRootQuota: appliedQuota})
+
+	quotaObj, err := objectcontext.New(ns.Ctx, ns.Client, client.ObjectKey{Namespace: ns.Name(), Name: ns.Name()}, composedRQ)
+	if err != nil {
+		return err
+	}
+
+	if !quotaObj.IsPresent() {
+		return quotaObj.EnsureCreate()
+	}
+
+	if quotaObj.Object.GetAnnotations()[danav1.AppliedRootQuota] == appliedQuota {
+		return nil
+	}
+
+	return quotaObj.UpdateObject(func(object client.Object, log logr.Logger) (client.Object, logr.Logger) {
+		log = log.WithValues("updated root quota object", "spec")
+		object.(*corev1.ResourceQuota).Spec = normalizedResources
+		annotations := object.GetAnnotations()
+		if annotations == nil {
+			annotations = map[string]string{}
+		}
+		annotations[danav1.AppliedRootQuota] = appliedQuota
+		object.SetAnnotations(annotations)
+		return object, log
+	})
+}
+
+// resourceListHash returns a hash which identifies the quantities of a ResourceList.
+func resourceListHash(resources corev1.ResourceList) string {
+	var entries []string
+	for resourceName, quantity := range resources {
+		entries = append(entries, fmt.Sprintf("%s=%s", resourceName, quantity.String()))
+	}
+	sort.Strings(entries)
+
+	hash := sha256.Sum256([]byte(strings.Join(entries, ",")))
+	return hex.EncodeToString(hash[:])[:10]
+}
@@ -4,13 +4,14 @@ import (
 	danav1 "github.com/dana-team/hns/api/v1"
 	quotav1 "github.com/openshift/api/quota/v1"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
 // IsZeroed returns whether a quota object is zeroed.
 func IsZeroed(QuotaObject client.Object) bool {
 	for _, quantity := range GetQuotaObjectSpec(QuotaObject).Hard {
-		if quantity.Value() != 0 {
+		if !quantity.IsZero() {
 			return false
 		}
 	}
@@ -41,6 +42,19 @@ func GetQuotaObjectSpec(QuotaObject client.Object) corev1.ResourceQuotaSpec {
 
 }
 
+// SpecEqual returns whether the specs of two quota objects (RQ or CRQ) or two LimitRanges are equal.
+func SpecEqual(oldObject, newObject client.Object) bool {
+	if oldLimitRange, ok := oldObject.(*corev1.LimitRange); ok {
+		return equality.Semantic.DeepEqual(oldLimitRange.Spec, newObject.(*corev1.LimitRange).Spec)
+	}
+
+	if oldCRQ, ok := oldObject.(*quotav1.ClusterResourceQuota); ok {
+		return equality.Semantic.DeepEqual(oldCRQ.Spec, newObject.(*quotav1.ClusterResourceQuota).Spec)
+	}
+
+	return equality.Semantic.DeepEqual(GetQuotaObjectSpec(oldObject), GetQuotaObjectSpec(newObject))
+}
+
 // GetQuotaUsed returns the used value from the status of a quota object (RQ or CRQ).
 func GetQuotaUsed(QuotaObject client.Object) corev1.ResourceList {
 	crqCast, ok := QuotaObject.(*quotav1.ClusterResourceQuota)
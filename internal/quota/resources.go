@@ -1,8 +1,10 @@
 package quota
 
 import (
+	"fmt"
 	"slices"
 
+	"github.com/dana-team/hns/internal/common"
 	"github.com/dana-team/hns/internal/objectcontext"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
@@ -27,16 +29,114 @@ func ResourceListEqual(resourceListA, resourceListB corev1.ResourceList) bool {
 	return true
 }
 
+// AddResourceLists returns a new ResourceList which is the exact sum of the given ResourceLists.
+// A resource that is missing from one of the ResourceLists is treated as zero.
+func AddResourceLists(resourceListA, resourceListB corev1.ResourceList) corev1.ResourceList {
+	sum := resourceListA.DeepCopy()
+	if sum == nil {
+		sum = corev1.ResourceList{}
+	}
+
+	for resourceName, quantity := range resourceListB {
+		current, ok := sum[resourceName]
+		if !ok {
+			sum[resourceName] = quantity.DeepCopy()
+			continue
+		}
+
+		current.Add(quantity)
+		sum[resourceName] = current
+	}
+
+	return sum
+}
+
+// SubResourceLists returns a new ResourceList which is the exact difference of the given ResourceLists.
+// A resource that is missing from one of the ResourceLists is treated as zero.
+func SubResourceLists(resourceListA, resourceListB corev1.ResourceList) corev1.ResourceList {
+	difference := resourceListA.DeepCopy()
+	if difference == nil {
+		difference = corev1.ResourceList{}
+	}
+
+	for resourceName, quantity := range resourceListB {
+		current, ok := difference[resourceName]
+		if !ok {
+			current = resource.Quantity{Format: quantity.Format}
+		}
+
+		current.Sub(quantity)
+		difference[resourceName] = current
+	}
+
+	return difference
+}
+
+// NormalizeResourceList returns a copy of the given ResourceList in which every resource alias is replaced
+// by its canonical resource name. It returns an error if a resource is set more than once under different
+// names with different quantities.
+func NormalizeResourceList(resourceList corev1.ResourceList, aliases common.ResourceAliases) (corev1.ResourceList, error) {
+	if resourceList == nil {
+		return nil, nil
+	}
+
+	normalized := corev1.ResourceList{}
+	for resourceName, quantity := range resourceList {
+		canonicalName := aliases.Canonical(resourceName)
+
+		if existing, ok := normalized[canonicalName]; ok && existing.Cmp(quantity) != 0 {
+			return nil, fmt.Errorf("resource %q is set more than once with different quantities, "+
+				"%q is an alias of %q", canonicalName.String(), resourceName.String(), canonicalName.String())
+		}
+
+		normalized[canonicalName] = quantity.DeepCopy()
+	}
+
+	return normalized, nil
+}
+
+// NormalizeResourceQuotaSpec returns a copy of the given ResourceQuotaSpec in which every resource alias
+// is replaced by its canonical resource name.
+func NormalizeResourceQuotaSpec(resourceQuotaSpec corev1.ResourceQuotaSpec, aliases common.ResourceAliases) (corev1.ResourceQuotaSpec, error) {
+	normalized := *resourceQuotaSpec.DeepCopy()
+
+	hard, err := NormalizeResourceList(resourceQuotaSpec.Hard, aliases)
+	if err != nil {
+		return corev1.ResourceQuotaSpec{}, err
+	}
+	normalized.Hard = hard
+
+	return normalized, nil
+}
+
+// IsResourceListNormalized returns whether the given ResourceList only uses canonical resource names.
+func IsResourceListNormalized(resourceList corev1.ResourceList, aliases common.ResourceAliases) bool {
+	for resourceName := range resourceList {
+		if aliases.Canonical(resourceName) != resourceName {
+			return false
+		}
+	}
+
+	return true
+}
+
 // ResourceQuotaSpecEqual gets two ResourceQuotaSpecs and returns whether their specs are equal.
-func ResourceQuotaSpecEqual(resourceQuotaSpecA, resourceQuotaSpecB corev1.ResourceQuotaSpec, observedResourcesSpec corev1.ResourceQuotaSpec) bool {
+// Resource aliases are compared by their canonical resource names.
+func ResourceQuotaSpecEqual(resourceQuotaSpecA, resourceQuotaSpecB corev1.ResourceQuotaSpec, observedResourcesSpec corev1.ResourceQuotaSpec, aliases common.ResourceAliases) bool {
 	var resources []string
 
 	for resourceName := range observedResourcesSpec.Hard {
-		resources = append(resources, resourceName.String())
+		resources = append(resources, aliases.Canonical(resourceName).String())
+	}
+
+	normalizedA, errA := NormalizeResourceList(resourceQuotaSpecA.Hard, aliases)
+	normalizedB, errB := NormalizeResourceList(resourceQuotaSpecB.Hard, aliases)
+	if errA != nil || errB != nil {
+		return false
 	}
 
-	resourceQuotaSpecAFiltered := filterResources(resourceQuotaSpecA.Hard, resources)
-	resourceQuotaSpecBFiltered := filterResources(resourceQuotaSpecB.Hard, resources)
+	resourceQuotaSpecAFiltered := filterResources(normalizedA, resources)
+	resourceQuotaSpecBFiltered := filterResources(normalizedB, resources)
 
 	return ResourceListEqual(resourceQuotaSpecAFiltered, resourceQuotaSpecBFiltered)
 }
@@ -64,7 +164,7 @@ func addResourcesToList(resourcesList *corev1.ResourceList, quantity resource.Qu
 			return
 		}
 	}
-	(*resourcesList)[corev1.ResourceName(name)] = quantity
+	(*resourcesList)[corev1.ResourceName(name)] = quantity.DeepCopy()
 }
 
 // GetQuotaObjectsListResources returns a ResourceList with all the resources of the given objects summed up.
@@ -85,6 +185,6 @@ func addQuantityToResourceList(resourceList corev1.ResourceList, resourceName co
 		currentQuantity.Add(quantity)
 		resourceList[resourceName] = currentQuantity
 	} else {
-		resourceList[resourceName] = quantity
+		resourceList[resourceName] = quantity.DeepCopy()
 	}
 }
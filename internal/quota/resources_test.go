@@ -0,0 +1,269 @@
+package quota
+
+import (
+	"fmt"
+	"math/rand"
+	"reflect"
+	"testing"
+	"testing/quick"
+
+	"github.com/dana-team/hns/internal/common"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+var (
+	testResourceNames = []corev1.ResourceName{
+		"cpu",
+		"memory",
+		"pods",
+		"requests.nvidia.com/gpu",
+		"basic.storageclass.storage.k8s.io/requests.storage",
+		"fast.storageclass.storage.k8s.io/requests.storage",
+	}
+	testUnitSuffixes = []string{"", "n", "u", "m", "k", "M", "G", "Ki", "Mi", "Gi", "e3"}
+)
+
+// mixedUnitsResourceList is a ResourceList whose quantities are generated with mixed units,
+// and in which every resource may or may not be present.
+type mixedUnitsResourceList corev1.ResourceList
+
+// Generate implements quick.Generator.
+func (mixedUnitsResourceList) Generate(r *rand.Rand, _ int) reflect.Value {
+	resourceList := mixedUnitsResourceList{}
+
+	for _, resourceName := range testResourceNames {
+		if r.Intn(3) == 0 {
+			continue
+		}
+
+		value := r.Int63n(1000000)
+		if r.Intn(4) == 0 {
+			value = -value
+		}
+		suffix := testUnitSuffixes[r.Intn(len(testUnitSuffixes))]
+
+		resourceList[resourceName] = resource.MustParse(fmt.Sprintf("%d%s", value, suffix))
+	}
+
+	return reflect.ValueOf(resourceList)
+}
+
+// exactSum returns the exact sum or difference of two quantities computed as arbitrary precision decimals.
+func exactSum(a, b resource.Quantity, sub bool) resource.Quantity {
+	a = a.DeepCopy()
+	sum := a.AsDec()
+	if sub {
+		sum.Sub(sum, b.AsDec())
+	} else {
+		sum.Add(sum, b.AsDec())
+	}
+
+	return *resource.NewDecimalQuantity(*sum, resource.DecimalSI)
+}
+
+func TestAddResourceListsIsExact(t *testing.T) {
+	property := func(a, b mixedUnitsResourceList) bool {
+		sum := AddResourceLists(corev1.ResourceList(a), corev1.ResourceList(b))
+
+		for _, resourceName := range testResourceNames {
+			quantityA, okA := a[resourceName]
+			quantityB, okB := b[resourceName]
+			quantitySum, okSum := sum[resourceName]
+
+			if okSum != (okA || okB) {
+				return false
+			}
+			if okSum && quantitySum.Cmp(exactSum(quantityA, quantityB, false)) != 0 {
+				return false
+			}
+		}
+
+		return true
+	}
+
+	if err := quick.Check(property, nil); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestSubResourceListsIsExact(t *testing.T) {
+	property := func(a, b mixedUnitsResourceList) bool {
+		difference := SubResourceLists(corev1.ResourceList(a), corev1.ResourceList(b))
+
+		for _, resourceName := range testResourceNames {
+			quantityA, okA := a[resourceName]
+			quantityB, okB := b[resourceName]
+			quantityDifference, okDifference := difference[resourceName]
+
+			if okDifference != (okA || okB) {
+				return false
+			}
+			if okDifference && quantityDifference.Cmp(exactSum(quantityA, quantityB, true)) != 0 {
+				return false
+			}
+		}
+
+		return true
+	}
+
+	if err := quick.Check(property, nil); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestAddResourceListsIsCommutative(t *testing.T) {
+	property := func(a, b mixedUnitsResourceList) bool {
+		return ResourceListEqual(
+			AddResourceLists(corev1.ResourceList(a), corev1.ResourceList(b)),
+			AddResourceLists(corev1.ResourceList(b), corev1.ResourceList(a)))
+	}
+
+	if err := quick.Check(property, nil); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestSubResourceListsUndoesAddResourceLists(t *testing.T) {
+	property := func(a, b mixedUnitsResourceList) bool {
+		result := SubResourceLists(AddResourceLists(corev1.ResourceList(a), corev1.ResourceList(b)), corev1.ResourceList(b))
+
+		for resourceName, quantity := range result {
+			original := a[resourceName]
+			if quantity.Cmp(original) != 0 {
+				return false
+			}
+		}
+
+		return len(result) >= len(a)
+	}
+
+	if err := quick.Check(property, nil); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestResourceListsArithmeticDoesNotMutateArguments(t *testing.T) {
+	property := func(a, b mixedUnitsResourceList) bool {
+		copyA := corev1.ResourceList(a).DeepCopy()
+		copyB := corev1.ResourceList(b).DeepCopy()
+
+		AddResourceLists(corev1.ResourceList(a), corev1.ResourceList(b))
+		SubResourceLists(corev1.ResourceList(a), corev1.ResourceList(b))
+
+		return ResourceListEqual(copyA, corev1.ResourceList(a)) && ResourceListEqual(copyB, corev1.ResourceList(b))
+	}
+
+	if err := quick.Check(property, nil); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestResourceListsArithmetic(t *testing.T) {
+	tests := []struct {
+		name       string
+		a          corev1.ResourceList
+		b          corev1.ResourceList
+		sum        corev1.ResourceList
+		difference corev1.ResourceList
+	}{
+		{
+			name:       "millicores",
+			a:          corev1.ResourceList{"cpu": resource.MustParse("500m")},
+			b:          corev1.ResourceList{"cpu": resource.MustParse("250m")},
+			sum:        corev1.ResourceList{"cpu": resource.MustParse("750m")},
+			difference: corev1.ResourceList{"cpu": resource.MustParse("250m")},
+		},
+		{
+			name:       "mixed units",
+			a:          corev1.ResourceList{"memory": resource.MustParse("1.5Gi"), "cpu": resource.MustParse("1")},
+			b:          corev1.ResourceList{"memory": resource.MustParse("512Mi"), "cpu": resource.MustParse("100m")},
+			sum:        corev1.ResourceList{"memory": resource.MustParse("2Gi"), "cpu": resource.MustParse("1100m")},
+			difference: corev1.ResourceList{"memory": resource.MustParse("1Gi"), "cpu": resource.MustParse("900m")},
+		},
+		{
+			name:       "resource missing on one side",
+			a:          corev1.ResourceList{"cpu": resource.MustParse("2")},
+			b:          corev1.ResourceList{"fast.storageclass.storage.k8s.io/requests.storage": resource.MustParse("10Gi")},
+			sum:        corev1.ResourceList{"cpu": resource.MustParse("2"), "fast.storageclass.storage.k8s.io/requests.storage": resource.MustParse("10Gi")},
+			difference: corev1.ResourceList{"cpu": resource.MustParse("2"), "fast.storageclass.storage.k8s.io/requests.storage": resource.MustParse("-10Gi")},
+		},
+		{
+			name:       "nil ResourceList",
+			a:          nil,
+			b:          corev1.ResourceList{"requests.nvidia.com/gpu": resource.MustParse("1")},
+			sum:        corev1.ResourceList{"requests.nvidia.com/gpu": resource.MustParse("1")},
+			difference: corev1.ResourceList{"requests.nvidia.com/gpu": resource.MustParse("-1")},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if sum := AddResourceLists(tt.a, tt.b); !ResourceListEqual(sum, tt.sum) {
+				t.Errorf("AddResourceLists() = %v, want %v", sum, tt.sum)
+			}
+			if difference := SubResourceLists(tt.a, tt.b); !ResourceListEqual(difference, tt.difference) {
+				t.Errorf("SubResourceLists() = %v, want %v", difference, tt.difference)
+			}
+		})
+	}
+}
+
+func TestNormalizeResourceList(t *testing.T) {
+	aliases := common.ResourceAliases{
+		"requests.cpu":     "cpu",
+		"requests.memory":  "memory",
+		"requests.storage": "basic.storageclass.storage.k8s.io/requests.storage",
+	}
+
+	tests := []struct {
+		name         string
+		resourceList corev1.ResourceList
+		want         corev1.ResourceList
+		wantErr      bool
+	}{
+		{
+			name:         "canonical names are kept",
+			resourceList: corev1.ResourceList{"cpu": resource.MustParse("1"), "pods": resource.MustParse("10")},
+			want:         corev1.ResourceList{"cpu": resource.MustParse("1"), "pods": resource.MustParse("10")},
+		},
+		{
+			name: "aliases are replaced by canonical names",
+			resourceList: corev1.ResourceList{
+				"requests.cpu":     resource.MustParse("500m"),
+				"requests.memory":  resource.MustParse("1Gi"),
+				"requests.storage": resource.MustParse("10Gi"),
+			},
+			want: corev1.ResourceList{
+				"cpu":    resource.MustParse("500m"),
+				"memory": resource.MustParse("1Gi"),
+				"basic.storageclass.storage.k8s.io/requests.storage": resource.MustParse("10Gi"),
+			},
+		},
+		{
+			name:         "alias and canonical name with equal quantities",
+			resourceList: corev1.ResourceList{"cpu": resource.MustParse("1"), "requests.cpu": resource.MustParse("1000m")},
+			want:         corev1.ResourceList{"cpu": resource.MustParse("1")},
+		},
+		{
+			name:         "alias and canonical name with different quantities",
+			resourceList: corev1.ResourceList{"cpu": resource.MustParse("1"), "requests.cpu": resource.MustParse("2")},
+			wantErr:      true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NormalizeResourceList(tt.resourceList, aliases)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NormalizeResourceList() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !ResourceListEqual(got, tt.want) {
+				t.Errorf("NormalizeResourceList() = %v, want %v", got, tt.want)
+			}
+			if !tt.wantErr && !IsResourceListNormalized(got, aliases) {
+				t.Errorf("IsResourceListNormalized() = false for %v", got)
+			}
+		})
+	}
+}
@@ -3,6 +3,7 @@ package quota
 import (
 	"fmt"
 	"strconv"
+	"time"
 
 	"github.com/dana-team/hns/internal/common"
 
@@ -288,3 +289,36 @@ func SubnamespaceChildrenObjects(sns *objectcontext.ObjectContext) []*objectcont
 
 	return childrenQuotaObjects
 }
+
+// EnsureSubnamespaceObjectEqual compares the sns quota spec and the quota object spec in a loop until they are equal,
+// this way we can know that the subnamespace has been properly updated before changing the quota of other subnamespaces.
+func EnsureSubnamespaceObjectEqual(sns *objectcontext.ObjectContext) error {
+	ok := false
+	retries := 0
+
+	snsQuotaSpec := sns.Object.(*danav1.Subnamespace).Spec.ResourceQuotaSpec
+
+	// To avoid an infinite loop in case of an actual failure, the loop runs at most a MAX_RETRIES number times
+	for (!ok) && (retries < danav1.MaxRetries) {
+		ok = true
+		quotaObject, err := SubnamespaceObject(sns)
+		if err != nil {
+			return err
+		}
+		resourceQuotaSpec := GetQuotaObjectSpec(quotaObject.Object)
+		for res, quantity := range resourceQuotaSpec.Hard {
+			if quantity.Cmp(snsQuotaSpec.Hard[res]) != 0 {
+				ok = false
+			}
+		}
+		for res := range snsQuotaSpec.Hard {
+			if _, found := resourceQuotaSpec.Hard[res]; !found {
+				ok = false
+			}
+		}
+		// wait between iterations because we don't want to overload the API with many requests
+		time.Sleep(danav1.SleepTimeout * time.Millisecond)
+		retries++
+	}
+	return nil
+}
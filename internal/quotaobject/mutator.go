@@ -0,0 +1,109 @@
+package quotaobject
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	danav1 "github.com/dana-team/hns/api/v1"
+	"github.com/dana-team/hns/internal/common"
+	"github.com/dana-team/hns/internal/quota"
+	quotav1 "github.com/openshift/api/quota/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+type QuotaObjectMutator struct {
+	Client  client.Client
+	Decoder admission.Decoder
+}
+
+// +kubebuilder:webhook:path=/mutate-v1-resourcequota,mutating=true,sideEffects=NoneOnDryRun,failurePolicy=fail,groups="",resources=resourcequotas,verbs=create;update,versions=v1,name=resourcequota.dana.io,admissionReviewVersions=v1;v1beta1
+// +kubebuilder:webhook:path=/mutate-v1-clusterresourcequota,mutating=true,sideEffects=NoneOnDryRun,failurePolicy=fail,groups="quota.openshift.io",resources=clusterresourcequotas,verbs=create;update,versions=v1,name=clusterresourcequota.dana.io,admissionReviewVersions=v1;v1beta1
+
+// Handle implements the mutation webhook.
+func (m *QuotaObjectMutator) Handle(ctx context.Context, req admission.Request) admission.Response {
+	logger := log.FromContext(ctx).WithValues("webhook", "Quota Object mutation Webhook", "Name", req.Name)
+	logger.Info("webhook request received")
+
+	var quotaObject client.Object
+	if req.Kind.Kind == "ClusterResourceQuota" {
+		quotaObject = &quotav1.ClusterResourceQuota{}
+	} else {
+		quotaObject = &corev1.ResourceQuota{}
+	}
+
+	if err := m.Decoder.DecodeRaw(req.Object, quotaObject); err != nil {
+		logger.Error(err, "failed to decode object", "request object", req.Object)
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+
+	isHNSQuotaObject, err := m.isHNSQuotaObject(ctx, quotaObject)
+	if err != nil {
+		logger.Error(err, "failed to check if quota object is managed by HNS")
+		return admission.Errored(http.StatusInternalServerError, err)
+	}
+
+	if !isHNSQuotaObject {
+		return admission.Allowed("quota objects not managed by HNS are not mutated")
+	}
+
+	aliases, err := common.GetResourceAliases(ctx, m.Client)
+	if err != nil {
+		logger.Error(err, "failed to get resource aliases")
+		return admission.Errored(http.StatusInternalServerError, err)
+	}
+
+	if err := normalizeQuotaObject(quotaObject, aliases); err != nil {
+		return admission.Denied(err.Error())
+	}
+
+	marshalQuotaObject, err := json.Marshal(quotaObject)
+	if err != nil {
+		logger.Error(err, "failed to marshal object", "object", quotaObject)
+		return admission.Errored(http.StatusInternalServerError, err)
+	}
+
+	return admission.PatchResponseFromRaw(req.Object.Raw, marshalQuotaObject)
+}
+
+// isHNSQuotaObject returns whether a quota object is managed by HNS. A ResourceQuota is managed by HNS
+// if its namespace is managed by HNS, and a ClusterResourceQuota is managed by HNS if it selects namespaces
+// using the HNS selector annotations.
+func (m *QuotaObjectMutator) isHNSQuotaObject(ctx context.Context, quotaObject client.Object) (bool, error) {
+	if crq, ok := quotaObject.(*quotav1.ClusterResourceQuota); ok {
+		for key := range crq.Spec.Selector.AnnotationSelector {
+			if strings.HasPrefix(key, danav1.CrqSelector) {
+				return true, nil
+			}
+		}
+		return false, nil
+	}
+
+	ns := &corev1.Namespace{}
+	if err := m.Client.Get(ctx, types.NamespacedName{Name: quotaObject.GetNamespace()}, ns); err != nil {
+		return false, client.IgnoreNotFound(err)
+	}
+
+	return ns.GetLabels()[danav1.Hns] == "true", nil
+}
+
+// normalizeQuotaObject replaces the resource aliases in the spec of a quota object with their canonical names.
+func normalizeQuotaObject(quotaObject client.Object, aliases common.ResourceAliases) error {
+	quotaSpec, err := quota.NormalizeResourceQuotaSpec(quota.GetQuotaObjectSpec(quotaObject), aliases)
+	if err != nil {
+		return err
+	}
+
+	if crq, ok := quotaObject.(*quotav1.ClusterResourceQuota); ok {
+		crq.Spec.Quota = quotaSpec
+	} else {
+		quotaObject.(*corev1.ResourceQuota).Spec = quotaSpec
+	}
+
+	return nil
+}
@@ -0,0 +1,136 @@
+package quotaobject
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	danav1 "github.com/dana-team/hns/api/v1"
+	"github.com/dana-team/hns/internal/common"
+	"github.com/dana-team/hns/internal/namespace/nsutils"
+	"github.com/dana-team/hns/internal/quota"
+	quotav1 "github.com/openshift/api/quota/v1"
+	admissionv1 "k8s.io/api/admission/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+// QuotaObjectValidator validates that the quota objects and LimitRanges of subnamespaces,
+// which are managed by HNS, are only changed by HNS.
+type QuotaObjectValidator struct {
+	Client  client.Client
+	Decoder admission.Decoder
+}
+
+// +kubebuilder:webhook:path=/validate-v1-resourcequota,mutating=false,sideEffects=NoneOnDryRun,failurePolicy=fail,groups="",resources=resourcequotas,verbs=create;update;delete,versions=v1,name=resourcequota.dana.io,admissionReviewVersions=v1;v1beta1
+// +kubebuilder:webhook:path=/validate-v1-clusterresourcequota,mutating=false,sideEffects=NoneOnDryRun,failurePolicy=fail,groups="quota.openshift.io",resources=clusterresourcequotas,verbs=create;update;delete,versions=v1,name=clusterresourcequota.dana.io,admissionReviewVersions=v1;v1beta1
+// +kubebuilder:webhook:path=/validate-v1-limitrange,mutating=false,sideEffects=NoneOnDryRun,failurePolicy=fail,groups="",resources=limitranges,verbs=create;update;delete,versions=v1,name=limitrange.dana.io,admissionReviewVersions=v1;v1beta1
+
+// Handle implements the validation webhook.
+func (v *QuotaObjectValidator) Handle(ctx context.Context, req admission.Request) admission.Response {
+	logger := log.FromContext(ctx).WithValues("webhook", "Quota Object Webhook", "Name", req.Name)
+	logger.Info("webhook request received")
+
+	newObject, oldObject := newObjectsOfKind(req.Kind.Kind)
+
+	if req.Operation != admissionv1.Delete {
+		if err := v.Decoder.DecodeRaw(req.Object, newObject); err != nil {
+			logger.Error(err, "failed to decode object", "request object", req.Object)
+			return admission.Errored(http.StatusBadRequest, err)
+		}
+	}
+
+	if req.Operation != admissionv1.Create {
+		if err := v.Decoder.DecodeRaw(req.OldObject, oldObject); err != nil {
+			logger.Error(err, "failed to decode object", "request object", req.OldObject)
+			return admission.Errored(http.StatusBadRequest, err)
+		}
+	}
+
+	object := newObject
+	if req.Operation == admissionv1.Delete {
+		object = oldObject
+	}
+
+	snsNS, err := v.subnamespaceNamespace(ctx, object)
+	if err != nil {
+		logger.Error(err, "failed to get the namespace of the object")
+		return admission.Errored(http.StatusInternalServerError, err)
+	}
+
+	if snsNS == nil {
+		return admission.Allowed("objects not managed by HNS are not validated")
+	}
+
+	if req.Operation == admissionv1.Delete && !snsNS.DeletionTimestamp.IsZero() {
+		return admission.Allowed("objects of a namespace which is being deleted can be deleted")
+	}
+
+	if req.Operation == admissionv1.Update && quota.SpecEqual(oldObject, newObject) {
+		return admission.Allowed("the spec of the object was not changed")
+	}
+
+	canManage, err := common.CanManageHNSObjects(ctx, req.UserInfo.Username, v.Client)
+	if err != nil {
+		logger.Error(err, "failed to check if user can manage HNS objects")
+		return admission.Errored(http.StatusInternalServerError, err)
+	}
+
+	if !canManage {
+		message := fmt.Sprintf("it's forbidden to %s %s %q, it is managed by HNS and can only be changed "+
+			"through the subnamespace %q", req.Operation, req.Kind.Kind, object.GetName(), snsNS.Name)
+		return admission.Denied(message)
+	}
+
+	return admission.Allowed("all validations passed")
+}
+
+// newObjectsOfKind returns two empty objects of the given kind, to decode the new and old objects into.
+func newObjectsOfKind(kind string) (client.Object, client.Object) {
+	switch kind {
+	case "ClusterResourceQuota":
+		return &quotav1.ClusterResourceQuota{}, &quotav1.ClusterResourceQuota{}
+	case "LimitRange":
+		return &corev1.LimitRange{}, &corev1.LimitRange{}
+	default:
+		return &corev1.ResourceQuota{}, &corev1.ResourceQuota{}
+	}
+}
+
+// subnamespaceNamespace returns the namespace of the subnamespace an object belongs to, or nil if the
+// object is not managed by HNS. A ResourceQuota or a LimitRange is managed by HNS if it's named after
+// its namespace and the namespace is a non-root namespace managed by HNS, and a ClusterResourceQuota
+// is managed by HNS if it selects namespaces using the HNS selector annotations.
+func (v *QuotaObjectValidator) subnamespaceNamespace(ctx context.Context, object client.Object) (*corev1.Namespace, error) {
+	nsName := object.GetNamespace()
+
+	if crq, ok := object.(*quotav1.ClusterResourceQuota); ok {
+		isHNSQuotaObject := false
+		for key := range crq.Spec.Selector.AnnotationSelector {
+			if strings.HasPrefix(key, danav1.CrqSelector) {
+				isHNSQuotaObject = true
+			}
+		}
+		if !isHNSQuotaObject {
+			return nil, nil
+		}
+		nsName = crq.Name
+	} else if object.GetName() != nsName {
+		return nil, nil
+	}
+
+	ns := &corev1.Namespace{}
+	if err := v.Client.Get(ctx, types.NamespacedName{Name: nsName}, ns); err != nil {
+		return nil, client.IgnoreNotFound(err)
+	}
+
+	if ns.Labels[danav1.Hns] == "" || nsutils.IsRoot(ns) {
+		return nil, nil
+	}
+
+	return ns, nil
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 
 	danav1 "github.com/dana-team/hns/api/v1"
+	"github.com/dana-team/hns/internal/common"
 	"github.com/dana-team/hns/internal/objectcontext"
 	"github.com/dana-team/hns/internal/rolebinding/rbutils"
 	"github.com/go-logr/logr"
@@ -16,7 +17,7 @@ import (
 
 // cleanUp takes care of clean-up related operations that need to be done when
 // a roleBinding relevant to HNS is deleted.
-func (r *RoleBindingReconciler) cleanUp(rbObject *objectcontext.ObjectContext, snsList *objectcontext.ObjectContextList) error {
+func (r *RoleBindingReconciler) cleanUp(rbObject *objectcontext.ObjectContext, snsList *objectcontext.ObjectContextList, exclusions common.SubjectExclusions) error {
 	ctx := rbObject.Ctx
 	logger := log.FromContext(ctx)
 	logger.Info("cleaning up roleBinding")
@@ -24,12 +25,10 @@ func (r *RoleBindingReconciler) cleanUp(rbObject *objectcontext.ObjectContext, s
 	rbName := rbObject.Name()
 	rbNamespace := rbObject.Object.GetNamespace()
 
-	if !rbutils.IsServiceAccount(rbObject.Object) {
-		if err := deleteSubjectsFromHNSViewClusterRoleBinding(rbObject); err != nil {
-			return fmt.Errorf("failed to delete subjects from roleBinding %q to HNS View ClusterRoleBinding: %v", rbName, err.Error())
-		}
-		logger.Info("successfully deleted subjects from roleBinding to HNS View ClusterRoleBinding", "roleBinding", rbName)
+	if err := removeNamespaceFromSubjectsHNSView(rbObject, exclusions); err != nil {
+		return fmt.Errorf("failed to remove namespace %q from the HNS view of the subjects of roleBinding %q: %v", rbNamespace, rbName, err.Error())
 	}
+	logger.Info("successfully removed namespace from the HNS view of the subjects of roleBinding", "roleBinding", rbName)
 
 	if err := deleteRoleBindingsInSnsList(rbObject, snsList); err != nil {
 		return fmt.Errorf("failed to delete RoleBinding in every child of namespace %q: %v", rbNamespace, err.Error())
@@ -44,26 +43,35 @@ func (r *RoleBindingReconciler) cleanUp(rbObject *objectcontext.ObjectContext, s
 	return nil
 }
 
-// deleteSubjectsFromHNSViewClusterRoleBinding deletes any subjects existing in the reconciled
-// roleBinding from the relevant HNS View CRB.
-func deleteSubjectsFromHNSViewClusterRoleBinding(rbObject *objectcontext.ObjectContext) error {
-	nsHNSViewClusterRoleBinding, err := rbutils.NamespaceHNSViewCRB(rbObject)
+// removeNamespaceFromSubjectsHNSView stops the subjects of the reconciled roleBinding from viewing the HNS
+// objects of its namespace, unless they are also subjects of another roleBinding in the namespace. Subjects
+// which have since been excluded from propagation are removed as well, since they may have been added before.
+func removeNamespaceFromSubjectsHNSView(rbObject *objectcontext.ObjectContext, exclusions common.SubjectExclusions) error {
+	nsRoleBindings, err := objectcontext.NewList(rbObject.Ctx, rbObject.Client, &rbacv1.RoleBindingList{}, client.InNamespace(rbObject.Namespace()), client.MatchingFields{"rb.propagate": "true"})
 	if err != nil {
 		return err
 	}
 
-	hnsViewSubjects := nsHNSViewClusterRoleBinding.Object.(*rbacv1.ClusterRoleBinding).Subjects
-
-	var newSubjects []rbacv1.Subject
-	roleBindingSubjects := rbObject.Object.(*rbacv1.RoleBinding).Subjects
+	viewingSubjects := map[string]bool{}
+	for _, roleBinding := range nsRoleBindings.Objects.(*rbacv1.RoleBindingList).Items {
+		if roleBinding.Name == rbObject.Name() || common.DeletionTimeStampExists(&roleBinding) {
+			continue
+		}
+		for _, subject := range rbutils.HNSViewSubjects(&roleBinding, exclusions) {
+			viewingSubjects[rbutils.HNSViewSubjectHash(subject)] = true
+		}
+	}
 
-	for _, subject := range hnsViewSubjects {
-		if !rbutils.IsSubjectInSubjects(roleBindingSubjects, subject) {
-			newSubjects = append(newSubjects, subject)
+	for _, subject := range rbutils.Subjects(rbObject.Object) {
+		if subject.Kind == rbacv1.ServiceAccountKind || viewingSubjects[rbutils.HNSViewSubjectHash(subject)] {
+			continue
+		}
+		if err := rbutils.RemoveNamespaceFromHNSView(rbObject, subject, rbObject.Namespace()); err != nil {
+			return err
 		}
 	}
 
-	return rbutils.UpdateNamespaceHNSViewCRBSubjects(nsHNSViewClusterRoleBinding, newSubjects)
+	return nil
 }
 
 // deleteRoleBindingsInSNSList deletes the RoleBinding in every namespace linked to a subnamespace
@@ -78,22 +86,27 @@ func deleteRoleBindingsInSnsList(rbObject *objectcontext.ObjectContext, snsList
 	return nil
 }
 
-// deleteRoleBinding deletes a RoleBinding from a namespace.
+// deleteRoleBinding deletes the RoleBindings inherited from a RoleBinding from a namespace. The inherited
+// RoleBindings are found by the source they record, so RoleBindings of the namespace which only share
+// the name of the RoleBinding are not deleted.
 func deleteRoleBinding(rbObject *objectcontext.ObjectContext, sns danav1.Subnamespace) error {
-	rbName := rbObject.Name()
-
-	rbSubjects := rbutils.Subjects(rbObject.Object)
-	rbRoleRef := rbutils.RoleRef(rbObject.Object)
-
-	rb := rbutils.Compose(rbName, sns.Name, rbSubjects, rbRoleRef)
-	roleBindingToDelete, err := objectcontext.New(rbObject.Ctx, rbObject.Client, types.NamespacedName{Name: rbName, Namespace: sns.Name}, rb)
+	inherited, err := rbutils.InheritedRoleBindings(rbObject, sns.Name)
 	if err != nil {
 		return err
 	}
 
-	err = roleBindingToDelete.EnsureDelete()
+	for _, roleBinding := range inherited {
+		roleBindingToDelete, err := objectcontext.New(rbObject.Ctx, rbObject.Client, types.NamespacedName{Name: roleBinding.Name, Namespace: sns.Name}, &rbacv1.RoleBinding{})
+		if err != nil {
+			return err
+		}
 
-	return err
+		if err := roleBindingToDelete.EnsureDelete(); err != nil {
+			return err
+		}
+	}
+
+	return nil
 }
 
 // deleteRBFinalizer deletes the HNS finalizer from a RoleBinding.
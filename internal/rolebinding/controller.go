@@ -27,15 +27,18 @@ type RoleBindingReconciler struct {
 // +kubebuilder:rbac:groups=rbac.authorization.k8s.io,resources=rolebindings,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=rbac.authorization.k8s.io,resources=rolebindings/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups="rbac.authorization.k8s.io",resources=roles,verbs=get;list;watch;create;update;patch;delete;bind
+// +kubebuilder:rbac:groups="rbac.authorization.k8s.io",resources=clusterrolebindings,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups="rbac.authorization.k8s.io",resources=clusterroles,verbs=get;list;watch;create;update;patch;delete;escalate;bind
 
 // SetupWithManager sets up the controller by specifying the following: indexes the "rb.propagate" field for
-// RoleBindings, filters events to only include RoleBindings that are part of a namespace with
+// RoleBindings which have subjects, filters events to only include RoleBindings that are part of a namespace with
 // the "danav1.Hns" label, and then watches for events on RoleBindings.
 func (r *RoleBindingReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	// define a function for creating the index used for filtering events; this index is used to only include
-	// events for RoleBindings that are considered value
+	// RoleBindings which have subjects, since the subjects which are excluded from propagation are read from
+	// the HNSConfig and may change, so they are filtered when the RoleBindings are used
 	indexFunc := func(rawObj client.Object) []string {
-		if rbutils.IsHNSRelated(rawObj) {
+		if rbutils.HasSubjects(rawObj) {
 			return []string{"true"}
 		}
 		return nil
@@ -78,7 +81,14 @@ func (r *RoleBindingReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 		return ctrl.Result{}, nil
 	}
 
-	if !rbutils.IsHNSRelated(rbObject.Object) {
+	exclusions, err := common.GetSubjectExclusions(ctx, r.Client)
+	if err != nil {
+		return ctrl.Result{}, fmt.Errorf("failed to get the subjects excluded from propagation: %v", err.Error())
+	}
+
+	// a RoleBinding which was propagated is cleaned up even if its subjects have since been excluded
+	isBeingDeleted := common.DeletionTimeStampExists(rbObject.Object)
+	if !rbutils.IsHNSRelated(rbObject.Object, exclusions) && !(isBeingDeleted && DoesRBFinalizerExist(rbObject.Object)) {
 		logger.Info("roleBinding object is not valid for HNS reconciliation. Ignoring")
 		return ctrl.Result{}, nil
 	}
@@ -88,10 +98,9 @@ func (r *RoleBindingReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 		return ctrl.Result{}, fmt.Errorf("failed to get list of subnamespaces in namespace %q: %v", req.Namespace, err.Error())
 	}
 
-	isBeingDeleted := common.DeletionTimeStampExists(rbObject.Object)
 	if isBeingDeleted {
-		return ctrl.Result{}, r.cleanUp(rbObject, snsList)
+		return ctrl.Result{}, r.cleanUp(rbObject, snsList, exclusions)
 	}
 
-	return ctrl.Result{}, r.init(rbObject, snsList)
+	return ctrl.Result{}, r.init(rbObject, snsList, exclusions)
 }
@@ -7,6 +7,7 @@ import (
 	"github.com/dana-team/hns/internal/common"
 	"github.com/dana-team/hns/internal/namespace/nsutils"
 	"github.com/dana-team/hns/internal/objectcontext"
+	"github.com/dana-team/hns/internal/rolebinding/rbutils"
 	corev1 "k8s.io/api/core/v1"
 	rbacv1 "k8s.io/api/rbac/v1"
 	"k8s.io/apimachinery/pkg/types"
@@ -21,7 +22,6 @@ func (v *RoleBindingValidator) handle(rbObject *objectcontext.ObjectContext) adm
 	logger := log.FromContext(ctx)
 
 	rbNamespace := rbObject.Object.GetNamespace()
-	rbName := rbObject.Name()
 
 	namespace, err := objectcontext.New(ctx, v.Client, types.NamespacedName{Name: rbNamespace}, &corev1.Namespace{})
 	if err != nil {
@@ -33,12 +33,20 @@ func (v *RoleBindingValidator) handle(rbObject *objectcontext.ObjectContext) adm
 		return response
 	}
 
-	if response := v.validateParentRoleBinding(namespace, rbName); response.Allowed {
+	if !rbutils.IsInherited(rbObject.Object) {
+		return admission.Allowed("it is allowed to delete the RoleBinding because it was not inherited from an ancestor")
+	}
+
+	if response := v.validateInheritedSource(namespace, rbObject); response.Allowed {
+		return response
+	}
+
+	if response := v.validateInheritancePolicy(namespace, rbObject); response.Allowed {
 		return response
 	}
 
-	message := fmt.Sprintf("it's forbidden to delete a RoleBinding not at the top of the hierarchy."+
-		"Delete the RoleBinding %q in the highest hierarchy it exists", rbName)
+	message := fmt.Sprintf("it's forbidden to delete a RoleBinding inherited from an ancestor. "+
+		"Delete the RoleBinding %q in namespace %q it was inherited from", rbutils.InheritedSourceName(rbObject.Object), rbutils.InheritedSource(rbObject.Object))
 	return admission.Denied(message)
 }
 
@@ -51,23 +59,57 @@ func (v *RoleBindingValidator) validateNamespaceDeletion(ns *objectcontext.Objec
 	return admission.Denied("")
 }
 
-// validateParentRoleBinding validates the state of the parent RoleBinding.
-func (v *RoleBindingValidator) validateParentRoleBinding(ns *objectcontext.ObjectContext, name string) admission.Response {
+// validateInheritedSource validates the state of the RoleBinding an inherited RoleBinding was inherited from,
+// according to the namespace, name and UID of the source recorded in the inherited RoleBinding.
+func (v *RoleBindingValidator) validateInheritedSource(ns *objectcontext.ObjectContext, rbObject *objectcontext.ObjectContext) admission.Response {
 	logger := log.FromContext(ns.Ctx)
-	rbParentNS := nsutils.Parent(ns.Object)
+	source := rbutils.InheritedSource(rbObject.Object)
+	sourceName := rbutils.InheritedSourceName(rbObject.Object)
+
+	ancestors, err := nsutils.Ancestors(ns.Object)
+	if err != nil {
+		logger.Error(err, "failed to get ancestors", "namespace", ns.Name())
+		return admission.Errored(http.StatusBadRequest, err)
+	}
 
-	parentRoleBinding, err := objectcontext.New(ns.Ctx, v.Client, types.NamespacedName{Namespace: rbParentNS, Name: name}, &rbacv1.RoleBinding{})
+	if !common.ContainsString(ancestors[:len(ancestors)-1], source) {
+		return admission.Allowed("it is allowed to delete the RoleBinding because it was inherited from a namespace which is no longer an ancestor")
+	}
+
+	sourceRoleBinding, err := objectcontext.New(ns.Ctx, v.Client, types.NamespacedName{Namespace: source, Name: sourceName}, &rbacv1.RoleBinding{})
 	if err != nil {
-		logger.Error(err, "failed to create object", "parent roleBinding", name)
+		logger.Error(err, "failed to create object", "source roleBinding", sourceName)
 		return admission.Errored(http.StatusBadRequest, err)
 	}
 
-	if common.DeletionTimeStampExists(parentRoleBinding.Object) {
-		return admission.Allowed("it is allowed to delete the RoleBinding because its parent RoleBinding is being deleted")
+	if !sourceRoleBinding.IsPresent() {
+		return admission.Allowed("it is allowed to delete the RoleBinding because the RoleBinding it was inherited from doesn't exist")
+	}
+
+	if string(sourceRoleBinding.Object.GetUID()) != rbutils.InheritedSourceUID(rbObject.Object) {
+		return admission.Allowed("it is allowed to delete the RoleBinding because the RoleBinding it was inherited from was replaced")
+	}
+
+	if common.DeletionTimeStampExists(sourceRoleBinding.Object) {
+		return admission.Allowed("it is allowed to delete the RoleBinding because the RoleBinding it was inherited from is being deleted")
+	}
+
+	return admission.Denied("")
+}
+
+// validateInheritancePolicy validates whether an inherited RoleBinding is excluded by the inheritance policy of
+// its namespace or of one of the namespaces it was inherited through, in which case HNS removes it.
+func (v *RoleBindingValidator) validateInheritancePolicy(ns *objectcontext.ObjectContext, rbObject *objectcontext.ObjectContext) admission.Response {
+	logger := log.FromContext(ns.Ctx)
+
+	excluded, err := rbutils.IsExcludedOnPath(ns.Ctx, v.Client, ns.Object, rbObject.Object)
+	if err != nil {
+		logger.Error(err, "failed to check the inheritance policy", "roleBinding", rbObject.Name())
+		return admission.Errored(http.StatusBadRequest, err)
 	}
 
-	if !parentRoleBinding.IsPresent() {
-		return admission.Allowed("it is allowed to delete the RoleBinding because its parent RoleBinding doesn't exist")
+	if excluded {
+		return admission.Allowed("it is allowed to delete the RoleBinding because it is excluded by an inheritance policy")
 	}
 
 	return admission.Denied("")
@@ -4,11 +4,10 @@ import (
 	"fmt"
 
 	danav1 "github.com/dana-team/hns/api/v1"
+	"github.com/dana-team/hns/internal/common"
 	"github.com/dana-team/hns/internal/objectcontext"
 	"github.com/dana-team/hns/internal/rolebinding/rbutils"
 	"github.com/go-logr/logr"
-	rbacv1 "k8s.io/api/rbac/v1"
-	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 	"sigs.k8s.io/controller-runtime/pkg/log"
@@ -16,7 +15,7 @@ import (
 
 // init takes care of initializing related operations that need to be done when
 // a namespace is reconciled for the first time.
-func (r *RoleBindingReconciler) init(rbObject *objectcontext.ObjectContext, snsList *objectcontext.ObjectContextList) error {
+func (r *RoleBindingReconciler) init(rbObject *objectcontext.ObjectContext, snsList *objectcontext.ObjectContextList, exclusions common.SubjectExclusions) error {
 	ctx := rbObject.Ctx
 	logger := log.FromContext(ctx)
 	logger.Info("initializing roleBinding")
@@ -36,12 +35,10 @@ func (r *RoleBindingReconciler) init(rbObject *objectcontext.ObjectContext, snsL
 	}
 	logger.Info("successfully created RoleBinding in every child of namespace", "roleBinding namespace", rbNamespace)
 
-	if !rbutils.IsServiceAccount(rbObject.Object) {
-		if err := addSubjectsToHNSViewClusterRoleBinding(rbObject); err != nil {
-			return fmt.Errorf("failed to add subjects from roleBinding %q to HNS View ClusterRoleBinding: %v", rbName, err.Error())
-		}
-		logger.Info("successfully added subjects from roleBinding to HNS View ClusterRoleBinding", "roleBinding", rbName)
+	if err := addNamespaceToSubjectsHNSView(rbObject, exclusions); err != nil {
+		return fmt.Errorf("failed to add namespace %q to the HNS view of the subjects of roleBinding %q: %v", rbNamespace, rbName, err.Error())
 	}
+	logger.Info("successfully added namespace to the HNS view of the subjects of roleBinding", "roleBinding", rbName)
 
 	return nil
 }
@@ -74,38 +71,24 @@ func createRoleBindingsInSNSList(rbObject *objectcontext.ObjectContext, snsList
 	return nil
 }
 
-// createRoleBinding creates a RoleBinding in a namespace.
+// createRoleBinding creates the RoleBinding inherited from a RoleBinding in a namespace, unless
+// the namespace already has it or the inheritance policy of its subnamespace excludes the RoleBinding.
 func createRoleBinding(rbObject *objectcontext.ObjectContext, sns danav1.Subnamespace) error {
-	rbName := rbObject.Name()
-
-	rbSubjects := rbutils.Subjects(rbObject.Object)
-	rbRoleRef := rbutils.RoleRef(rbObject.Object)
-
-	rb := rbutils.Compose(rbName, sns.Name, rbSubjects, rbRoleRef)
-	roleBindingToCreate, err := objectcontext.New(rbObject.Ctx, rbObject.Client, types.NamespacedName{Name: rbName, Namespace: sns.Name}, rb)
-	if err != nil {
-		return err
+	if rbutils.IsExcluded(sns.Spec.InheritancePolicy, rbObject.Object) {
+		return nil
 	}
 
-	err = roleBindingToCreate.EnsureCreate()
-
-	return err
+	return rbutils.EnsureInheritedRoleBinding(rbObject, sns.Name)
 }
 
-// addSubjectsToHNSViewClusterRoleBinding adds any subjects existing in the reconciled
-// roleBinding but missing from the relevant HNS View CRB to that ClusterRoleBinding.
-func addSubjectsToHNSViewClusterRoleBinding(rbObject *objectcontext.ObjectContext) error {
-	nsHNSViewClusterRoleBinding, err := rbutils.NamespaceHNSViewCRB(rbObject)
-	if err != nil {
-		return err
-	}
-
-	hnsViewSubjects := nsHNSViewClusterRoleBinding.Object.(*rbacv1.ClusterRoleBinding).Subjects
-
-	for _, subject := range rbObject.Object.(*rbacv1.RoleBinding).Subjects {
-		if !rbutils.IsSubjectInSubjects(hnsViewSubjects, subject) {
-			hnsViewSubjects = append(hnsViewSubjects, subject)
+// addNamespaceToSubjectsHNSView allows the subjects propagated by HNS of the reconciled roleBinding to view
+// the HNS objects of its namespace. ServiceAccounts are not added to the HNS view.
+func addNamespaceToSubjectsHNSView(rbObject *objectcontext.ObjectContext, exclusions common.SubjectExclusions) error {
+	for _, subject := range rbutils.HNSViewSubjects(rbObject.Object, exclusions) {
+		if err := rbutils.AddNamespaceToHNSView(rbObject, subject, rbObject.Namespace()); err != nil {
+			return err
 		}
 	}
-	return rbutils.UpdateNamespaceHNSViewCRBSubjects(nsHNSViewClusterRoleBinding, hnsViewSubjects)
+
+	return nil
 }
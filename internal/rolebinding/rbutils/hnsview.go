@@ -0,0 +1,291 @@
+package rbutils
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+
+	danav1 "github.com/dana-team/hns/api/v1"
+	"github.com/dana-team/hns/internal/common"
+	"github.com/dana-team/hns/internal/objectcontext"
+	"github.com/go-logr/logr"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	hnsViewNamePrefix   = "hns-view-"
+	hnsViewHashLen      = 10
+	legacyHNSViewSuffix = "-hns-view"
+
+	// hnsViewMaxNamespaces is the number of namespaces a single HNS view ClusterRole of a subject allows to view.
+	// A subject has a single HNS view ClusterRole and ClusterRoleBinding, and another pair is only created when
+	// the ClusterRoles of the subject are full, so that the size of every ClusterRole stays bounded
+	hnsViewMaxNamespaces = 500
+)
+
+// HNSViewSubjectHash returns the hash which identifies the HNS view objects of a subject.
+func HNSViewSubjectHash(subject rbacv1.Subject) string {
+	hash := sha256.Sum256([]byte(subject.Kind + "/" + subject.Name))
+	return hex.EncodeToString(hash[:])[:hnsViewHashLen]
+}
+
+// HNSViewName returns the name of an HNS view ClusterRole and ClusterRoleBinding of a subject, according to
+// the index of the pair out of the pairs of the subject.
+func HNSViewName(subject rbacv1.Subject, index int) string {
+	return fmt.Sprintf("%s%s-%d", hnsViewNamePrefix, HNSViewSubjectHash(subject), index)
+}
+
+// hnsViewClusterRoles returns the HNS view ClusterRoles of a subject, sorted by name.
+func hnsViewClusterRoles(objectContext *objectcontext.ObjectContext, subject rbacv1.Subject) ([]rbacv1.ClusterRole, error) {
+	clusterRoles, err := objectcontext.NewList(objectContext.Ctx, objectContext.Client, &rbacv1.ClusterRoleList{},
+		client.MatchingLabels{danav1.HNSViewSubject: HNSViewSubjectHash(subject)})
+	if err != nil {
+		return nil, err
+	}
+
+	items := clusterRoles.Objects.(*rbacv1.ClusterRoleList).Items
+	sort.Slice(items, func(i, j int) bool { return items[i].Name < items[j].Name })
+	return items, nil
+}
+
+// AddNamespaceToHNSView allows a subject to view the HNS objects of a namespace, by adding the namespace to an HNS
+// view ClusterRole of the subject which is not full and making sure the subject is bound to the ClusterRole. A new
+// ClusterRole is only created for the subject if it has none, or if all of its ClusterRoles are full.
+func AddNamespaceToHNSView(objectContext *objectcontext.ObjectContext, subject rbacv1.Subject, namespace string) error {
+	clusterRoles, err := hnsViewClusterRoles(objectContext, subject)
+	if err != nil {
+		return err
+	}
+
+	name := ""
+	for _, clusterRole := range clusterRoles {
+		if common.ContainsString(HNSViewNamespaces(&clusterRole), namespace) {
+			name = clusterRole.Name
+			break
+		}
+	}
+
+	if name == "" {
+		if name, err = addNamespaceToHNSViewClusterRole(objectContext, subject, clusterRoles, namespace); err != nil {
+			return err
+		}
+	}
+
+	clusterRoleBinding, err := objectcontext.New(objectContext.Ctx, objectContext.Client, types.NamespacedName{Name: name}, composeHNSViewClusterRoleBinding(name, subject))
+	if err != nil {
+		return err
+	}
+
+	return clusterRoleBinding.EnsureCreate()
+}
+
+// addNamespaceToHNSViewClusterRole adds a namespace to the first HNS view ClusterRole of a subject which is not
+// full, or creates a new ClusterRole for it with the lowest index which is not in use, and returns its name.
+func addNamespaceToHNSViewClusterRole(objectContext *objectcontext.ObjectContext, subject rbacv1.Subject, clusterRoles []rbacv1.ClusterRole, namespace string) (string, error) {
+	names := map[string]bool{}
+	for _, clusterRole := range clusterRoles {
+		names[clusterRole.Name] = true
+		if len(HNSViewNamespaces(&clusterRole)) >= hnsViewMaxNamespaces {
+			continue
+		}
+
+		existing, err := objectcontext.New(objectContext.Ctx, objectContext.Client, types.NamespacedName{Name: clusterRole.Name}, &rbacv1.ClusterRole{})
+		if err != nil {
+			return "", err
+		}
+
+		if err := existing.EnsureUpdateObject(func(object client.Object, log logr.Logger) (client.Object, logr.Logger, error) {
+			namespaces := HNSViewNamespaces(object)
+			if common.ContainsString(namespaces, namespace) {
+				return object, log, nil
+			}
+			if len(namespaces) >= hnsViewMaxNamespaces {
+				return object, log, fmt.Errorf("HNS view ClusterRole %q is full", existing.Name())
+			}
+
+			log = log.WithValues("updated HNS view ClusterRole namespaces", existing.Name())
+			object.(*rbacv1.ClusterRole).Rules = hnsViewRules(append(namespaces, namespace))
+			return object, log, nil
+		}, false); err != nil {
+			return "", err
+		}
+
+		return clusterRole.Name, nil
+	}
+
+	index := 0
+	for names[HNSViewName(subject, index)] {
+		index++
+	}
+	name := HNSViewName(subject, index)
+
+	clusterRole, err := objectcontext.New(objectContext.Ctx, objectContext.Client, types.NamespacedName{Name: name}, composeHNSViewClusterRole(name, subject, []string{namespace}))
+	if err != nil {
+		return "", err
+	}
+
+	return name, clusterRole.EnsureCreate()
+}
+
+// RemoveNamespaceFromHNSView stops a subject from viewing the HNS objects of a namespace, by removing the namespace
+// from the HNS view ClusterRoles of the subject. A ClusterRole and its ClusterRoleBinding are deleted once there
+// are no namespaces left in them.
+func RemoveNamespaceFromHNSView(objectContext *objectcontext.ObjectContext, subject rbacv1.Subject, namespace string) error {
+	clusterRoles, err := hnsViewClusterRoles(objectContext, subject)
+	if err != nil {
+		return err
+	}
+
+	for _, clusterRole := range clusterRoles {
+		if !common.ContainsString(HNSViewNamespaces(&clusterRole), namespace) {
+			continue
+		}
+
+		if err := removeNamespaceFromHNSViewClusterRole(objectContext, clusterRole.Name, namespace); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// removeNamespaceFromHNSViewClusterRole removes a namespace from an HNS view ClusterRole, and deletes
+// the ClusterRole and its ClusterRoleBinding if there are no namespaces left in it.
+func removeNamespaceFromHNSViewClusterRole(objectContext *objectcontext.ObjectContext, name, namespace string) error {
+	clusterRole, err := objectcontext.New(objectContext.Ctx, objectContext.Client, types.NamespacedName{Name: name}, &rbacv1.ClusterRole{})
+	if err != nil {
+		return err
+	}
+
+	var namespaces []string
+	for _, ns := range HNSViewNamespaces(clusterRole.Object) {
+		if ns != namespace {
+			namespaces = append(namespaces, ns)
+		}
+	}
+
+	if len(namespaces) > 0 {
+		return clusterRole.EnsureUpdateObject(func(object client.Object, log logr.Logger) (client.Object, logr.Logger, error) {
+			var remaining []string
+			for _, ns := range HNSViewNamespaces(object) {
+				if ns != namespace {
+					remaining = append(remaining, ns)
+				}
+			}
+			// a rule without resource names allows to view all the namespaces
+			if len(remaining) == 0 {
+				return object, log, fmt.Errorf("HNS view ClusterRole %q has no namespaces left", name)
+			}
+
+			log = log.WithValues("updated HNS view ClusterRole namespaces", name)
+			object.(*rbacv1.ClusterRole).Rules = hnsViewRules(remaining)
+			return object, log, nil
+		}, false)
+	}
+
+	clusterRoleBinding, err := objectcontext.New(objectContext.Ctx, objectContext.Client, types.NamespacedName{Name: name}, &rbacv1.ClusterRoleBinding{})
+	if err != nil {
+		return err
+	}
+
+	if err := clusterRoleBinding.EnsureDelete(); err != nil {
+		return err
+	}
+
+	return clusterRole.EnsureDelete()
+}
+
+// HNSViewNamespaces returns the namespaces an HNS view ClusterRole allows to view.
+func HNSViewNamespaces(clusterRole client.Object) []string {
+	rules := clusterRole.(*rbacv1.ClusterRole).Rules
+	if len(rules) == 0 {
+		return nil
+	}
+
+	return rules[0].ResourceNames
+}
+
+// composeHNSViewClusterRole returns the HNS view ClusterRole of a subject which allows to view the given namespaces.
+func composeHNSViewClusterRole(name string, subject rbacv1.Subject, namespaces []string) *rbacv1.ClusterRole {
+	return &rbacv1.ClusterRole{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   name,
+			Labels: map[string]string{danav1.HNSViewSubject: HNSViewSubjectHash(subject)},
+		},
+		Rules: hnsViewRules(namespaces),
+	}
+}
+
+// hnsViewRules returns the rules which allow to view the subnamespaces and the quota objects of the given namespaces.
+func hnsViewRules(namespaces []string) []rbacv1.PolicyRule {
+	resourceNames := append([]string(nil), namespaces...)
+	sort.Strings(resourceNames)
+
+	return []rbacv1.PolicyRule{
+		{
+			Verbs:         []string{"get"},
+			APIGroups:     []string{"dana.hns.io"},
+			Resources:     []string{"subnamespaces"},
+			ResourceNames: resourceNames,
+		},
+		{
+			Verbs:         []string{"get", "list"},
+			APIGroups:     []string{"quota.openshift.io"},
+			Resources:     []string{"clusterresourcequotas"},
+			ResourceNames: resourceNames,
+		},
+		{
+			Verbs:         []string{"get"},
+			APIGroups:     []string{""},
+			Resources:     []string{"resourcequotas"},
+			ResourceNames: resourceNames,
+		},
+		{
+			Verbs:     []string{"list"},
+			APIGroups: []string{""},
+			Resources: []string{"resourcequotas"},
+		},
+	}
+}
+
+// composeHNSViewClusterRoleBinding returns the ClusterRoleBinding which binds a subject to its HNS view ClusterRole.
+func composeHNSViewClusterRoleBinding(name string, subject rbacv1.Subject) *rbacv1.ClusterRoleBinding {
+	return &rbacv1.ClusterRoleBinding{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   name,
+			Labels: map[string]string{danav1.HNSViewSubject: HNSViewSubjectHash(subject)},
+		},
+		Subjects: []rbacv1.Subject{subject},
+		RoleRef: rbacv1.RoleRef{
+			APIGroup: rbacv1.GroupName,
+			Kind:     "ClusterRole",
+			Name:     name,
+		},
+	}
+}
+
+// DeleteLegacyHNSView deletes the ClusterRole and ClusterRoleBinding which were created for every
+// namespace before the HNS view objects were created per subject.
+func DeleteLegacyHNSView(nsObject *objectcontext.ObjectContext) error {
+	name := nsObject.Name() + legacyHNSViewSuffix
+
+	clusterRoleBinding, err := objectcontext.New(nsObject.Ctx, nsObject.Client, types.NamespacedName{Name: name}, &rbacv1.ClusterRoleBinding{})
+	if err != nil {
+		return err
+	}
+
+	if err := clusterRoleBinding.EnsureDelete(); err != nil {
+		return err
+	}
+
+	clusterRole, err := objectcontext.New(nsObject.Ctx, nsObject.Client, types.NamespacedName{Name: name}, &rbacv1.ClusterRole{})
+	if err != nil {
+		return err
+	}
+
+	return clusterRole.EnsureDelete()
+}
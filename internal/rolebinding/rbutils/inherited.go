@@ -0,0 +1,368 @@
+package rbutils
+
+import (
+	"fmt"
+	"reflect"
+
+	danav1 "github.com/dana-team/hns/api/v1"
+	"github.com/dana-team/hns/internal/common"
+	"github.com/dana-team/hns/internal/namespace/nsutils"
+	"github.com/dana-team/hns/internal/objectcontext"
+	"github.com/go-logr/logr"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const inheritedNameUIDLength = 8
+
+// IsInherited returns true if a RoleBinding was inherited from a RoleBinding of one of the ancestors of its namespace.
+func IsInherited(roleBinding client.Object) bool {
+	_, ok := roleBinding.GetLabels()[danav1.InheritedFromUID]
+	return ok
+}
+
+// InheritedSource returns the name of the namespace a RoleBinding was originally created in. It is the
+// namespace of the RoleBinding itself, unless the RoleBinding was inherited from one of its ancestors.
+func InheritedSource(roleBinding client.Object) string {
+	if source := roleBinding.GetAnnotations()[danav1.InheritedFrom]; source != "" {
+		return source
+	}
+
+	return roleBinding.GetNamespace()
+}
+
+// InheritedSourceName returns the name of the RoleBinding a RoleBinding was inherited from. It is the name
+// of the RoleBinding itself, unless the RoleBinding was inherited from one of its ancestors.
+func InheritedSourceName(roleBinding client.Object) string {
+	if name := roleBinding.GetAnnotations()[danav1.InheritedFromName]; name != "" {
+		return name
+	}
+
+	return roleBinding.GetName()
+}
+
+// InheritedSourceUID returns the UID of the RoleBinding a RoleBinding was inherited from. It is the UID
+// of the RoleBinding itself, unless the RoleBinding was inherited from one of its ancestors.
+func InheritedSourceUID(roleBinding client.Object) string {
+	if uid := roleBinding.GetLabels()[danav1.InheritedFromUID]; uid != "" {
+		return uid
+	}
+
+	return string(roleBinding.GetUID())
+}
+
+// ComposeInherited returns the RoleBinding with the given name which a child namespace inherits from
+// a RoleBinding of its parent. The inherited RoleBinding only has the subjects of the parent RoleBinding which
+// are propagated by HNS, and records the namespace, name and UID of the RoleBinding it was originally inherited from.
+func ComposeInherited(roleBinding client.Object, namespace, name string, exclusions common.SubjectExclusions) *rbacv1.RoleBinding {
+	rb := Compose(name, namespace, EligibleSubjects(roleBinding, exclusions), RoleRef(roleBinding))
+	rb.Labels = map[string]string{danav1.InheritedFromUID: InheritedSourceUID(roleBinding)}
+	rb.Annotations = map[string]string{
+		danav1.InheritedFrom:     InheritedSource(roleBinding),
+		danav1.InheritedFromName: InheritedSourceName(roleBinding),
+	}
+
+	return rb
+}
+
+// InheritedName returns the name of the RoleBinding a namespace inherits from a RoleBinding of its parent. It is
+// the name of the parent RoleBinding, unless the name is already taken in the namespace by a RoleBinding which
+// was not inherited from the same source, in which case the UID of the source is added to the name so that
+// the existing RoleBinding is neither overwritten nor blocks the inheritance.
+func InheritedName(roleBinding client.Object, takenNames []string) string {
+	name := roleBinding.GetName()
+	if !common.ContainsString(takenNames, name) {
+		return name
+	}
+
+	uid := InheritedSourceUID(roleBinding)
+	if len(uid) > inheritedNameUIDLength {
+		uid = uid[:inheritedNameUIDLength]
+	}
+
+	return fmt.Sprintf("%s-%s", name, uid)
+}
+
+// IsInheritedFrom returns true if a RoleBinding of a namespace was inherited from a RoleBinding of its parent,
+// either according to the source it records or, for RoleBindings which were inherited before their source was
+// recorded, because it has the same name, role and subjects.
+func IsInheritedFrom(roleBinding, parentRoleBinding client.Object) bool {
+	if IsInherited(roleBinding) {
+		return InheritedSourceUID(roleBinding) == InheritedSourceUID(parentRoleBinding)
+	}
+
+	return roleBinding.GetName() == parentRoleBinding.GetName() &&
+		reflect.DeepEqual(RoleRef(roleBinding), RoleRef(parentRoleBinding)) &&
+		reflect.DeepEqual(Subjects(roleBinding), Subjects(parentRoleBinding))
+}
+
+// InheritedRoleBindings returns the RoleBindings of a namespace which were inherited from a RoleBinding of its parent.
+func InheritedRoleBindings(rbObject *objectcontext.ObjectContext, namespace string) ([]rbacv1.RoleBinding, error) {
+	nsRoleBindings, err := objectcontext.NewList(rbObject.Ctx, rbObject.Client, &rbacv1.RoleBindingList{}, client.InNamespace(namespace))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list rolebindings in namespace %q: %v", namespace, err.Error())
+	}
+
+	var inherited []rbacv1.RoleBinding
+	for _, roleBinding := range nsRoleBindings.Objects.(*rbacv1.RoleBindingList).Items {
+		if IsInheritedFrom(&roleBinding, rbObject.Object) {
+			inherited = append(inherited, roleBinding)
+		}
+	}
+
+	return inherited, nil
+}
+
+// EnsureInheritedRoleBinding makes sure that a namespace inherits a RoleBinding of its parent. Nothing is done
+// if the namespace already has a RoleBinding inherited from the same source.
+func EnsureInheritedRoleBinding(rbObject *objectcontext.ObjectContext, namespace string) error {
+	exclusions, err := common.GetSubjectExclusions(rbObject.Ctx, rbObject.Client)
+	if err != nil {
+		return err
+	}
+
+	nsRoleBindings, err := objectcontext.NewList(rbObject.Ctx, rbObject.Client, &rbacv1.RoleBindingList{}, client.InNamespace(namespace))
+	if err != nil {
+		return fmt.Errorf("failed to list rolebindings in namespace %q: %v", namespace, err.Error())
+	}
+
+	var takenNames []string
+	for _, roleBinding := range nsRoleBindings.Objects.(*rbacv1.RoleBindingList).Items {
+		if IsInheritedFrom(&roleBinding, rbObject.Object) {
+			return nil
+		}
+		takenNames = append(takenNames, roleBinding.Name)
+	}
+
+	return createInheritedRoleBinding(rbObject, rbObject.Object, namespace, takenNames, exclusions)
+}
+
+// EnsureInheritedRoleBindings makes sure that the RoleBindings a namespace inherits match its current ancestors
+// and its inheritance policy. Inherited RoleBindings whose source is no longer an ancestor of the namespace, is no
+// longer inherited by its parent, or is excluded by the inheritance policy, are deleted, and the RoleBindings of the
+// parent which are missing from the namespace are created. Such an inherited RoleBinding with the same name and role
+// as a RoleBinding of the parent is replaced by it in place instead, so that the subjects of both do not lose their
+// permissions in the meantime.
+func EnsureInheritedRoleBindings(nsObject *objectcontext.ObjectContext) error {
+	if nsutils.IsRoot(nsObject.Object) {
+		return nil
+	}
+
+	ctx := nsObject.Ctx
+	nsName := nsObject.Name()
+	parentName := nsutils.Parent(nsObject.Object)
+
+	parentRoleBindings, err := objectcontext.NewList(ctx, nsObject.Client, &rbacv1.RoleBindingList{}, client.InNamespace(parentName), client.MatchingFields{"rb.propagate": "true"})
+	if err != nil {
+		return fmt.Errorf("failed to list rolebindings in namespace %q: %v", parentName, err.Error())
+	}
+
+	nsRoleBindings, err := objectcontext.NewList(ctx, nsObject.Client, &rbacv1.RoleBindingList{}, client.InNamespace(nsName))
+	if err != nil {
+		return fmt.Errorf("failed to list rolebindings in namespace %q: %v", nsName, err.Error())
+	}
+
+	exclusions, err := common.GetSubjectExclusions(ctx, nsObject.Client)
+	if err != nil {
+		return err
+	}
+
+	policy, err := InheritancePolicy(ctx, nsObject.Client, nsName, parentName)
+	if err != nil {
+		return fmt.Errorf("failed to get the inheritance policy of namespace %q: %v", nsName, err.Error())
+	}
+
+	// the RoleBindings of the parent which have no subjects propagated by HNS or which are excluded by the
+	// inheritance policy are not inherited, so RoleBindings already inherited from them are considered stale and deleted
+	var inherited []rbacv1.RoleBinding
+	for _, roleBinding := range parentRoleBindings.Objects.(*rbacv1.RoleBindingList).Items {
+		if IsHNSRelated(&roleBinding, exclusions) && !IsExcluded(policy, &roleBinding) {
+			inherited = append(inherited, roleBinding)
+		}
+	}
+
+	ancestors, err := nsutils.Ancestors(nsObject.Object)
+	if err != nil {
+		return err
+	}
+
+	var existing, stale []rbacv1.RoleBinding
+	var takenNames []string
+	for _, roleBinding := range nsRoleBindings.Objects.(*rbacv1.RoleBindingList).Items {
+		takenNames = append(takenNames, roleBinding.Name)
+		if isStaleInheritedRoleBinding(ancestors, roleBinding, inherited) {
+			stale = append(stale, roleBinding)
+		} else {
+			existing = append(existing, roleBinding)
+		}
+	}
+
+	for _, roleBinding := range stale {
+		parentRoleBinding, ok := findRoleBinding(inherited, roleBinding.Name)
+		if ok && reflect.DeepEqual(parentRoleBinding.RoleRef, roleBinding.RoleRef) && !isInheritedIn(existing, &parentRoleBinding) {
+			replaced, err := replaceInheritedRoleBinding(nsObject, roleBinding, parentRoleBinding, exclusions)
+			if err != nil {
+				return err
+			}
+			existing = append(existing, *replaced)
+			continue
+		}
+
+		if err := deleteInheritedRoleBinding(nsObject, roleBinding); err != nil {
+			return err
+		}
+	}
+
+	for _, roleBinding := range inherited {
+		if isInheritedIn(existing, &roleBinding) {
+			if err := adoptInheritedRoleBinding(nsObject, existing, roleBinding); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if err := createInheritedRoleBinding(nsObject, &roleBinding, nsName, takenNames, exclusions); err != nil {
+			return err
+		}
+		takenNames = append(takenNames, InheritedName(&roleBinding, takenNames))
+	}
+
+	return nil
+}
+
+// isStaleInheritedRoleBinding returns true if a RoleBinding was inherited from a namespace which is no longer one of
+// the ancestors of its namespace, or from a RoleBinding which is no longer inherited by the parent of its namespace.
+func isStaleInheritedRoleBinding(ancestors []string, roleBinding rbacv1.RoleBinding, inherited []rbacv1.RoleBinding) bool {
+	if !IsInherited(&roleBinding) {
+		return false
+	}
+
+	if !common.ContainsString(ancestors[:len(ancestors)-1], InheritedSource(&roleBinding)) {
+		return true
+	}
+
+	for _, parentRoleBinding := range inherited {
+		if InheritedSourceUID(&parentRoleBinding) == InheritedSourceUID(&roleBinding) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// findRoleBinding returns the RoleBinding with the given name from a slice of RoleBindings.
+func findRoleBinding(roleBindings []rbacv1.RoleBinding, name string) (rbacv1.RoleBinding, bool) {
+	for _, roleBinding := range roleBindings {
+		if roleBinding.Name == name {
+			return roleBinding, true
+		}
+	}
+
+	return rbacv1.RoleBinding{}, false
+}
+
+// isInheritedIn returns true if one of the given RoleBindings was inherited from the parent RoleBinding.
+func isInheritedIn(roleBindings []rbacv1.RoleBinding, parentRoleBinding client.Object) bool {
+	for _, roleBinding := range roleBindings {
+		if IsInheritedFrom(&roleBinding, parentRoleBinding) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// createInheritedRoleBinding creates the RoleBinding a namespace inherits from a RoleBinding of its parent.
+func createInheritedRoleBinding(object *objectcontext.ObjectContext, parentRoleBinding client.Object, namespace string, takenNames []string, exclusions common.SubjectExclusions) error {
+	name := InheritedName(parentRoleBinding, takenNames)
+
+	rbObject, err := objectcontext.New(object.Ctx, object.Client, types.NamespacedName{}, ComposeInherited(parentRoleBinding, namespace, name, exclusions))
+	if err != nil {
+		return err
+	}
+
+	if err := rbObject.EnsureCreate(); err != nil {
+		return fmt.Errorf("failed to create roleBinding %q in namespace %q: %v", name, namespace, err.Error())
+	}
+
+	return nil
+}
+
+// replaceInheritedRoleBinding replaces an inherited RoleBinding whose source is no longer an ancestor of its
+// namespace with the RoleBinding of the same name and role inherited from the new parent.
+func replaceInheritedRoleBinding(nsObject *objectcontext.ObjectContext, roleBinding, parentRoleBinding rbacv1.RoleBinding, exclusions common.SubjectExclusions) (*rbacv1.RoleBinding, error) {
+	rbObject, err := objectcontext.New(nsObject.Ctx, nsObject.Client, types.NamespacedName{Name: roleBinding.Name, Namespace: roleBinding.Namespace}, &rbacv1.RoleBinding{})
+	if err != nil {
+		return nil, err
+	}
+
+	err = rbObject.EnsureUpdateObject(func(object client.Object, log logr.Logger) (client.Object, logr.Logger, error) {
+		log = log.WithValues("replaced inherited roleBinding source", InheritedSource(&parentRoleBinding))
+		setInheritedSource(object, &parentRoleBinding)
+		object.(*rbacv1.RoleBinding).Subjects = EligibleSubjects(&parentRoleBinding, exclusions)
+		return object, log, nil
+	}, false)
+	if err != nil {
+		return nil, err
+	}
+
+	return rbObject.Object.(*rbacv1.RoleBinding), nil
+}
+
+// adoptInheritedRoleBinding records the source of a RoleBinding which was inherited from a RoleBinding of the parent
+// of its namespace before the source of inherited RoleBindings was recorded.
+func adoptInheritedRoleBinding(nsObject *objectcontext.ObjectContext, roleBindings []rbacv1.RoleBinding, parentRoleBinding rbacv1.RoleBinding) error {
+	for _, roleBinding := range roleBindings {
+		if IsInherited(&roleBinding) || !IsInheritedFrom(&roleBinding, &parentRoleBinding) {
+			continue
+		}
+
+		rbObject, err := objectcontext.New(nsObject.Ctx, nsObject.Client, types.NamespacedName{Name: roleBinding.Name, Namespace: roleBinding.Namespace}, &rbacv1.RoleBinding{})
+		if err != nil {
+			return err
+		}
+
+		if err := rbObject.EnsureUpdateObject(func(object client.Object, log logr.Logger) (client.Object, logr.Logger, error) {
+			log = log.WithValues("adopted inherited roleBinding source", InheritedSource(&parentRoleBinding))
+			setInheritedSource(object, &parentRoleBinding)
+			return object, log, nil
+		}, false); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// setInheritedSource records in a RoleBinding the source of the parent RoleBinding it is inherited from.
+func setInheritedSource(object client.Object, parentRoleBinding client.Object) {
+	labels := object.GetLabels()
+	if labels == nil {
+		labels = map[string]string{}
+	}
+	labels[danav1.InheritedFromUID] = InheritedSourceUID(parentRoleBinding)
+	object.SetLabels(labels)
+
+	annotations := object.GetAnnotations()
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	annotations[danav1.InheritedFrom] = InheritedSource(parentRoleBinding)
+	annotations[danav1.InheritedFromName] = InheritedSourceName(parentRoleBinding)
+	object.SetAnnotations(annotations)
+}
+
+// deleteInheritedRoleBinding deletes an inherited RoleBinding whose source is no longer an ancestor of its namespace.
+func deleteInheritedRoleBinding(nsObject *objectcontext.ObjectContext, roleBinding rbacv1.RoleBinding) error {
+	rbObject, err := objectcontext.New(nsObject.Ctx, nsObject.Client, types.NamespacedName{Name: roleBinding.Name, Namespace: roleBinding.Namespace}, &rbacv1.RoleBinding{})
+	if err != nil {
+		return err
+	}
+
+	if err := rbObject.EnsureDelete(); err != nil {
+		return fmt.Errorf("failed to delete roleBinding %q in namespace %q: %v", roleBinding.Name, roleBinding.Namespace, err.Error())
+	}
+
+	return nil
+}
@@ -0,0 +1,142 @@
+package rbutils
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"reflect"
+
+	danav1 "github.com/dana-team/hns/api/v1"
+	"github.com/dana-team/hns/internal/objectcontext"
+	"github.com/go-logr/logr"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	ownerNamePrefix  = "hns-owner-"
+	ownerNameHashLen = 10
+)
+
+// IsOwner returns true if a RoleBinding was created by HNS for an owner of the subnamespace bound to its namespace.
+func IsOwner(roleBinding client.Object) bool {
+	return roleBinding.GetLabels()[danav1.OwnerRoleBinding] == danav1.True
+}
+
+// OwnerName returns the name of the RoleBinding of an owner of a subnamespace. The name is derived from the
+// namespace and the owner, so that it doesn't collide with the RoleBindings of the same owner inherited from
+// the ancestors of the namespace.
+func OwnerName(namespace string, owner danav1.SubnamespaceOwner) string {
+	hash := sha256.Sum256([]byte(fmt.Sprintf("%s/%s/%s/%s/%s", namespace, owner.Kind, owner.Namespace, owner.Name, owner.Role)))
+	return ownerNamePrefix + hex.EncodeToString(hash[:])[:ownerNameHashLen]
+}
+
+// ComposeOwner returns the RoleBinding which grants an owner of a subnamespace its role in the namespace.
+func ComposeOwner(namespace string, owner danav1.SubnamespaceOwner) *rbacv1.RoleBinding {
+	subject := rbacv1.Subject{Kind: string(owner.Kind), Name: owner.Name}
+	if owner.Kind == danav1.ServiceAccountOwner {
+		subject.Namespace = owner.Namespace
+		if subject.Namespace == "" {
+			subject.Namespace = namespace
+		}
+	} else {
+		subject.APIGroup = rbacv1.GroupName
+	}
+
+	ref := rbacv1.RoleRef{APIGroup: rbacv1.GroupName, Kind: "ClusterRole", Name: owner.Role}
+
+	rb := Compose(OwnerName(namespace, owner), namespace, []rbacv1.Subject{subject}, ref)
+	rb.Labels = map[string]string{danav1.OwnerRoleBinding: danav1.True}
+
+	return rb
+}
+
+// EnsureOwnerRoleBindings makes the owner RoleBindings in the namespace bound to a subnamespace match the owners
+// in its spec: the RoleBindings of new owners are created, the RoleBindings which drifted from their owner are
+// fixed, and the RoleBindings of owners which were removed from the spec are deleted.
+func EnsureOwnerRoleBindings(snsObject *objectcontext.ObjectContext) error {
+	namespace := snsObject.Name()
+
+	nsRoleBindings, err := objectcontext.NewList(snsObject.Ctx, snsObject.Client, &rbacv1.RoleBindingList{},
+		client.InNamespace(namespace), client.MatchingLabels{danav1.OwnerRoleBinding: danav1.True})
+	if err != nil {
+		return fmt.Errorf("failed to list owner roleBindings in namespace %q: %v", namespace, err.Error())
+	}
+
+	desired := map[string]*rbacv1.RoleBinding{}
+	for _, owner := range snsObject.Object.(*danav1.Subnamespace).Spec.Owners {
+		rb := ComposeOwner(namespace, owner)
+		desired[rb.Name] = rb
+	}
+
+	existing := map[string]bool{}
+	for _, roleBinding := range nsRoleBindings.Objects.(*rbacv1.RoleBindingList).Items {
+		existing[roleBinding.Name] = true
+		rb, ok := desired[roleBinding.Name]
+
+		// the roleRef of a RoleBinding can't be changed, so a RoleBinding with a different roleRef is recreated
+		if !ok || !reflect.DeepEqual(roleBinding.RoleRef, rb.RoleRef) {
+			if err := deleteOwnerRoleBinding(snsObject, roleBinding); err != nil {
+				return err
+			}
+			existing[roleBinding.Name] = false
+			continue
+		}
+
+		if !reflect.DeepEqual(roleBinding.Subjects, rb.Subjects) {
+			if err := updateOwnerRoleBindingSubjects(snsObject, roleBinding, rb.Subjects); err != nil {
+				return err
+			}
+		}
+	}
+
+	for name, rb := range desired {
+		if existing[name] {
+			continue
+		}
+
+		rbObject, err := objectcontext.New(snsObject.Ctx, snsObject.Client, types.NamespacedName{}, rb)
+		if err != nil {
+			return err
+		}
+
+		if err := rbObject.EnsureCreate(); err != nil {
+			return fmt.Errorf("failed to create owner roleBinding %q in namespace %q: %v", name, namespace, err.Error())
+		}
+	}
+
+	return nil
+}
+
+// updateOwnerRoleBindingSubjects sets the subjects of an owner RoleBinding.
+func updateOwnerRoleBindingSubjects(snsObject *objectcontext.ObjectContext, roleBinding rbacv1.RoleBinding, subjects []rbacv1.Subject) error {
+	rbObject, err := objectcontext.New(snsObject.Ctx, snsObject.Client, types.NamespacedName{Name: roleBinding.Name, Namespace: roleBinding.Namespace}, &rbacv1.RoleBinding{})
+	if err != nil {
+		return err
+	}
+
+	if err := rbObject.EnsureUpdateObject(func(object client.Object, log logr.Logger) (client.Object, logr.Logger, error) {
+		object.(*rbacv1.RoleBinding).Subjects = subjects
+		log = log.WithValues("updated owner roleBinding subjects", roleBinding.Name)
+		return object, log, nil
+	}, false); err != nil {
+		return fmt.Errorf("failed to update owner roleBinding %q in namespace %q: %v", roleBinding.Name, roleBinding.Namespace, err.Error())
+	}
+
+	return nil
+}
+
+// deleteOwnerRoleBinding deletes an owner RoleBinding.
+func deleteOwnerRoleBinding(snsObject *objectcontext.ObjectContext, roleBinding rbacv1.RoleBinding) error {
+	rbObject, err := objectcontext.New(snsObject.Ctx, snsObject.Client, types.NamespacedName{Name: roleBinding.Name, Namespace: roleBinding.Namespace}, &rbacv1.RoleBinding{})
+	if err != nil {
+		return err
+	}
+
+	if err := rbObject.EnsureDelete(); err != nil {
+		return fmt.Errorf("failed to delete owner roleBinding %q in namespace %q: %v", roleBinding.Name, roleBinding.Namespace, err.Error())
+	}
+
+	return nil
+}
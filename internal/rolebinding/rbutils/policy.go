@@ -0,0 +1,97 @@
+package rbutils
+
+import (
+	"context"
+
+	danav1 "github.com/dana-team/hns/api/v1"
+	"github.com/dana-team/hns/internal/common"
+	"github.com/dana-team/hns/internal/namespace/nsutils"
+	"github.com/dana-team/hns/internal/objectcontext"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// InheritancePolicy returns the inheritance policy of the subnamespace bound to a namespace. It returns nil
+// if the namespace has no subnamespace bound to it or the subnamespace has no inheritance policy.
+func InheritancePolicy(ctx context.Context, k8sClient client.Client, nsName, parentName string) (*danav1.InheritancePolicy, error) {
+	if parentName == "" {
+		return nil, nil
+	}
+
+	snsObject, err := objectcontext.New(ctx, k8sClient, types.NamespacedName{Name: nsName, Namespace: parentName}, &danav1.Subnamespace{})
+	if err != nil {
+		return nil, err
+	}
+
+	if !snsObject.IsPresent() {
+		return nil, nil
+	}
+
+	return snsObject.Object.(*danav1.Subnamespace).Spec.InheritancePolicy, nil
+}
+
+// IsExcluded returns true if an inheritance policy excludes a RoleBinding, either because the policy blocks
+// all inheritance, or because the name of the RoleBinding, one of its subjects or its role is excluded.
+// The name of an inherited RoleBinding is taken from the RoleBinding it was inherited from.
+func IsExcluded(policy *danav1.InheritancePolicy, roleBinding client.Object) bool {
+	if policy == nil {
+		return false
+	}
+
+	if policy.BlockAll {
+		return true
+	}
+
+	if common.ContainsString(policy.ExcludedNames, InheritedSourceName(roleBinding)) {
+		return true
+	}
+
+	if common.ContainsString(policy.ExcludedRoles, RoleRef(roleBinding).Name) {
+		return true
+	}
+
+	for _, subject := range roleBinding.(*rbacv1.RoleBinding).Subjects {
+		for _, excludedSubject := range policy.ExcludedSubjects {
+			if subject.Kind == excludedSubject.Kind && subject.Name == excludedSubject.Name &&
+				(excludedSubject.Namespace == "" || subject.Namespace == excludedSubject.Namespace) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// IsExcludedOnPath returns true if an inherited RoleBinding is excluded by the inheritance policy of a subnamespace
+// on the path from the namespace it was inherited from to its namespace, including the namespace itself.
+func IsExcludedOnPath(ctx context.Context, k8sClient client.Client, ns client.Object, roleBinding client.Object) (bool, error) {
+	ancestors, err := nsutils.Ancestors(ns)
+	if err != nil {
+		return false, err
+	}
+	source := InheritedSource(roleBinding)
+
+	sourceIndex := -1
+	for i, ancestor := range ancestors {
+		if ancestor == source {
+			sourceIndex = i
+		}
+	}
+	if sourceIndex == -1 {
+		return false, nil
+	}
+
+	for i := sourceIndex + 1; i < len(ancestors); i++ {
+		policy, err := InheritancePolicy(ctx, k8sClient, ancestors[i], ancestors[i-1])
+		if err != nil {
+			return false, err
+		}
+
+		if IsExcluded(policy, roleBinding) {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
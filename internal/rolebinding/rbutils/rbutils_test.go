@@ -0,0 +1,146 @@
+package rbutils
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/dana-team/hns/internal/common"
+	"github.com/dana-team/hns/internal/objectcontext"
+	"github.com/dana-team/hns/internal/testutils"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func TestEligibleSubjects(t *testing.T) {
+	user := rbacv1.Subject{Kind: rbacv1.UserKind, Name: "user"}
+	group := rbacv1.Subject{Kind: rbacv1.GroupKind, Name: "team"}
+	systemGroup := rbacv1.Subject{Kind: rbacv1.GroupKind, Name: "system:authenticated"}
+	defaultSA := rbacv1.Subject{Kind: rbacv1.ServiceAccountKind, Name: "default", Namespace: "ns"}
+	sa := rbacv1.Subject{Kind: rbacv1.ServiceAccountKind, Name: "bot"}
+
+	tests := []struct {
+		name       string
+		subjects   []rbacv1.Subject
+		exclusions common.SubjectExclusions
+		want       []rbacv1.Subject
+	}{
+		{
+			name:       "keeps the subjects after an excluded first subject",
+			subjects:   []rbacv1.Subject{systemGroup, user, group},
+			exclusions: common.DefaultSubjectExclusions,
+			want:       []rbacv1.Subject{user, group},
+		},
+		{
+			name:       "sets the namespace of every service account without one",
+			subjects:   []rbacv1.Subject{defaultSA, sa},
+			exclusions: common.DefaultSubjectExclusions,
+			want:       []rbacv1.Subject{{Kind: rbacv1.ServiceAccountKind, Name: "bot", Namespace: "ns"}},
+		},
+		{
+			name:       "uses the given exclusions",
+			subjects:   []rbacv1.Subject{systemGroup, defaultSA, group},
+			exclusions: common.SubjectExclusions{GroupPrefixes: []string{"te"}},
+			want:       []rbacv1.Subject{systemGroup, defaultSA},
+		},
+		{
+			name:       "excludes all the subjects",
+			subjects:   []rbacv1.Subject{systemGroup, defaultSA},
+			exclusions: common.DefaultSubjectExclusions,
+			want:       nil,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			roleBinding := &rbacv1.RoleBinding{
+				ObjectMeta: metav1.ObjectMeta{Name: "rb", Namespace: "ns"},
+				Subjects:   append([]rbacv1.Subject(nil), test.subjects...),
+			}
+
+			if got := EligibleSubjects(roleBinding, test.exclusions); !reflect.DeepEqual(got, test.want) {
+				t.Errorf("expected subjects %v, got %v", test.want, got)
+			}
+			if IsHNSRelated(roleBinding, test.exclusions) != (len(test.want) > 0) {
+				t.Errorf("expected IsHNSRelated to be %v", len(test.want) > 0)
+			}
+			if !reflect.DeepEqual(roleBinding.Subjects, test.subjects) {
+				t.Errorf("expected the subjects of the roleBinding not to be changed")
+			}
+		})
+	}
+}
+
+func TestHNSView(t *testing.T) {
+	user := rbacv1.Subject{Kind: rbacv1.UserKind, Name: "team"}
+	group := rbacv1.Subject{Kind: rbacv1.GroupKind, Name: "team"}
+
+	if HNSViewName(user, 0) == HNSViewName(group, 0) {
+		t.Errorf("expected subjects of different kinds to have different HNS view names")
+	}
+
+	clusterRole := composeHNSViewClusterRole(HNSViewName(user, 0), user, []string{"b", "a"})
+	want := []string{"a", "b"}
+	if got := HNSViewNamespaces(clusterRole); !reflect.DeepEqual(got, want) {
+		t.Errorf("expected namespaces %v, got %v", want, got)
+	}
+
+	for _, rule := range clusterRole.Rules {
+		if rule.ResourceNames != nil && !reflect.DeepEqual(rule.ResourceNames, want) {
+			t.Errorf("expected the rule %v to allow namespaces %v", rule, want)
+		}
+	}
+}
+
+func TestHNSViewShards(t *testing.T) {
+	fakeClient := testutils.NewFakeClient(t)
+	objectContext, err := objectcontext.New(context.Background(), fakeClient, types.NamespacedName{Name: "rb", Namespace: "ns"}, &rbacv1.RoleBinding{})
+	if err != nil {
+		t.Fatalf("failed to create object context: %v", err)
+	}
+
+	user := rbacv1.Subject{Kind: rbacv1.UserKind, Name: "user"}
+	namespaces := make([]string, hnsViewMaxNamespaces+1)
+	for i := range namespaces {
+		namespaces[i] = fmt.Sprintf("ns-%d", i)
+		if err := AddNamespaceToHNSView(objectContext, user, namespaces[i]); err != nil {
+			t.Fatalf("failed to add namespace to HNS view: %v", err)
+		}
+	}
+
+	expectShards := func(want int) {
+		t.Helper()
+		clusterRoles := &rbacv1.ClusterRoleList{}
+		if err := fakeClient.List(context.Background(), clusterRoles); err != nil {
+			t.Fatalf("failed to list clusterroles: %v", err)
+		}
+		clusterRoleBindings := &rbacv1.ClusterRoleBindingList{}
+		if err := fakeClient.List(context.Background(), clusterRoleBindings); err != nil {
+			t.Fatalf("failed to list clusterrolebindings: %v", err)
+		}
+		if len(clusterRoles.Items) != want || len(clusterRoleBindings.Items) != want {
+			t.Errorf("expected %d HNS view clusterroles and clusterrolebindings, got %d and %d",
+				want, len(clusterRoles.Items), len(clusterRoleBindings.Items))
+		}
+	}
+
+	// a second ClusterRole is only created once the first one is full
+	expectShards(2)
+
+	// a namespace which was removed from a full ClusterRole frees room for a new namespace in it
+	if err := RemoveNamespaceFromHNSView(objectContext, user, namespaces[0]); err != nil {
+		t.Fatalf("failed to remove namespace from HNS view: %v", err)
+	}
+	if err := AddNamespaceToHNSView(objectContext, user, "new"); err != nil {
+		t.Fatalf("failed to add namespace to HNS view: %v", err)
+	}
+	expectShards(2)
+
+	// a ClusterRole with no namespaces left is deleted
+	if err := RemoveNamespaceFromHNSView(objectContext, user, namespaces[hnsViewMaxNamespaces]); err != nil {
+		t.Fatalf("failed to remove namespace from HNS view: %v", err)
+	}
+	expectShards(1)
+}
@@ -0,0 +1,61 @@
+package rolebinding
+
+import (
+	"fmt"
+	"sort"
+
+	danav1 "github.com/dana-team/hns/api/v1"
+	"github.com/dana-team/hns/internal/common"
+	"github.com/dana-team/hns/internal/objectcontext"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+// provenanceLabels and provenanceAnnotations are the labels and annotations HNS sets on an inherited RoleBinding
+// to record the RoleBinding it was inherited from. They are used to decide whether the RoleBinding can be deleted.
+var (
+	provenanceLabels      = []string{danav1.InheritedFromUID}
+	provenanceAnnotations = []string{danav1.InheritedFrom, danav1.InheritedFromName}
+)
+
+// handleUpdate validates that only HNS changes the labels and annotations which record where a RoleBinding
+// was inherited from, so that an inherited RoleBinding can't be made to look like it was not inherited
+// in order to delete it, and a RoleBinding can't be made to look like it was inherited.
+func (v *RoleBindingValidator) handleUpdate(oldRB *rbacv1.RoleBinding, rbObject *objectcontext.ObjectContext, userName string) admission.Response {
+	if common.IsHNSServiceAccount(userName) {
+		return admission.Allowed("HNS is allowed to change the labels and annotations of RoleBindings")
+	}
+
+	changedLabels := changedKeys(provenanceLabels, oldRB.Labels, rbObject.Object.GetLabels())
+	if len(changedLabels) > 0 {
+		message := fmt.Sprintf("it's forbidden to change the labels %q of RoleBinding %q, they are managed by HNS",
+			changedLabels, rbObject.Name())
+		return admission.Denied(message)
+	}
+
+	changedAnnotations := changedKeys(provenanceAnnotations, oldRB.Annotations, rbObject.Object.GetAnnotations())
+	if len(changedAnnotations) > 0 {
+		message := fmt.Sprintf("it's forbidden to change the annotations %q of RoleBinding %q, they are managed by HNS",
+			changedAnnotations, rbObject.Name())
+		return admission.Denied(message)
+	}
+
+	return admission.Allowed("RoleBindings are only validated for changes to the labels and annotations managed by HNS")
+}
+
+// changedKeys returns the sorted keys out of the given keys which were added, changed or removed
+// between the old and the new labels or annotations of a RoleBinding.
+func changedKeys(keys []string, oldMap, newMap map[string]string) []string {
+	var changed []string
+
+	for _, key := range keys {
+		oldValue, oldOK := oldMap[key]
+		newValue, newOK := newMap[key]
+		if oldOK != newOK || oldValue != newValue {
+			changed = append(changed, key)
+		}
+	}
+
+	sort.Strings(changed)
+	return changed
+}
@@ -18,7 +18,7 @@ type RoleBindingValidator struct {
 	Decoder admission.Decoder
 }
 
-// +kubebuilder:webhook:path=/validate-v1-rolebinding,mutating=false,sideEffects=NoneOnDryRun,failurePolicy=fail,groups="rbac.authorization.k8s.io",resources=rolebindings,verbs=delete,versions=v1,name=rolebinding.dana.io,admissionReviewVersions=v1;v1beta1
+// +kubebuilder:webhook:path=/validate-v1-rolebinding,mutating=false,sideEffects=NoneOnDryRun,failurePolicy=fail,groups="rbac.authorization.k8s.io",resources=rolebindings,verbs=update;delete,versions=v1,name=rolebinding.dana.io,admissionReviewVersions=v1;v1beta1
 
 // Handle implements the validation webhook.
 func (v *RoleBindingValidator) Handle(ctx context.Context, req admission.Request) admission.Response {
@@ -42,5 +42,22 @@ func (v *RoleBindingValidator) Handle(ctx context.Context, req admission.Request
 		}
 	}
 
+	if req.Operation == admissionv1.Update {
+		if err := v.Decoder.DecodeRaw(req.Object, rbObject.Object); err != nil {
+			logger.Error(err, "failed to decode object", "request object", req.Object)
+			return admission.Errored(http.StatusBadRequest, err)
+		}
+
+		oldRB := &rbacv1.RoleBinding{}
+		if err := v.Decoder.DecodeRaw(req.OldObject, oldRB); err != nil {
+			logger.Error(err, "failed to decode object", "request object", req.OldObject)
+			return admission.Errored(http.StatusBadRequest, err)
+		}
+
+		if response := v.handleUpdate(oldRB, rbObject, req.UserInfo.Username); !response.Allowed {
+			return response
+		}
+	}
+
 	return admission.Allowed("")
 }
@@ -3,40 +3,52 @@ package setup
 import (
 	"fmt"
 
+	. "github.com/dana-team/hns/internal/batchmigration"
+	"github.com/dana-team/hns/internal/enqueuer"
+	. "github.com/dana-team/hns/internal/hierarchyroot"
 	. "github.com/dana-team/hns/internal/migrationhierarchy"
 	. "github.com/dana-team/hns/internal/namespace"
 	"github.com/dana-team/hns/internal/namespacedb"
 	. "github.com/dana-team/hns/internal/rolebinding"
 	. "github.com/dana-team/hns/internal/subnamespace"
+	. "github.com/dana-team/hns/internal/subnamespacerename"
 	. "github.com/dana-team/hns/internal/updatequota"
+	"k8s.io/client-go/discovery"
 	_ "k8s.io/client-go/plugin/pkg/client/auth/gcp"
-	"sigs.k8s.io/controller-runtime/pkg/event"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
 )
 
 var (
-	nsEvents  = make(chan event.GenericEvent)
-	snsEvents = make(chan event.GenericEvent)
+	nsEvents  = enqueuer.New("Namespace")
+	snsEvents = enqueuer.New("Subnamespace")
+	upqEvents = enqueuer.New("Updatequota")
+	mhEvents  = enqueuer.New("MigrationHierarchy")
+
+	// quotaScheduler schedules the operations which change the quota of subnamespaces, which
+	// are run by both the UpdateQuota controller and the MigrationHierarchy controller
+	quotaScheduler = NewScheduler()
 )
 
 // Controllers sets up the different controllers with the manager.
-func Controllers(mgr manager.Manager, ndb *namespacedb.NamespaceDB) error {
+func Controllers(mgr manager.Manager, ndb *namespacedb.NamespaceDB, opts Options) error {
 	if err := (&NamespaceReconciler{
-		Client:      mgr.GetClient(),
-		Scheme:      mgr.GetScheme(),
-		NSEvents:    nsEvents,
-		SNSEvents:   snsEvents,
-		NamespaceDB: ndb,
+		Client:           mgr.GetClient(),
+		Scheme:           mgr.GetScheme(),
+		NSEvents:         nsEvents,
+		SNSEvents:        snsEvents,
+		NamespaceDB:      ndb,
+		ParentSyncWindow: opts.ParentSyncWindow,
 	}).SetupWithManager(mgr); err != nil {
 		return fmt.Errorf("unable to create controller: %v", err.Error())
 	}
 
 	if err := (&SubnamespaceReconciler{
-		Client:      mgr.GetClient(),
-		Scheme:      mgr.GetScheme(),
-		NSEvents:    nsEvents,
-		SNSEvents:   snsEvents,
-		NamespaceDB: ndb,
+		Client:           mgr.GetClient(),
+		Scheme:           mgr.GetScheme(),
+		NSEvents:         nsEvents,
+		SNSEvents:        snsEvents,
+		NamespaceDB:      ndb,
+		ParentSyncWindow: opts.ParentSyncWindow,
 	}).SetupWithManager(mgr); err != nil {
 		return fmt.Errorf("unable to create controller: %v", err.Error())
 	}
@@ -49,8 +61,11 @@ func Controllers(mgr manager.Manager, ndb *namespacedb.NamespaceDB) error {
 	}
 
 	if err := (&UpdateQuotaReconciler{
-		Client: mgr.GetClient(),
-		Scheme: mgr.GetScheme(),
+		Client:                  mgr.GetClient(),
+		Scheme:                  mgr.GetScheme(),
+		UPQEvents:               upqEvents,
+		Scheduler:               quotaScheduler,
+		MaxConcurrentReconciles: opts.UPQMaxConcurrentReconciles,
 	}).SetupWithManager(mgr); err != nil {
 		return fmt.Errorf("unable to create controller: %v", err.Error())
 	}
@@ -60,6 +75,39 @@ func Controllers(mgr manager.Manager, ndb *namespacedb.NamespaceDB) error {
 		Scheme:      mgr.GetScheme(),
 		NamespaceDB: ndb,
 		SnsEvents:   snsEvents,
+		MHEvents:    mhEvents,
+		Scheduler:   quotaScheduler,
+		MaxSNS:      opts.MaxSNSInHierarchy,
+	}).SetupWithManager(mgr); err != nil {
+		return fmt.Errorf("unable to create controller: %v", err.Error())
+	}
+
+	if err := (&BatchMigrationReconciler{
+		Client: mgr.GetClient(),
+		Scheme: mgr.GetScheme(),
+	}).SetupWithManager(mgr); err != nil {
+		return fmt.Errorf("unable to create controller: %v", err.Error())
+	}
+
+	discoveryClient, err := discovery.NewDiscoveryClientForConfig(mgr.GetConfig())
+	if err != nil {
+		return fmt.Errorf("unable to create discovery client: %v", err.Error())
+	}
+
+	if err := (&SubnamespaceRenameReconciler{
+		Client:      mgr.GetClient(),
+		Scheme:      mgr.GetScheme(),
+		NamespaceDB: ndb,
+		SNSEvents:   snsEvents,
+		APIReader:   mgr.GetAPIReader(),
+		Discovery:   discoveryClient,
+	}).SetupWithManager(mgr); err != nil {
+		return fmt.Errorf("unable to create controller: %v", err.Error())
+	}
+
+	if err := (&HierarchyRootReconciler{
+		Client: mgr.GetClient(),
+		Scheme: mgr.GetScheme(),
 	}).SetupWithManager(mgr); err != nil {
 		return fmt.Errorf("unable to create controller: %v", err.Error())
 	}
@@ -1,12 +1,19 @@
 package setup
 
 import (
+	"time"
+
+	. "github.com/dana-team/hns/internal/batchmigration"
 	. "github.com/dana-team/hns/internal/buildconfig"
+	"github.com/dana-team/hns/internal/common"
+	. "github.com/dana-team/hns/internal/hierarchyroot"
 	. "github.com/dana-team/hns/internal/migrationhierarchy"
 	. "github.com/dana-team/hns/internal/namespace"
 	"github.com/dana-team/hns/internal/namespacedb"
+	. "github.com/dana-team/hns/internal/quotaobject"
 	. "github.com/dana-team/hns/internal/rolebinding"
 	. "github.com/dana-team/hns/internal/subnamespace"
+	. "github.com/dana-team/hns/internal/subnamespacerename"
 	. "github.com/dana-team/hns/internal/updatequota"
 	"k8s.io/apimachinery/pkg/runtime"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
@@ -17,9 +24,11 @@ import (
 )
 
 type Options struct {
-	NoWebhooks        bool
-	OnlyResourcePool  bool
-	MaxSNSInHierarchy int
+	NoWebhooks                 bool
+	OnlyResourcePool           bool
+	MaxSNSInHierarchy          int
+	ParentSyncWindow           time.Duration
+	UPQMaxConcurrentReconciles int
 }
 
 // Webhooks registers the different webhooks.
@@ -27,6 +36,7 @@ func Webhooks(mgr manager.Manager, ndb *namespacedb.NamespaceDB, scheme *runtime
 	hookServer := mgr.GetWebhookServer()
 
 	decoder := admission.NewDecoder(scheme)
+	authorizer := common.NewSubjectAccessReviewAuthorizer(mgr.GetClient())
 
 	hookServer.Register("/validate-v1-namespace", &webhook.Admission{Handler: &NamespaceValidator{
 		Client:  mgr.GetClient(),
@@ -39,6 +49,7 @@ func Webhooks(mgr manager.Manager, ndb *namespacedb.NamespaceDB, scheme *runtime
 		NamespaceDB: ndb,
 		MaxSNS:      opts.MaxSNSInHierarchy,
 		OnlyRP:      opts.OnlyResourcePool,
+		Authorizer:  authorizer,
 	}})
 
 	hookServer.Register("/validate-v1-rolebinding", &webhook.Admission{Handler: &RoleBindingValidator{
@@ -59,15 +70,62 @@ func Webhooks(mgr manager.Manager, ndb *namespacedb.NamespaceDB, scheme *runtime
 		Decoder: decoder,
 	}})
 
-	hookServer.Register("/validate-v1-updatequota", &webhook.Admission{Handler: &UpdateQuotaValidator{
+	hookServer.Register("/mutate-v1-subnamespace", &webhook.Admission{Handler: &SubnamespaceMutator{
+		Client:  mgr.GetClient(),
+		Decoder: decoder,
+	}})
+	hookServer.Register("/mutate-v1-resourcequota", &webhook.Admission{Handler: &QuotaObjectMutator{
+		Client:  mgr.GetClient(),
+		Decoder: decoder,
+	}})
+	hookServer.Register("/mutate-v1-clusterresourcequota", &webhook.Admission{Handler: &QuotaObjectMutator{
 		Client:  mgr.GetClient(),
 		Decoder: decoder,
 	}})
 
+	hookServer.Register("/validate-v1-updatequota", &webhook.Admission{Handler: &UpdateQuotaValidator{
+		Client:     mgr.GetClient(),
+		Decoder:    decoder,
+		Authorizer: authorizer,
+	}})
+
 	hookServer.Register("/validate-v1-migrationhierarchy", &webhook.Admission{Handler: &MigrationHierarchyValidator{
 		Client:      mgr.GetClient(),
 		Decoder:     decoder,
 		NamespaceDB: ndb,
 		MaxSNS:      opts.MaxSNSInHierarchy,
+		Authorizer:  authorizer,
+	}})
+
+	hookServer.Register("/validate-v1-batchmigration", &webhook.Admission{Handler: &BatchMigrationValidator{
+		Client:      mgr.GetClient(),
+		Decoder:     decoder,
+		NamespaceDB: ndb,
+		MaxSNS:      opts.MaxSNSInHierarchy,
+		Authorizer:  authorizer,
+	}})
+
+	hookServer.Register("/validate-v1-subnamespacerename", &webhook.Admission{Handler: &SubnamespaceRenameValidator{
+		Client:     mgr.GetClient(),
+		Decoder:    decoder,
+		Authorizer: authorizer,
+	}})
+
+	hookServer.Register("/validate-v1-resourcequota", &webhook.Admission{Handler: &QuotaObjectValidator{
+		Client:  mgr.GetClient(),
+		Decoder: decoder,
+	}})
+	hookServer.Register("/validate-v1-clusterresourcequota", &webhook.Admission{Handler: &QuotaObjectValidator{
+		Client:  mgr.GetClient(),
+		Decoder: decoder,
+	}})
+	hookServer.Register("/validate-v1-limitrange", &webhook.Admission{Handler: &QuotaObjectValidator{
+		Client:  mgr.GetClient(),
+		Decoder: decoder,
+	}})
+
+	hookServer.Register("/validate-v1-hierarchyroot", &webhook.Admission{Handler: &HierarchyRootValidator{
+		Client:  mgr.GetClient(),
+		Decoder: decoder,
 	}})
 }
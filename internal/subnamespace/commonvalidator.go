@@ -1,28 +1,43 @@
 package subnamespace
 
 import (
+	"fmt"
 	"net/http"
+	"slices"
 	"strings"
 
 	"github.com/dana-team/hns/internal/common"
 
+	danav1 "github.com/dana-team/hns/api/v1"
 	"github.com/dana-team/hns/internal/objectcontext"
 	"github.com/dana-team/hns/internal/quota"
 	"github.com/dana-team/hns/internal/subnamespace/resourcepool"
+	authenticationv1 "k8s.io/api/authentication/v1"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
 )
 
 // ValidateResourceQuotaParams validates that in a regular Subnamespace all quota params: storage, cpu, memory, gpu exists and are positive.
 // In a ResourcePool it validates that the upper resource pool cannot be created with an empty quota.
 func ValidateResourceQuotaParams(snsObject *objectcontext.ObjectContext, isSNSResourcePool bool) admission.Response {
-	snsQuota := quota.SubnamespaceSpec(snsObject.Object).Hard
-
 	observedResources, err := common.GetObservedResources(snsObject.Ctx, snsObject.Client)
 	if err != nil {
 		return admission.Errored(http.StatusBadRequest, err)
 	}
 
+	aliases, err := common.GetResourceAliases(snsObject.Ctx, snsObject.Client)
+	if err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+
+	// the observed resources use canonical resource names, so the quota of
+	// the subnamespace is normalized before comparing the two
+	snsQuota, err := quota.NormalizeResourceList(quota.SubnamespaceSpec(snsObject.Object).Hard, aliases)
+	if err != nil {
+		return admission.Denied(err.Error())
+	}
+
 	resourceQuotaParams := observedResources.Hard
 
 	if isSNSResourcePool {
@@ -96,3 +111,90 @@ func validateNegativeResources(resourceQuotaParams, snsQuota corev1.ResourceList
 
 	return admission.Allowed("")
 }
+
+// validateLimitRangePolicy validates that the LimitRange policy of a subnamespace only tightens
+// the limits it inherits from its ancestors and never loosens them.
+func validateLimitRangePolicy(snsObject *objectcontext.ObjectContext) admission.Response {
+	policy := quota.SubnamespaceLimitRangePolicy(snsObject.Object)
+	if len(policy) == 0 {
+		return admission.Allowed("")
+	}
+
+	inherited, err := quota.SubnamespaceInheritedLimits(snsObject)
+	if err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+
+	if loosened := quota.LoosenedLimits(inherited, policy); len(loosened) > 0 {
+		message := fmt.Sprintf("it's forbidden to set a LimitRange policy on subnamespace %q that loosens the limits it "+
+			"inherits from its ancestors. A subnamespace may only tighten the inherited limits: %s", snsObject.Name(), strings.Join(loosened, "; "))
+		return admission.Denied(message)
+	}
+
+	return admission.Allowed("")
+}
+
+// validateOwners validates that the user is allowed to grant the roles of the owners added to a subnamespace,
+// so that owners can't be used to escalate privileges. The roles are checked in the namespace the subnamespace
+// is created in, since the RoleBindings of that namespace are inherited by the namespace bound to the subnamespace.
+// Owners which the subnamespace already had are not validated again.
+func (v *SubnamespaceValidator) validateOwners(snsObject *objectcontext.ObjectContext, oldOwners []danav1.SubnamespaceOwner, userInfo authenticationv1.UserInfo) admission.Response {
+	ctx := snsObject.Ctx
+	parentNSName := snsObject.Object.GetNamespace()
+
+	var addedOwners []danav1.SubnamespaceOwner
+	for _, owner := range snsObject.Object.(*danav1.Subnamespace).Spec.Owners {
+		if !slices.Contains(oldOwners, owner) {
+			addedOwners = append(addedOwners, owner)
+		}
+	}
+	if len(addedOwners) == 0 {
+		return admission.Allowed("")
+	}
+
+	canManage, err := common.CanManageHNSObjects(ctx, userInfo.Username, v.Client)
+	if err != nil {
+		return admission.Errored(http.StatusInternalServerError, err)
+	}
+	if canManage {
+		return admission.Allowed("")
+	}
+
+	for _, owner := range addedOwners {
+		allowed, err := v.Authorizer.AuthorizeBind(ctx, userInfo, owner.Role, parentNSName)
+		if err != nil {
+			return admission.Errored(http.StatusInternalServerError, err)
+		}
+
+		if !allowed {
+			message := fmt.Sprintf("it's forbidden to make %s %q an owner of subnamespace %q with role %q. You must either "+
+				"be allowed to bind ClusterRole %q in %q, or already have all of its permissions there",
+				owner.Kind, owner.Name, snsObject.Name(), owner.Role, owner.Role, parentNSName)
+			return admission.Denied(message)
+		}
+	}
+
+	return admission.Allowed("")
+}
+
+// validateInheritancePolicy validates that only members of the permitted groups set or change the
+// inheritance policy of a subnamespace, since the policy can hide a namespace from the admins of its ancestors.
+func (v *SubnamespaceValidator) validateInheritancePolicy(snsObject *objectcontext.ObjectContext, oldPolicy *danav1.InheritancePolicy, userInfo authenticationv1.UserInfo) admission.Response {
+	policy := snsObject.Object.(*danav1.Subnamespace).Spec.InheritancePolicy
+	if equality.Semantic.DeepEqual(policy, oldPolicy) {
+		return admission.Allowed("")
+	}
+
+	canManage, err := common.CanManageHNSObjects(snsObject.Ctx, userInfo.Username, v.Client)
+	if err != nil {
+		return admission.Errored(http.StatusInternalServerError, err)
+	}
+
+	if !canManage {
+		message := fmt.Sprintf("it's forbidden to set the inheritance policy of subnamespace %q. Only members of "+
+			"the permitted groups may set the inheritance policy of a subnamespace", snsObject.Name())
+		return admission.Denied(message)
+	}
+
+	return admission.Allowed("")
+}
@@ -3,28 +3,44 @@ package subnamespace
 import (
 	"context"
 	"fmt"
+	"sync"
+	"time"
 
 	danav1 "github.com/dana-team/hns/api/v1"
+	"github.com/dana-team/hns/internal/enqueuer"
+	"github.com/dana-team/hns/internal/namespace/nsutils"
 	"github.com/dana-team/hns/internal/namespacedb"
 	"github.com/dana-team/hns/internal/objectcontext"
+	"github.com/dana-team/hns/internal/quota"
+	quotav1 "github.com/openshift/api/quota/v1"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/event"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/log"
-	"sigs.k8s.io/controller-runtime/pkg/source"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 )
 
 // SubnamespaceReconciler reconciles a Subnamespace object
 type SubnamespaceReconciler struct {
 	client.Client
 	Scheme      *runtime.Scheme
-	NSEvents    chan event.GenericEvent
-	SNSEvents   chan event.GenericEvent
+	NSEvents    *enqueuer.Enqueuer
+	SNSEvents   *enqueuer.Enqueuer
 	NamespaceDB *namespacedb.NamespaceDB
+
+	// ParentSyncWindow is the window in which the reconciliations of a subnamespace triggered by its children
+	// are coalesced into a single reconciliation
+	ParentSyncWindow time.Duration
+
+	// listedChildren holds the subnamespaces whose children were listed since HNS started, after which
+	// their status is kept up-to-date by their children
+	listedChildren sync.Map
 }
 
 type snsPhaseFunc func(*objectcontext.ObjectContext, *objectcontext.ObjectContext) (ctrl.Result, error)
@@ -40,15 +56,63 @@ type snsPhaseFunc func(*objectcontext.ObjectContext, *objectcontext.ObjectContex
 // +kubebuilder:rbac:groups=core,resources=configmaps,verbs=get;list;watch
 
 // SetupWithManager sets up the controller by specifying the following: controller is managing the reconciliation
-// of subnamespace objects and is watching for changes to the SNSEvents channel and enqueues requests for the
-// associated object.
+// of subnamespace objects and of the subnamespaces enqueued to SNSEvents by the HNS controllers. It is also
+// watching for changes to the spec or the deletion of the quota objects and LimitRanges of subnamespaces,
+// so that any drift from the subnamespace is reverted.
 func (r *SubnamespaceReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	return ctrl.NewControllerManagedBy(mgr).
-		WatchesRawSource(source.Channel(r.SNSEvents, &handler.EnqueueRequestForObject{})).
+		WatchesRawSource(r.SNSEvents).
 		For(&danav1.Subnamespace{}).
+		Watches(&corev1.ResourceQuota{}, handler.EnqueueRequestsFromMapFunc(r.mapNamespacedObjectToSubnamespace),
+			builder.WithPredicates(driftPredicate)).
+		Watches(&corev1.LimitRange{}, handler.EnqueueRequestsFromMapFunc(r.mapNamespacedObjectToSubnamespace),
+			builder.WithPredicates(driftPredicate)).
+		Watches(&quotav1.ClusterResourceQuota{}, handler.EnqueueRequestsFromMapFunc(r.mapClusterResourceQuotaToSubnamespace),
+			builder.WithPredicates(driftPredicate)).
 		Complete(r)
 }
 
+// driftPredicate filters the events of the quota objects and LimitRanges of subnamespaces,
+// keeping only the ones in which the object may have drifted from its subnamespace.
+var driftPredicate = predicate.Funcs{
+	CreateFunc: func(event.CreateEvent) bool { return false },
+	UpdateFunc: func(e event.UpdateEvent) bool {
+		return !quota.SpecEqual(e.ObjectOld, e.ObjectNew)
+	},
+	DeleteFunc:  func(event.DeleteEvent) bool { return true },
+	GenericFunc: func(event.GenericEvent) bool { return false },
+}
+
+// mapNamespacedObjectToSubnamespace maps a ResourceQuota or a LimitRange named after its
+// namespace to the subnamespace bound to the namespace.
+func (r *SubnamespaceReconciler) mapNamespacedObjectToSubnamespace(ctx context.Context, object client.Object) []reconcile.Request {
+	if object.GetName() != object.GetNamespace() {
+		return nil
+	}
+
+	return r.mapNamespaceToSubnamespace(ctx, object.GetNamespace())
+}
+
+// mapClusterResourceQuotaToSubnamespace maps a ClusterResourceQuota to the subnamespace it is named after.
+func (r *SubnamespaceReconciler) mapClusterResourceQuotaToSubnamespace(ctx context.Context, object client.Object) []reconcile.Request {
+	return r.mapNamespaceToSubnamespace(ctx, object.GetName())
+}
+
+// mapNamespaceToSubnamespace maps a namespace managed by HNS to the subnamespace bound to it.
+func (r *SubnamespaceReconciler) mapNamespaceToSubnamespace(ctx context.Context, nsName string) []reconcile.Request {
+	ns := &corev1.Namespace{}
+	if err := r.Get(ctx, types.NamespacedName{Name: nsName}, ns); err != nil {
+		return nil
+	}
+
+	parent := nsutils.Parent(ns)
+	if ns.Labels[danav1.Hns] == "" || parent == "" {
+		return nil
+	}
+
+	return []reconcile.Request{{NamespacedName: types.NamespacedName{Name: nsName, Namespace: parent}}}
+}
+
 func (r *SubnamespaceReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
 	logger := log.FromContext(ctx).WithName("controllers").WithName("Subnamespace").WithValues("sns", req.NamespacedName)
 	logger.Info("starting to reconcile")
@@ -60,7 +124,8 @@ func (r *SubnamespaceReconciler) Reconcile(ctx context.Context, req ctrl.Request
 
 	if !snsObject.IsPresent() {
 		logger.Info("resource not found. Ignoring since object must be deleted")
-		return ctrl.Result{}, nil
+		r.listedChildren.Delete(req.NamespacedName)
+		return ctrl.Result{}, r.removeFromParent(ctx, req.NamespacedName)
 	}
 
 	snsParentNSName := snsObject.Object.(*danav1.Subnamespace).GetNamespace()
@@ -83,3 +148,30 @@ func (r *SubnamespaceReconciler) Reconcile(ctx context.Context, req ctrl.Request
 
 	return phaseMap[phase](snsParentNS, snsObject)
 }
+
+// removeFromParent removes a subnamespace which was deleted from the status of the subnamespace of its parent
+// namespace and triggers the reconciliation of the parent so that it can be aware of the change.
+func (r *SubnamespaceReconciler) removeFromParent(ctx context.Context, sns types.NamespacedName) error {
+	parentNS, err := objectcontext.New(ctx, r.Client, types.NamespacedName{Name: sns.Namespace}, &corev1.Namespace{})
+	if err != nil {
+		return err
+	}
+	if !parentNS.IsPresent() {
+		return nil
+	}
+
+	parentSNS, err := objectcontext.New(ctx, r.Client, types.NamespacedName{Name: sns.Namespace, Namespace: nsutils.Parent(parentNS.Object)}, &danav1.Subnamespace{})
+	if err != nil {
+		return err
+	}
+	if !parentSNS.IsPresent() {
+		return nil
+	}
+
+	if err := removeFromParentStatus(parentSNS, sns.Name); err != nil {
+		return fmt.Errorf("failed to remove subnamespace %q from the status of parent subnamespace %q: %v", sns.Name, parentSNS.Name(), err.Error())
+	}
+	r.SNSEvents.EnqueueAfter(parentSNS.Name(), parentSNS.Namespace(), r.ParentSyncWindow)
+
+	return nil
+}
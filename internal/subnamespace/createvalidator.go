@@ -4,11 +4,16 @@ import (
 	"fmt"
 	"net/http"
 	"regexp"
+	"strings"
 
+	danav1 "github.com/dana-team/hns/api/v1"
+	"github.com/dana-team/hns/internal/common"
+	"github.com/dana-team/hns/internal/namespace/nsutils"
 	"github.com/dana-team/hns/internal/objectcontext"
 	"github.com/dana-team/hns/internal/quota"
 	"github.com/dana-team/hns/internal/subnamespace/resourcepool"
 	"github.com/dana-team/hns/internal/subnamespace/snsutils"
+	authenticationv1 "k8s.io/api/authentication/v1"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/log"
@@ -17,11 +22,19 @@ import (
 
 // handleCreate implements the non-boilerplate logic of the validator, allowing it to be more easily unit
 // tested (i.e. without constructing a full admission.Request).
-func (v *SubnamespaceValidator) handleCreate(snsObject *objectcontext.ObjectContext) admission.Response {
+func (v *SubnamespaceValidator) handleCreate(snsObject *objectcontext.ObjectContext, userInfo authenticationv1.UserInfo) admission.Response {
 	if response := v.validateSubnamespaceName(snsObject); !response.Allowed {
 		return response
 	}
 
+	if response := v.validateCreateChildPermissions(snsObject, userInfo); !response.Allowed {
+		return response
+	}
+
+	if response := v.validateInheritancePolicy(snsObject, nil, userInfo); !response.Allowed {
+		return response
+	}
+
 	if response := v.validateUniqueSNSName(snsObject); !response.Allowed {
 		return response
 	}
@@ -35,10 +48,26 @@ func (v *SubnamespaceValidator) handleCreate(snsObject *objectcontext.ObjectCont
 		return response
 	}
 
+	isHNS := common.IsHNSServiceAccount(userInfo.Username)
+
+	// a subnamespace which is migrated by HNS, either by a MigrationHierarchy or along with its renamed parent, was
+	// already counted in the hierarchy of its new parent, and its quota is already part of the quota of its new
+	// parent, since a MigrationHierarchy reserves it before the subnamespace is created
+	isMigrated := isHNS && snsObject.Object.(*danav1.Subnamespace).Status.Phase == danav1.Migrated
+
+	// a subnamespace which is renamed by HNS replaces the subnamespace it's renamed from, so both
+	// are counted as a single subnamespace with a single allocation of resources
+	renamedFrom := ""
+	if isHNS {
+		renamedFrom = renamedFromSNS(snsObject)
+	}
+
 	// validate that the new parent doesn't already have too many subnamespaces in its branch
 	// the maximum number a subnamespace can have in its branch is called by the MaxSNS flag
-	if response := v.validateKeyCountInDB(snsObject); !response.Allowed {
-		return response
+	if !isMigrated {
+		if response := v.validateKeyCountInDB(snsObject, renamedFrom); !response.Allowed {
+			return response
+		}
 	}
 
 	if response := v.validateSNSUnderRP(snsObject, isSNSResourcePool); !response.Allowed {
@@ -49,13 +78,45 @@ func (v *SubnamespaceValidator) handleCreate(snsObject *objectcontext.ObjectCont
 		return rsp
 	}
 
-	if rsp := v.validateEnoughResourcesInParentSNS(snsObject); !rsp.Allowed {
+	if !isMigrated {
+		if rsp := v.validateEnoughResourcesInParentSNS(snsObject, renamedFrom); !rsp.Allowed {
+			return rsp
+		}
+	}
+
+	if rsp := validateLimitRangePolicy(snsObject); !rsp.Allowed {
 		return rsp
 	}
 
+	// the owners of a subnamespace which is migrated or renamed by HNS were already validated
+	if !isMigrated && renamedFrom == "" {
+		if rsp := v.validateOwners(snsObject, nil, userInfo); !rsp.Allowed {
+			return rsp
+		}
+	}
+
 	return admission.Allowed("")
 }
 
+// renamedFromSNS returns the name of the subnamespace which a subnamespace is renamed from, which is its
+// last previous name, if a subnamespace with this name still exists under the same parent. Otherwise, it
+// returns an empty string.
+func renamedFromSNS(snsObject *objectcontext.ObjectContext) string {
+	previousNames := snsObject.Object.GetAnnotations()[danav1.PreviousNames]
+	if previousNames == "" {
+		return ""
+	}
+	names := strings.Split(previousNames, ",")
+	name := names[len(names)-1]
+
+	oldSNS, err := objectcontext.New(snsObject.Ctx, snsObject.Client, types.NamespacedName{Name: name, Namespace: snsObject.Namespace()}, &danav1.Subnamespace{})
+	if err != nil || !oldSNS.IsPresent() {
+		return ""
+	}
+
+	return name
+}
+
 // validateSubnamespaceName validate name for subnamespace according to RFC 1123, to match namespace name validation.
 func (v *SubnamespaceValidator) validateSubnamespaceName(snsObject *objectcontext.ObjectContext) admission.Response {
 	snsName := snsObject.Name()
@@ -92,6 +153,36 @@ func (v *SubnamespaceValidator) validateUniqueSNSName(snsObject *objectcontext.O
 	return admission.Allowed("")
 }
 
+// validateCreateChildPermissions validates that the user is allowed to create children in the namespace the
+// subnamespace is created in, which is granted by the createchild verb on subnamespaces, e.g. through the
+// hns-structure-admin ClusterRole. Being allowed to create subnamespaces is not enough on its own, so that
+// users who only manage the quota of a namespace can't change its structure.
+func (v *SubnamespaceValidator) validateCreateChildPermissions(snsObject *objectcontext.ObjectContext, userInfo authenticationv1.UserInfo) admission.Response {
+	ctx := snsObject.Ctx
+	parentNSName := snsObject.Object.GetNamespace()
+
+	canManage, err := common.CanManageHNSObjects(ctx, userInfo.Username, v.Client)
+	if err != nil {
+		return admission.Errored(http.StatusInternalServerError, err)
+	}
+	if canManage {
+		return admission.Allowed("")
+	}
+
+	allowed, err := v.Authorizer.Authorize(ctx, userInfo, common.CreateChildVerb, parentNSName)
+	if err != nil {
+		return admission.Errored(http.StatusInternalServerError, err)
+	}
+
+	if !allowed {
+		message := fmt.Sprintf("you must be allowed to %q subnamespaces in %q to create a subnamespace in it",
+			common.CreateChildVerb, parentNSName)
+		return admission.Denied(message)
+	}
+
+	return admission.Allowed("")
+}
+
 // validateResourcePoolOnly validates whether only ResourcePools can be created
 // in accordance to a set environment variable
 func (v *SubnamespaceValidator) validateResourcePoolOnly(isSNSResourcePool bool) admission.Response {
@@ -105,12 +196,27 @@ func (v *SubnamespaceValidator) validateResourcePoolOnly(isSNSResourcePool bool)
 
 // validateKeyCountInDB validates that creating a new subnamespace under a given parent
 // will not cause the new parent to exceed the maximum limit of namespaces in its hierarchy.
-func (v *SubnamespaceValidator) validateKeyCountInDB(snsObject *objectcontext.ObjectContext) admission.Response {
+func (v *SubnamespaceValidator) validateKeyCountInDB(snsObject *objectcontext.ObjectContext, renamedFrom string) admission.Response {
 	parentSNSName := snsObject.Object.GetNamespace()
-	key := v.NamespaceDB.Key(parentSNSName)
+	parentNS, err := objectcontext.New(snsObject.Ctx, snsObject.Client, types.NamespacedName{Name: parentSNSName}, &corev1.Namespace{})
+	if err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+
+	root, err := nsutils.Root(parentNS.Object)
+	if err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+
+	key := v.NamespaceDB.Key(root, parentSNSName)
 
 	if key != "" {
-		if v.NamespaceDB.KeyCount(key) >= v.MaxSNS {
+		count := v.NamespaceDB.KeyCount(root, key)
+		// a subnamespace which is renamed takes the place of the subnamespace it's renamed from in the hierarchy
+		if renamedFrom != "" {
+			count--
+		}
+		if count >= v.MaxSNS {
 			message := fmt.Sprintf("it's forbidden to create more than '%v' namespaces under hierarchy %q", v.MaxSNS, key)
 			return admission.Denied(message)
 		}
@@ -143,7 +249,7 @@ func (v *SubnamespaceValidator) validateSNSUnderRP(snsObject *objectcontext.Obje
 
 // validateEnoughResourcesInParentSNS validates that there are enough resources available in a parent subnamespace
 // to create a new subnamespace with certain resources under it.
-func (v *SubnamespaceValidator) validateEnoughResourcesInParentSNS(snsObject *objectcontext.ObjectContext) admission.Response {
+func (v *SubnamespaceValidator) validateEnoughResourcesInParentSNS(snsObject *objectcontext.ObjectContext, renamedFrom string) admission.Response {
 	logger := log.FromContext(snsObject.Ctx)
 
 	snsName := snsObject.Name()
@@ -157,7 +263,15 @@ func (v *SubnamespaceValidator) validateEnoughResourcesInParentSNS(snsObject *ob
 
 	quotaParent := quota.GetQuotaObjectSpec(parentQuotaObject.Object).Hard
 	quotaSNS := quota.SubnamespaceSpec(snsObject.Object).Hard
-	siblingsResources := quota.GetQuotaObjectsListResources(quota.SubnamespaceSiblingObjects(snsObject))
+
+	// the resources of the subnamespace which is renamed are allocated to the subnamespace it's renamed to
+	var siblings []*objectcontext.ObjectContext
+	for _, sibling := range quota.SubnamespaceSiblingObjects(snsObject) {
+		if renamedFrom == "" || sibling.Name() != renamedFrom {
+			siblings = append(siblings, sibling)
+		}
+	}
+	siblingsResources := quota.GetQuotaObjectsListResources(siblings)
 
 	for resourceName := range quotaParent {
 		var (
@@ -168,7 +282,7 @@ func (v *SubnamespaceValidator) validateEnoughResourcesInParentSNS(snsObject *ob
 
 		parent.Sub(siblings)
 		parent.Sub(request)
-		if parent.Value() < 0 {
+		if parent.Sign() < 0 {
 			message := fmt.Sprintf("it's forbidden to create subnamespace %q under %q when there are are not "+
 				"enough resources of type %q in %q", snsName, snsParentName, resourceName.String(), snsParentName)
 			return admission.Denied(message)
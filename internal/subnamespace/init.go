@@ -112,7 +112,10 @@ func (r *SubnamespaceReconciler) init(snsParentNS, snsObject *objectcontext.Obje
 // createSNSNamespace makes sure a namespace is created for subnamespace.
 func createSNSNamespace(snsParentNS, snsObject *objectcontext.ObjectContext) error {
 	snsName := snsObject.Name()
-	snsNamespace := composeSNSNamespace(snsParentNS, snsObject)
+	snsNamespace, err := composeSNSNamespace(snsParentNS, snsObject)
+	if err != nil {
+		return err
+	}
 
 	snsNamespaceObject, err := objectcontext.New(snsObject.Ctx, snsObject.Client, types.NamespacedName{Name: snsName}, snsNamespace)
 	if err != nil {
@@ -150,13 +153,21 @@ func syncNSLabelsAnnotations(snsNamespace *corev1.Namespace, snsNamespaceObject
 
 // composeSNSNamespace creates a new namespace for a particular subnamespace with labels
 // and annotations based on the namespace linked to the parent of the subnamespace.
-func composeSNSNamespace(snsParentNS, snsObject *objectcontext.ObjectContext) *corev1.Namespace {
+func composeSNSNamespace(snsParentNS, snsObject *objectcontext.ObjectContext) (*corev1.Namespace, error) {
 	nsName := snsObject.Name()
-	labels := nsutils.LabelsBasedOnParent(snsParentNS, nsName)
+	labels, err := nsutils.LabelsBasedOnParent(snsParentNS, nsName)
+	if err != nil {
+		return nil, err
+	}
 	annotations := nsutils.AnnotationsBasedOnParent(snsParentNS, nsName)
 
 	// add the ResourcePool label separately from the function
 	labels[danav1.ResourcePool] = snsObject.Object.GetLabels()[danav1.ResourcePool]
 
-	return nsutils.ComposeNamespace(nsName, labels, annotations)
+	// keep the previous names of a renamed subnamespace on its namespace
+	if previousNames := snsObject.Object.GetAnnotations()[danav1.PreviousNames]; previousNames != "" {
+		annotations[danav1.PreviousNames] = previousNames
+	}
+
+	return nsutils.ComposeNamespace(nsName, labels, annotations), nil
 }
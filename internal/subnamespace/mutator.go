@@ -0,0 +1,53 @@
+package subnamespace
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	danav1 "github.com/dana-team/hns/api/v1"
+	"github.com/dana-team/hns/internal/common"
+	"github.com/dana-team/hns/internal/quota"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+type SubnamespaceMutator struct {
+	Client  client.Client
+	Decoder admission.Decoder
+}
+
+// +kubebuilder:webhook:path=/mutate-v1-subnamespace,mutating=true,sideEffects=NoneOnDryRun,failurePolicy=fail,groups="dana.hns.io",resources=subnamespaces,verbs=create;update,versions=v1,name=subnamespace.dana.io,admissionReviewVersions=v1;v1beta1
+
+// Handle implements the mutation webhook.
+func (m *SubnamespaceMutator) Handle(ctx context.Context, req admission.Request) admission.Response {
+	logger := log.FromContext(ctx).WithValues("webhook", "Subnamespace mutation Webhook", "Name", req.Name)
+	logger.Info("webhook request received")
+
+	sns := danav1.Subnamespace{}
+	if err := m.Decoder.DecodeRaw(req.Object, &sns); err != nil {
+		logger.Error(err, "failed to decode object", "request object", req.Object)
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+
+	aliases, err := common.GetResourceAliases(ctx, m.Client)
+	if err != nil {
+		logger.Error(err, "failed to get resource aliases")
+		return admission.Errored(http.StatusInternalServerError, err)
+	}
+
+	quotaSpec, err := quota.NormalizeResourceQuotaSpec(sns.Spec.ResourceQuotaSpec, aliases)
+	if err != nil {
+		return admission.Denied(err.Error())
+	}
+	sns.Spec.ResourceQuotaSpec = quotaSpec
+
+	marshalSNS, err := json.Marshal(sns)
+	if err != nil {
+		logger.Error(err, "failed to marshal object", "object", sns)
+		return admission.Errored(http.StatusInternalServerError, err)
+	}
+
+	return admission.PatchResponseFromRaw(req.Object.Raw, marshalSNS)
+}
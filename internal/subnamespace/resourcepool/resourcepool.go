@@ -6,7 +6,6 @@ import (
 	danav1 "github.com/dana-team/hns/api/v1"
 	"github.com/dana-team/hns/internal/namespace/nsutils"
 	"github.com/dana-team/hns/internal/objectcontext"
-	"github.com/go-logr/logr"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -154,11 +153,7 @@ func SetSNSResourcePoolLabel(snsParentNS, snsObject *objectcontext.ObjectContext
 		return err
 	}
 
-	if err := snsObject.UpdateObject(func(object client.Object, log logr.Logger) (client.Object, logr.Logger) {
-		log = log.WithValues(danav1.ResourcePool, isResourcePool)
-		object.SetLabels(map[string]string{danav1.ResourcePool: strconv.FormatBool(isResourcePool)})
-		return object, log
-	}); err != nil {
+	if err := snsObject.AppendLabels(map[string]string{danav1.ResourcePool: strconv.FormatBool(isResourcePool)}); err != nil {
 		return err
 	}
 
@@ -2,6 +2,8 @@ package snsutils
 
 import (
 	"fmt"
+	"sort"
+	"strconv"
 
 	danav1 "github.com/dana-team/hns/api/v1"
 	"github.com/dana-team/hns/internal/namespace/nsutils"
@@ -64,30 +66,43 @@ func GetRqDepthFromSNS(sns *objectcontext.ObjectContext) (string, error) {
 	return rootNS.Annotations[danav1.RqDepth], nil
 }
 
-// GetAllChildren returns a slice of all the descendants of a namespace or subnamespace, including it itself
+// GetAllChildren returns a slice of all the descendants of a namespace or subnamespace, including it itself.
+// The descendants are found using the tree label of the namespace and are ordered by their distance from it.
 func GetAllChildren(obj *objectcontext.ObjectContext) []*objectcontext.ObjectContext {
 	if obj == nil {
 		return []*objectcontext.ObjectContext{}
 	}
 
-	children, err := objectcontext.NewList(obj.Ctx, obj.Client, &danav1.SubnamespaceList{}, client.InNamespace(obj.Name()))
+	treeLabel := nsutils.TreeLabel(obj.Name())
+	descendants, err := objectcontext.NewList(obj.Ctx, obj.Client, &corev1.NamespaceList{}, client.HasLabels{treeLabel})
 	if err != nil {
 		return nil
 	}
 
-	var subspaceDescendant []*objectcontext.ObjectContext
-	for _, objectItem := range children.Objects.(*danav1.SubnamespaceList).Items {
-		var object *objectcontext.ObjectContext
+	descendantNamespaces := descendants.Objects.(*corev1.NamespaceList).Items
+	sort.SliceStable(descendantNamespaces, func(i, j int) bool {
+		distanceI, _ := strconv.Atoi(descendantNamespaces[i].Labels[treeLabel])
+		distanceJ, _ := strconv.Atoi(descendantNamespaces[j].Labels[treeLabel])
+		return distanceI < distanceJ
+	})
+
+	subspaceDescendant := []*objectcontext.ObjectContext{obj}
+	for _, descendant := range descendantNamespaces {
+		if descendant.Name == obj.Name() {
+			continue
+		}
 
+		var object *objectcontext.ObjectContext
 		if nsutils.IsNamespace(obj.Object) {
-			object, _ = objectcontext.New(obj.Ctx, obj.Client, types.NamespacedName{Name: objectItem.GetName()}, &corev1.Namespace{})
+			object, _ = objectcontext.New(obj.Ctx, obj.Client, types.NamespacedName{Name: descendant.Name}, &corev1.Namespace{})
 		} else {
-			object, _ = objectcontext.New(obj.Ctx, obj.Client, types.NamespacedName{Name: objectItem.GetName(), Namespace: objectItem.GetNamespace()}, &danav1.Subnamespace{})
+			object, _ = objectcontext.New(obj.Ctx, obj.Client, types.NamespacedName{Name: descendant.Name, Namespace: nsutils.Parent(&descendant)}, &danav1.Subnamespace{})
 		}
 
-		childrenObject := GetAllChildren(object)
-		subspaceDescendant = append(subspaceDescendant, childrenObject...)
+		if object != nil {
+			subspaceDescendant = append(subspaceDescendant, object)
+		}
 	}
 
-	return append([]*objectcontext.ObjectContext{obj}, subspaceDescendant...)
+	return subspaceDescendant
 }
@@ -3,6 +3,8 @@ package subnamespace
 import (
 	"context"
 	"fmt"
+	"slices"
+	"strings"
 
 	"github.com/dana-team/hns/internal/common"
 
@@ -13,15 +15,13 @@ import (
 	"github.com/dana-team/hns/internal/namespacedb"
 	"github.com/dana-team/hns/internal/objectcontext"
 	"github.com/dana-team/hns/internal/quota"
+	"github.com/dana-team/hns/internal/rolebinding/rbutils"
 	"github.com/dana-team/hns/internal/subnamespace/resourcepool"
 	"github.com/go-logr/logr"
 	corev1 "k8s.io/api/core/v1"
-	"k8s.io/apimachinery/pkg/api/resource"
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
-	"sigs.k8s.io/controller-runtime/pkg/event"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 )
 
@@ -36,12 +36,12 @@ func (r *SubnamespaceReconciler) sync(snsParentNS, snsObject *objectcontext.Obje
 
 	// compute the current resources allocated by the synced subnamesapce to its children
 	// and the allocated still free to allocate
-	snsChildren, err := objectcontext.NewList(snsObject.Ctx, snsObject.Client, &danav1.SubnamespaceList{}, client.InNamespace(snsName))
+	childrenRequests, listed, err := r.getSNSChildrenRequests(snsObject)
 	if err != nil {
 		return ctrl.Result{}, fmt.Errorf("failed to get children subnamespace objects under namespace %q: %v", snsName, err.Error())
 	}
-	childrenRequests, resourceAllocatedToChildren := getResourcesAllocatedToSNSChildren(snsChildren)
-	free := getFreeToAllocateSNSResources(snsObject, resourceAllocatedToChildren)
+	resourceAllocatedToChildren := getResourcesAllocatedToSNSChildren(childrenRequests)
+	free := getFreeToAllocateSNSResources(snsObject.Object.(*danav1.Subnamespace).Spec.ResourceQuotaSpec.Hard, resourceAllocatedToChildren)
 	if resourcepool.SNSLabel(snsObject.Object) == "" {
 		if err := resourcepool.SetSNSResourcePoolLabel(snsParentNS, snsObject); err != nil {
 			return ctrl.Result{}, fmt.Errorf("failed to set ResourcePool label for subnamespace %q: %v", snsName, err.Error())
@@ -50,6 +50,15 @@ func (r *SubnamespaceReconciler) sync(snsParentNS, snsObject *objectcontext.Obje
 		return ctrl.Result{Requeue: true}, nil
 	}
 
+	// subnamespaces created before resource aliases were defined in the HNSConfig may use
+	// resource aliases in their spec, so they are migrated to use the canonical resource names
+	if normalized, err := normalizeSNSQuotaSpec(snsObject); err != nil {
+		return ctrl.Result{}, fmt.Errorf("failed to normalize resources of subnamespace %q: %v", snsName, err.Error())
+	} else if normalized {
+		logger.Info("successfully normalized resources of subnamespace", "subnamespace", snsName)
+		return ctrl.Result{Requeue: true}, nil
+	}
+
 	rqFlag, err := quota.IsRQ(snsObject, danav1.SelfOffset)
 	if err != nil {
 		return ctrl.Result{}, fmt.Errorf("failed to compute isRq flag for subnamespace %q: %v", snsName, err.Error())
@@ -131,6 +140,26 @@ func (r *SubnamespaceReconciler) sync(snsParentNS, snsObject *objectcontext.Obje
 	}
 	logger.Info("successfully synced annotations for subnamespace", "subnamespace", snsName)
 
+	limitRangeChanged, err := quota.SyncSNSLimitRange(snsObject)
+	if err != nil {
+		return ctrl.Result{}, fmt.Errorf("failed to sync LimitRange for subnamespace %q: %v", snsName, err.Error())
+	}
+	logger.Info("successfully synced LimitRange for subnamespace", "subnamespace", snsName)
+
+	// the children of the subnamespace inherit its limits, so their LimitRange
+	// objects have to be synced as well whenever the limits of the subnamespace change
+	if limitRangeChanged {
+		for _, child := range childrenRequests {
+			r.enqueueSNSEvent(child.Namespace, snsName)
+		}
+		logger.Info("successfully enqueued children subnamespaces for reconciliation", "subnamespace", snsName)
+	}
+
+	if err := rbutils.EnsureOwnerRoleBindings(snsObject); err != nil {
+		return ctrl.Result{}, fmt.Errorf("failed to sync owner roleBindings for subnamespace %q: %v", snsName, err.Error())
+	}
+	logger.Info("successfully synced owner roleBindings for subnamespace", "subnamespace", snsName)
+
 	if err := namespacedb.EnsureSNSInDB(ctx, snsObject, r.NamespaceDB); err != nil {
 		return ctrl.Result{}, fmt.Errorf("failed to ensure presence in namespacedb for subnamespace %q: %v", snsObject.Name(), err.Error())
 	}
@@ -141,24 +170,41 @@ func (r *SubnamespaceReconciler) sync(snsParentNS, snsObject *objectcontext.Obje
 			return ctrl.Result{}, fmt.Errorf("failed to set status for subnamespace %q: %v", snsName, err.Error())
 		}
 	}
+	if listed {
+		r.listedChildren.Store(types.NamespacedName{Name: snsName, Namespace: snsParentName}, true)
+	}
 	logger.Info("successfully set status for subnamespace", "subnamespace", snsName)
 
-	updateSNSMetrics(snsName, snsParentName, resourceAllocatedToChildren, free, snsObject.Object.(*danav1.Subnamespace).Spec.ResourceQuotaSpec.Hard)
+	root, err := nsutils.Root(snsParentNS.Object)
+	if err != nil {
+		return ctrl.Result{}, fmt.Errorf("failed to get root namespace of subnamespace %q: %v", snsName, err.Error())
+	}
+
+	updateSNSMetrics(snsName, snsParentName, root, resourceAllocatedToChildren, free, snsObject.Object.(*danav1.Subnamespace).Spec.ResourceQuotaSpec.Hard)
 	logger.Info("successfully set metrics for subnamespace", "subnamespace", snsName)
 
-	// trigger reconciliation for parent subnamespace so that it can be aware of
-	// potential changes in one of its children
-	r.enqueueSNSEvent(parentSNS.Name(), parentSNS.Object.GetNamespace())
-	logger.Info("successfully enqueued parent subnamespace for reconcliation", "parent subnamespace", parentSNS.Name(), "subnamespace", snsName)
+	// the subnamespace keeps its own entry in the status of its parent subnamespace up-to-date, so that the status
+	// of the parent is updated incrementally rather than computed again from all of its children. The parent is then
+	// triggered for reconciliation so that its metrics are updated, and the reconciliations triggered by the children
+	// of the parent within the parent sync window are coalesced into a single one
+	if !isParentStatusUpToDate(snsObject, parentSNS) {
+		if err := updateParentStatus(snsObject, parentSNS); err != nil {
+			return ctrl.Result{}, fmt.Errorf("failed to set status for parent subnamespace %q: %v", parentSNS.Name(), err.Error())
+		}
+		logger.Info("successfully set status for parent subnamespace", "parent subnamespace", parentSNS.Name(), "subnamespace", snsName)
+
+		r.SNSEvents.EnqueueAfter(parentSNS.Name(), parentSNS.Object.GetNamespace(), r.ParentSyncWindow)
+		logger.Info("successfully enqueued parent subnamespace for reconcliation", "parent subnamespace", parentSNS.Name(), "subnamespace", snsName)
+	}
 
-	if err := r.enqueueChildrenRPToSNSConversionEvents(snsObject, snsChildren); err != nil {
+	if err := r.enqueueChildrenRPToSNSConversionEvents(snsObject, childrenRequests); err != nil {
 		return ctrl.Result{}, fmt.Errorf("failed to enqueue children subnamespaces of subnamespace %q for reconciliation: %v", snsName, err.Error())
 	}
 
 	// trigger the child subnamespaces if the subnamespace was converted into a ResourcePool
 	// it will update the isUpperRp annotation and the quotas accordingly
 	if isSNSResourcePool {
-		if err := r.enqueueChildrenSNSToRPConversionEvents(snsObject, snsChildren); err != nil {
+		if err := r.enqueueChildrenSNSToRPConversionEvents(snsObject, childrenRequests); err != nil {
 			return ctrl.Result{}, fmt.Errorf("failed to enqueue children subnamespaces of subnamespace %q for reconciliation: %v", snsName, err.Error())
 		}
 	}
@@ -169,6 +215,33 @@ func (r *SubnamespaceReconciler) sync(snsParentNS, snsObject *objectcontext.Obje
 	return ctrl.Result{}, nil
 }
 
+// normalizeSNSQuotaSpec replaces the resource aliases in the spec of a subnamespace with their canonical names.
+// It returns whether the spec of the subnamespace was changed.
+func normalizeSNSQuotaSpec(snsObject *objectcontext.ObjectContext) (bool, error) {
+	aliases, err := common.GetResourceAliases(snsObject.Ctx, snsObject.Client)
+	if err != nil {
+		return false, err
+	}
+
+	quotaSpec := quota.SubnamespaceSpec(snsObject.Object)
+	if quota.IsResourceListNormalized(quotaSpec.Hard, aliases) {
+		return false, nil
+	}
+
+	normalizedQuotaSpec, err := quota.NormalizeResourceQuotaSpec(quotaSpec, aliases)
+	if err != nil {
+		return false, err
+	}
+
+	err = snsObject.UpdateObject(func(object client.Object, log logr.Logger) (client.Object, logr.Logger) {
+		log = log.WithValues("updated subnamespace", "normalized spec.resourcequota")
+		object.(*danav1.Subnamespace).Spec.ResourceQuotaSpec = normalizedQuotaSpec
+		return object, log
+	})
+
+	return true, err
+}
+
 // updateSNSResourcesStatus updates the resources-related fields of the status of a subnamespace object.
 func updateSNSResourcesStatus(snsObject *objectcontext.ObjectContext, childrenRequests []danav1.Namespaces, allocated, free corev1.ResourceList) error {
 	return snsObject.UpdateObject(func(object client.Object, log logr.Logger) (client.Object, logr.Logger) {
@@ -179,53 +252,122 @@ func updateSNSResourcesStatus(snsObject *objectcontext.ObjectContext, childrenRe
 	})
 }
 
-// getResourcesAllocatedToSNSChildren returns a ResourceList which is the computation of the
-// total quantity of resources allocated to the children of a subnamespace.
-func getResourcesAllocatedToSNSChildren(snsChildren *objectcontext.ObjectContextList) ([]danav1.Namespaces, corev1.ResourceList) {
-	var childrenRequests []danav1.Namespaces
-	var resourceAllocatedToChildren = corev1.ResourceList{}
+// getSNSChildrenRequests returns the children of a subnamespace along with their quota, sorted by name. They are
+// taken from the status of the subnamespace, whose entries are kept up-to-date by the children themselves. The
+// children are listed only in the first sync of the subnamespace since HNS started, since children which were
+// deleted while HNS was down could not remove their entries. It also returns whether the children were listed.
+func (r *SubnamespaceReconciler) getSNSChildrenRequests(snsObject *objectcontext.ObjectContext) ([]danav1.Namespaces, bool, error) {
+	if _, ok := r.listedChildren.Load(types.NamespacedName{Name: snsObject.Name(), Namespace: snsObject.Namespace()}); ok {
+		return snsObject.Object.(*danav1.Subnamespace).Status.Namespaces, false, nil
+	}
 
+	snsChildren, err := objectcontext.NewList(snsObject.Ctx, snsObject.Client, &danav1.SubnamespaceList{}, client.InNamespace(snsObject.Name()))
+	if err != nil {
+		return nil, false, err
+	}
+
+	var childrenRequests []danav1.Namespaces
 	for _, childSNS := range snsChildren.Objects.(*danav1.SubnamespaceList).Items {
-		var childNameQuotaPair = danav1.Namespaces{
+		childrenRequests = append(childrenRequests, danav1.Namespaces{
 			Namespace:         childSNS.GetName(),
 			ResourceQuotaSpec: childSNS.Spec.ResourceQuotaSpec,
-		}
-		childrenRequests = append(childrenRequests, childNameQuotaPair)
-
-		childSNSRequest := childSNS.Spec.ResourceQuotaSpec.Hard
-		for resourceName := range childSNS.Spec.ResourceQuotaSpec.Hard {
-			var (
-				totalRequest, _ = resourceAllocatedToChildren[resourceName]
-				vRequest, _     = childSNSRequest[resourceName]
-			)
-			resourceAllocatedToChildren[resourceName] = *resource.NewQuantity(vRequest.Value()+totalRequest.Value(), resource.BinarySI)
-		}
+		})
 	}
+	sortChildrenRequests(childrenRequests)
 
-	return childrenRequests, resourceAllocatedToChildren
+	return childrenRequests, true, nil
+}
+
+// getResourcesAllocatedToSNSChildren returns a ResourceList which is the computation of the
+// total quantity of resources allocated to the children of a subnamespace.
+func getResourcesAllocatedToSNSChildren(childrenRequests []danav1.Namespaces) corev1.ResourceList {
+	var resourceAllocatedToChildren = corev1.ResourceList{}
+
+	for _, child := range childrenRequests {
+		resourceAllocatedToChildren = quota.AddResourceLists(resourceAllocatedToChildren, child.ResourceQuotaSpec.Hard)
+	}
+
+	return resourceAllocatedToChildren
 }
 
 // getFreeToAllocateSNSResources computes the resources that are still free to allocate by
 // looking at the total available resources in the subnamespace spec and the currently allocated resources.
-func getFreeToAllocateSNSResources(snsObject *objectcontext.ObjectContext, allocated corev1.ResourceList) corev1.ResourceList {
+func getFreeToAllocateSNSResources(hard, allocated corev1.ResourceList) corev1.ResourceList {
 	var freeToAllocate = corev1.ResourceList{}
 
-	for resourceName := range snsObject.Object.(*danav1.Subnamespace).Spec.ResourceQuotaSpec.Hard {
-
-		var (
-			totalRequest, _ = allocated[resourceName]
-			vRequest, _     = snsObject.Object.(*danav1.Subnamespace).Spec.ResourceQuotaSpec.Hard[resourceName]
-		)
-		value := vRequest.Value() - totalRequest.Value()
-		freeToAllocate[resourceName] = *resource.NewQuantity(value, resource.BinarySI)
+	for resourceName, quantity := range hard {
+		free := quantity.DeepCopy()
+		free.Sub(allocated[resourceName])
+		freeToAllocate[resourceName] = free
 	}
 
 	return freeToAllocate
 }
 
+// sortChildrenRequests sorts the children of a subnamespace by name, so that the status of a subnamespace
+// doesn't depend on the order in which the entries of its children were added.
+func sortChildrenRequests(childrenRequests []danav1.Namespaces) {
+	slices.SortFunc(childrenRequests, func(a, b danav1.Namespaces) int {
+		return strings.Compare(a.Namespace, b.Namespace)
+	})
+}
+
+// updateParentStatus sets the entry of a subnamespace in the status of its parent subnamespace to the
+// current quota of the subnamespace, and updates the resources allocated by the parent and still free
+// to allocate accordingly. The status is updated based on the latest version of the parent, so children
+// which update the status of the same parent concurrently don't override each other's entries.
+func updateParentStatus(snsObject, parentSNS *objectcontext.ObjectContext) error {
+	entry := danav1.Namespaces{
+		Namespace:         snsObject.Name(),
+		ResourceQuotaSpec: snsObject.Object.(*danav1.Subnamespace).Spec.ResourceQuotaSpec,
+	}
+
+	return parentSNS.UpdateObject(func(object client.Object, log logr.Logger) (client.Object, logr.Logger) {
+		parent := object.(*danav1.Subnamespace)
+		children := slices.DeleteFunc(parent.Status.Namespaces, func(child danav1.Namespaces) bool {
+			return child.Namespace == entry.Namespace
+		})
+		children = append(children, entry)
+		sortChildrenRequests(children)
+
+		setSNSChildrenStatus(parent, children)
+		return object, log.WithValues("updated status entry", entry.Namespace)
+	})
+}
+
+// removeFromParentStatus removes the entry of a subnamespace which was deleted from the status of its parent
+// subnamespace, and updates the resources allocated by the parent and still free to allocate accordingly.
+func removeFromParentStatus(parentSNS *objectcontext.ObjectContext, snsName string) error {
+	if !slices.ContainsFunc(parentSNS.Object.(*danav1.Subnamespace).Status.Namespaces, func(child danav1.Namespaces) bool {
+		return child.Namespace == snsName
+	}) {
+		return nil
+	}
+
+	return parentSNS.UpdateObject(func(object client.Object, log logr.Logger) (client.Object, logr.Logger) {
+		parent := object.(*danav1.Subnamespace)
+		children := slices.DeleteFunc(parent.Status.Namespaces, func(child danav1.Namespaces) bool {
+			return child.Namespace == snsName
+		})
+
+		setSNSChildrenStatus(parent, children)
+		return object, log.WithValues("removed status entry", snsName)
+	})
+}
+
+// setSNSChildrenStatus sets the children of a subnamespace in its status, along with the resources
+// allocated to them and the resources still free to allocate.
+func setSNSChildrenStatus(sns *danav1.Subnamespace, childrenRequests []danav1.Namespaces) {
+	allocated := getResourcesAllocatedToSNSChildren(childrenRequests)
+
+	sns.Status.Namespaces = childrenRequests
+	sns.Status.Total.Allocated = allocated
+	sns.Status.Total.Free = getFreeToAllocateSNSResources(sns.Spec.ResourceQuotaSpec.Hard, allocated)
+}
+
 // syncSNSAnnotations syncs subnamespace annotations.
 func syncSNSAnnotations(snsObject, snsParentNS, parentSNS *objectcontext.ObjectContext, isRq bool) error {
-	annotations := snsObject.Object.GetAnnotations()
+	annotations := map[string]string{}
 
 	if isRq {
 		annotations[danav1.IsRq] = danav1.True
@@ -321,51 +463,38 @@ func syncQuotaObject(snsObject *objectcontext.ObjectContext, isRq bool) (ctrl.Re
 	return ctrl.Result{}, nil
 }
 
-// enqueueSNSEvent enqueues subanmespace events in the SnsEvents channel to trigger SNS reconciliation.
+// enqueueSNSEvent enqueues a subnamespace to trigger SNS reconciliation.
 func (r *SubnamespaceReconciler) enqueueSNSEvent(snsName, snsNamespace string) {
-	r.SNSEvents <- event.GenericEvent{Object: &danav1.Subnamespace{
-		ObjectMeta: metav1.ObjectMeta{
-			Name:      snsName,
-			Namespace: snsNamespace,
-		},
-	}}
+	r.SNSEvents.Enqueue(snsName, snsNamespace)
 }
 
-// enqueueSNSNamespaceEvent enqueues namespace events in the ResourcePoolEvents channel to trigger NS reconciliation.
+// enqueueSNSNamespaceEvent enqueues the namespace of a subnamespace to trigger NS reconciliation.
 func (r *SubnamespaceReconciler) enqueueSNSNamespaceEvent(snsName string) {
-	r.NSEvents <- event.GenericEvent{Object: &corev1.Namespace{
-		ObjectMeta: metav1.ObjectMeta{
-			Name:   snsName,
-			Labels: map[string]string{danav1.Hns: "true"},
-		},
-	}}
+	r.NSEvents.Enqueue(snsName, "")
 }
 
 // enqueueChildrenRPToSNSConversionEvents enqueues children subanmespace events
 // in cases where the subnamespace was converted from ResourcePool to regular subnamespace.
-func (r *SubnamespaceReconciler) enqueueChildrenRPToSNSConversionEvents(snsObject *objectcontext.ObjectContext, snsChildren *objectcontext.ObjectContextList) error {
+func (r *SubnamespaceReconciler) enqueueChildrenRPToSNSConversionEvents(snsObject *objectcontext.ObjectContext, childrenRequests []danav1.Namespaces) error {
 	snsName := snsObject.Name()
 
-	for _, sns := range snsChildren.Objects.(*danav1.SubnamespaceList).Items {
-		snsChild, err := objectcontext.New(snsObject.Ctx, snsObject.Client, types.NamespacedName{Name: sns.GetName(), Namespace: snsName}, &danav1.Subnamespace{})
+	for _, child := range childrenRequests {
+		snsChild, err := objectcontext.New(snsObject.Ctx, snsObject.Client, types.NamespacedName{Name: child.Namespace, Namespace: snsName}, &danav1.Subnamespace{})
 		if err != nil {
 			return err
 		}
 
+		if !snsChild.IsPresent() {
+			continue
+		}
+
 		isResourcePool, err := resourcepool.IsChildUpper(snsObject.Object, snsChild.Object)
 		if err != nil {
 			return err
 		}
 
 		if isResourcePool {
-			r.enqueueSNSEvent(sns.GetName(), snsName)
-
-			r.SNSEvents <- event.GenericEvent{Object: &danav1.Subnamespace{
-				ObjectMeta: metav1.ObjectMeta{
-					Name:      sns.GetName(),
-					Namespace: snsName,
-				},
-			}}
+			r.enqueueSNSEvent(child.Namespace, snsName)
 		}
 	}
 	return nil
@@ -373,28 +502,40 @@ func (r *SubnamespaceReconciler) enqueueChildrenRPToSNSConversionEvents(snsObjec
 
 // enqueueChildrenSNSToRPConversionEvents enqueues children subanmespace events
 // in cases where the subnamespace was converted from regular subnamespace to ResourcePool.
-func (r *SubnamespaceReconciler) enqueueChildrenSNSToRPConversionEvents(snsObject *objectcontext.ObjectContext, snsChildren *objectcontext.ObjectContextList) error {
+func (r *SubnamespaceReconciler) enqueueChildrenSNSToRPConversionEvents(snsObject *objectcontext.ObjectContext, childrenRequests []danav1.Namespaces) error {
 	snsName := snsObject.Name()
 
-	for _, sns := range snsChildren.Objects.(*danav1.SubnamespaceList).Items {
-		snsChild, err := objectcontext.New(snsObject.Ctx, snsObject.Client, types.NamespacedName{Name: sns.GetName(), Namespace: snsObject.Name()}, &danav1.Subnamespace{})
+	for _, child := range childrenRequests {
+		snsChild, err := objectcontext.New(snsObject.Ctx, snsObject.Client, types.NamespacedName{Name: child.Namespace, Namespace: snsObject.Name()}, &danav1.Subnamespace{})
 		if err != nil {
 			return err
 		}
 		isUpperResourcePool := snsChild.Object.GetAnnotations()[danav1.IsUpperRp]
 		if isUpperResourcePool == danav1.True {
-			r.SNSEvents <- event.GenericEvent{Object: &danav1.Subnamespace{
-				ObjectMeta: metav1.ObjectMeta{
-					Name:      snsChild.Name(),
-					Namespace: snsName,
-				},
-			}}
+			r.SNSEvents.Enqueue(snsChild.Name(), snsName)
 		}
 	}
 
 	return nil
 }
 
+// isParentStatusUpToDate returns whether the status of the parent subnamespace of a subnamespace lists the
+// subnamespace with its current quota. A missing parent subnamespace, as for the children of a root namespace,
+// is always up-to-date.
+func isParentStatusUpToDate(snsObject, parentSNS *objectcontext.ObjectContext) bool {
+	if !parentSNS.IsPresent() {
+		return true
+	}
+
+	for _, child := range parentSNS.Object.(*danav1.Subnamespace).Status.Namespaces {
+		if child.Namespace == snsObject.Name() {
+			return quota.ResourceListEqual(child.ResourceQuotaSpec.Hard, snsObject.Object.(*danav1.Subnamespace).Spec.ResourceQuotaSpec.Hard)
+		}
+	}
+
+	return false
+}
+
 // IsUpdateNeeded gets a subnamespace object, a []danav1.Namespaces and two resource lists and returns whether
 // the subnamespace object status has to be updated.
 func IsUpdateNeeded(ctx context.Context, k8sClient client.Client, sns client.Object, childrenRequests []danav1.Namespaces, allocated, free corev1.ResourceList) bool {
@@ -415,23 +556,30 @@ func NamespacesEqual(ctx context.Context, k8sClient client.Client, nsA, nsB []da
 	if err != nil {
 		return false
 	}
+	aliases, err := common.GetResourceAliases(ctx, k8sClient)
+	if err != nil {
+		return false
+	}
 	for i, nameQuotaPair := range nsA {
-		if !quota.ResourceQuotaSpecEqual(nameQuotaPair.ResourceQuotaSpec, nsB[i].ResourceQuotaSpec, observedResources) {
+		if nameQuotaPair.Namespace != nsB[i].Namespace {
+			return false
+		}
+		if !quota.ResourceQuotaSpecEqual(nameQuotaPair.ResourceQuotaSpec, nsB[i].ResourceQuotaSpec, observedResources, aliases) {
 			return false
 		}
 	}
 	return true
 }
 
-// updateSNSMetrics updates the metrics for the subnamespace.
-func updateSNSMetrics(snsName, snsNS string, allocated, free, total corev1.ResourceList) {
+// updateSNSMetrics updates the metrics for the subnamespace, labelled by the root namespace of its hierarchy.
+func updateSNSMetrics(snsName, snsNS, root string, allocated, free, total corev1.ResourceList) {
 	for resourceName := range total {
 		allocatedResource := allocated[resourceName]
 		freeResource := free[resourceName]
 		totalResource := total[resourceName]
 
-		metrics.ObserveSNSAllocatedResource(snsName, snsNS, resourceName.String(), allocatedResource.AsApproximateFloat64())
-		metrics.ObserveSNSFreeResource(snsName, snsNS, resourceName.String(), freeResource.AsApproximateFloat64())
-		metrics.ObserveSNSTotalResource(snsName, snsNS, resourceName.String(), totalResource.AsApproximateFloat64())
+		metrics.ObserveSNSAllocatedResource(snsName, snsNS, root, resourceName.String(), allocatedResource.AsApproximateFloat64())
+		metrics.ObserveSNSFreeResource(snsName, snsNS, root, resourceName.String(), freeResource.AsApproximateFloat64())
+		metrics.ObserveSNSTotalResource(snsName, snsNS, root, resourceName.String(), totalResource.AsApproximateFloat64())
 	}
 }
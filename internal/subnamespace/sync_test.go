@@ -0,0 +1,151 @@
+package subnamespace
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	danav1 "github.com/dana-team/hns/api/v1"
+	"github.com/dana-team/hns/internal/objectcontext"
+	"github.com/dana-team/hns/internal/quota"
+	"github.com/dana-team/hns/internal/testutils"
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func cpu(quantity string) corev1.ResourceList {
+	return corev1.ResourceList{corev1.ResourceCPU: resource.MustParse(quantity)}
+}
+
+func TestIsParentStatusUpToDate(t *testing.T) {
+	tests := []struct {
+		name           string
+		parentExists   bool
+		parentChildren []danav1.Namespaces
+		want           bool
+	}{
+		{
+			name: "parent does not exist",
+			want: true,
+		},
+		{
+			name:           "parent lists the subnamespace with its quota",
+			parentExists:   true,
+			parentChildren: []danav1.Namespaces{{Namespace: "child", ResourceQuotaSpec: corev1.ResourceQuotaSpec{Hard: cpu("1000m")}}},
+			want:           true,
+		},
+		{
+			name:           "parent lists the subnamespace with a different quota",
+			parentExists:   true,
+			parentChildren: []danav1.Namespaces{{Namespace: "child", ResourceQuotaSpec: corev1.ResourceQuotaSpec{Hard: cpu("2")}}},
+		},
+		{
+			name:           "parent does not list the subnamespace",
+			parentExists:   true,
+			parentChildren: []danav1.Namespaces{{Namespace: "other", ResourceQuotaSpec: corev1.ResourceQuotaSpec{Hard: cpu("1")}}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			objects := []client.Object{&danav1.Subnamespace{
+				ObjectMeta: metav1.ObjectMeta{Name: "child", Namespace: "parent"},
+				Spec:       danav1.SubnamespaceSpec{ResourceQuotaSpec: corev1.ResourceQuotaSpec{Hard: cpu("1")}},
+			}}
+			if tt.parentExists {
+				objects = append(objects, &danav1.Subnamespace{
+					ObjectMeta: metav1.ObjectMeta{Name: "parent", Namespace: "root"},
+					Status:     danav1.SubnamespaceStatus{Namespaces: tt.parentChildren},
+				})
+			}
+			fakeClient := testutils.NewFakeClient(t, objects...)
+
+			ctx := context.Background()
+			snsObject, err := objectcontext.New(ctx, fakeClient, types.NamespacedName{Name: "child", Namespace: "parent"}, &danav1.Subnamespace{})
+			if err != nil {
+				t.Fatalf("failed to get subnamespace: %v", err)
+			}
+			parentSNS, err := objectcontext.New(ctx, fakeClient, types.NamespacedName{Name: "parent", Namespace: "root"}, &danav1.Subnamespace{})
+			if err != nil {
+				t.Fatalf("failed to get subnamespace: %v", err)
+			}
+
+			if got := isParentStatusUpToDate(snsObject, parentSNS); got != tt.want {
+				t.Errorf("expected isParentStatusUpToDate to be %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestUpdateParentStatus(t *testing.T) {
+	// parent has a quota of 10 CPUs, of which 2 are allocated to other
+	fakeClient := testutils.NewFakeClient(t,
+		&danav1.Subnamespace{
+			ObjectMeta: metav1.ObjectMeta{Name: "child", Namespace: "parent"},
+			Spec:       danav1.SubnamespaceSpec{ResourceQuotaSpec: corev1.ResourceQuotaSpec{Hard: cpu("1")}},
+		},
+		&danav1.Subnamespace{
+			ObjectMeta: metav1.ObjectMeta{Name: "parent", Namespace: "root"},
+			Spec:       danav1.SubnamespaceSpec{ResourceQuotaSpec: corev1.ResourceQuotaSpec{Hard: cpu("10")}},
+			Status: danav1.SubnamespaceStatus{
+				Namespaces: []danav1.Namespaces{{Namespace: "other", ResourceQuotaSpec: corev1.ResourceQuotaSpec{Hard: cpu("2")}}},
+				Total:      danav1.Total{Allocated: cpu("2"), Free: cpu("8")},
+			},
+		})
+
+	ctx := context.Background()
+	snsObject, err := objectcontext.New(ctx, fakeClient, types.NamespacedName{Name: "child", Namespace: "parent"}, &danav1.Subnamespace{})
+	if err != nil {
+		t.Fatalf("failed to get subnamespace: %v", err)
+	}
+	parentSNS, err := objectcontext.New(ctx, fakeClient, types.NamespacedName{Name: "parent", Namespace: "root"}, &danav1.Subnamespace{})
+	if err != nil {
+		t.Fatalf("failed to get subnamespace: %v", err)
+	}
+
+	assertStatus := func(children []string, allocated, free corev1.ResourceList) {
+		t.Helper()
+
+		parent := &danav1.Subnamespace{}
+		if err := fakeClient.Get(ctx, types.NamespacedName{Name: "parent", Namespace: "root"}, parent); err != nil {
+			t.Fatalf("failed to get subnamespace: %v", err)
+		}
+
+		var names []string
+		for _, child := range parent.Status.Namespaces {
+			names = append(names, child.Namespace)
+		}
+		if !reflect.DeepEqual(names, children) {
+			t.Errorf("expected the parent to list %v, got %v", children, names)
+		}
+		if !quota.ResourceListEqual(parent.Status.Total.Allocated, allocated) || !quota.ResourceListEqual(parent.Status.Total.Free, free) {
+			t.Errorf("expected allocated %v and free %v, got allocated %v and free %v", allocated, free, parent.Status.Total.Allocated, parent.Status.Total.Free)
+		}
+	}
+
+	if err := updateParentStatus(snsObject, parentSNS); err != nil {
+		t.Fatalf("failed to update parent status: %v", err)
+	}
+	assertStatus([]string{"child", "other"}, cpu("3"), cpu("7"))
+
+	// the entry of a subnamespace is replaced when its quota changes
+	if err := snsObject.UpdateObject(func(object client.Object, log logr.Logger) (client.Object, logr.Logger) {
+		object.(*danav1.Subnamespace).Spec.ResourceQuotaSpec.Hard = cpu("4")
+		return object, log
+	}); err != nil {
+		t.Fatalf("failed to update subnamespace: %v", err)
+	}
+	if err := updateParentStatus(snsObject, parentSNS); err != nil {
+		t.Fatalf("failed to update parent status: %v", err)
+	}
+	assertStatus([]string{"child", "other"}, cpu("6"), cpu("4"))
+
+	if err := removeFromParentStatus(parentSNS, "other"); err != nil {
+		t.Fatalf("failed to remove from parent status: %v", err)
+	}
+	assertStatus([]string{"child"}, cpu("4"), cpu("6"))
+}
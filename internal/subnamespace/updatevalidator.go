@@ -4,10 +4,13 @@ import (
 	"fmt"
 	"net/http"
 
+	danav1 "github.com/dana-team/hns/api/v1"
 	"github.com/dana-team/hns/internal/objectcontext"
 	"github.com/dana-team/hns/internal/quota"
 	"github.com/dana-team/hns/internal/subnamespace/resourcepool"
+	authenticationv1 "k8s.io/api/authentication/v1"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
@@ -15,11 +18,27 @@ import (
 
 // handleUpdate implements the non-boilerplate logic of the validator, allowing it to be more easily unit
 // tested (i.e. without constructing a full admission.Request).
-func (v *SubnamespaceValidator) handleUpdate(snsObject, snsOldObject *objectcontext.ObjectContext) admission.Response {
+func (v *SubnamespaceValidator) handleUpdate(snsObject, snsOldObject *objectcontext.ObjectContext, userInfo authenticationv1.UserInfo) admission.Response {
 	if response := v.validateRPLabelDeletion(snsObject, snsOldObject); !response.Allowed {
 		return response
 	}
 
+	if response := v.validateOwners(snsObject, snsOldObject.Object.(*danav1.Subnamespace).Spec.Owners, userInfo); !response.Allowed {
+		return response
+	}
+
+	if response := v.validateInheritancePolicy(snsObject, snsOldObject.Object.(*danav1.Subnamespace).Spec.InheritancePolicy, userInfo); !response.Allowed {
+		return response
+	}
+
+	// the LimitRange policy is only validated when it changes, since tightening the policy of an
+	// ancestor must not block unrelated updates to subnamespaces with a now looser policy
+	if !equality.Semantic.DeepEqual(snsObject.Object.(*danav1.Subnamespace).Spec.LimitRangeSpec, snsOldObject.Object.(*danav1.Subnamespace).Spec.LimitRangeSpec) {
+		if response := validateLimitRangePolicy(snsObject); !response.Allowed {
+			return response
+		}
+	}
+
 	snsParentName := snsObject.Object.GetNamespace()
 	snsParentNS, err := objectcontext.New(snsObject.Ctx, snsObject.Client, types.NamespacedName{Name: snsParentName}, &corev1.Namespace{})
 	if err != nil {
@@ -120,7 +139,7 @@ func (v *SubnamespaceValidator) validateEnoughResourcesForChildren(snsObject *ob
 		for resourceName, vMy := range quotaRequest {
 			var vChildren, _ = childrenQuotaResources[resourceName]
 			vMy.Sub(vChildren)
-			if vMy.Value() < 0 {
+			if vMy.Sign() < 0 {
 				message := fmt.Sprintf("it's forbidden to update %q to have resource of type %q that are "+
 					"fewer than the resources of type %q that are already allocated to the subnamespace children",
 					snsName, resourceName.String(), resourceName.String())
@@ -162,13 +181,13 @@ func (v *SubnamespaceValidator) validateUpdateSnsRequest(snsObject, snsOldObject
 		parent.Sub(siblings)
 		parent.Sub(request)
 		parent.Add(old)
-		if parent.Value() < 0 {
+		if parent.Sign() < 0 {
 			message := fmt.Sprintf("it's forbidden to update subnamespace %q because there are not enough resources of type %q "+
 				"in parent subnamespace %q to complete the request", snsName, resourceName.String(), snsParentName)
 			return admission.Denied(message)
 		}
 		request.Sub(used)
-		if request.Value() < 0 {
+		if request.Sign() < 0 {
 			message := fmt.Sprintf("it's forbidden to update subnamespace %q because active workloads "+
 				"in the hierarchy of %q request more resources of type %q than the new desired quantity",
 				snsName, snsName, resourceName.String())
@@ -5,6 +5,7 @@ import (
 	"net/http"
 
 	danav1 "github.com/dana-team/hns/api/v1"
+	"github.com/dana-team/hns/internal/common"
 	"github.com/dana-team/hns/internal/namespacedb"
 	"github.com/dana-team/hns/internal/objectcontext"
 	admissionv1 "k8s.io/api/admission/v1"
@@ -20,6 +21,7 @@ type SubnamespaceValidator struct {
 	NamespaceDB *namespacedb.NamespaceDB
 	MaxSNS      int
 	OnlyRP      bool
+	Authorizer  common.Authorizer
 }
 
 // +kubebuilder:webhook:path=/validate-v1-subnamespace,mutating=false,sideEffects=NoneOnDryRun,failurePolicy=fail,groups="dana.hns.io",resources=subnamespaces,verbs=delete;create;update,versions=v1,name=subnamespace.dana.io,admissionReviewVersions=v1;v1beta1
@@ -48,7 +50,7 @@ func (v *SubnamespaceValidator) Handle(ctx context.Context, req admission.Reques
 	}
 
 	if req.Operation == admissionv1.Create {
-		if response := v.handleCreate(snsObject); !response.Allowed {
+		if response := v.handleCreate(snsObject, req.UserInfo); !response.Allowed {
 			return response
 		}
 	}
@@ -65,7 +67,7 @@ func (v *SubnamespaceValidator) Handle(ctx context.Context, req admission.Reques
 			return admission.Errored(http.StatusBadRequest, err)
 		}
 
-		if response := v.handleUpdate(snsObject, snsOldObject); !response.Allowed {
+		if response := v.handleUpdate(snsObject, snsOldObject, req.UserInfo); !response.Allowed {
 			return response
 		}
 	}
@@ -0,0 +1,466 @@
+package subnamespacerename
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	danav1 "github.com/dana-team/hns/api/v1"
+	"github.com/dana-team/hns/internal/common"
+	"github.com/dana-team/hns/internal/enqueuer"
+	"github.com/dana-team/hns/internal/migrationhierarchy"
+	"github.com/dana-team/hns/internal/namespace/nsutils"
+	"github.com/dana-team/hns/internal/namespacedb"
+	"github.com/dana-team/hns/internal/objectcontext"
+	"github.com/dana-team/hns/internal/subnamespace/snsutils"
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/discovery"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// requeueInterval is the interval after which a SubnamespaceRename is reconciled again
+// while it waits for objects of the new namespace to become ready.
+const requeueInterval = 2 * time.Second
+
+// SubnamespaceRenameReconciler reconciles a SubnamespaceRename object
+type SubnamespaceRenameReconciler struct {
+	client.Client
+	Scheme      *runtime.Scheme
+	NamespaceDB *namespacedb.NamespaceDB
+	SNSEvents   *enqueuer.Enqueuer
+	APIReader   client.Reader
+	Discovery   discovery.DiscoveryInterface
+}
+
+// +kubebuilder:rbac:groups=dana.hns.io,resources=subnamespacerenames,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=dana.hns.io,resources=subnamespacerenames/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups="",resources=namespaces,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups="",resources=configmaps,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups="",resources=persistentvolumeclaims,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups="",resources=persistentvolumes,verbs=get;list;watch;update;patch
+// +kubebuilder:rbac:groups="",resources=serviceaccounts,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=apps,resources=deployments,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=*,resources=*,verbs=list
+
+func (r *SubnamespaceRenameReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&danav1.SubnamespaceRename{}).
+		Complete(r)
+}
+
+func (r *SubnamespaceRenameReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := log.FromContext(ctx).WithName("controllers").WithName("SubnamespaceRename").WithValues("snsr", req.NamespacedName)
+	logger.Info("starting to reconcile")
+
+	renameObject, err := objectcontext.New(ctx, r.Client, client.ObjectKey{Name: req.NamespacedName.Name}, &danav1.SubnamespaceRename{})
+	if err != nil {
+		return ctrl.Result{}, fmt.Errorf("failed to get object %q: %v", req.NamespacedName, err.Error())
+	}
+
+	if !renameObject.IsPresent() {
+		logger.Info("resource not found. Ignoring since object must be deleted")
+		return ctrl.Result{}, nil
+	}
+
+	phase := renameObject.Object.(*danav1.SubnamespaceRename).Status.Phase
+	if shouldRevert(renameObject) {
+		return r.revert(renameObject)
+	} else if common.ShouldReconcile(phase) {
+		result, err := r.reconcile(renameObject)
+		if err != nil {
+			if updateErr := updateRenameStatus(renameObject, danav1.Error, err.Error()); updateErr != nil {
+				return ctrl.Result{}, updateErr
+			}
+		}
+		return result, err
+	} else {
+		logger.Info("no need to reconcile, object phase is: ", "phase", phase)
+	}
+
+	return ctrl.Result{}, nil
+}
+
+// reconcile renames a subnamespace by creating a new subnamespace under the same parent, moving the
+// objects and the children of the old subnamespace to it and deleting the old namespace. The steps of the
+// rename which were not completed yet are executed, so that a rename which was interrupted is resumed after
+// its last completed step. The step is recorded in the status of the SubnamespaceRename after every step is
+// completed, and every step can be safely executed again in case the controller stopped before it was recorded.
+func (r *SubnamespaceRenameReconciler) reconcile(renameObject *objectcontext.ObjectContext) (ctrl.Result, error) {
+	ctx := renameObject.Ctx
+	logger := log.FromContext(ctx)
+
+	currentName := renameObject.Object.(*danav1.SubnamespaceRename).Spec.CurrentNamespace
+	newName := renameObject.Object.(*danav1.SubnamespaceRename).Spec.NewNamespace
+
+	if renameObject.Object.(*danav1.SubnamespaceRename).Status.Phase == danav1.None {
+		if err := updateRenameStatus(renameObject, danav1.InProgress, ""); err != nil {
+			return ctrl.Result{}, err
+		}
+		logger.Info("successfully updated status of SubnamespaceRename object", "phase", danav1.InProgress)
+	}
+
+	if !isStepCompleted(renameObject, danav1.OldNSDeleted) {
+		oldNS, err := objectcontext.New(ctx, r.Client, client.ObjectKey{Name: currentName}, &corev1.Namespace{})
+		if err != nil {
+			return ctrl.Result{}, fmt.Errorf("failed getting namespace object %q: %v", currentName, err.Error())
+		}
+
+		// the old namespace is deleted in the last step of the rename, so it may already be gone
+		// if the controller stopped before the step was recorded
+		if oldNS.IsPresent() {
+			if result, err := r.rename(renameObject, oldNS); err != nil || !result.IsZero() {
+				return result, err
+			}
+		} else if !isStepCompleted(renameObject, danav1.DBRenamed) {
+			return ctrl.Result{}, fmt.Errorf("namespace %q does not exist", currentName)
+		} else if err := completeStep(renameObject, danav1.OldNSDeleted); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+
+	// enqueue for reconciliation the parent and the descendants of the subnamespace so that their
+	// status, labels and annotations are updated properly
+	newNS, err := objectcontext.New(ctx, r.Client, client.ObjectKey{Name: newName}, &corev1.Namespace{})
+	if err != nil {
+		return ctrl.Result{}, fmt.Errorf("failed getting namespace object %q: %v", newName, err.Error())
+	}
+	if err := r.enqueueHierarchy(newNS); err != nil {
+		return ctrl.Result{}, err
+	}
+	logger.Info("successfully enqueued parent and descendants of subnamespace", "subnamespace", newName)
+
+	return ctrl.Result{}, r.complete(renameObject)
+}
+
+// rename executes the steps of the rename which were not completed yet, up to the deletion of the old namespace.
+// It returns a result with a requeue interval if a step is waiting for objects of the new namespace to be ready.
+func (r *SubnamespaceRenameReconciler) rename(renameObject, oldNS *objectcontext.ObjectContext) (ctrl.Result, error) {
+	ctx := renameObject.Ctx
+	logger := log.FromContext(ctx)
+
+	currentName := oldNS.Name()
+	newName := renameObject.Object.(*danav1.SubnamespaceRename).Spec.NewNamespace
+	parentName := nsutils.Parent(oldNS.Object)
+
+	if !isStepCompleted(renameObject, danav1.NewNSCreated) {
+		oldSNS, err := objectcontext.New(ctx, r.Client, client.ObjectKey{Name: currentName, Namespace: parentName}, &danav1.Subnamespace{})
+		if err != nil {
+			return ctrl.Result{}, fmt.Errorf("failed getting subnamespace object %q: %v", currentName, err.Error())
+		}
+
+		newSNS, err := r.createNewSNS(oldNS, oldSNS, newName)
+		if err != nil {
+			return ctrl.Result{}, fmt.Errorf("failed creating subnamespace %q under namespace %q: %v", newName, parentName, err.Error())
+		}
+
+		if newSNS.Object.(*danav1.Subnamespace).Status.Phase != danav1.Created {
+			logger.Info("waiting for the new subnamespace to be created", "subnamespace", newName)
+			return ctrl.Result{RequeueAfter: requeueInterval}, nil
+		}
+
+		if err := completeStep(renameObject, danav1.NewNSCreated); err != nil {
+			return ctrl.Result{}, err
+		}
+		logger.Info("successfully created new subnamespace", "subnamespace", newName, "parent", parentName)
+	}
+
+	newNS, err := objectcontext.New(ctx, r.Client, client.ObjectKey{Name: newName}, &corev1.Namespace{})
+	if err != nil {
+		return ctrl.Result{}, fmt.Errorf("failed getting namespace object %q: %v", newName, err.Error())
+	}
+	if !newNS.IsPresent() {
+		return ctrl.Result{}, fmt.Errorf("namespace %q does not exist", newName)
+	}
+
+	if !isStepCompleted(renameObject, danav1.ObjectsMoved) {
+		done, err := moveObjects(oldNS, newNS)
+		if err != nil {
+			return ctrl.Result{}, fmt.Errorf("failed moving objects from namespace %q to namespace %q: %v", currentName, newName, err.Error())
+		}
+		if !done {
+			logger.Info("waiting for the objects of the new namespace to be ready", "namespace", newName)
+			return ctrl.Result{RequeueAfter: requeueInterval}, nil
+		}
+
+		if err := completeStep(renameObject, danav1.ObjectsMoved); err != nil {
+			return ctrl.Result{}, err
+		}
+		logger.Info("successfully moved objects to the new namespace", "namespace", newName)
+	}
+
+	if !isStepCompleted(renameObject, danav1.ChildrenMoved) {
+		if err := moveChildren(oldNS, newNS); err != nil {
+			return ctrl.Result{}, fmt.Errorf("failed moving the children of subnamespace %q to subnamespace %q: %v", currentName, newName, err.Error())
+		}
+
+		notMoved, err := r.listNotMovedObjects(oldNS)
+		if err != nil {
+			return ctrl.Result{}, fmt.Errorf("failed listing the objects of namespace %q which are not moved: %v", currentName, err.Error())
+		}
+		if err := updateNotMovedStatus(renameObject, notMoved); err != nil {
+			return ctrl.Result{}, err
+		}
+		if len(notMoved) > 0 {
+			logger.Info("objects of the old namespace are not moved to the new namespace", "namespace", currentName, "objects", notMoved)
+		}
+
+		if err := completeStep(renameObject, danav1.ChildrenMoved); err != nil {
+			return ctrl.Result{}, err
+		}
+		logger.Info("successfully moved children subnamespaces to the new subnamespace", "subnamespace", newName)
+	}
+
+	if !isStepCompleted(renameObject, danav1.DBRenamed) {
+		root, err := nsutils.Root(oldNS.Object)
+		if err != nil {
+			return ctrl.Result{}, fmt.Errorf("failed to get root namespace of %q: %v", currentName, err.Error())
+		}
+		r.NamespaceDB.RenameNS(root, currentName, newName)
+
+		if err := completeStep(renameObject, danav1.DBRenamed); err != nil {
+			return ctrl.Result{}, err
+		}
+		logger.Info("successfully renamed subnamespace in namespacedb", "subnamespace", currentName)
+	}
+
+	if err := deleteNS(oldNS); err != nil {
+		return ctrl.Result{}, fmt.Errorf("failed deleting namespace %q: %v", currentName, err.Error())
+	}
+	if err := completeStep(renameObject, danav1.OldNSDeleted); err != nil {
+		return ctrl.Result{}, err
+	}
+	logger.Info("successfully deleted old namespace", "namespace", currentName)
+
+	return ctrl.Result{}, nil
+}
+
+// enqueueHierarchy enqueues for reconciliation the parent of a namespace and the subnamespaces
+// of the namespace and of all its descendants.
+func (r *SubnamespaceRenameReconciler) enqueueHierarchy(ns *objectcontext.ObjectContext) error {
+	parentName := nsutils.Parent(ns.Object)
+	parentNS, err := objectcontext.New(ns.Ctx, r.Client, client.ObjectKey{Name: parentName}, &corev1.Namespace{})
+	if err != nil {
+		return fmt.Errorf("failed getting namespace object %q: %v", parentName, err.Error())
+	}
+
+	sns, err := objectcontext.New(ns.Ctx, r.Client, client.ObjectKey{Name: ns.Name(), Namespace: parentName}, &danav1.Subnamespace{})
+	if err != nil {
+		return fmt.Errorf("failed getting subnamespace object %q: %v", ns.Name(), err.Error())
+	}
+
+	r.addSNSToSNSEvent(parentName, nsutils.Parent(parentNS.Object))
+	r.addSNSToSNSEvent(ns.Name(), parentName)
+	for _, child := range snsutils.GetAllChildren(sns) {
+		r.addSNSToSNSEvent(child.Name(), child.Namespace())
+	}
+
+	return nil
+}
+
+// createNewSNS creates the renamed subnamespace under the parent of the old subnamespace, with the same
+// spec as the old subnamespace and with the previous names of the subnamespace recorded in an annotation.
+func (r *SubnamespaceRenameReconciler) createNewSNS(oldNS, oldSNS *objectcontext.ObjectContext, newName string) (*objectcontext.ObjectContext, error) {
+	previousNames := previousNamesOf(oldNS.Object)
+	previousNames = append(previousNames, oldNS.Name())
+
+	composedSNS := &danav1.Subnamespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        newName,
+			Namespace:   oldSNS.Namespace(),
+			Labels:      map[string]string{danav1.ResourcePool: oldSNS.Object.GetLabels()[danav1.ResourcePool]},
+			Annotations: map[string]string{danav1.PreviousNames: strings.Join(previousNames, ",")},
+		},
+		Spec: *oldSNS.Object.(*danav1.Subnamespace).Spec.DeepCopy(),
+	}
+	composedSNS.Spec.NamespaceRef.Name = newName
+
+	newSNS, err := objectcontext.New(oldSNS.Ctx, r.Client, types.NamespacedName{Name: newName, Namespace: oldSNS.Namespace()}, composedSNS)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := newSNS.EnsureCreate(); err != nil {
+		return nil, err
+	}
+
+	return newSNS, nil
+}
+
+// moveChildren moves the children subnamespaces of the old namespace to be under the new namespace
+// and updates the labels and annotations of all the descendants of the new namespace accordingly.
+func moveChildren(oldNS, newNS *objectcontext.ObjectContext) error {
+	ctx := oldNS.Ctx
+
+	children, err := objectcontext.NewList(ctx, oldNS.Client, &danav1.SubnamespaceList{}, client.InNamespace(oldNS.Name()))
+	if err != nil {
+		return err
+	}
+
+	for _, child := range children.Objects.(*danav1.SubnamespaceList).Items {
+		labels := map[string]string{danav1.ResourcePool: child.GetLabels()[danav1.ResourcePool]}
+		composedSNS := migrationhierarchy.ComposeSNS(child.Name, newNS.Name(), child.Spec.ResourceQuotaSpec.Hard, labels)
+		composedSNS.Spec.LimitRangeSpec = child.Spec.LimitRangeSpec
+		composedSNS.Spec.Owners = child.Spec.Owners
+		composedSNS.Spec.InheritancePolicy = child.Spec.InheritancePolicy
+		composedSNS.Status.Phase = danav1.Migrated
+
+		newChild, err := objectcontext.New(ctx, oldNS.Client, types.NamespacedName{Name: child.Name, Namespace: newNS.Name()}, composedSNS)
+		if err != nil {
+			return err
+		}
+		if err := newChild.EnsureCreate(); err != nil {
+			return fmt.Errorf("failed creating subnamespace %q under namespace %q: %v", child.Name, newNS.Name(), err.Error())
+		}
+
+		oldChild, err := objectcontext.New(ctx, oldNS.Client, types.NamespacedName{Name: child.Name, Namespace: oldNS.Name()}, &danav1.Subnamespace{})
+		if err != nil {
+			return err
+		}
+		if err := oldChild.EnsureDelete(); err != nil {
+			return fmt.Errorf("failed deleting subnamespace %q from namespace %q: %v", child.Name, oldNS.Name(), err.Error())
+		}
+	}
+
+	if err := migrationhierarchy.UpdateAllNSChildrenOfNs(ctx, newNS); err != nil {
+		return fmt.Errorf("failed updating labels and annotations of child namespaces of subnamespace %q: %v", newNS.Name(), err.Error())
+	}
+
+	return removeStaleLabels(oldNS, newNS)
+}
+
+// removeStaleLabels removes the labels which point to the old name of a renamed subnamespace
+// from the namespaces of its descendants.
+func removeStaleLabels(oldNS, newNS *objectcontext.ObjectContext) error {
+	oldName := oldNS.Name()
+
+	descendants, err := objectcontext.NewList(oldNS.Ctx, oldNS.Client, &corev1.NamespaceList{}, client.HasLabels{nsutils.TreeLabel(oldName)})
+	if err != nil {
+		return err
+	}
+
+	for _, descendant := range descendants.Objects.(*corev1.NamespaceList).Items {
+		if descendant.Name == oldName || descendant.Labels[nsutils.TreeLabel(newNS.Name())] == "" {
+			continue
+		}
+
+		descendantNS, err := objectcontext.New(oldNS.Ctx, oldNS.Client, types.NamespacedName{Name: descendant.Name}, &corev1.Namespace{})
+		if err != nil {
+			return err
+		}
+
+		if err := descendantNS.UpdateObject(func(object client.Object, l logr.Logger) (client.Object, logr.Logger) {
+			labels := object.GetLabels()
+			delete(labels, oldName)
+			delete(labels, nsutils.TreeLabel(oldName))
+			object.SetLabels(labels)
+			return object, l
+		}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// deleteNS deletes the namespace of the subnamespace which is replaced by a rename, which is the old namespace
+// of a rename or the new namespace of a reverted rename. The namespace no longer has children at this point,
+// so its role is updated to Leaf to allow it to be deleted.
+func deleteNS(ns *objectcontext.ObjectContext) error {
+	if !ns.IsPresent() || !ns.Object.GetDeletionTimestamp().IsZero() {
+		return nil
+	}
+
+	if err := ns.UpdateObject(func(object client.Object, l logr.Logger) (client.Object, logr.Logger) {
+		object.GetLabels()[danav1.Role] = danav1.Leaf
+		object.GetAnnotations()[danav1.Role] = danav1.Leaf
+		return object, l
+	}); err != nil {
+		return err
+	}
+
+	return ns.EnsureDelete()
+}
+
+// previousNamesOf returns the names a namespace had before it was renamed.
+func previousNamesOf(object client.Object) []string {
+	previousNames := object.GetAnnotations()[danav1.PreviousNames]
+	if previousNames == "" {
+		return []string{}
+	}
+
+	return strings.Split(previousNames, ",")
+}
+
+// addSNSToSNSEvent enqueues the sns to trigger new re-sync for the sns.
+func (r *SubnamespaceRenameReconciler) addSNSToSNSEvent(snsName string, snsNamespace string) {
+	r.SNSEvents.Enqueue(snsName, snsNamespace)
+}
+
+// complete sets the phase of the SubnamespaceRename object to Complete.
+func (r *SubnamespaceRenameReconciler) complete(renameObject *objectcontext.ObjectContext) error {
+	if err := updateRenameStatus(renameObject, danav1.Complete, ""); err != nil {
+		return err
+	}
+	log.FromContext(renameObject.Ctx).Info("successfully updated status of SubnamespaceRename object", "phase", danav1.Complete)
+
+	return nil
+}
+
+// isStepCompleted returns true if the given step of a rename was already completed.
+func isStepCompleted(renameObject *objectcontext.ObjectContext, step danav1.RenameStep) bool {
+	completed := renameObject.Object.(*danav1.SubnamespaceRename).Status.Step
+	return completed != "" && slices.Index(danav1.RenameSteps, completed) >= slices.Index(danav1.RenameSteps, step)
+}
+
+// completeStep records in the status of the SubnamespaceRename object the last step of the rename which was completed.
+func completeStep(renameObject *objectcontext.ObjectContext, step danav1.RenameStep) error {
+	err := renameObject.EnsureUpdateObject(func(object client.Object, l logr.Logger) (client.Object, logr.Logger, error) {
+		object.(*danav1.SubnamespaceRename).Status.Step = step
+		return object, l, nil
+	}, false)
+
+	if err != nil {
+		return fmt.Errorf("failed updating the status of object %q: %v", renameObject.Name(), err.Error())
+	}
+
+	return nil
+}
+
+// updateNotMovedStatus records in the status of the SubnamespaceRename object
+// the objects of the old namespace which are not moved to the new namespace.
+func updateNotMovedStatus(renameObject *objectcontext.ObjectContext, notMoved []string) error {
+	err := renameObject.UpdateObject(func(object client.Object, l logr.Logger) (client.Object, logr.Logger) {
+		object.(*danav1.SubnamespaceRename).Status.NotMoved = notMoved
+		return object, l
+	})
+
+	if err != nil {
+		return fmt.Errorf("failed updating the status of object %q: %v", renameObject.Name(), err.Error())
+	}
+
+	return nil
+}
+
+// updateRenameStatus updates the status of the SubnamespaceRename object.
+func updateRenameStatus(renameObject *objectcontext.ObjectContext, phase danav1.Phase, reason string) error {
+	err := renameObject.UpdateObject(func(object client.Object, l logr.Logger) (client.Object, logr.Logger) {
+		object.(*danav1.SubnamespaceRename).Status.Phase = phase
+		object.(*danav1.SubnamespaceRename).Status.Reason = reason
+		return object, l
+	})
+
+	if err != nil {
+		return fmt.Errorf("failed updating the status of object %q: %v", renameObject.Name(), err.Error())
+	}
+
+	return nil
+}
@@ -0,0 +1,101 @@
+package subnamespacerename
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+
+	danav1 "github.com/dana-team/hns/api/v1"
+	"github.com/dana-team/hns/internal/common"
+	"github.com/dana-team/hns/internal/namespace/nsutils"
+	"github.com/dana-team/hns/internal/objectcontext"
+	quotav1 "github.com/openshift/api/quota/v1"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+func (v *SubnamespaceRenameValidator) handleCreate(renameObject *objectcontext.ObjectContext, userInfo authenticationv1.UserInfo) admission.Response {
+	ctx := renameObject.Ctx
+	logger := log.FromContext(ctx)
+
+	currentNSName := renameObject.Object.(*danav1.SubnamespaceRename).Spec.CurrentNamespace
+	newNSName := renameObject.Object.(*danav1.SubnamespaceRename).Spec.NewNamespace
+
+	if currentNSName == newNSName {
+		message := "it's forbidden to rename a Subnamespace to its current name"
+		return admission.Denied(message)
+	}
+
+	currentNS, err := objectcontext.New(ctx, v.Client, client.ObjectKey{Name: currentNSName}, &corev1.Namespace{})
+	if err != nil {
+		logger.Error(err, "failed to create object", "currentNS", currentNSName)
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+
+	if response := common.ValidateNamespaceExist(currentNS); !response.Allowed {
+		return response
+	}
+
+	if currentNS.Object.GetLabels()[danav1.Hns] == "" || nsutils.IsRoot(currentNS.Object) {
+		message := fmt.Sprintf("it's forbidden to rename namespace %q, only a Subnamespace can be renamed", currentNSName)
+		return admission.Denied(message)
+	}
+
+	if response := validateNewName(newNSName); !response.Allowed {
+		return response
+	}
+
+	if response := v.validateNewNameUnique(renameObject, newNSName); !response.Allowed {
+		return response
+	}
+
+	parentNSName := nsutils.Parent(currentNS.Object)
+	ancestors, err := nsutils.Ancestors(currentNS.Object)
+	if err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+	if response := common.ValidatePermissions(ctx, ancestors, parentNSName, parentNSName, parentNSName, userInfo, common.CreateChildVerb, false, v.Client, v.Authorizer); !response.Allowed {
+		return response
+	}
+
+	return admission.Allowed("")
+}
+
+// validateNewName validates the new name of a Subnamespace according to RFC 1123, to match namespace name validation.
+func validateNewName(newNSName string) admission.Response {
+	if len(newNSName) > 63 {
+		message := fmt.Sprintf("Invalid value: %q: the subnamespace name should be at most 63 characters", newNSName)
+		return admission.Denied(message)
+	}
+	if match, _ := regexp.MatchString("^[a-z0-9]([-a-z0-9]*[a-z0-9])?$", newNSName); !match {
+		message := fmt.Sprintf("Invalid value: %q: a lowercase RFC 1123 label must consist of lower case alphanumeric characters or '-', and must start and end with an alphanumeric character (e.g. 'my-name', or '123-abc',", newNSName)
+		return admission.Denied(message)
+	}
+
+	return admission.Allowed("")
+}
+
+// validateNewNameUnique validates that a namespace or a ClusterResourceQuota with the new name of a
+// Subnamespace doesn't already exist, since both are named after the Subnamespace.
+func (v *SubnamespaceRenameValidator) validateNewNameUnique(renameObject *objectcontext.ObjectContext, newNSName string) admission.Response {
+	newNS, err := objectcontext.New(renameObject.Ctx, v.Client, client.ObjectKey{Name: newNSName}, &corev1.Namespace{})
+	if err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+
+	crq, err := objectcontext.New(renameObject.Ctx, v.Client, client.ObjectKey{Name: newNSName}, &quotav1.ClusterResourceQuota{})
+	if err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+
+	if newNS.IsPresent() || crq.IsPresent() {
+		message := fmt.Sprintf("it's forbidden to rename a Subnamespace to %q since a namespace or a ClusterResourceQuota "+
+			"with this name already exists", newNSName)
+		return admission.Denied(message)
+	}
+
+	return admission.Allowed("")
+}
@@ -0,0 +1,79 @@
+package subnamespacerename
+
+import (
+	"sort"
+
+	danav1 "github.com/dana-team/hns/api/v1"
+	"github.com/dana-team/hns/internal/common"
+	"github.com/dana-team/hns/internal/objectcontext"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// handledKinds are the kinds of objects which are either moved to the new namespace by the rename,
+// or are created in the new namespace by the cluster or by HNS, per API group.
+var handledKinds = map[string][]string{
+	"": {"ConfigMap", "Secret", "ServiceAccount", "PersistentVolumeClaim", "Event", "Endpoints",
+		"ResourceQuota", "LimitRange"},
+	"apps":                      {"Deployment"},
+	"rbac.authorization.k8s.io": {"RoleBinding"},
+	"events.k8s.io":             {"Event"},
+	"discovery.k8s.io":          {"EndpointSlice"},
+}
+
+// listNotMovedObjects returns the objects of the old namespace, as "<kind>/<name>", which are not moved to
+// the new namespace by the rename. Objects which are owned by other objects are left out, since they are
+// re-created by their owners, and so are the kinds of objects HNS is not permitted to list.
+func (r *SubnamespaceRenameReconciler) listNotMovedObjects(oldNS *objectcontext.ObjectContext) ([]string, error) {
+	resourceLists, err := r.Discovery.ServerPreferredNamespacedResources()
+	if err != nil && len(resourceLists) == 0 {
+		return nil, err
+	}
+
+	var notMoved []string
+	for _, resourceList := range resourceLists {
+		groupVersion, err := schema.ParseGroupVersion(resourceList.GroupVersion)
+		if err != nil {
+			continue
+		}
+
+		for _, resource := range resourceList.APIResources {
+			if !common.ContainsString(resource.Verbs, "list") || isHandledKind(groupVersion.Group, resource.Kind) {
+				continue
+			}
+
+			objects := &metav1.PartialObjectMetadataList{}
+			objects.SetGroupVersionKind(groupVersion.WithKind(resource.Kind + "List"))
+			if err := r.APIReader.List(oldNS.Ctx, objects, client.InNamespace(oldNS.Name())); err != nil {
+				if apierrors.IsForbidden(err) || apierrors.IsNotFound(err) || meta.IsNoMatchError(err) {
+					continue
+				}
+				return nil, err
+			}
+
+			for _, object := range objects.Items {
+				if len(object.OwnerReferences) > 0 {
+					continue
+				}
+				notMoved = append(notMoved, resource.Kind+"/"+object.Name)
+			}
+		}
+	}
+
+	sort.Strings(notMoved)
+	return notMoved, nil
+}
+
+// isHandledKind returns true if objects of the kind are moved by the rename or are created in the new
+// namespace by the cluster or by HNS. All the HNS objects are handled, since the children of the
+// subnamespace are moved by the rename and UpdateQuotas only record operations which already ran.
+func isHandledKind(group, kind string) bool {
+	if group == danav1.GroupVersion.Group {
+		return true
+	}
+
+	return common.ContainsString(handledKinds[group], kind)
+}
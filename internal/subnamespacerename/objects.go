@@ -0,0 +1,550 @@
+package subnamespacerename
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	danav1 "github.com/dana-team/hns/api/v1"
+	"github.com/dana-team/hns/internal/common"
+	"github.com/dana-team/hns/internal/objectcontext"
+	"github.com/dana-team/hns/internal/rolebinding/rbutils"
+	"github.com/go-logr/logr"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	serviceAccountNameAnnotation = "kubernetes.io/service-account.name"
+	deploymentRevisionAnnotation = "deployment.kubernetes.io/revision"
+	bindingAnnotationPrefix      = "pv.kubernetes.io/"
+)
+
+// generatedConfigMaps are ConfigMaps which are created in every namespace by the cluster,
+// and therefore are not moved to the new namespace.
+var generatedConfigMaps = []string{"kube-root-ca.crt", "openshift-service-ca.crt"}
+
+// moveObjects copies the supported objects of the old namespace to the new namespace. ConfigMaps, Secrets,
+// ServiceAccounts, RoleBindings and Deployments are copied, the Deployments of the old namespace are scaled
+// down before they are copied, and the PersistentVolumes bound to the PersistentVolumeClaims of the old namespace
+// are re-bound to new claims in the new namespace. It returns false if the old Deployments are not scaled down
+// yet or if the new claims are not bound yet.
+func moveObjects(oldNS, newNS *objectcontext.ObjectContext) (bool, error) {
+	if err := copyConfigMaps(oldNS, newNS); err != nil {
+		return false, fmt.Errorf("failed copying ConfigMaps: %v", err.Error())
+	}
+
+	if err := copySecrets(oldNS, newNS); err != nil {
+		return false, fmt.Errorf("failed copying Secrets: %v", err.Error())
+	}
+
+	if err := copyServiceAccounts(oldNS, newNS); err != nil {
+		return false, fmt.Errorf("failed copying ServiceAccounts: %v", err.Error())
+	}
+
+	if err := copyRoleBindings(oldNS, newNS); err != nil {
+		return false, fmt.Errorf("failed copying RoleBindings: %v", err.Error())
+	}
+
+	scaledDown, err := moveDeployments(oldNS, newNS)
+	if err != nil {
+		return false, fmt.Errorf("failed moving Deployments: %v", err.Error())
+	}
+
+	bound, err := movePersistentVolumeClaims(oldNS, newNS)
+	if err != nil {
+		return false, fmt.Errorf("failed moving PersistentVolumeClaims: %v", err.Error())
+	}
+
+	return scaledDown && bound, nil
+}
+
+// copyConfigMaps copies the ConfigMaps of the old namespace to the new namespace.
+func copyConfigMaps(oldNS, newNS *objectcontext.ObjectContext) error {
+	configMaps, err := objectcontext.NewList(oldNS.Ctx, oldNS.Client, &corev1.ConfigMapList{}, client.InNamespace(oldNS.Name()))
+	if err != nil {
+		return err
+	}
+
+	for _, configMap := range configMaps.Objects.(*corev1.ConfigMapList).Items {
+		if common.ContainsString(generatedConfigMaps, configMap.Name) {
+			continue
+		}
+
+		composedConfigMap := &corev1.ConfigMap{
+			ObjectMeta: copyObjectMeta(configMap.ObjectMeta, newNS.Name()),
+			Data:       configMap.Data,
+			BinaryData: configMap.BinaryData,
+			Immutable:  configMap.Immutable,
+		}
+		if err := ensureCopy(newNS, composedConfigMap); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// copySecrets copies the Secrets of the old namespace to the new namespace, except for
+// the Secrets which are generated for ServiceAccounts.
+func copySecrets(oldNS, newNS *objectcontext.ObjectContext) error {
+	secrets, err := objectcontext.NewList(oldNS.Ctx, oldNS.Client, &corev1.SecretList{}, client.InNamespace(oldNS.Name()))
+	if err != nil {
+		return err
+	}
+
+	for _, secret := range secrets.Objects.(*corev1.SecretList).Items {
+		if secret.Type == corev1.SecretTypeServiceAccountToken || secret.Annotations[serviceAccountNameAnnotation] != "" {
+			continue
+		}
+
+		composedSecret := &corev1.Secret{
+			ObjectMeta: copyObjectMeta(secret.ObjectMeta, newNS.Name()),
+			Data:       secret.Data,
+			Type:       secret.Type,
+			Immutable:  secret.Immutable,
+		}
+		if err := ensureCopy(newNS, composedSecret); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// copyServiceAccounts copies the ServiceAccounts of the old namespace to the new namespace, except
+// for the ServiceAccounts which are created in every namespace by the cluster.
+func copyServiceAccounts(oldNS, newNS *objectcontext.ObjectContext) error {
+	serviceAccounts, err := objectcontext.NewList(oldNS.Ctx, oldNS.Client, &corev1.ServiceAccountList{}, client.InNamespace(oldNS.Name()))
+	if err != nil {
+		return err
+	}
+
+	for _, serviceAccount := range serviceAccounts.Objects.(*corev1.ServiceAccountList).Items {
+		if common.ContainsString(common.DefaultSubjectExclusions.ServiceAccounts, serviceAccount.Name) {
+			continue
+		}
+
+		composedServiceAccount := &corev1.ServiceAccount{
+			ObjectMeta:                   copyObjectMeta(serviceAccount.ObjectMeta, newNS.Name()),
+			ImagePullSecrets:             serviceAccount.ImagePullSecrets,
+			AutomountServiceAccountToken: serviceAccount.AutomountServiceAccountToken,
+		}
+		if err := ensureCopy(newNS, composedServiceAccount); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// copyRoleBindings copies the RoleBindings of the old namespace to the new namespace. RoleBindings
+// which are inherited from the ancestors of the namespace are propagated to the new namespace by HNS,
+// and the RoleBindings of the owners of the subnamespace are created in it by HNS, so they are not copied.
+// Subjects which are ServiceAccounts of the old namespace are replaced by the copies of the ServiceAccounts.
+func copyRoleBindings(oldNS, newNS *objectcontext.ObjectContext) error {
+	roleBindings, err := objectcontext.NewList(oldNS.Ctx, oldNS.Client, &rbacv1.RoleBindingList{}, client.InNamespace(oldNS.Name()))
+	if err != nil {
+		return err
+	}
+
+	for _, roleBinding := range roleBindings.Objects.(*rbacv1.RoleBindingList).Items {
+		if rbutils.IsOwner(&roleBinding) || rbutils.IsInherited(&roleBinding) {
+			continue
+		}
+
+		var subjects []rbacv1.Subject
+		for _, subject := range roleBinding.Subjects {
+			if subject.Kind == rbacv1.ServiceAccountKind && (subject.Namespace == oldNS.Name() || subject.Namespace == "") {
+				subject.Namespace = newNS.Name()
+			}
+			subjects = append(subjects, subject)
+		}
+
+		composedRoleBinding := &rbacv1.RoleBinding{
+			ObjectMeta: copyObjectMeta(roleBinding.ObjectMeta, newNS.Name()),
+			Subjects:   subjects,
+			RoleRef:    roleBinding.RoleRef,
+		}
+		if err := ensureCopy(newNS, composedRoleBinding); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// moveDeployments scales down the Deployments of the old namespace and copies them to the new namespace with
+// their original number of replicas. A Deployment is copied only once all the pods of the old Deployment are
+// gone, so that the old and new pods don't run at the same time and contend for the same volumes. It returns
+// false if not all the old Deployments are scaled down yet.
+func moveDeployments(oldNS, newNS *objectcontext.ObjectContext) (bool, error) {
+	deployments, err := objectcontext.NewList(oldNS.Ctx, oldNS.Client, &appsv1.DeploymentList{}, client.InNamespace(oldNS.Name()))
+	if err != nil {
+		return false, err
+	}
+
+	allScaledDown := true
+	for _, deployment := range deployments.Objects.(*appsv1.DeploymentList).Items {
+		oldDeployment, err := objectcontext.New(oldNS.Ctx, oldNS.Client, types.NamespacedName{Name: deployment.Name, Namespace: oldNS.Name()}, &appsv1.Deployment{})
+		if err != nil {
+			return false, err
+		}
+
+		if err := scaleDownDeployment(oldDeployment); err != nil {
+			return false, err
+		}
+
+		if oldDeployment.Object.(*appsv1.Deployment).Status.Replicas > 0 {
+			allScaledDown = false
+			continue
+		}
+
+		objectMeta := copyObjectMeta(oldDeployment.Object.(*appsv1.Deployment).ObjectMeta, newNS.Name())
+		delete(objectMeta.Annotations, deploymentRevisionAnnotation)
+		delete(objectMeta.Annotations, danav1.OriginalReplicas)
+
+		composedDeployment := &appsv1.Deployment{
+			ObjectMeta: objectMeta,
+			Spec:       *oldDeployment.Object.(*appsv1.Deployment).Spec.DeepCopy(),
+		}
+		replicas, err := strconv.ParseInt(oldDeployment.Object.GetAnnotations()[danav1.OriginalReplicas], 10, 32)
+		if err != nil {
+			return false, fmt.Errorf("failed parsing the original replicas of Deployment %q: %v", deployment.Name, err.Error())
+		}
+		originalReplicas := int32(replicas)
+		composedDeployment.Spec.Replicas = &originalReplicas
+
+		if err := ensureCopy(newNS, composedDeployment); err != nil {
+			return false, err
+		}
+	}
+
+	return allScaledDown, nil
+}
+
+// scaleDownDeployment scales a Deployment down to zero replicas and records its original number of replicas.
+func scaleDownDeployment(deployment *objectcontext.ObjectContext) error {
+	if _, ok := deployment.Object.GetAnnotations()[danav1.OriginalReplicas]; ok {
+		return nil
+	}
+
+	replicas := int32(1)
+	if deployment.Object.(*appsv1.Deployment).Spec.Replicas != nil {
+		replicas = *deployment.Object.(*appsv1.Deployment).Spec.Replicas
+	}
+
+	return deployment.UpdateObject(func(object client.Object, l logr.Logger) (client.Object, logr.Logger) {
+		annotations := object.GetAnnotations()
+		if annotations == nil {
+			annotations = map[string]string{}
+		}
+		annotations[danav1.OriginalReplicas] = strconv.Itoa(int(replicas))
+		object.SetAnnotations(annotations)
+
+		zero := int32(0)
+		object.(*appsv1.Deployment).Spec.Replicas = &zero
+		return object, l
+	})
+}
+
+// movePersistentVolumeClaims re-binds the PersistentVolumes bound to the PersistentVolumeClaims of the old
+// namespace to new claims in the new namespace. The reclaim policy of a PersistentVolume is set to Retain
+// while it is being re-bound, so that it's not deleted with the old claim, and is restored once the new
+// claim is bound. It returns false if not all the new claims are bound yet.
+func movePersistentVolumeClaims(oldNS, newNS *objectcontext.ObjectContext) (bool, error) {
+	claims, err := objectcontext.NewList(oldNS.Ctx, oldNS.Client, &corev1.PersistentVolumeClaimList{}, client.InNamespace(oldNS.Name()))
+	if err != nil {
+		return false, err
+	}
+
+	allBound := true
+	for _, claim := range claims.Objects.(*corev1.PersistentVolumeClaimList).Items {
+		volumeName := claim.Spec.VolumeName
+		if volumeName == "" {
+			continue
+		}
+
+		volume, err := objectcontext.New(oldNS.Ctx, oldNS.Client, types.NamespacedName{Name: volumeName}, &corev1.PersistentVolume{})
+		if err != nil {
+			return false, err
+		}
+		if !volume.IsPresent() {
+			continue
+		}
+
+		// the volume was already re-bound to the new claim and its reclaim policy was restored
+		claimRef := volume.Object.(*corev1.PersistentVolume).Spec.ClaimRef
+		_, retained := volume.Object.GetAnnotations()[danav1.OriginalReclaimPolicy]
+		if claimRef != nil && claimRef.Namespace == newNS.Name() && !retained {
+			continue
+		}
+
+		if err := retainVolume(volume); err != nil {
+			return false, err
+		}
+
+		// the binding annotations are set by the cluster and must not be copied to the unbound new claim
+		objectMeta := copyObjectMeta(claim.ObjectMeta, newNS.Name())
+		for key := range objectMeta.Annotations {
+			if strings.HasPrefix(key, bindingAnnotationPrefix) {
+				delete(objectMeta.Annotations, key)
+			}
+		}
+
+		composedClaim := &corev1.PersistentVolumeClaim{
+			ObjectMeta: objectMeta,
+			Spec:       *claim.Spec.DeepCopy(),
+		}
+		newClaim, err := objectcontext.New(newNS.Ctx, newNS.Client, types.NamespacedName{Name: claim.Name, Namespace: newNS.Name()}, composedClaim)
+		if err != nil {
+			return false, err
+		}
+		if err := newClaim.EnsureCreate(); err != nil {
+			return false, err
+		}
+
+		if err := volume.UpdateObject(func(object client.Object, l logr.Logger) (client.Object, logr.Logger) {
+			object.(*corev1.PersistentVolume).Spec.ClaimRef = &corev1.ObjectReference{
+				Kind:      "PersistentVolumeClaim",
+				Namespace: newNS.Name(),
+				Name:      claim.Name,
+			}
+			return object, l
+		}); err != nil {
+			return false, err
+		}
+
+		if newClaim.Object.(*corev1.PersistentVolumeClaim).Status.Phase != corev1.ClaimBound {
+			allBound = false
+			continue
+		}
+
+		if err := restoreVolumeReclaimPolicy(volume); err != nil {
+			return false, err
+		}
+	}
+
+	return allBound, nil
+}
+
+// retainVolume sets the reclaim policy of a PersistentVolume to Retain and records its original policy.
+func retainVolume(volume *objectcontext.ObjectContext) error {
+	if _, ok := volume.Object.GetAnnotations()[danav1.OriginalReclaimPolicy]; ok {
+		return nil
+	}
+
+	policy := volume.Object.(*corev1.PersistentVolume).Spec.PersistentVolumeReclaimPolicy
+	return volume.UpdateObject(func(object client.Object, l logr.Logger) (client.Object, logr.Logger) {
+		annotations := object.GetAnnotations()
+		if annotations == nil {
+			annotations = map[string]string{}
+		}
+		annotations[danav1.OriginalReclaimPolicy] = string(policy)
+		object.SetAnnotations(annotations)
+		object.(*corev1.PersistentVolume).Spec.PersistentVolumeReclaimPolicy = corev1.PersistentVolumeReclaimRetain
+		return object, l
+	})
+}
+
+// restoreVolumeReclaimPolicy restores the original reclaim policy of a PersistentVolume.
+func restoreVolumeReclaimPolicy(volume *objectcontext.ObjectContext) error {
+	policy, ok := volume.Object.GetAnnotations()[danav1.OriginalReclaimPolicy]
+	if !ok {
+		return nil
+	}
+
+	return volume.UpdateObject(func(object client.Object, l logr.Logger) (client.Object, logr.Logger) {
+		annotations := object.GetAnnotations()
+		delete(annotations, danav1.OriginalReclaimPolicy)
+		object.SetAnnotations(annotations)
+		object.(*corev1.PersistentVolume).Spec.PersistentVolumeReclaimPolicy = corev1.PersistentVolumeReclaimPolicy(policy)
+		return object, l
+	})
+}
+
+// restoreObjects undoes the move of the objects of the old namespace to the new namespace. The Deployments
+// of the new namespace are scaled down, the PersistentVolumes are re-bound to the PersistentVolumeClaims of the
+// old namespace, and the Deployments of the old namespace are then scaled back up to their original number of
+// replicas. It returns false if the new Deployments are not scaled down yet or if the old claims are not bound yet.
+func restoreObjects(oldNS, newNS *objectcontext.ObjectContext) (bool, error) {
+	scaledDown, err := scaleDownDeployments(newNS)
+	if err != nil {
+		return false, fmt.Errorf("failed scaling down Deployments: %v", err.Error())
+	}
+	if !scaledDown {
+		return false, nil
+	}
+
+	bound, err := restorePersistentVolumeClaims(oldNS)
+	if err != nil {
+		return false, fmt.Errorf("failed restoring PersistentVolumeClaims: %v", err.Error())
+	}
+	if !bound {
+		return false, nil
+	}
+
+	if err := scaleUpDeployments(oldNS); err != nil {
+		return false, fmt.Errorf("failed scaling up Deployments: %v", err.Error())
+	}
+
+	return true, nil
+}
+
+// scaleDownDeployments scales down the Deployments of a namespace. It returns false
+// if not all the Deployments are scaled down yet.
+func scaleDownDeployments(ns *objectcontext.ObjectContext) (bool, error) {
+	deployments, err := objectcontext.NewList(ns.Ctx, ns.Client, &appsv1.DeploymentList{}, client.InNamespace(ns.Name()))
+	if err != nil {
+		return false, err
+	}
+
+	allScaledDown := true
+	for _, deployment := range deployments.Objects.(*appsv1.DeploymentList).Items {
+		deploymentObject, err := objectcontext.New(ns.Ctx, ns.Client, types.NamespacedName{Name: deployment.Name, Namespace: ns.Name()}, &appsv1.Deployment{})
+		if err != nil {
+			return false, err
+		}
+
+		if err := scaleDownDeployment(deploymentObject); err != nil {
+			return false, err
+		}
+
+		if deploymentObject.Object.(*appsv1.Deployment).Status.Replicas > 0 {
+			allScaledDown = false
+		}
+	}
+
+	return allScaledDown, nil
+}
+
+// scaleUpDeployments scales the Deployments of a namespace which were scaled down
+// back up to their original number of replicas.
+func scaleUpDeployments(ns *objectcontext.ObjectContext) error {
+	deployments, err := objectcontext.NewList(ns.Ctx, ns.Client, &appsv1.DeploymentList{}, client.InNamespace(ns.Name()))
+	if err != nil {
+		return err
+	}
+
+	for _, deployment := range deployments.Objects.(*appsv1.DeploymentList).Items {
+		originalReplicas, ok := deployment.Annotations[danav1.OriginalReplicas]
+		if !ok {
+			continue
+		}
+
+		replicas, err := strconv.ParseInt(originalReplicas, 10, 32)
+		if err != nil {
+			return fmt.Errorf("failed parsing the original replicas of Deployment %q: %v", deployment.Name, err.Error())
+		}
+
+		deploymentObject, err := objectcontext.New(ns.Ctx, ns.Client, types.NamespacedName{Name: deployment.Name, Namespace: ns.Name()}, &appsv1.Deployment{})
+		if err != nil {
+			return err
+		}
+
+		if err := deploymentObject.UpdateObject(func(object client.Object, l logr.Logger) (client.Object, logr.Logger) {
+			annotations := object.GetAnnotations()
+			delete(annotations, danav1.OriginalReplicas)
+			object.SetAnnotations(annotations)
+
+			restoredReplicas := int32(replicas)
+			object.(*appsv1.Deployment).Spec.Replicas = &restoredReplicas
+			return object, l
+		}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// restorePersistentVolumeClaims re-binds the PersistentVolumes which were re-bound to claims in the new namespace
+// back to the PersistentVolumeClaims of the old namespace. The old claims still refer to their volumes, so a volume
+// is bound to its old claim again once the claim reference of the volume points to it. It returns false if not all
+// the old claims are bound yet.
+func restorePersistentVolumeClaims(oldNS *objectcontext.ObjectContext) (bool, error) {
+	claims, err := objectcontext.NewList(oldNS.Ctx, oldNS.Client, &corev1.PersistentVolumeClaimList{}, client.InNamespace(oldNS.Name()))
+	if err != nil {
+		return false, err
+	}
+
+	allBound := true
+	for _, claim := range claims.Objects.(*corev1.PersistentVolumeClaimList).Items {
+		volumeName := claim.Spec.VolumeName
+		if volumeName == "" {
+			continue
+		}
+
+		volume, err := objectcontext.New(oldNS.Ctx, oldNS.Client, types.NamespacedName{Name: volumeName}, &corev1.PersistentVolume{})
+		if err != nil {
+			return false, err
+		}
+		if !volume.IsPresent() {
+			continue
+		}
+
+		// the volume was never re-bound, or was already re-bound to the old claim and its reclaim policy was restored
+		claimRef := volume.Object.(*corev1.PersistentVolume).Spec.ClaimRef
+		_, retained := volume.Object.GetAnnotations()[danav1.OriginalReclaimPolicy]
+		if claimRef != nil && claimRef.UID == claim.UID && !retained {
+			continue
+		}
+
+		if err := retainVolume(volume); err != nil {
+			return false, err
+		}
+
+		if err := volume.UpdateObject(func(object client.Object, l logr.Logger) (client.Object, logr.Logger) {
+			object.(*corev1.PersistentVolume).Spec.ClaimRef = &corev1.ObjectReference{
+				Kind:      "PersistentVolumeClaim",
+				Namespace: oldNS.Name(),
+				Name:      claim.Name,
+				UID:       claim.UID,
+			}
+			return object, l
+		}); err != nil {
+			return false, err
+		}
+
+		if claim.Status.Phase != corev1.ClaimBound {
+			allBound = false
+			continue
+		}
+
+		if err := restoreVolumeReclaimPolicy(volume); err != nil {
+			return false, err
+		}
+	}
+
+	return allBound, nil
+}
+
+// copyObjectMeta returns the metadata of an object to be created in the new namespace, without
+// the fields which are set by the cluster.
+func copyObjectMeta(objectMeta metav1.ObjectMeta, namespace string) metav1.ObjectMeta {
+	annotations := map[string]string{}
+	for key, value := range objectMeta.Annotations {
+		annotations[key] = value
+	}
+
+	return metav1.ObjectMeta{
+		Name:        objectMeta.Name,
+		Namespace:   namespace,
+		Labels:      objectMeta.Labels,
+		Annotations: annotations,
+	}
+}
+
+// ensureCopy creates a copy of an object in the new namespace if it doesn't exist.
+func ensureCopy(newNS *objectcontext.ObjectContext, object client.Object) error {
+	objectCopy, err := objectcontext.New(newNS.Ctx, newNS.Client, types.NamespacedName{Name: object.GetName(), Namespace: newNS.Name()}, object)
+	if err != nil {
+		return err
+	}
+
+	return objectCopy.EnsureCreate()
+}
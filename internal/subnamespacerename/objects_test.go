@@ -0,0 +1,123 @@
+package subnamespacerename
+
+import (
+	"context"
+	"testing"
+
+	danav1 "github.com/dana-team/hns/api/v1"
+	"github.com/dana-team/hns/internal/objectcontext"
+	"github.com/dana-team/hns/internal/testutils"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func TestRestoreObjects(t *testing.T) {
+	tests := []struct {
+		name             string
+		newReplicas      int32
+		claimPhase       corev1.PersistentVolumeClaimPhase
+		expectedDone     bool
+		expectedReplicas int32
+		expectedPolicy   corev1.PersistentVolumeReclaimPolicy
+	}{
+		{
+			name:             "the old namespace is not restored while the new Deployment is running",
+			newReplicas:      2,
+			claimPhase:       corev1.ClaimBound,
+			expectedDone:     false,
+			expectedReplicas: 0,
+			expectedPolicy:   corev1.PersistentVolumeReclaimRetain,
+		},
+		{
+			name:             "the old Deployment is not scaled up before the old claim is bound again",
+			claimPhase:       corev1.ClaimLost,
+			expectedDone:     false,
+			expectedReplicas: 0,
+			expectedPolicy:   corev1.PersistentVolumeReclaimRetain,
+		},
+		{
+			name:             "the volume and the Deployment of the old namespace are restored",
+			claimPhase:       corev1.ClaimBound,
+			expectedDone:     true,
+			expectedReplicas: 3,
+			expectedPolicy:   corev1.PersistentVolumeReclaimDelete,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// namespace a was being renamed to b, its Deployment was scaled down and its volume was re-bound to a claim in b
+			zero := int32(0)
+			oldDeployment := &appsv1.Deployment{
+				ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: "a", Annotations: map[string]string{danav1.OriginalReplicas: "3"}},
+				Spec:       appsv1.DeploymentSpec{Replicas: &zero},
+			}
+			newDeployment := &appsv1.Deployment{
+				ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: "b"},
+				Spec:       appsv1.DeploymentSpec{Replicas: &tt.newReplicas},
+				Status:     appsv1.DeploymentStatus{Replicas: tt.newReplicas},
+			}
+			oldClaim := &corev1.PersistentVolumeClaim{
+				ObjectMeta: metav1.ObjectMeta{Name: "data", Namespace: "a", UID: "old-claim"},
+				Spec:       corev1.PersistentVolumeClaimSpec{VolumeName: "pv"},
+				Status:     corev1.PersistentVolumeClaimStatus{Phase: tt.claimPhase},
+			}
+			volume := &corev1.PersistentVolume{
+				ObjectMeta: metav1.ObjectMeta{Name: "pv", Annotations: map[string]string{danav1.OriginalReclaimPolicy: string(corev1.PersistentVolumeReclaimDelete)}},
+				Spec: corev1.PersistentVolumeSpec{
+					PersistentVolumeReclaimPolicy: corev1.PersistentVolumeReclaimRetain,
+					ClaimRef:                      &corev1.ObjectReference{Kind: "PersistentVolumeClaim", Namespace: "b", Name: "data"},
+				},
+			}
+
+			fakeClient := testutils.NewFakeClient(t,
+				&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "a"}}, &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "b"}},
+				oldDeployment, newDeployment, oldClaim, volume)
+
+			oldNS, err := objectcontext.New(context.Background(), fakeClient, types.NamespacedName{Name: "a"}, &corev1.Namespace{})
+			if err != nil {
+				t.Fatalf("failed to get namespace: %v", err)
+			}
+			newNS, err := objectcontext.New(context.Background(), fakeClient, types.NamespacedName{Name: "b"}, &corev1.Namespace{})
+			if err != nil {
+				t.Fatalf("failed to get namespace: %v", err)
+			}
+
+			done, err := restoreObjects(oldNS, newNS)
+			if err != nil {
+				t.Fatalf("failed to restore objects: %v", err)
+			}
+			if done != tt.expectedDone {
+				t.Errorf("expected done to be %v, got %v", tt.expectedDone, done)
+			}
+
+			restoredDeployment := &appsv1.Deployment{}
+			if err := fakeClient.Get(context.Background(), client.ObjectKey{Name: "app", Namespace: "a"}, restoredDeployment); err != nil {
+				t.Fatalf("failed to get Deployment: %v", err)
+			}
+			if *restoredDeployment.Spec.Replicas != tt.expectedReplicas {
+				t.Errorf("expected the old Deployment to have %v replicas, got %v", tt.expectedReplicas, *restoredDeployment.Spec.Replicas)
+			}
+
+			restoredVolume := &corev1.PersistentVolume{}
+			if err := fakeClient.Get(context.Background(), client.ObjectKey{Name: "pv"}, restoredVolume); err != nil {
+				t.Fatalf("failed to get PersistentVolume: %v", err)
+			}
+			if restoredVolume.Spec.PersistentVolumeReclaimPolicy != tt.expectedPolicy {
+				t.Errorf("expected reclaim policy %q, got %q", tt.expectedPolicy, restoredVolume.Spec.PersistentVolumeReclaimPolicy)
+			}
+
+			// the volume is re-bound to the old claim only once the new Deployment is scaled down
+			expectedClaimUID := oldClaim.UID
+			if tt.newReplicas > 0 {
+				expectedClaimUID = ""
+			}
+			if restoredVolume.Spec.ClaimRef.UID != expectedClaimUID {
+				t.Errorf("expected the volume to be bound to claim %q, got %q", expectedClaimUID, restoredVolume.Spec.ClaimRef.UID)
+			}
+		})
+	}
+}
@@ -0,0 +1,122 @@
+package subnamespacerename
+
+import (
+	"fmt"
+
+	danav1 "github.com/dana-team/hns/api/v1"
+	"github.com/dana-team/hns/internal/namespace/nsutils"
+	"github.com/dana-team/hns/internal/objectcontext"
+	corev1 "k8s.io/api/core/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// shouldRevert returns true if a failed rename was asked to be reverted and was not reverted yet.
+func shouldRevert(renameObject *objectcontext.ObjectContext) bool {
+	rename := renameObject.Object.(*danav1.SubnamespaceRename)
+	return rename.Spec.Revert && (rename.Status.Phase == danav1.Error || rename.Status.Phase == danav1.Reverting)
+}
+
+// revert undoes the completed steps of a failed rename in the opposite order to the one they were completed in.
+// The subnamespace is renamed back in the namespacedb, its children are moved back to the old subnamespace, the
+// Deployments and the PersistentVolumes of the old namespace are restored, and the new subnamespace is finally
+// deleted along with its namespace. A rename can only be reverted as long as its old namespace was not deleted.
+// Every step of the revert can be safely executed again, so a revert which was interrupted is resumed by
+// executing it again.
+func (r *SubnamespaceRenameReconciler) revert(renameObject *objectcontext.ObjectContext) (ctrl.Result, error) {
+	ctx := renameObject.Ctx
+	logger := log.FromContext(ctx)
+
+	currentName := renameObject.Object.(*danav1.SubnamespaceRename).Spec.CurrentNamespace
+	newName := renameObject.Object.(*danav1.SubnamespaceRename).Spec.NewNamespace
+
+	if renameObject.Object.(*danav1.SubnamespaceRename).Status.Phase == danav1.Error {
+		if err := updateRenameStatus(renameObject, danav1.Reverting, ""); err != nil {
+			return ctrl.Result{}, err
+		}
+		logger.Info("successfully updated status of SubnamespaceRename object", "phase", danav1.Reverting)
+	}
+
+	if isStepCompleted(renameObject, danav1.OldNSDeleted) {
+		return ctrl.Result{}, fmt.Errorf("failed reverting rename %q: namespace %q was already deleted", renameObject.Name(), currentName)
+	}
+
+	oldNS, err := objectcontext.New(ctx, r.Client, client.ObjectKey{Name: currentName}, &corev1.Namespace{})
+	if err != nil {
+		return ctrl.Result{}, fmt.Errorf("failed getting namespace object %q: %v", currentName, err.Error())
+	}
+	if !oldNS.IsPresent() {
+		return ctrl.Result{}, fmt.Errorf("failed reverting rename %q: namespace %q does not exist", renameObject.Name(), currentName)
+	}
+
+	newNS, err := objectcontext.New(ctx, r.Client, client.ObjectKey{Name: newName}, &corev1.Namespace{})
+	if err != nil {
+		return ctrl.Result{}, fmt.Errorf("failed getting namespace object %q: %v", newName, err.Error())
+	}
+
+	if isStepCompleted(renameObject, danav1.DBRenamed) {
+		root, err := nsutils.Root(oldNS.Object)
+		if err != nil {
+			return ctrl.Result{}, fmt.Errorf("failed to get root namespace of %q: %v", currentName, err.Error())
+		}
+		r.NamespaceDB.RenameNS(root, newName, currentName)
+
+		if err := completeStep(renameObject, danav1.ChildrenMoved); err != nil {
+			return ctrl.Result{}, err
+		}
+		logger.Info("successfully renamed subnamespace back in namespacedb", "subnamespace", currentName)
+	}
+
+	if newNS.IsPresent() {
+		// the children may have been moved even if the step was not completed, so they are always moved back
+		if err := moveChildren(newNS, oldNS); err != nil {
+			return ctrl.Result{}, fmt.Errorf("failed moving the children of subnamespace %q back to subnamespace %q: %v", newName, currentName, err.Error())
+		}
+		if err := updateNotMovedStatus(renameObject, nil); err != nil {
+			return ctrl.Result{}, err
+		}
+		logger.Info("successfully moved children subnamespaces back to the old subnamespace", "subnamespace", currentName)
+
+		done, err := restoreObjects(oldNS, newNS)
+		if err != nil {
+			return ctrl.Result{}, fmt.Errorf("failed restoring objects of namespace %q: %v", currentName, err.Error())
+		}
+		if !done {
+			logger.Info("waiting for the objects of the old namespace to be restored", "namespace", currentName)
+			return ctrl.Result{RequeueAfter: requeueInterval}, nil
+		}
+		logger.Info("successfully restored objects of the old namespace", "namespace", currentName)
+
+		if err := deleteNS(newNS); err != nil {
+			return ctrl.Result{}, fmt.Errorf("failed deleting namespace %q: %v", newName, err.Error())
+		}
+		logger.Info("successfully deleted new namespace", "namespace", newName)
+	}
+
+	// the new subnamespace may have been created before its namespace was
+	newSNS, err := objectcontext.New(ctx, r.Client, client.ObjectKey{Name: newName, Namespace: nsutils.Parent(oldNS.Object)}, &danav1.Subnamespace{})
+	if err != nil {
+		return ctrl.Result{}, fmt.Errorf("failed getting subnamespace object %q: %v", newName, err.Error())
+	}
+	if err := newSNS.EnsureDelete(); err != nil {
+		return ctrl.Result{}, fmt.Errorf("failed deleting subnamespace %q: %v", newName, err.Error())
+	}
+
+	// no step of the rename is in effect anymore
+	if err := completeStep(renameObject, ""); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	if err := r.enqueueHierarchy(oldNS); err != nil {
+		return ctrl.Result{}, err
+	}
+	logger.Info("successfully enqueued parent and descendants of subnamespace", "subnamespace", currentName)
+
+	if err := updateRenameStatus(renameObject, danav1.Reverted, ""); err != nil {
+		return ctrl.Result{}, err
+	}
+	logger.Info("successfully updated status of SubnamespaceRename object", "phase", danav1.Reverted)
+
+	return ctrl.Result{}, nil
+}
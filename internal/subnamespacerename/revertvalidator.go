@@ -0,0 +1,46 @@
+package subnamespacerename
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	danav1 "github.com/dana-team/hns/api/v1"
+	"github.com/dana-team/hns/internal/common"
+	"github.com/dana-team/hns/internal/namespace/nsutils"
+	"github.com/dana-team/hns/internal/objectcontext"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+// handleRevert validates that a SubnamespaceRename may be reverted by a user. Only a failed rename whose old
+// namespace was not deleted yet can be reverted, and the user needs the same permissions needed to rename it.
+func (v *SubnamespaceRenameValidator) handleRevert(ctx context.Context, rename *danav1.SubnamespaceRename, userInfo authenticationv1.UserInfo) admission.Response {
+	if rename.Status.Phase != danav1.Error {
+		message := fmt.Sprintf("it's forbidden to revert a SubnamespaceRename whose phase is not %q", danav1.Error)
+		return admission.Denied(message)
+	}
+
+	if rename.Status.Step == danav1.OldNSDeleted {
+		message := fmt.Sprintf("it's forbidden to revert a SubnamespaceRename after namespace %q was deleted", rename.Spec.CurrentNamespace)
+		return admission.Denied(message)
+	}
+
+	currentNS, err := objectcontext.New(ctx, v.Client, client.ObjectKey{Name: rename.Spec.CurrentNamespace}, &corev1.Namespace{})
+	if err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+	if response := common.ValidateNamespaceExist(currentNS); !response.Allowed {
+		return response
+	}
+
+	parentNSName := nsutils.Parent(currentNS.Object)
+	ancestors, err := nsutils.Ancestors(currentNS.Object)
+	if err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+
+	return common.ValidatePermissions(ctx, ancestors, parentNSName, parentNSName, parentNSName, userInfo, common.CreateChildVerb, false, v.Client, v.Authorizer)
+}
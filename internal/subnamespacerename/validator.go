@@ -0,0 +1,71 @@
+package subnamespacerename
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"reflect"
+
+	danav1 "github.com/dana-team/hns/api/v1"
+	"github.com/dana-team/hns/internal/common"
+	"github.com/dana-team/hns/internal/objectcontext"
+	admissionv1 "k8s.io/api/admission/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+type SubnamespaceRenameValidator struct {
+	Client     client.Client
+	Decoder    admission.Decoder
+	Authorizer common.Authorizer
+}
+
+// +kubebuilder:webhook:path=/validate-v1-subnamespacerename,mutating=false,sideEffects=NoneOnDryRun,failurePolicy=fail,groups="dana.hns.io",resources=subnamespacerenames,verbs=create;update,versions=v1,name=subnamespacerename.dana.io,admissionReviewVersions=v1;v1beta1
+
+// Handle implements the validation webhook.
+func (v *SubnamespaceRenameValidator) Handle(ctx context.Context, req admission.Request) admission.Response {
+	logger := log.FromContext(ctx).WithValues("webhook", "SubnamespaceRename Webhook", "Name", req.Name)
+	logger.Info("webhook request received")
+
+	renameObject, err := objectcontext.New(ctx, v.Client, types.NamespacedName{}, &danav1.SubnamespaceRename{})
+	if err != nil {
+		logger.Error(err, "failed to create object context")
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+
+	if err := v.Decoder.DecodeRaw(req.Object, renameObject.Object); err != nil {
+		logger.Error(err, "failed to decode object", "request object", req.Object)
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+
+	if req.Operation == admissionv1.Create {
+		if response := v.handleCreate(renameObject, req.UserInfo); !response.Allowed {
+			return response
+		}
+	}
+
+	// deny update of a SubnamespaceRename object after it's already been created
+	if req.Operation == admissionv1.Update {
+		oldRename := &danav1.SubnamespaceRename{}
+		if err := v.Decoder.DecodeRaw(req.OldObject, oldRename); err != nil {
+			logger.Error(err, "could not decode object")
+			return admission.Errored(http.StatusBadRequest, err)
+		}
+		// a failed rename may be asked to be reverted, which is the only change allowed to the spec
+		spec := renameObject.Object.(*danav1.SubnamespaceRename).Spec
+		if spec.Revert && !oldRename.Spec.Revert {
+			if response := v.handleRevert(ctx, oldRename, req.UserInfo); !response.Allowed {
+				return response
+			}
+			spec.Revert = false
+		}
+		if !reflect.DeepEqual(spec, oldRename.Spec) {
+			message := fmt.Sprintf("it is forbidden to update an object of type %q", oldRename.TypeMeta.Kind)
+			return admission.Denied(message)
+		}
+	}
+
+	return admission.Allowed("all validations passed")
+}
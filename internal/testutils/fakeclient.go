@@ -0,0 +1,29 @@
+// Package testutils holds the fixtures shared by the unit tests of the internal packages. The behavior of HNS
+// against a real cluster is tested by the e2e tests; unit tests cover logic which can be exercised against a
+// fake client, and build it with NewFakeClient.
+package testutils
+
+import (
+	"testing"
+
+	danav1 "github.com/dana-team/hns/api/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+// NewFakeClient returns a fake client which knows the built-in types and the HNS types, holding the given objects.
+func NewFakeClient(t *testing.T, objects ...client.Object) client.Client {
+	t.Helper()
+
+	scheme := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(scheme); err != nil {
+		t.Fatalf("failed to add to scheme: %v", err)
+	}
+	if err := danav1.AddToScheme(scheme); err != nil {
+		t.Fatalf("failed to add to scheme: %v", err)
+	}
+
+	return fake.NewClientBuilder().WithScheme(scheme).WithObjects(objects...).Build()
+}
@@ -3,10 +3,9 @@ package updatequota
 import (
 	"context"
 	"fmt"
-	"strings"
-	"time"
 
 	"github.com/dana-team/hns/internal/common"
+	"github.com/dana-team/hns/internal/enqueuer"
 	"github.com/dana-team/hns/internal/namespace/nsutils"
 	"github.com/dana-team/hns/internal/objectcontext"
 	"github.com/dana-team/hns/internal/quota"
@@ -17,25 +16,31 @@ import (
 	"github.com/go-logr/logr"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
 )
 
 // UpdateQuotaReconciler reconciles a UpdateQuota object
 type UpdateQuotaReconciler struct {
 	client.Client
-	Scheme *runtime.Scheme
+	Scheme                  *runtime.Scheme
+	UPQEvents               *enqueuer.Enqueuer
+	Scheduler               *Scheduler
+	MaxConcurrentReconciles int
 }
 
 // +kubebuilder:rbac:groups=dana.hns.io,resources=updatequota,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=dana.hns.io,resources=updatequota/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups=user.openshift.io,resources=groups,verbs=get;list;watch
-// +kubebuilder:rbac:groups="",resources=users,verbs=impersonate
-// +kubebuilder:rbac:groups="",resources=serviceaccounts,verbs=impersonate
+// +kubebuilder:rbac:groups=authorization.k8s.io,resources=subjectaccessreviews,verbs=create
 
 func (r *UpdateQuotaReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&danav1.Updatequota{}).
+		WatchesRawSource(r.UPQEvents).
+		WithOptions(controller.Options{MaxConcurrentReconciles: r.MaxConcurrentReconciles}).
 		Complete(r)
 }
 
@@ -50,6 +55,7 @@ func (r *UpdateQuotaReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 
 	if !upqObject.IsPresent() {
 		logger.Info("resource not found. Ignoring since object must be deleted")
+		r.Scheduler.Release(req.NamespacedName)
 		return ctrl.Result{}, nil
 	}
 
@@ -81,14 +87,35 @@ func (r *UpdateQuotaReconciler) reconcile(upqObject *objectcontext.ObjectContext
 	// get the Ancestor namespace of the source and destination namespaces. The Ancestor namespace is the
 	// first namespace the two namespaces have in common in their hierarchy. There are several cases and
 	// each case is treated differently based on the Ancestor namespace
-	sourceNSSliced := nsutils.DisplayNameSlice(sourceNS)
-	destNSSliced := nsutils.DisplayNameSlice(destNS)
+	sourceNSSliced, err := nsutils.Ancestors(sourceNS.Object)
+	if err != nil {
+		return fmt.Errorf("failed to get ancestors of %q: %v", sourceNSName, err.Error())
+	}
+
+	destNSSliced, err := nsutils.Ancestors(destNS.Object)
+	if err != nil {
+		return fmt.Errorf("failed to get ancestors of %q: %v", destNSName, err.Error())
+	}
 
 	ancestorNSName, _, err := snsutils.GetAncestor(sourceNSSliced, destNSSliced)
 	if err != nil {
 		return fmt.Errorf("failed to find ancestor namespace of %q and %q: %v", sourceNSSliced, destNSSliced, err.Error())
 	}
 
+	// operations which share a namespace on their path do read-modify-write on the same subnamespaces or draw
+	// from the same free resources, so they are run one after the other rather than in parallel
+	path, err := getSnsPath(ancestorNSName, sourceNS, destNS)
+	if err != nil {
+		return err
+	}
+
+	key := types.NamespacedName{Name: upqObject.Name(), Namespace: upqObject.Object.GetNamespace()}
+	if acquired, position := r.Scheduler.Acquire(key, path, r.UPQEvents); !acquired {
+		upqObject.Log.Info("waiting for operations on overlapping subnamespaces", "position", position)
+		return updateUPQPendingStatus(upqObject, position)
+	}
+	defer r.Scheduler.Release(key)
+
 	if isNSAncestor(sourceNSName, ancestorNSName) {
 		if err := moveResourcesDown(ancestorNSName, destNS, upqObject); err != nil {
 			updateErr := updateUPQStatus(upqObject, danav1.Error, err.Error())
@@ -126,6 +153,28 @@ func (r *UpdateQuotaReconciler) reconcile(upqObject *objectcontext.ObjectContext
 	return nil
 }
 
+// getSnsPath returns the names of the namespaces whose quota is updated when moving resources from `sourceNS`
+// up to `ancestorNS` and from `ancestorNS` down to `destNS`, together with `ancestorNS`. The quota of the ancestor
+// is not updated, but the resources are drawn from and returned to its free resources, so operations which share
+// an ancestor must not run in parallel even if their paths below the ancestor are disjoint.
+func getSnsPath(ancestorNS string, sourceNS, destNS *objectcontext.ObjectContext) ([]string, error) {
+	path := []string{ancestorNS}
+	for _, ns := range []*objectcontext.ObjectContext{sourceNS, destNS} {
+		namespaces, err := nsutils.Ancestors(ns.Object)
+		if err != nil {
+			return nil, err
+		}
+
+		index, err := common.IndexOf(ancestorNS, namespaces)
+		if err != nil {
+			return nil, err
+		}
+		path = append(path, namespaces[index+1:]...)
+	}
+
+	return path, nil
+}
+
 // isNSAncestor returns true if the namespace and ancestor are the same.
 func isNSAncestor(namespace, ancestor string) bool {
 	return namespace == ancestor
@@ -199,8 +248,10 @@ func moveResourcesUp(ancestorNS string, ns, upqObject *objectcontext.ObjectConte
 
 // getSnsListDown creates a slice of all subnamespaces in the hierarchy from `ancestorNS` to `ns`.
 func getSnsListDown(ancestorNS string, ns *objectcontext.ObjectContext) ([]*objectcontext.ObjectContext, error) {
-	displayName := ns.Object.GetAnnotations()[danav1.DisplayName]
-	namespaces := strings.Split(displayName, "/")
+	namespaces, err := nsutils.Ancestors(ns.Object)
+	if err != nil {
+		return nil, err
+	}
 
 	index, err := common.IndexOf(ancestorNS, namespaces)
 	if err != nil {
@@ -222,8 +273,10 @@ func getSnsListDown(ancestorNS string, ns *objectcontext.ObjectContext) ([]*obje
 
 // getSnsListUp creates a slice of all subnamespaces in the hierarchy from `ns` to `ancestorNS`.
 func getSnsListUp(ns *objectcontext.ObjectContext, ancestorNS string) ([]*objectcontext.ObjectContext, error) {
-	displayName := ns.Object.GetAnnotations()[danav1.DisplayName]
-	namespaces := strings.Split(displayName, "/")
+	namespaces, err := nsutils.Ancestors(ns.Object)
+	if err != nil {
+		return nil, err
+	}
 
 	index, err := common.IndexOf(ancestorNS, namespaces)
 	if err != nil {
@@ -244,19 +297,11 @@ func getSnsListUp(ns *objectcontext.ObjectContext, ancestorNS string) ([]*object
 }
 
 // addSnsQuota updates the resource quota for a subnamespace by adding the quota specified
-// in quotaSpec to the existing quota. It retrieves the existing quota from the subnamespace object and
-// loops through each resource, adding the requested quota if it is specified.
+// in quotaSpec to the existing quota. Resources which are missing from the existing quota are added to it.
 func addSnsQuota(sns *objectcontext.ObjectContext, quotaSpec corev1.ResourceQuotaSpec) error {
 	err := sns.EnsureUpdateObject(func(object client.Object, l logr.Logger) (client.Object, logr.Logger, error) {
 		snsQuota := object.(*danav1.Subnamespace).Spec.ResourceQuotaSpec
-		for resourceName := range snsQuota.Hard {
-			var (
-				before, _  = snsQuota.Hard[resourceName]
-				request, _ = quotaSpec.Hard[resourceName]
-			)
-			before.Set(before.Value() + request.Value())
-			object.(*danav1.Subnamespace).Spec.ResourceQuotaSpec.Hard[resourceName] = before
-		}
+		object.(*danav1.Subnamespace).Spec.ResourceQuotaSpec.Hard = quota.AddResourceLists(snsQuota.Hard, quotaSpec.Hard)
 		return object, l, nil
 	}, false)
 
@@ -267,7 +312,7 @@ func addSnsQuota(sns *objectcontext.ObjectContext, quotaSpec corev1.ResourceQuot
 	// since the update of the subnamespace spec done above triggers reconciliation for the subnamespace,
 	// it is needed to make sure that its reconciliation completes successfully before continuing;
 	// a race condition can be created if this is not ensured, potentially causing the UpdateQuota to fail
-	err = ensureSnsEqualQuota(sns)
+	err = quota.EnsureSubnamespaceObjectEqual(sns)
 	if err != nil {
 		return err
 	}
@@ -276,19 +321,11 @@ func addSnsQuota(sns *objectcontext.ObjectContext, quotaSpec corev1.ResourceQuot
 }
 
 // subSnsQuota updates the resource quota for a subnamespace by subtracting the quota specified
-// in quotaSpec from the existing quota. It retrieves the existing quota from the subnamespace object and
-// loops through each resource, subtracting the requested quota if it is specified.
+// in quotaSpec from the existing quota. Resources which are missing from the existing quota are subtracted from zero.
 func subSnsQuota(sns *objectcontext.ObjectContext, quotaSpec corev1.ResourceQuotaSpec) error {
 	err := sns.EnsureUpdateObject(func(object client.Object, l logr.Logger) (client.Object, logr.Logger, error) {
 		snsQuota := object.(*danav1.Subnamespace).Spec.ResourceQuotaSpec
-		for resourceName := range snsQuota.Hard {
-			var (
-				before, _  = snsQuota.Hard[resourceName]
-				request, _ = quotaSpec.Hard[resourceName]
-			)
-			before.Set(before.Value() - request.Value())
-			object.(*danav1.Subnamespace).Spec.ResourceQuotaSpec.Hard[resourceName] = before
-		}
+		object.(*danav1.Subnamespace).Spec.ResourceQuotaSpec.Hard = quota.SubResourceLists(snsQuota.Hard, quotaSpec.Hard)
 		return object, l, nil
 	}, false)
 
@@ -299,7 +336,7 @@ func subSnsQuota(sns *objectcontext.ObjectContext, quotaSpec corev1.ResourceQuot
 	// since the update of the subnamespace spec done above triggers reconciliation for the subnamespace,
 	// it is needed to make sure that its reconciliation completes successfully before continuing;
 	// a race condition can be created if this is not ensured, potentially causing the UpdateQuota to fail
-	err = ensureSnsEqualQuota(sns)
+	err = quota.EnsureSubnamespaceObjectEqual(sns)
 	if err != nil {
 		return err
 	}
@@ -307,32 +344,17 @@ func subSnsQuota(sns *objectcontext.ObjectContext, quotaSpec corev1.ResourceQuot
 	return nil
 }
 
-// ensureSnsEqualQuota compares the sns quota spec and the resource quota spec in a loop until they are equal,
-// this way we can know that the subnamespace has been properly updated before doing the updatequota operation.
-func ensureSnsEqualQuota(sns *objectcontext.ObjectContext) error {
-	ok := false
-	retries := 0
-
-	snsQuotaSpec := sns.Object.(*danav1.Subnamespace).Spec.ResourceQuotaSpec
+// updateUPQPendingStatus sets the status of the UPQ object to Pending with its position in the queue
+// of operations on overlapping subnamespaces, unless the status is already up to date.
+func updateUPQPendingStatus(upqObject *objectcontext.ObjectContext, position int) error {
+	reason := fmt.Sprintf("waiting for %d operations on overlapping subnamespaces to complete", position)
 
-	// To avoid an infinite loop in case of an actual failure, the loop runs at most a MAX_RETRIES number times
-	for (!ok) && (retries < danav1.MaxRetries) {
-		ok = true
-		quotaObject, err := quota.SubnamespaceObject(sns)
-		if err != nil {
-			return err
-		}
-		resourceQuotaSpec := quota.GetQuotaObjectSpec(quotaObject.Object)
-		for res := range resourceQuotaSpec.Hard {
-			if snsQuotaSpec.Hard[res] != resourceQuotaSpec.Hard[res] {
-				ok = false
-			}
-		}
-		// wait between iterations because we don't want to overload the API with many requests
-		time.Sleep(danav1.SleepTimeout * time.Millisecond)
-		retries++
+	status := upqObject.Object.(*danav1.Updatequota).Status
+	if status.Phase == danav1.Pending && status.Reason == reason {
+		return nil
 	}
-	return nil
+
+	return updateUPQStatus(upqObject, danav1.Pending, reason)
 }
 
 // updateUPQStatus updates the status of the UPQ object.
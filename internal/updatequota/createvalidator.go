@@ -10,6 +10,7 @@ import (
 	"github.com/dana-team/hns/internal/objectcontext"
 	"github.com/dana-team/hns/internal/quota"
 	"github.com/dana-team/hns/internal/subnamespace/snsutils"
+	authenticationv1 "k8s.io/api/authentication/v1"
 	corev1 "k8s.io/api/core/v1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/log"
@@ -18,7 +19,7 @@ import (
 
 // handleCreate implements the non-boilerplate logic of the validator, allowing it to be more easily unit
 // tested (i.e. without constructing a full admission.Request).
-func (v *UpdateQuotaValidator) handleCreate(upqObject *objectcontext.ObjectContext, username string) admission.Response {
+func (v *UpdateQuotaValidator) handleCreate(upqObject *objectcontext.ObjectContext, userInfo authenticationv1.UserInfo) admission.Response {
 
 	ctx := upqObject.Ctx
 	logger := log.FromContext(ctx)
@@ -45,8 +46,21 @@ func (v *UpdateQuotaValidator) handleCreate(upqObject *objectcontext.ObjectConte
 		return response
 	}
 
-	sourceNSSliced := nsutils.DisplayNameSlice(sourceNS)
-	destNSSliced := nsutils.DisplayNameSlice(destNS)
+	sourceNSSliced, err := nsutils.Ancestors(sourceNS.Object)
+	if err != nil {
+		logger.Error(err, "failed to get ancestors", "source namespace", sourceNSName)
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+
+	destNSSliced, err := nsutils.Ancestors(destNS.Object)
+	if err != nil {
+		logger.Error(err, "failed to get ancestors", "destination namespace", destNSName)
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+	if response := common.ValidateSameRoot(sourceNSSliced, destNSSliced); !response.Allowed {
+		return response
+	}
+
 	ancestorNSName, isAncestorRoot, err := snsutils.GetAncestor(sourceNSSliced, destNSSliced)
 	if err != nil {
 		logger.Error(err, "failed to get ancestor", "source namespace", sourceNSName, "destination namespace", destNSName)
@@ -61,7 +75,7 @@ func (v *UpdateQuotaValidator) handleCreate(upqObject *objectcontext.ObjectConte
 		}
 	}
 
-	if response := common.ValidatePermissions(ctx, sourceNSSliced, sourceNSName, destNSName, ancestorNSName, username, true, v.Client); !response.Allowed {
+	if response := common.ValidatePermissions(ctx, sourceNSSliced, sourceNSName, destNSName, ancestorNSName, userInfo, common.TransferQuotaVerb, true, v.Client, v.Authorizer); !response.Allowed {
 		return response
 	}
 
@@ -6,6 +6,8 @@ import (
 	"net/http"
 
 	danav1 "github.com/dana-team/hns/api/v1"
+	"github.com/dana-team/hns/internal/common"
+	"github.com/dana-team/hns/internal/quota"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
@@ -27,6 +29,19 @@ func (m *UpdateQuotaMutator) Handle(ctx context.Context, req admission.Request)
 		logger.Error(err, "failed to decode object", "request object", req.Object)
 		return admission.Errored(http.StatusBadRequest, err)
 	}
+
+	aliases, err := common.GetResourceAliases(ctx, m.Client)
+	if err != nil {
+		logger.Error(err, "failed to get resource aliases")
+		return admission.Errored(http.StatusInternalServerError, err)
+	}
+
+	quotaSpec, err := quota.NormalizeResourceQuotaSpec(updateQuota.Spec.ResourceQuotaSpec, aliases)
+	if err != nil {
+		return admission.Denied(err.Error())
+	}
+	updateQuota.Spec.ResourceQuotaSpec = quotaSpec
+
 	marshalUpdateQuota, err := m.UpdateRequester(updateQuota, req.UserInfo.Username)
 	if err != nil {
 		logger.Error(err, "failed to marshal object", "object", updateQuota)
@@ -41,7 +56,7 @@ func (m *UpdateQuotaMutator) UpdateRequester(updateQuotaObject danav1.Updatequot
 	if updateQuotaObject.Annotations == nil {
 		updateQuotaObject.Annotations = make(map[string]string)
 	}
-	updateQuotaObject.Annotations["requester"] = requester
+	updateQuotaObject.Annotations[danav1.Requester] = requester
 	marshalUpdateQuota, err := json.Marshal(updateQuotaObject)
 	if err != nil {
 		return nil, err
@@ -0,0 +1,136 @@
+package updatequota
+
+import (
+	"sync"
+
+	"github.com/dana-team/hns/internal/enqueuer"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// operation is an operation on the quota of subnamespaces, the subnamespaces on its path, and the
+// Enqueuer of the controller which executes it.
+type operation struct {
+	key    types.NamespacedName
+	path   map[string]bool
+	events *enqueuer.Enqueuer
+}
+
+// overlaps returns true if the two operations share a subnamespace on their path.
+func (o operation) overlaps(other operation) bool {
+	for sns := range o.path {
+		if other.path[sns] {
+			return true
+		}
+	}
+	return false
+}
+
+// Scheduler schedules operations on the quota of subnamespaces, i.e. UpdateQuotas and the quota transfers of
+// MigrationHierarchies, according to the subnamespaces on their path. Operations which share a subnamespace on
+// their path are run one after the other in the order they were scheduled in, while operations whose paths are
+// disjoint are run in parallel. The order is only kept in memory, so it is lost when the manager restarts.
+type Scheduler struct {
+	mu      sync.Mutex
+	running []operation
+	waiting []operation
+}
+
+// NewScheduler returns an empty Scheduler.
+func NewScheduler() *Scheduler {
+	return &Scheduler{}
+}
+
+// Acquire schedules the operation with the given key on the given path of subnamespaces. It returns true if the
+// operation can run; otherwise, it returns false along with the position of the operation in the queue, which is
+// the number of running or waiting operations which were scheduled before it and share a subnamespace with it.
+// A waiting operation is enqueued in the given Enqueuer once an operation it waits for is released.
+func (s *Scheduler) Acquire(key types.NamespacedName, path []string, events *enqueuer.Enqueuer) (bool, int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, op := range s.running {
+		if op.key == key {
+			return true, 0
+		}
+	}
+
+	current := operation{key: key, path: map[string]bool{}, events: events}
+	for _, sns := range path {
+		current.path[sns] = true
+	}
+
+	position := 0
+	for _, op := range s.running {
+		if op.overlaps(current) {
+			position++
+		}
+	}
+
+	index := len(s.waiting)
+	for i, op := range s.waiting {
+		if op.key == key {
+			index = i
+			break
+		}
+		if op.overlaps(current) {
+			position++
+		}
+	}
+
+	if position == 0 {
+		if index < len(s.waiting) {
+			s.waiting = append(s.waiting[:index], s.waiting[index+1:]...)
+		}
+		s.running = append(s.running, current)
+		return true, 0
+	}
+
+	if index < len(s.waiting) {
+		s.waiting[index] = current
+	} else {
+		s.waiting = append(s.waiting, current)
+	}
+
+	return false, position
+}
+
+// Release removes the operation with the given key from the Scheduler, whether it is running or waiting.
+// The waiting operations which shared a subnamespace with it are enqueued so that they are retried, and
+// their keys are returned.
+func (s *Scheduler) Release(key types.NamespacedName) []types.NamespacedName {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var released operation
+	found := false
+	s.running, released, found = removeOperation(s.running, key)
+	if !found {
+		s.waiting, released, found = removeOperation(s.waiting, key)
+	}
+	if !found {
+		return nil
+	}
+
+	var keys []types.NamespacedName
+	for _, op := range s.waiting {
+		if op.overlaps(released) {
+			keys = append(keys, op.key)
+			if op.events != nil {
+				op.events.Enqueue(op.key.Name, op.key.Namespace)
+			}
+		}
+	}
+
+	return keys
+}
+
+// removeOperation removes the operation with the given key from a slice of operations, and returns
+// the resulting slice, the removed operation and whether it was found.
+func removeOperation(ops []operation, key types.NamespacedName) ([]operation, operation, bool) {
+	for i, op := range ops {
+		if op.key == key {
+			return append(ops[:i], ops[i+1:]...), op, true
+		}
+	}
+	return ops, operation{}, false
+}
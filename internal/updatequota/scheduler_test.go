@@ -0,0 +1,95 @@
+package updatequota
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/dana-team/hns/internal/namespace/nsutils"
+	"github.com/dana-team/hns/internal/objectcontext"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func TestScheduler(t *testing.T) {
+	first := types.NamespacedName{Name: "first", Namespace: "a"}
+	second := types.NamespacedName{Name: "second", Namespace: "b"}
+	third := types.NamespacedName{Name: "third", Namespace: "c"}
+	disjoint := types.NamespacedName{Name: "disjoint", Namespace: "d"}
+
+	scheduler := NewScheduler()
+
+	if acquired, _ := scheduler.Acquire(first, []string{"a", "b"}, nil); !acquired {
+		t.Fatalf("expected the first operation to run")
+	}
+	if acquired, _ := scheduler.Acquire(first, []string{"a", "b"}, nil); !acquired {
+		t.Errorf("expected a running operation to keep running")
+	}
+	if acquired, _ := scheduler.Acquire(disjoint, []string{"d", "e"}, nil); !acquired {
+		t.Errorf("expected an operation with a disjoint path to run in parallel")
+	}
+
+	if acquired, position := scheduler.Acquire(second, []string{"b", "c"}, nil); acquired || position != 1 {
+		t.Errorf("expected an overlapping operation to wait at position 1, got acquired %v at position %d", acquired, position)
+	}
+	if acquired, position := scheduler.Acquire(third, []string{"c"}, nil); acquired || position != 1 {
+		t.Errorf("expected an operation overlapping a waiting operation to wait at position 1, got acquired %v at position %d", acquired, position)
+	}
+
+	if got, want := scheduler.Release(first), []types.NamespacedName{second}; !reflect.DeepEqual(got, want) {
+		t.Errorf("expected releasing an operation to return the overlapping waiting operations %v, got %v", want, got)
+	}
+	if acquired, _ := scheduler.Acquire(third, []string{"c"}, nil); acquired {
+		t.Errorf("expected an operation not to run before an overlapping operation which was scheduled before it")
+	}
+	if acquired, _ := scheduler.Acquire(second, []string{"b", "c"}, nil); !acquired {
+		t.Errorf("expected a waiting operation to run once the operations it overlaps are released")
+	}
+
+	scheduler.Release(second)
+	if acquired, _ := scheduler.Acquire(third, []string{"c"}, nil); !acquired {
+		t.Errorf("expected the last waiting operation to run")
+	}
+	if got := scheduler.Release(types.NamespacedName{Name: "missing"}); got != nil {
+		t.Errorf("expected releasing an unknown operation to return nothing, got %v", got)
+	}
+}
+
+func TestGetSnsPathIncludesAncestor(t *testing.T) {
+	namespace := func(hierarchy string) *objectcontext.ObjectContext {
+		ancestors := strings.Split(hierarchy, "/")
+		labels := map[string]string{}
+		for i, ancestor := range ancestors {
+			labels[nsutils.TreeLabel(ancestor)] = strconv.Itoa(len(ancestors) - 1 - i)
+		}
+
+		return &objectcontext.ObjectContext{Object: &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{
+			Name:   ancestors[len(ancestors)-1],
+			Labels: labels,
+		}}}
+	}
+
+	first, err := getSnsPath("ancestor", namespace("root/ancestor"), namespace("root/ancestor/child1"))
+	if err != nil {
+		t.Fatalf("failed to get path: %v", err)
+	}
+	second, err := getSnsPath("ancestor", namespace("root/ancestor/child2"), namespace("root/ancestor"))
+	if err != nil {
+		t.Fatalf("failed to get path: %v", err)
+	}
+
+	if want := []string{"ancestor", "child1"}; !reflect.DeepEqual(first, want) {
+		t.Errorf("expected path %v, got %v", want, first)
+	}
+
+	// operations which draw from and return to the free resources of the same ancestor must not run in parallel
+	scheduler := NewScheduler()
+	if acquired, _ := scheduler.Acquire(types.NamespacedName{Name: "first"}, first, nil); !acquired {
+		t.Fatalf("expected the first operation to run")
+	}
+	if acquired, _ := scheduler.Acquire(types.NamespacedName{Name: "second"}, second, nil); acquired {
+		t.Errorf("expected an operation with the same ancestor to wait")
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"reflect"
 
 	danav1 "github.com/dana-team/hns/api/v1"
+	"github.com/dana-team/hns/internal/common"
 	"github.com/dana-team/hns/internal/objectcontext"
 	admissionv1 "k8s.io/api/admission/v1"
 	"k8s.io/apimachinery/pkg/types"
@@ -16,8 +17,9 @@ import (
 )
 
 type UpdateQuotaValidator struct {
-	Client  client.Client
-	Decoder admission.Decoder
+	Client     client.Client
+	Decoder    admission.Decoder
+	Authorizer common.Authorizer
 }
 
 // +kubebuilder:webhook:path=/validate-v1-updatequota,mutating=false,sideEffects=NoneOnDryRun,failurePolicy=fail,groups="dana.hns.io",resources=updatequota,verbs=create;update,versions=v1,name=updatequota.dana.io,admissionReviewVersions=v1;v1beta1
@@ -40,21 +42,23 @@ func (v *UpdateQuotaValidator) Handle(ctx context.Context, req admission.Request
 	}
 
 	if req.Operation == admissionv1.Create {
-		username := req.UserInfo.Username
-		if response := v.handleCreate(upqObject, username); !response.Allowed {
+		if response := v.handleCreate(upqObject, req.UserInfo); !response.Allowed {
 			return response
 		}
 	}
 
 	// deny update of an UpdateQuota object after it's already been created
-	// (i.e. the Phase in the Status is not empty)
+	// (i.e. the Phase in the Status is not empty), except for the update of the Status
+	// of an UpdateQuota which is waiting for operations on overlapping subnamespaces
 	if req.Operation == admissionv1.Update {
 		oldUPQ := &danav1.Updatequota{}
 		if err := v.Decoder.DecodeRaw(req.OldObject, oldUPQ); err != nil {
 			logger.Error(err, "failed to decode object", "request object", req.OldObject)
 			return admission.Errored(http.StatusBadRequest, err)
 		}
-		if !reflect.ValueOf(oldUPQ.Status).IsZero() {
+		isPendingStatusUpdate := oldUPQ.Status.Phase == danav1.Pending &&
+			reflect.DeepEqual(oldUPQ.Spec, upqObject.Object.(*danav1.Updatequota).Spec)
+		if !reflect.ValueOf(oldUPQ.Status).IsZero() && !isPendingStatusUpdate {
 			message := fmt.Sprintf("it is forbidden to update an object of type %q", oldUPQ.TypeMeta.Kind)
 			return admission.Denied(message)
 		}
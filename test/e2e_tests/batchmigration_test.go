@@ -0,0 +1,100 @@
+package e2e_tests
+
+import (
+	danav1 "github.com/dana-team/hns/api/v1"
+	. "github.com/dana-team/hns/test/testutils"
+	. "github.com/onsi/ginkgo/v2"
+)
+
+var _ = Describe("BatchMigration", func() {
+	testPrefix := "bm-test"
+	var randPrefix string
+	var nsRoot string
+
+	BeforeEach(func() {
+		randPrefix = RandStr()
+
+		CleanupTestNamespaces(randPrefix)
+		CleanupTestBatchMigrations(randPrefix)
+
+		nsRoot = GenerateE2EName("root", testPrefix, randPrefix)
+		CreateRootNS(nsRoot, randPrefix, rqDepth)
+		CreateResourceQuota(nsRoot, nsRoot, storage, "100Gi", cpu, "100", memory, "100Gi", pods, "100", gpu, "100")
+	})
+
+	AfterEach(func() {
+		CleanupTestNamespaces(randPrefix)
+		CleanupTestBatchMigrations(randPrefix)
+	})
+
+	It("should migrate all the subnamespaces of the plan", func() {
+		nsA := GenerateE2EName("a", testPrefix, randPrefix)
+		nsB := GenerateE2EName("b", testPrefix, randPrefix)
+		nsC := GenerateE2EName("c", testPrefix, randPrefix)
+		nsD := GenerateE2EName("d", testPrefix, randPrefix)
+
+		CreateSubnamespace(nsA, nsRoot, randPrefix, false, storage, "40Gi", cpu, "40", memory, "40Gi", pods, "40", gpu, "40")
+		CreateSubnamespace(nsB, nsRoot, randPrefix, false, storage, "40Gi", cpu, "40", memory, "40Gi", pods, "40", gpu, "40")
+		CreateSubnamespace(nsC, nsA, randPrefix, false, storage, "10Gi", cpu, "10", memory, "10Gi", pods, "10", gpu, "10")
+		CreateSubnamespace(nsD, nsA, randPrefix, false, storage, "10Gi", cpu, "10", memory, "10Gi", pods, "10", gpu, "10")
+
+		bmName := GenerateE2EName("bm", testPrefix, randPrefix)
+		CreateBatchMigration(bmName, nsC, nsB, nsD, nsB)
+		LabelTestingBatchMigrations(bmName, randPrefix)
+		FieldShouldContain("batchmigration", "", bmName, ".status.phase", "Complete")
+
+		// make sure all the subnamespaces were migrated and the parents were updated
+		FieldShouldContain("subnamespace", nsB, nsC, ".metadata.namespace", nsB)
+		FieldShouldContain("subnamespace", nsB, nsD, ".metadata.namespace", nsB)
+		FieldShouldContain("namespace", "", nsC, ".metadata.labels", danav1.Parent+":"+nsB)
+		FieldShouldContain("namespace", "", nsD, ".metadata.labels", danav1.Parent+":"+nsB)
+
+		// make sure every migration was executed by a MigrationHierarchy owned by the BatchMigration
+		FieldShouldContain("batchmigration", "", bmName, ".status.migrations", "Complete")
+		FieldShouldContain("migrationhierarchy", "", bmName+"-0", ".metadata.labels", danav1.BatchMigrationLabel+":"+bmName)
+	})
+
+	It("should migrate a subnamespace to be under its descendant when the descendant is migrated out first", func() {
+		nsA := GenerateE2EName("a", testPrefix, randPrefix)
+		nsB := GenerateE2EName("b", testPrefix, randPrefix)
+
+		CreateSubnamespace(nsA, nsRoot, randPrefix, false, storage, "20Gi", cpu, "20", memory, "20Gi", pods, "20", gpu, "20")
+		CreateSubnamespace(nsB, nsA, randPrefix, false, storage, "10Gi", cpu, "10", memory, "10Gi", pods, "10", gpu, "10")
+
+		bmName := GenerateE2EName("bm", testPrefix, randPrefix)
+		CreateBatchMigration(bmName, nsA, nsB, nsB, nsRoot)
+		LabelTestingBatchMigrations(bmName, randPrefix)
+		FieldShouldContain("batchmigration", "", bmName, ".status.phase", "Complete")
+
+		// make sure the hierarchy was inverted
+		FieldShouldContain("namespace", "", nsB, ".metadata.labels", danav1.Parent+":"+nsRoot)
+		FieldShouldContain("namespace", "", nsA, ".metadata.labels", danav1.Parent+":"+nsB)
+
+		// delete the migrated subnamespace before its new parent
+		MustRun("kubectl delete ns", nsA)
+	})
+
+	It("should not create a BatchMigration whose plan creates a loop", func() {
+		nsA := GenerateE2EName("a", testPrefix, randPrefix)
+		nsB := GenerateE2EName("b", testPrefix, randPrefix)
+
+		CreateSubnamespace(nsA, nsRoot, randPrefix, false, storage, "10Gi", cpu, "10", memory, "10Gi", pods, "10", gpu, "10")
+		CreateSubnamespace(nsB, nsRoot, randPrefix, false, storage, "10Gi", cpu, "10", memory, "10Gi", pods, "10", gpu, "10")
+
+		bmName := GenerateE2EName("bm", testPrefix, randPrefix)
+		ShouldNotCreateBatchMigration(bmName, nsA, nsB, nsB, nsA)
+	})
+
+	It("should not create a BatchMigration which migrates the same subnamespace more than once", func() {
+		nsA := GenerateE2EName("a", testPrefix, randPrefix)
+		nsB := GenerateE2EName("b", testPrefix, randPrefix)
+		nsC := GenerateE2EName("c", testPrefix, randPrefix)
+
+		CreateSubnamespace(nsA, nsRoot, randPrefix, false, storage, "10Gi", cpu, "10", memory, "10Gi", pods, "10", gpu, "10")
+		CreateSubnamespace(nsB, nsRoot, randPrefix, false, storage, "10Gi", cpu, "10", memory, "10Gi", pods, "10", gpu, "10")
+		CreateSubnamespace(nsC, nsRoot, randPrefix, false, storage, "10Gi", cpu, "10", memory, "10Gi", pods, "10", gpu, "10")
+
+		bmName := GenerateE2EName("bm", testPrefix, randPrefix)
+		ShouldNotCreateBatchMigration(bmName, nsC, nsA, nsC, nsB)
+	})
+})
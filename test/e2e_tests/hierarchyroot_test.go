@@ -0,0 +1,91 @@
+package e2e_tests
+
+import (
+	danav1 "github.com/dana-team/hns/api/v1"
+	. "github.com/dana-team/hns/test/testutils"
+	. "github.com/onsi/ginkgo/v2"
+)
+
+var _ = Describe("HierarchyRoot", func() {
+	testPrefix := "hr-test"
+	var randPrefix string
+	var nsRoot string
+
+	BeforeEach(func() {
+		randPrefix = RandStr()
+
+		CleanupTestNamespaces(randPrefix)
+		CleanupTestHierarchyRoots(randPrefix)
+
+		nsRoot = GenerateE2EName("root", testPrefix, randPrefix)
+	})
+
+	AfterEach(func() {
+		CleanupTestNamespaces(randPrefix)
+		CleanupTestHierarchyRoots(randPrefix)
+	})
+
+	It("should create the root namespace with its annotations and ResourceQuota", func() {
+		CreateHierarchyRoot(nsRoot, randPrefix, rqDepth, nil, storage, "100Gi", cpu, "100", memory, "100Gi", pods, "100", gpu, "100")
+
+		FieldShouldContain("namespace", "", nsRoot, ".metadata.labels", danav1.Hns+":true")
+		FieldShouldContain("namespace", "", nsRoot, ".metadata.annotations", danav1.Role+":"+danav1.Root)
+		FieldShouldContain("namespace", "", nsRoot, ".metadata.annotations", danav1.RootCrqSelector+":"+nsRoot)
+		FieldShouldContain("namespace", "", nsRoot, ".metadata.annotations", danav1.RqDepth+":2")
+		FieldShouldContain("resourcequota", nsRoot, nsRoot, ".spec.hard", cpu+":100")
+		FieldShouldContain("hierarchyroot", "", nsRoot, ".status.phase", string(danav1.Created))
+	})
+
+	It("should update the ResourceQuota of the root namespace", func() {
+		CreateHierarchyRoot(nsRoot, randPrefix, rqDepth, nil, storage, "100Gi", cpu, "100", memory, "100Gi", pods, "100", gpu, "100")
+		CreateHierarchyRoot(nsRoot, randPrefix, rqDepth, nil, storage, "100Gi", cpu, "200", memory, "100Gi", pods, "100", gpu, "100")
+
+		FieldShouldContain("resourcequota", nsRoot, nsRoot, ".spec.hard", cpu+":200")
+	})
+
+	It("should annotate the declared secondary roots", func() {
+		nsA := GenerateE2EName("a", testPrefix, randPrefix)
+		nsB := GenerateE2EName("b", testPrefix, randPrefix)
+
+		CreateHierarchyRoot(nsRoot, randPrefix, rqDepth, nil, storage, "100Gi", cpu, "100", memory, "100Gi", pods, "100", gpu, "100")
+		CreateSubnamespace(nsA, nsRoot, randPrefix, false, storage, "50Gi", cpu, "50", memory, "50Gi", pods, "50", gpu, "50")
+		CreateSubnamespace(nsB, nsRoot, randPrefix, false, storage, "50Gi", cpu, "50", memory, "50Gi", pods, "50", gpu, "50")
+
+		CreateHierarchyRoot(nsRoot, randPrefix, rqDepth, []string{nsA}, storage, "100Gi", cpu, "100", memory, "100Gi", pods, "100", gpu, "100")
+		FieldShouldContain("namespace", "", nsA, ".metadata.annotations", danav1.IsSecondaryRoot+":"+danav1.True)
+		FieldShouldNotContain("namespace", "", nsB, ".metadata.annotations", danav1.IsSecondaryRoot)
+
+		CreateHierarchyRoot(nsRoot, randPrefix, rqDepth, nil, storage, "100Gi", cpu, "100", memory, "100Gi", pods, "100", gpu, "100")
+		FieldShouldNotContain("namespace", "", nsA, ".metadata.annotations", danav1.IsSecondaryRoot)
+	})
+
+	It("should fail to declare a subnamespace as a root namespace", func() {
+		nsA := GenerateE2EName("a", testPrefix, randPrefix)
+
+		CreateHierarchyRoot(nsRoot, randPrefix, rqDepth, nil, storage, "100Gi", cpu, "100", memory, "100Gi", pods, "100", gpu, "100")
+		CreateSubnamespace(nsA, nsRoot, randPrefix, false, storage, "50Gi", cpu, "50", memory, "50Gi", pods, "50", gpu, "50")
+
+		ShouldNotCreateHierarchyRoot(nsA, rqDepth, nil, storage, "50Gi", cpu, "50", memory, "50Gi", pods, "50", gpu, "50")
+	})
+
+	It("should fail to make changes that invalidate the existing hierarchy", func() {
+		nsA := GenerateE2EName("a", testPrefix, randPrefix)
+		nsB := GenerateE2EName("b", testPrefix, randPrefix)
+
+		CreateHierarchyRoot(nsRoot, randPrefix, rqDepth, nil, storage, "100Gi", cpu, "100", memory, "100Gi", pods, "100", gpu, "100")
+		CreateSubnamespace(nsA, nsRoot, randPrefix, false, storage, "50Gi", cpu, "50", memory, "50Gi", pods, "50", gpu, "50")
+		CreateSubnamespace(nsB, nsA, randPrefix, false, storage, "25Gi", cpu, "25", memory, "25Gi", pods, "25", gpu, "25")
+
+		// the rqDepth can't be changed while the root namespace has subnamespaces
+		ShouldNotCreateHierarchyRoot(nsRoot, rqDepth+1, nil, storage, "100Gi", cpu, "100", memory, "100Gi", pods, "100", gpu, "100")
+
+		// the quota of the root namespace can't be lower than the quota allocated to its children
+		ShouldNotCreateHierarchyRoot(nsRoot, rqDepth, nil, storage, "100Gi", cpu, "10", memory, "100Gi", pods, "100", gpu, "100")
+
+		// secondary roots must be direct children of the root namespace
+		ShouldNotCreateHierarchyRoot(nsRoot, rqDepth, []string{nsB}, storage, "100Gi", cpu, "100", memory, "100Gi", pods, "100", gpu, "100")
+
+		// the HierarchyRoot can't be deleted while the root namespace has subnamespaces
+		MustNotRun("kubectl delete hierarchyroot", nsRoot)
+	})
+})
@@ -0,0 +1,59 @@
+package e2e_tests
+
+import (
+	. "github.com/dana-team/hns/test/testutils"
+	. "github.com/onsi/ginkgo/v2"
+)
+
+var _ = Describe("HNS Roles", func() {
+	testPrefix := "roles-test"
+	var randPrefix string
+	var nsRoot string
+
+	BeforeEach(func() {
+		randPrefix = RandStr()
+
+		CleanupTestNamespaces(randPrefix)
+		CleanupTestUsers(randPrefix)
+
+		nsRoot = GenerateE2EName("root", testPrefix, randPrefix)
+		CreateRootNS(nsRoot, randPrefix, rqDepth)
+		CreateResourceQuota(nsRoot, nsRoot, storage, "100Gi", cpu, "100", memory, "100Gi", pods, "100", gpu, "100")
+	})
+
+	AfterEach(func() {
+		CleanupTestNamespaces(randPrefix)
+		CleanupTestUsers(randPrefix)
+	})
+
+	It("should allow an hns-structure-admin to create subnamespaces but not to move resources", func() {
+		nsA := GenerateE2EName("a", testPrefix, randPrefix)
+		nsB := GenerateE2EName("b", testPrefix, randPrefix)
+		CreateSubnamespace(nsA, nsRoot, randPrefix, false, storage, "50Gi", cpu, "50", memory, "50Gi", pods, "50", gpu, "50")
+
+		userA := GenerateE2EUserName("user-a")
+		CreateUser(userA, randPrefix)
+		GrantTestingUserClusterRole(userA, nsA, "hns-structure-admin")
+
+		CreateSubnamespaceAsUser(nsB, nsA, randPrefix, userA, false, storage, "10Gi", cpu, "10", memory, "10Gi", pods, "10", gpu, "10")
+		ShouldNotCreateUpdateQuota("updatequota-from-"+nsB+"-to-"+nsA, nsB, nsA, userA, "pods", "5")
+	})
+
+	It("should allow an hns-quota-admin to move resources but not to create subnamespaces", func() {
+		nsA := GenerateE2EName("a", testPrefix, randPrefix)
+		nsB := GenerateE2EName("b", testPrefix, randPrefix)
+		nsC := GenerateE2EName("c", testPrefix, randPrefix)
+		CreateSubnamespace(nsA, nsRoot, randPrefix, false, storage, "50Gi", cpu, "50", memory, "50Gi", pods, "50", gpu, "50")
+		CreateSubnamespace(nsB, nsA, randPrefix, false, storage, "10Gi", cpu, "10", memory, "10Gi", pods, "10", gpu, "10")
+
+		userA := GenerateE2EUserName("user-a")
+		CreateUser(userA, randPrefix)
+		GrantTestingUserClusterRole(userA, nsA, "hns-quota-admin")
+
+		ShouldNotCreateSubnamespaceAsUser(nsC, nsA, userA, false, storage, "10Gi", cpu, "10", memory, "10Gi", pods, "10", gpu, "10")
+
+		// the rolebinding is inherited by the child, so the user may move its resources up the branch
+		CreateUpdateQuota("updatequota-from-"+nsB+"-to-"+nsA, nsB, nsA, userA, "pods", "5")
+		FieldShouldContain("subnamespace", nsA, nsB, ".spec.resourcequota.hard.pods", "5")
+	})
+})
@@ -16,6 +16,7 @@ var _ = Describe("MigrationHierarchy", func() {
 
 		CleanupTestNamespaces(randPrefix)
 		CleanupTestMigrationHierarchies(randPrefix)
+		CleanupTestUsers(randPrefix)
 
 		nsRoot = GenerateE2EName("root", testPrefix, randPrefix)
 		CreateRootNS(nsRoot, randPrefix, rqDepth)
@@ -25,6 +26,7 @@ var _ = Describe("MigrationHierarchy", func() {
 	AfterEach(func() {
 		CleanupTestNamespaces(randPrefix)
 		CleanupTestMigrationHierarchies(randPrefix)
+		CleanupTestUsers(randPrefix)
 
 	})
 
@@ -39,7 +41,7 @@ var _ = Describe("MigrationHierarchy", func() {
 		// create hierarchy
 		CreateSubnamespace(nsA, nsRoot, randPrefix, false, storage, "50Gi", cpu, "50", memory, "50Gi", pods, "50", gpu, "50")
 		CreateSubnamespace(nsB, nsA, randPrefix, false, storage, "25Gi", cpu, "25", memory, "25Gi", pods, "25", gpu, "25")
-		CreateSubnamespace(nsC, nsB, randPrefix, false, storage, "10Gi", cpu, "10", memory, "10Gi", pods, "10", gpu, "10")
+		CreateSubnamespace(nsC, nsB, randPrefix, false, storage, "11Gi", cpu, "11", memory, "11Gi", pods, "11", gpu, "11")
 		CreateSubnamespace(nsD, nsC, randPrefix, false, storage, "5Gi", cpu, "5", memory, "5Gi", pods, "5", gpu, "5")
 		CreateSubnamespace(nsE, nsC, randPrefix, false, storage, "5Gi", cpu, "5", memory, "5Gi", pods, "5", gpu, "5")
 		CreateSubnamespace(nsF, nsD, randPrefix, false, storage, "1Gi", cpu, "1", memory, "1Gi", pods, "1", gpu, "1")
@@ -73,6 +75,70 @@ var _ = Describe("MigrationHierarchy", func() {
 		LabelTestingMigrationHierarchies(mhName, randPrefix)
 	})
 
+	It("should migrate a subnamespace if the requesting user has permissions on both the current and the new parent", func() {
+		nsA := GenerateE2EName("a", testPrefix, randPrefix)
+		nsB := GenerateE2EName("b", testPrefix, randPrefix)
+		nsC := GenerateE2EName("c", testPrefix, randPrefix)
+		CreateSubnamespace(nsA, nsRoot, randPrefix, false, storage, "50Gi", cpu, "50", memory, "50Gi", pods, "50", gpu, "50")
+		CreateSubnamespace(nsB, nsRoot, randPrefix, false, storage, "25Gi", cpu, "25", memory, "25Gi", pods, "25", gpu, "25")
+		CreateSubnamespace(nsC, nsA, randPrefix, false, storage, "10Gi", cpu, "10", memory, "10Gi", pods, "10", gpu, "10")
+
+		// create user and give it admin rolebinding on both parents
+		userA := GenerateE2EUserName("user-a")
+		CreateUser(userA, randPrefix)
+		GrantTestingUserAdmin(userA, nsA)
+		GrantTestingUserAdmin(userA, nsB)
+
+		mhName := CreateMigrationHierarchy(nsC, nsB, userA)
+
+		// verify phase is complete before labeling it
+		FieldShouldContain("migrationhierarchy", "", mhName, ".status.phase", "Complete")
+		FieldShouldContain("migrationhierarchy", "", mhName, ".metadata.annotations.requester", userA)
+		FieldShouldContain("namespace", "", nsC, ".metadata.labels", danav1.Parent+":"+nsB)
+		LabelTestingMigrationHierarchies(mhName, randPrefix)
+	})
+
+	It("should migrate a subnamespace if the requesting user has permissions on the common ancestor", func() {
+		nsA := GenerateE2EName("a", testPrefix, randPrefix)
+		nsB := GenerateE2EName("b", testPrefix, randPrefix)
+		nsC := GenerateE2EName("c", testPrefix, randPrefix)
+		nsD := GenerateE2EName("d", testPrefix, randPrefix)
+		CreateSubnamespace(nsA, nsRoot, randPrefix, false, storage, "50Gi", cpu, "50", memory, "50Gi", pods, "50", gpu, "50")
+		CreateSubnamespace(nsB, nsA, randPrefix, false, storage, "20Gi", cpu, "20", memory, "20Gi", pods, "20", gpu, "20")
+		CreateSubnamespace(nsC, nsA, randPrefix, false, storage, "20Gi", cpu, "20", memory, "20Gi", pods, "20", gpu, "20")
+		CreateSubnamespace(nsD, nsB, randPrefix, false, storage, "10Gi", cpu, "10", memory, "10Gi", pods, "10", gpu, "10")
+
+		// create user and give it admin rolebinding on the common ancestor
+		userA := GenerateE2EUserName("user-a")
+		CreateUser(userA, randPrefix)
+		GrantTestingUserAdmin(userA, nsA)
+
+		mhName := CreateMigrationHierarchy(nsD, nsC, userA)
+
+		// verify phase is complete before labeling it
+		FieldShouldContain("migrationhierarchy", "", mhName, ".status.phase", "Complete")
+		FieldShouldContain("namespace", "", nsD, ".metadata.labels", danav1.Parent+":"+nsC)
+		LabelTestingMigrationHierarchies(mhName, randPrefix)
+	})
+
+	It("should not migrate a subnamespace if the requesting user has permissions only on the new parent", func() {
+		nsA := GenerateE2EName("a", testPrefix, randPrefix)
+		nsB := GenerateE2EName("b", testPrefix, randPrefix)
+		nsC := GenerateE2EName("c", testPrefix, randPrefix)
+		CreateSubnamespace(nsA, nsRoot, randPrefix, false, storage, "50Gi", cpu, "50", memory, "50Gi", pods, "50", gpu, "50")
+		CreateSubnamespace(nsB, nsRoot, randPrefix, false, storage, "25Gi", cpu, "25", memory, "25Gi", pods, "25", gpu, "25")
+		CreateSubnamespace(nsC, nsA, randPrefix, false, storage, "10Gi", cpu, "10", memory, "10Gi", pods, "10", gpu, "10")
+
+		// create user and give it admin rolebinding only on the new parent and the migrated subnamespace
+		userA := GenerateE2EUserName("user-a")
+		CreateUser(userA, randPrefix)
+		GrantTestingUserAdmin(userA, nsB)
+		GrantTestingUserAdmin(userA, nsC)
+
+		ShouldNotCreateMigrationHierarchy(nsC, nsB, userA)
+		FieldShouldContain("namespace", "", nsC, ".metadata.labels", danav1.Parent+":"+nsA)
+	})
+
 	It("should migrate subnamespace that doesn't have a CRQ and their direct parent doesn't have a CRQ,", func() {
 		nsA := GenerateE2EName("a", testPrefix, randPrefix)
 		nsB := GenerateE2EName("b", testPrefix, randPrefix)
@@ -82,7 +148,7 @@ var _ = Describe("MigrationHierarchy", func() {
 		// create hierarchy
 		CreateSubnamespace(nsA, nsRoot, randPrefix, false, storage, "50Gi", cpu, "50", memory, "50Gi", pods, "50", gpu, "50")
 		CreateSubnamespace(nsB, nsA, randPrefix, false, storage, "25Gi", cpu, "25", memory, "25Gi", pods, "25", gpu, "25")
-		CreateSubnamespace(nsC, nsA, randPrefix, false, storage, "25Gi", cpu, "25", memory, "25Gi", pods, "25", gpu, "25")
+		CreateSubnamespace(nsC, nsA, randPrefix, false, storage, "15Gi", cpu, "15", memory, "15Gi", pods, "15", gpu, "15")
 		CreateSubnamespace(nsD, nsB, randPrefix, false, storage, "10Gi", cpu, "10", memory, "10Gi", pods, "10", gpu, "10")
 
 		mhName := CreateMigrationHierarchy(nsD, nsC, "")
@@ -105,7 +171,7 @@ var _ = Describe("MigrationHierarchy", func() {
 		// create hierarchy
 		CreateSubnamespace(nsA, nsRoot, randPrefix, false, storage, "50Gi", cpu, "50", memory, "50Gi", pods, "50", gpu, "50")
 		CreateSubnamespace(nsB, nsA, randPrefix, false, storage, "25Gi", cpu, "25", memory, "25Gi", pods, "25", gpu, "25")
-		CreateSubnamespace(nsC, nsA, randPrefix, false, storage, "25Gi", cpu, "25", memory, "25Gi", pods, "25", gpu, "25")
+		CreateSubnamespace(nsC, nsA, randPrefix, false, storage, "15Gi", cpu, "15", memory, "15Gi", pods, "15", gpu, "15")
 		CreateSubnamespace(nsD, nsB, randPrefix, false, storage, "10Gi", cpu, "10", memory, "10Gi", pods, "10", gpu, "10")
 		CreateSubnamespace(nsE, nsD, randPrefix, false, storage, "2Gi", cpu, "2", memory, "2Gi", pods, "2", gpu, "2")
 		CreateSubnamespace(nsF, nsE, randPrefix, false, storage, "1Gi", cpu, "1", memory, "1Gi", pods, "1", gpu, "1")
@@ -136,8 +202,8 @@ var _ = Describe("MigrationHierarchy", func() {
 		CreateSubnamespace(nsD, nsB, randPrefix, false, storage, "10Gi", cpu, "10", memory, "10Gi", pods, "10", gpu, "10")
 		CreateSubnamespace(nsE, nsD, randPrefix, false, storage, "2Gi", cpu, "2", memory, "2Gi", pods, "2", gpu, "2")
 		CreateSubnamespace(nsF, nsE, randPrefix, false, storage, "1Gi", cpu, "1", memory, "1Gi", pods, "1", gpu, "1")
-		CreateSubnamespace(nsA, nsRoot, randPrefix, false, storage, "50Gi", cpu, "50", memory, "50Gi", pods, "50", gpu, "50")
-		CreateSubnamespace(nsC, nsA, randPrefix, false, storage, "25Gi", cpu, "25", memory, "25Gi", pods, "25", gpu, "25")
+		CreateSubnamespace(nsA, nsRoot, randPrefix, false, storage, "20Gi", cpu, "20", memory, "20Gi", pods, "20", gpu, "20")
+		CreateSubnamespace(nsC, nsA, randPrefix, false, storage, "10Gi", cpu, "10", memory, "10Gi", pods, "10", gpu, "10")
 
 		mhName := CreateMigrationHierarchy(nsA, nsF, "")
 
@@ -146,8 +212,8 @@ var _ = Describe("MigrationHierarchy", func() {
 		FieldShouldContain("subnamespace", nsF, nsA, ".metadata.namespace", nsF)
 		FieldShouldContain("namespace", "", nsA, ".metadata.labels", danav1.Parent+":"+nsF)
 
-		FieldShouldNotContain("resourcequota", nsA, nsA, ".spec.hard.pods", "50")
-		FieldShouldContain("clusterresourcequota", "", nsA, ".spec.quota.hard.pods", "50")
+		FieldShouldNotContain("resourcequota", nsA, nsA, ".spec.hard.pods", "20")
+		FieldShouldContain("clusterresourcequota", "", nsA, ".spec.quota.hard.pods", "20")
 
 		LabelTestingMigrationHierarchies(mhName, randPrefix)
 	})
@@ -165,7 +231,7 @@ var _ = Describe("MigrationHierarchy", func() {
 		// create hierarchy
 		CreateSubnamespace(nsA, nsRoot, randPrefix, false, storage, "50Gi", cpu, "50", memory, "50Gi", pods, "50", gpu, "50")
 		CreateSubnamespace(nsB, nsA, randPrefix, false, storage, "25Gi", cpu, "25", memory, "25Gi", pods, "25", gpu, "25")
-		CreateSubnamespace(nsC, nsB, randPrefix, false, storage, "10Gi", cpu, "10", memory, "10Gi", pods, "10", gpu, "10")
+		CreateSubnamespace(nsC, nsB, randPrefix, false, storage, "12Gi", cpu, "12", memory, "12Gi", pods, "12", gpu, "12")
 		CreateSubnamespace(nsD, nsC, randPrefix, false, storage, "5Gi", cpu, "5", memory, "5Gi", pods, "5", gpu, "5")
 		CreateSubnamespace(nsE, nsC, randPrefix, false, storage, "5Gi", cpu, "5", memory, "5Gi", pods, "5", gpu, "5")
 		CreateSubnamespace(nsF, nsD, randPrefix, false, storage, "2Gi", cpu, "2", memory, "2Gi", pods, "2", gpu, "2")
@@ -185,6 +251,11 @@ var _ = Describe("MigrationHierarchy", func() {
 		FieldShouldContain("subnamespace", nsG, nsH, ".metadata.namespace", nsG)
 		FieldShouldContain("namespace", "", nsH, ".metadata.labels", danav1.Parent+":"+nsG)
 
+		// make sure the tree labels of the migrated namespaces were updated
+		FieldShouldContain("namespace", "", nsF, ".metadata.labels", nsE+danav1.TreeLabelSuffix+":1")
+		FieldShouldContain("namespace", "", nsH, ".metadata.labels", nsE+danav1.TreeLabelSuffix+":3")
+		FieldShouldNotContain("namespace", "", nsH, ".metadata.labels", nsD+danav1.TreeLabelSuffix)
+
 		// verify phase is complete before labeling it
 		FieldShouldContain("migrationhierarchy", "", mhName, ".status.phase", "Complete")
 		LabelTestingMigrationHierarchies(mhName, randPrefix)
@@ -234,7 +305,7 @@ var _ = Describe("MigrationHierarchy", func() {
 		CreateSubnamespace(nsG, nsD, randPrefix, false, storage, "2Gi", cpu, "2", memory, "2Gi", pods, "2", gpu, "2")
 
 		// make sure the subnamespace was not migrated and the parent has not been updated
-		ShouldNotCreateMigrationHierarchy(nsG, nsF)
+		ShouldNotCreateMigrationHierarchy(nsG, nsF, "")
 
 		FieldShouldContain("subnamespace", nsD, nsG, ".metadata.namespace", nsD)
 		FieldShouldContain("namespace", "", nsG, ".metadata.labels", danav1.Parent+":"+nsD)
@@ -254,7 +325,7 @@ var _ = Describe("MigrationHierarchy", func() {
 		// create hierarchy
 		CreateSubnamespace(nsA, nsRoot, randPrefix, false, storage, "50Gi", cpu, "50", memory, "50Gi", pods, "50", gpu, "50")
 		CreateSubnamespace(nsB, nsA, randPrefix, false, storage, "25Gi", cpu, "25", memory, "25Gi", pods, "25", gpu, "25")
-		CreateSubnamespace(nsC, nsB, randPrefix, false, storage, "10Gi", cpu, "10", memory, "10Gi", pods, "10", gpu, "10")
+		CreateSubnamespace(nsC, nsB, randPrefix, false, storage, "12Gi", cpu, "12", memory, "12Gi", pods, "12", gpu, "12")
 		CreateSubnamespace(nsD, nsC, randPrefix, false, storage, "5Gi", cpu, "5", memory, "5Gi", pods, "5", gpu, "5")
 		CreateSubnamespace(nsE, nsC, randPrefix, false, storage, "5Gi", cpu, "5", memory, "5Gi", pods, "5", gpu, "5")
 		CreateSubnamespace(nsF, nsD, randPrefix, true, storage, "2Gi", cpu, "2", memory, "2Gi", pods, "2", gpu, "2")
@@ -296,7 +367,7 @@ var _ = Describe("MigrationHierarchy", func() {
 		// create hierarchy
 		CreateSubnamespace(nsA, nsRoot, randPrefix, false, storage, "50Gi", cpu, "50", memory, "50Gi", pods, "50", gpu, "50")
 		CreateSubnamespace(nsB, nsA, randPrefix, false, storage, "25Gi", cpu, "25", memory, "25Gi", pods, "25", gpu, "25")
-		CreateSubnamespace(nsC, nsB, randPrefix, false, storage, "10Gi", cpu, "10", memory, "10Gi", pods, "10", gpu, "10")
+		CreateSubnamespace(nsC, nsB, randPrefix, false, storage, "12Gi", cpu, "12", memory, "12Gi", pods, "12", gpu, "12")
 		CreateSubnamespace(nsD, nsC, randPrefix, false, storage, "5Gi", cpu, "5", memory, "5Gi", pods, "5", gpu, "5")
 		CreateSubnamespace(nsE, nsC, randPrefix, false, storage, "5Gi", cpu, "5", memory, "5Gi", pods, "5", gpu, "5")
 		CreateSubnamespace(nsI, nsE, randPrefix, true, storage, "2Gi", cpu, "2", memory, "2Gi", pods, "2", gpu, "5")
@@ -342,7 +413,7 @@ var _ = Describe("MigrationHierarchy", func() {
 		AnnotateNSSecondaryRoot(nsB)
 
 		// make sure the subnamespace was not migrated and the parent was updated
-		ShouldNotCreateMigrationHierarchy(nsD, nsA)
+		ShouldNotCreateMigrationHierarchy(nsD, nsA, "")
 
 		FieldShouldContain("subnamespace", nsB, nsD, ".metadata.namespace", nsB)
 		FieldShouldContain("namespace", "", nsD, ".metadata.labels", danav1.Parent+":"+nsB)
@@ -359,7 +430,7 @@ var _ = Describe("MigrationHierarchy", func() {
 		CreateSubnamespace(nsC, nsB, randPrefix, false, storage, "10Gi", cpu, "10", memory, "10Gi", pods, "10", gpu, "10")
 
 		// make sure the subnamespace was not migrated and the parent has not been updated
-		ShouldNotCreateMigrationHierarchy(nsC, nsB)
+		ShouldNotCreateMigrationHierarchy(nsC, nsB, "")
 	})
 
 	It("should migrate resources together with the subnamespaces", func() {
@@ -376,7 +447,7 @@ var _ = Describe("MigrationHierarchy", func() {
 		// create hierarchy
 		CreateSubnamespace(nsA, nsRoot, randPrefix, false, storage, "50Gi", cpu, "50", memory, "50Gi", pods, "50", gpu, "50")
 		CreateSubnamespace(nsB, nsA, randPrefix, false, storage, "25Gi", cpu, "25", memory, "25Gi", pods, "25", gpu, "25")
-		CreateSubnamespace(nsH, nsA, randPrefix, false, storage, "25Gi", cpu, "25", memory, "25Gi", pods, "25", gpu, "25")
+		CreateSubnamespace(nsH, nsA, randPrefix, false, storage, "20Gi", cpu, "20", memory, "20Gi", pods, "20", gpu, "20")
 		CreateSubnamespace(nsI, nsH, randPrefix, false, storage, "1Gi", cpu, "1", memory, "1Gi", pods, "1", gpu, "1")
 		CreateSubnamespace(nsC, nsB, randPrefix, false, storage, "10Gi", cpu, "10", memory, "10Gi", pods, "10", gpu, "10")
 		CreateSubnamespace(nsD, nsC, randPrefix, false, storage, "5Gi", cpu, "5", memory, "5Gi", pods, "5", gpu, "5")
@@ -398,6 +469,136 @@ var _ = Describe("MigrationHierarchy", func() {
 		LabelTestingMigrationHierarchies(mhName, randPrefix)
 	})
 
+	It("should transfer the quota through the common ancestor without changing the root quota", func() {
+		nsA := GenerateE2EName("a", testPrefix, randPrefix)
+		nsB := GenerateE2EName("b", testPrefix, randPrefix)
+		nsC := GenerateE2EName("c", testPrefix, randPrefix)
+		nsD := GenerateE2EName("d", testPrefix, randPrefix)
+
+		// create hierarchy
+		CreateSubnamespace(nsA, nsRoot, randPrefix, false, storage, "50Gi", cpu, "50", memory, "50Gi", pods, "50", gpu, "50")
+		CreateSubnamespace(nsB, nsA, randPrefix, false, storage, "20Gi", cpu, "20", memory, "20Gi", pods, "20", gpu, "20")
+		CreateSubnamespace(nsC, nsA, randPrefix, false, storage, "20Gi", cpu, "20", memory, "20Gi", pods, "20", gpu, "20")
+		CreateSubnamespace(nsD, nsB, randPrefix, false, storage, "5Gi", cpu, "5", memory, "5Gi", pods, "5", gpu, "5")
+
+		mhName := CreateMigrationHierarchy(nsD, nsC, "")
+		FieldShouldContain("migrationhierarchy", "", mhName, ".status.phase", "Complete")
+		LabelTestingMigrationHierarchies(mhName, randPrefix)
+
+		// make sure the quota was moved from the old branch to the new branch
+		FieldShouldContain("subnamespace", nsA, nsB, ".spec.resourcequota.hard."+cpu, "15")
+		FieldShouldContain("subnamespace", nsA, nsC, ".spec.resourcequota.hard."+cpu, "25")
+
+		// make sure the quota of the common ancestor and of the root were not changed
+		FieldShouldContain("subnamespace", nsRoot, nsA, ".spec.resourcequota.hard."+cpu, "50")
+		FieldShouldContain("resourcequota", nsRoot, nsRoot, ".spec.hard."+cpu, "100")
+
+		// make sure the transfer was recorded in the status
+		FieldShouldContain("migrationhierarchy", "", mhName, ".status.quotaTransfer.ancestor", nsA)
+	})
+
+	It("should fail a migration when the common ancestor does not have enough free resources", func() {
+		nsA := GenerateE2EName("a", testPrefix, randPrefix)
+		nsB := GenerateE2EName("b", testPrefix, randPrefix)
+		nsC := GenerateE2EName("c", testPrefix, randPrefix)
+		nsD := GenerateE2EName("d", testPrefix, randPrefix)
+
+		// create hierarchy in which all the resources of the common ancestor are allocated
+		CreateSubnamespace(nsA, nsRoot, randPrefix, false, storage, "20Gi", cpu, "20", memory, "20Gi", pods, "20", gpu, "20")
+		CreateSubnamespace(nsB, nsA, randPrefix, false, storage, "10Gi", cpu, "10", memory, "10Gi", pods, "10", gpu, "10")
+		CreateSubnamespace(nsC, nsA, randPrefix, false, storage, "10Gi", cpu, "10", memory, "10Gi", pods, "10", gpu, "10")
+		CreateSubnamespace(nsD, nsB, randPrefix, false, storage, "5Gi", cpu, "5", memory, "5Gi", pods, "5", gpu, "5")
+
+		mhName := CreateMigrationHierarchy(nsD, nsC, "")
+		FieldShouldContain("migrationhierarchy", "", mhName, ".status.phase", "Error")
+		FieldShouldContain("migrationhierarchy", "", mhName, ".status.reason", "not enough free resources")
+		LabelTestingMigrationHierarchies(mhName, randPrefix)
+
+		// make sure nothing was changed
+		FieldShouldContain("namespace", "", nsD, ".metadata.labels", danav1.Parent+":"+nsB)
+		FieldShouldContain("subnamespace", nsA, nsC, ".spec.resourcequota.hard."+cpu, "10")
+	})
+
+	It("should record the steps of the migration and not allow reverting a completed migration", func() {
+		nsA := GenerateE2EName("a", testPrefix, randPrefix)
+		nsB := GenerateE2EName("b", testPrefix, randPrefix)
+		nsC := GenerateE2EName("c", testPrefix, randPrefix)
+
+		// create hierarchy
+		CreateSubnamespace(nsA, nsRoot, randPrefix, false, storage, "20Gi", cpu, "20", memory, "20Gi", pods, "20", gpu, "20")
+		CreateSubnamespace(nsB, nsRoot, randPrefix, false, storage, "20Gi", cpu, "20", memory, "20Gi", pods, "20", gpu, "20")
+		CreateSubnamespace(nsC, nsA, randPrefix, false, storage, "5Gi", cpu, "5", memory, "5Gi", pods, "5", gpu, "5")
+
+		mhName := CreateMigrationHierarchy(nsC, nsB, "")
+		FieldShouldContain("migrationhierarchy", "", mhName, ".status.phase", "Complete")
+		LabelTestingMigrationHierarchies(mhName, randPrefix)
+
+		// make sure all the steps were completed and the original parent was recorded
+		FieldShouldContain("migrationhierarchy", "", mhName, ".status.step", string(danav1.QuotaReleased))
+		FieldShouldContain("migrationhierarchy", "", mhName, ".status.originalParent", nsA)
+
+		// make sure a migration which did not fail can't be reverted
+		MustNotRun("kubectl patch migrationhierarchy", mhName, "--type=merge", "-p", `{"spec":{"revert":true}}`)
+	})
+
+	It("should sync the inherited rolebindings of the migrated subnamespaces with their new ancestors", func() {
+		nsA := GenerateE2EName("a", testPrefix, randPrefix)
+		nsB := GenerateE2EName("b", testPrefix, randPrefix)
+		nsC := GenerateE2EName("c", testPrefix, randPrefix)
+		nsD := GenerateE2EName("d", testPrefix, randPrefix)
+		userA := GenerateE2EUserName("a")
+		userB := GenerateE2EUserName("b")
+
+		// create hierarchy
+		CreateSubnamespace(nsA, nsRoot, randPrefix, false, storage, "20Gi", cpu, "20", memory, "20Gi", pods, "20", gpu, "20")
+		CreateSubnamespace(nsB, nsRoot, randPrefix, false, storage, "20Gi", cpu, "20", memory, "20Gi", pods, "20", gpu, "20")
+		CreateSubnamespace(nsC, nsA, randPrefix, false, storage, "5Gi", cpu, "5", memory, "5Gi", pods, "5", gpu, "5")
+		CreateSubnamespace(nsD, nsC, randPrefix, false, storage, "1Gi", cpu, "1", memory, "1Gi", pods, "1", gpu, "1")
+
+		// create a rolebinding in each of the parents and make sure it is inherited by the old branch
+		GrantTestingUserAdmin(userA, nsA)
+		GrantTestingUserAdmin(userB, nsB)
+		rbA := "test-admin-" + userA + "-" + nsA
+		rbB := "test-admin-" + userB + "-" + nsB
+		FieldShouldContain("rolebinding", nsD, rbA, ".metadata.annotations", danav1.InheritedFrom+":"+nsA)
+
+		mhName := CreateMigrationHierarchy(nsC, nsB, "")
+		FieldShouldContain("migrationhierarchy", "", mhName, ".status.phase", "Complete")
+		LabelTestingMigrationHierarchies(mhName, randPrefix)
+
+		// make sure the rolebinding of the old parent was removed and the one of the new parent was added
+		ShouldNotExist("rolebinding", nsC, rbA)
+		ShouldNotExist("rolebinding", nsD, rbA)
+		FieldShouldContain("rolebinding", nsC, rbB, ".metadata.annotations", danav1.InheritedFrom+":"+nsB)
+		FieldShouldContain("rolebinding", nsD, rbB, ".metadata.annotations", danav1.InheritedFrom+":"+nsB)
+	})
+
+	It("should preview the impact of a migration without migrating the subnamespace", func() {
+		nsA := GenerateE2EName("a", testPrefix, randPrefix)
+		nsB := GenerateE2EName("b", testPrefix, randPrefix)
+		nsC := GenerateE2EName("c", testPrefix, randPrefix)
+		nsD := GenerateE2EName("d", testPrefix, randPrefix)
+
+		// create hierarchy
+		CreateSubnamespace(nsA, nsRoot, randPrefix, false, storage, "20Gi", cpu, "20", memory, "20Gi", pods, "20", gpu, "20")
+		CreateSubnamespace(nsB, nsRoot, randPrefix, false, storage, "20Gi", cpu, "20", memory, "20Gi", pods, "20", gpu, "20")
+		CreateSubnamespace(nsC, nsA, randPrefix, false, storage, "5Gi", cpu, "5", memory, "5Gi", pods, "5", gpu, "5")
+		CreateSubnamespace(nsD, nsC, randPrefix, false, storage, "1Gi", cpu, "1", memory, "1Gi", pods, "1", gpu, "1")
+
+		mhName := CreateDryRunMigrationHierarchy(nsC, nsB)
+		FieldShouldContain("migrationhierarchy", "", mhName, ".status.phase", "Complete")
+		LabelTestingMigrationHierarchies(mhName, randPrefix)
+
+		// make sure the preview holds the moved namespaces and the quota transfer
+		FieldShouldContain("migrationhierarchy", "", mhName, ".status.preview.namespaces", nsD)
+		FieldShouldContain("migrationhierarchy", "", mhName, ".status.preview.quotaFits", "true")
+		FieldShouldContain("migrationhierarchy", "", mhName, ".status.preview.quotaTransfer.ancestor", nsRoot)
+
+		// make sure nothing was migrated
+		FieldShouldContain("namespace", "", nsC, ".metadata.labels", danav1.Parent+":"+nsA)
+		FieldShouldContain("subnamespace", nsRoot, nsA, ".spec.resourcequota.hard."+cpu, "20")
+	})
+
 	It("should not migrate a non-Upper ResourcePool to a Subnamespace", func() {
 		nsA := GenerateE2EName("a", testPrefix, randPrefix)
 		nsB := GenerateE2EName("b", testPrefix, randPrefix)
@@ -413,7 +614,7 @@ var _ = Describe("MigrationHierarchy", func() {
 		CreateSubnamespace(nsE, nsA, randPrefix, false, storage, "25Gi", cpu, "25", memory, "25Gi", pods, "25", gpu, "25")
 
 		// make sure the subnamespace was not migrated and the parent has not been updated
-		ShouldNotCreateMigrationHierarchy(nsD, nsE)
+		ShouldNotCreateMigrationHierarchy(nsD, nsE, "")
 	})
 
 	It("should not migrate a Subnamespace to be under itself", func() {
@@ -427,7 +628,7 @@ var _ = Describe("MigrationHierarchy", func() {
 		CreateSubnamespace(nsC, nsB, randPrefix, true, storage, "10Gi", cpu, "10", memory, "10Gi", pods, "10", gpu, "10")
 
 		// make sure the subnamespace was not migrated and the parent has not been updated
-		ShouldNotCreateMigrationHierarchy(nsB, nsB)
+		ShouldNotCreateMigrationHierarchy(nsB, nsB, "")
 	})
 
 	It("should not migrate a Subnamespace to be under its own descendant and create a loop", func() {
@@ -441,7 +642,7 @@ var _ = Describe("MigrationHierarchy", func() {
 		CreateSubnamespace(nsC, nsB, randPrefix, true, storage, "10Gi", cpu, "10", memory, "10Gi", pods, "10", gpu, "10")
 
 		// make sure the subnamespace was not migrated and the parent has not been updated
-		ShouldNotCreateMigrationHierarchy(nsA, nsC)
+		ShouldNotCreateMigrationHierarchy(nsA, nsC, "")
 	})
 
 })
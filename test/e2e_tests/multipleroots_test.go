@@ -0,0 +1,79 @@
+package e2e_tests
+
+import (
+	danav1 "github.com/dana-team/hns/api/v1"
+	. "github.com/dana-team/hns/test/testutils"
+	. "github.com/onsi/ginkgo/v2"
+)
+
+var _ = Describe("Multiple roots", func() {
+	testPrefix := "mr-test"
+	var randPrefix string
+	var nsRootA string
+	var nsRootB string
+
+	BeforeEach(func() {
+		randPrefix = RandStr()
+
+		CleanupTestNamespaces(randPrefix)
+		CleanupTestMigrationHierarchies(randPrefix)
+
+		nsRootA = GenerateE2EName("roota", testPrefix, randPrefix)
+		nsRootB = GenerateE2EName("rootb", testPrefix, randPrefix)
+
+		CreateRootNS(nsRootA, randPrefix, 1)
+		CreateResourceQuota(nsRootA, nsRootA, storage, "100Gi", cpu, "100", memory, "100Gi", pods, "100", gpu, "100")
+		CreateRootNS(nsRootB, randPrefix, rqDepth)
+		CreateResourceQuota(nsRootB, nsRootB, storage, "100Gi", cpu, "100", memory, "100Gi", pods, "100", gpu, "100")
+	})
+
+	AfterEach(func() {
+		CleanupTestNamespaces(randPrefix)
+		CleanupTestMigrationHierarchies(randPrefix)
+	})
+
+	It("should create quota objects in accordance to the rq-depth of each root", func() {
+		nsA := GenerateE2EName("a", testPrefix, randPrefix)
+		nsB := GenerateE2EName("b", testPrefix, randPrefix)
+		nsC := GenerateE2EName("c", testPrefix, randPrefix)
+		nsD := GenerateE2EName("d", testPrefix, randPrefix)
+
+		CreateSubnamespace(nsA, nsRootA, randPrefix, false, storage, "50Gi", cpu, "50", memory, "50Gi", pods, "50", gpu, "50")
+		CreateSubnamespace(nsB, nsA, randPrefix, false, storage, "25Gi", cpu, "25", memory, "25Gi", pods, "25", gpu, "25")
+		CreateSubnamespace(nsC, nsRootB, randPrefix, false, storage, "50Gi", cpu, "50", memory, "50Gi", pods, "50", gpu, "50")
+		CreateSubnamespace(nsD, nsC, randPrefix, false, storage, "25Gi", cpu, "25", memory, "25Gi", pods, "25", gpu, "25")
+
+		// the rq-depth of the first root is 1, so a subnamespace of depth 2 gets a ClusterResourceQuota
+		FieldShouldContain("subnamespace", nsA, nsB, ".metadata.annotations", danav1.IsRq+":"+danav1.False)
+		FieldShouldContain("clusterresourcequota", "", nsB, ".metadata.name", nsB)
+
+		// the rq-depth of the second root is 2, so a subnamespace of depth 2 gets a ResourceQuota
+		FieldShouldContain("subnamespace", nsC, nsD, ".metadata.annotations", danav1.IsRq+":"+danav1.True)
+		RunShouldNotContain(nsD, propagationTime, "kubectl get clusterresourcequota")
+
+		FieldShouldContain("namespace", "", nsB, ".metadata.annotations", danav1.RootCrqSelector+":"+nsRootA)
+		FieldShouldContain("namespace", "", nsD, ".metadata.annotations", danav1.RootCrqSelector+":"+nsRootB)
+	})
+
+	It("should fail to move resources between subnamespaces of different roots", func() {
+		nsA := GenerateE2EName("a", testPrefix, randPrefix)
+		nsB := GenerateE2EName("b", testPrefix, randPrefix)
+
+		CreateSubnamespace(nsA, nsRootA, randPrefix, false, storage, "50Gi", cpu, "50", memory, "50Gi", pods, "50", gpu, "50")
+		CreateSubnamespace(nsB, nsRootB, randPrefix, false, storage, "50Gi", cpu, "50", memory, "50Gi", pods, "50", gpu, "50")
+
+		ShouldNotCreateUpdateQuota("updatequota-from-"+nsA+"-to-"+nsB, nsA, nsB, "", pods, "10")
+		ShouldNotCreateUpdateQuota("updatequota-from-"+nsRootA+"-to-"+nsB, nsRootA, nsB, "", pods, "10")
+	})
+
+	It("should fail to migrate subnamespaces between different roots", func() {
+		nsA := GenerateE2EName("a", testPrefix, randPrefix)
+		nsB := GenerateE2EName("b", testPrefix, randPrefix)
+
+		CreateSubnamespace(nsA, nsRootA, randPrefix, false, storage, "50Gi", cpu, "50", memory, "50Gi", pods, "50", gpu, "50")
+		CreateSubnamespace(nsB, nsRootB, randPrefix, false, storage, "50Gi", cpu, "50", memory, "50Gi", pods, "50", gpu, "50")
+
+		ShouldNotCreateMigrationHierarchy(nsA, nsB, "")
+		ShouldNotCreateMigrationHierarchy(nsA, nsRootB, "")
+	})
+})
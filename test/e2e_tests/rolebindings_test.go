@@ -1,6 +1,7 @@
 package e2e_tests
 
 import (
+	danav1 "github.com/dana-team/hns/api/v1"
 	. "github.com/dana-team/hns/test/testutils"
 	. "github.com/onsi/ginkgo/v2"
 )
@@ -79,23 +80,152 @@ var _ = Describe("RoleBindings", func() {
 
 		By("Trying to delete the rolebinding from the child namespace")
 		ShouldNotDelete("rolebinding", nsChild, "test-admin-"+user+"-"+nsRoot)
+
+		By("Trying to remove the label which records where the rolebinding was inherited from")
+		MustNotRun("kubectl label rolebinding", "test-admin-"+user+"-"+nsRoot, "-n", nsChild, danav1.InheritedFromUID+"-")
 	})
 
-	It("Should create hns-view rolebindings in subnamespace and bind to all other rolebinding subjects", func() {
+	It("Should not overwrite or block a local rolebinding with the same name as an inherited rolebinding", func() {
+		By("Creating a child namespace with a local rolebinding")
+		user := GenerateE2EUserName("user")
+		CreateUser(user, randPrefix)
+		nsChild := GenerateE2EName("child", testPrefix, randPrefix)
+		CreateSubnamespace(nsChild, nsRoot, randPrefix, false, storage, "50Gi",
+			cpu, "50", memory, "50Gi", pods, "50", gpu, "50")
+		rbName := "test-admin-" + user + "-" + nsRoot
+		MustRun("kubectl create rolebinding", rbName, "--user", user, "--namespace", nsChild, "--clusterrole view")
+
+		By("Granting the user admin role with a rolebinding of the same name in the root namespace")
+		GrantTestingUserAdmin(user, nsRoot)
+
+		By("Checking that the local rolebinding was kept and the rolebinding was inherited under another name")
+		RunShouldContain(rbName+"-", propagationTime, "kubectl get rolebinding -n", nsChild, "-l", danav1.InheritedFromUID)
+		FieldShouldContain("rolebinding", nsChild, rbName, ".roleRef.name", "view")
+
+		By("Checking that the local rolebinding can be deleted")
+		ShouldDelete("rolebinding", nsChild, rbName)
+	})
+
+	It("Should propagate the eligible subjects of a rolebinding whose first subject is excluded", func() {
+		By("Creating a child namespace")
+		user := GenerateE2EUserName("user")
+		CreateUser(user, randPrefix)
+		nsChild := GenerateE2EName("child", testPrefix, randPrefix)
+		CreateSubnamespace(nsChild, nsRoot, randPrefix, false, storage, "50Gi",
+			cpu, "50", memory, "50Gi", pods, "50", gpu, "50")
+
+		By("Creating a rolebinding for a system group and the user in the root namespace")
+		rbName := "test-multi-subject-" + user
+		CreateRoleBindingWithGroupAndUser(rbName, nsRoot, "view", "system:authenticated", user)
+
+		By("Checking that the rolebinding was inherited with the user only")
+		FieldShouldContain("rolebinding", nsChild, rbName, ".subjects", user)
+		FieldShouldNotContain("rolebinding", nsChild, rbName, ".subjects", "system:authenticated")
+		UserShouldBeAllowed(user, "get", "subnamespace/"+nsChild, nsRoot)
+	})
+
+	It("Should create rolebindings for the owners of a subnamespace and inherit them in its children", func() {
+		By("Creating a subnamespace with a child")
+		user := GenerateE2EUserName("user")
+		CreateUser(user, randPrefix)
+		nsA := GenerateE2EName("a", testPrefix, randPrefix)
+		nsB := GenerateE2EName("b", testPrefix, randPrefix)
+		CreateSubnamespace(nsA, nsRoot, randPrefix, false, storage, "50Gi", cpu, "50", memory, "50Gi", pods, "50", gpu, "50")
+		CreateSubnamespace(nsB, nsA, randPrefix, false, storage, "25Gi", cpu, "25", memory, "25Gi", pods, "25", gpu, "25")
+
+		By("Adding the user as an owner of the subnamespace")
+		MustRun("kubectl patch subnamespace", nsA, "-n", nsRoot, "--type=merge",
+			"-p", `{"spec":{"owners":[{"kind":"User","name":"`+user+`","role":"view"}]}}`)
+
+		By("Checking that the owner rolebinding was created and inherited by the child")
+		RunShouldContain(user, propagationTime, "kubectl get rolebinding -n", nsA, "-l", danav1.OwnerRoleBinding+"="+danav1.True,
+			"-o jsonpath={.items[*].subjects[*].name}")
+		RunShouldContain(user, propagationTime, "kubectl get rolebinding -n", nsB, "-l", danav1.InheritedFromUID,
+			"-o jsonpath={.items[*].subjects[*].name}")
+
+		By("Removing the user from the owners of the subnamespace")
+		MustRun("kubectl patch subnamespace", nsA, "-n", nsRoot, "--type=merge", "-p", `{"spec":{"owners":null}}`)
+		RunShouldNotContain(user, propagationTime, "kubectl get rolebinding -n", nsA, "-o jsonpath={.items[*].subjects[*].name}")
+		RunShouldNotContain(user, propagationTime, "kubectl get rolebinding -n", nsB, "-o jsonpath={.items[*].subjects[*].name}")
+	})
+
+	It("Should not allow granting an owner of a subnamespace a role the requester can't grant", func() {
+		By("Creating a subnamespace and granting a user admin role in its parent")
+		user := GenerateE2EUserName("user")
+		owner := GenerateE2EUserName("owner")
+		CreateUser(user, randPrefix)
+		CreateUser(owner, randPrefix)
+		GrantTestingUserAdmin(user, nsRoot)
+		nsA := GenerateE2EName("a", testPrefix, randPrefix)
+		CreateSubnamespace(nsA, nsRoot, randPrefix, false, storage, "50Gi", cpu, "50", memory, "50Gi", pods, "50", gpu, "50")
+
+		By("Failing to make another user an owner with a role the user doesn't have")
+		MustNotRun("kubectl patch subnamespace", nsA, "-n", nsRoot, "--as", user, "--type=merge",
+			"-p", `{"spec":{"owners":[{"kind":"User","name":"`+owner+`","role":"cluster-admin"}]}}`)
+
+		By("Making another user an owner with a role the user has")
+		MustRun("kubectl patch subnamespace", nsA, "-n", nsRoot, "--as", user, "--type=merge",
+			"-p", `{"spec":{"owners":[{"kind":"User","name":"`+owner+`","role":"view"}]}}`)
+		RunShouldContain(owner, propagationTime, "kubectl get rolebinding -n", nsA, "-l", danav1.OwnerRoleBinding+"="+danav1.True,
+			"-o jsonpath={.items[*].subjects[*].name}")
+	})
+
+	It("Should remove the rolebindings excluded by the inheritance policy of a subnamespace", func() {
+		By("Creating a subnamespace with a child and granting two users admin role in the root namespace")
+		user := GenerateE2EUserName("user")
+		manager := GenerateE2EUserName("manager")
+		CreateUser(user, randPrefix)
+		CreateUser(manager, randPrefix)
+		CreateGroup("test", manager, randPrefix)
+		GrantTestingUserAdmin(user, nsRoot)
+		GrantTestingUserAdmin(manager, nsRoot)
+		nsA := GenerateE2EName("a", testPrefix, randPrefix)
+		nsB := GenerateE2EName("b", testPrefix, randPrefix)
+		CreateSubnamespace(nsA, nsRoot, randPrefix, false, storage, "50Gi", cpu, "50", memory, "50Gi", pods, "50", gpu, "50")
+		CreateSubnamespace(nsB, nsA, randPrefix, false, storage, "25Gi", cpu, "25", memory, "25Gi", pods, "25", gpu, "25")
+		FieldShouldContain("rolebinding", nsB, "test-admin-"+user+"-"+nsRoot, ".metadata.name", "test-admin-"+user+"-"+nsRoot)
+
+		policy := `{"spec":{"inheritance":{"excludedSubjects":[{"kind":"User","name":"` + user + `"}]}}}`
+
+		By("Failing to set the inheritance policy as a user who is not in a permitted group")
+		MustNotRun("kubectl patch subnamespace", nsA, "-n", nsRoot, "--as", user, "--type=merge", "-p", policy)
+
+		By("Excluding the rolebinding of the user as a user in a permitted group")
+		MustRun("kubectl patch subnamespace", nsA, "-n", nsRoot, "--as", manager, "--type=merge", "-p", policy)
+
+		By("Checking that only the excluded rolebinding was removed from the subnamespace and its child")
+		ShouldNotExist("rolebinding", nsA, "test-admin-"+user+"-"+nsRoot)
+		ShouldNotExist("rolebinding", nsB, "test-admin-"+user+"-"+nsRoot)
+		FieldShouldContain("rolebinding", nsB, "test-admin-"+manager+"-"+nsRoot, ".metadata.name", "test-admin-"+manager+"-"+nsRoot)
+
+		By("Removing the inheritance policy and checking that the rolebinding is inherited again")
+		MustRun("kubectl patch subnamespace", nsA, "-n", nsRoot, "--as", manager, "--type=merge", "-p", `{"spec":{"inheritance":null}}`)
+		FieldShouldContain("rolebinding", nsB, "test-admin-"+user+"-"+nsRoot, ".metadata.name", "test-admin-"+user+"-"+nsRoot)
+		CleanupTestGroup("test")
+	})
+
+	It("Should allow the subjects of a rolebinding to view the subnamespace of its namespace", func() {
 		By("Creating a subnamespace")
 		nsChild := GenerateE2EName("child", testPrefix, randPrefix)
 		CreateSubnamespace(nsChild, nsRoot, randPrefix, false, storage, "50Gi",
 			cpu, "50", memory, "50Gi", pods, "50", gpu, "50")
 
+		By("Checking that the subject of a rolebinding can view the subnamespace")
 		user := GenerateE2EUserName("user")
 		CreateUser(user, randPrefix)
 		GrantTestingUserAdmin(user, nsChild)
-		FieldShouldContain("clusterrolebindings", "", nsChild+"-hns-view", ".metadata.name", nsChild+"-hns-view")
-		ComplexFieldShouldContain("clusterrolebindings", "", nsChild+"-hns-view",
-			"'{{range.subjects}}{{.name}}{{\"\\n\"}}{{end}}'", user)
+		UserShouldBeAllowed(user, "get", "subnamespace/"+nsChild, nsRoot)
+		RunShouldContain(user, propagationTime, "kubectl get clusterrolebindings -l", danav1.HNSViewSubject,
+			"-o jsonpath={.items[*].subjects[*].name}")
+
+		By("Checking that the subject can't view the subnamespace once the rolebinding is deleted")
+		ShouldDelete("rolebinding", nsChild, "test-admin-"+user+"-"+nsChild)
+		UserShouldNotBeAllowed(user, "get", "subnamespace/"+nsChild, nsRoot)
+		RunShouldNotContain(user, propagationTime, "kubectl get clusterrolebindings -l", danav1.HNSViewSubject,
+			"-o jsonpath={.items[*].subjects[*].name}")
 	})
 
-	It("Should not bind a serviceaccount to the hns-view rolebinding", func() {
+	It("Should not allow a serviceaccount to view the subnamespace of its namespace", func() {
 		By("Creating a subnamespace")
 		nsChild := GenerateE2EName("child", testPrefix, randPrefix)
 		CreateSubnamespace(nsChild, nsRoot, randPrefix, false, storage, "50Gi",
@@ -104,8 +234,9 @@ var _ = Describe("RoleBindings", func() {
 		serviceAccount := GenerateE2EUserName("serviceaccount")
 		CreateServiceAccount(serviceAccount, nsRoot, randPrefix)
 		GrantTestingServiceAccountAdmin(serviceAccount, nsRoot)
-		ComplexFieldShouldNotContain("clusterrolebindings", "", nsChild+"-hns-view",
-			"'{{range.subjects}}{{.name}}{{\"\\n\"}}{{end}}'", serviceAccount)
-
+		FieldShouldContain("rolebinding", nsChild, "test-admin-"+serviceAccount+"-"+nsRoot, ".metadata.name",
+			"test-admin-"+serviceAccount+"-"+nsRoot)
+		RunShouldNotContain(serviceAccount, propagationTime, "kubectl get clusterrolebindings -l", danav1.HNSViewSubject,
+			"-o jsonpath={.items[*].subjects[*].name}")
 	})
 })
@@ -96,6 +96,52 @@ var _ = Describe("Subnamespaces", func() {
 			danav1.DisplayName+":"+nsRoot+"/"+nsA+"/"+nsB+"/"+nsC)
 	})
 
+	It("should set the tree labels of a namespace and not allow users to change them", func() {
+		nsA := GenerateE2EName("a", testPrefix, randPrefix)
+		nsB := GenerateE2EName("b", testPrefix, randPrefix)
+
+		CreateSubnamespace(nsA, nsRoot, randPrefix, false, storage, "50Gi", cpu, "50", memory, "50Gi", pods, "50", gpu, "50")
+		CreateSubnamespace(nsB, nsA, randPrefix, false, storage, "25Gi", cpu, "25", memory, "25Gi", pods, "25", gpu, "25")
+
+		// verify tree labels
+		FieldShouldContain("namespace", "", nsB, ".metadata.labels", nsRoot+danav1.TreeLabelSuffix+":2")
+		FieldShouldContain("namespace", "", nsB, ".metadata.labels", nsA+danav1.TreeLabelSuffix+":1")
+		FieldShouldContain("namespace", "", nsB, ".metadata.labels", nsB+danav1.TreeLabelSuffix+":0")
+
+		// verify the tree labels and hierarchy annotations can't be changed by users
+		MustNotRun("kubectl label --overwrite ns", nsB, nsA+danav1.TreeLabelSuffix+"=3")
+		MustNotRun("kubectl label ns", nsB, nsA+danav1.TreeLabelSuffix+"-")
+		MustNotRun("kubectl annotate --overwrite ns", nsB, danav1.DisplayName+"="+nsB)
+	})
+
+	It("should not allow users to change the objects of a subnamespace which are managed by HNS", func() {
+		nsA := GenerateE2EName("a", testPrefix, randPrefix)
+		nsB := GenerateE2EName("b", testPrefix, randPrefix)
+		nsC := GenerateE2EName("c", testPrefix, randPrefix)
+
+		CreateSubnamespace(nsA, nsRoot, randPrefix, false, storage, "50Gi", cpu, "50", memory, "50Gi", pods, "50", gpu, "50")
+		CreateSubnamespace(nsB, nsA, randPrefix, false, storage, "25Gi", cpu, "25", memory, "25Gi", pods, "25", gpu, "25")
+		CreateSubnamespace(nsC, nsB, randPrefix, false, storage, "10Gi", cpu, "10", memory, "10Gi", pods, "10", gpu, "10")
+
+		// verify the HNS labels and annotations of the namespace can't be changed
+		MustNotRun("kubectl label --overwrite ns", nsA, danav1.ResourcePool+"=true")
+		MustNotRun("kubectl annotate --overwrite ns", nsA, danav1.Role+"="+danav1.Leaf)
+
+		// verify namespaces which are not managed by HNS can't be added to a hierarchy by labeling them
+		nsUnmanaged := GenerateE2EName("unmanaged", testPrefix, randPrefix)
+		MustRun("kubectl create ns", nsUnmanaged)
+		LabelTestingNs(nsUnmanaged, randPrefix)
+		MustNotRun("kubectl label ns", nsUnmanaged, danav1.Hns+"=true")
+		MustNotRun("kubectl label ns", nsUnmanaged, danav1.Parent+"="+nsA)
+		MustNotRun("kubectl label ns", nsUnmanaged, nsA+danav1.TreeLabelSuffix+"=1")
+
+		// verify the quota objects and the LimitRange of the subnamespace can't be changed
+		ShouldNotUpdateResourceQuota(nsA, nsA, storage, "100Gi", cpu, "100", memory, "100Gi", pods, "100", gpu, "100")
+		ShouldNotDelete("resourcequota", nsA, nsA)
+		ShouldNotDelete("limitrange", nsA, nsA)
+		MustNotRun("kubectl delete clusterresourcequota", nsC)
+	})
+
 	It("should update the role of a subnamespace after it creates children", func() {
 		nsA := GenerateE2EName("a", testPrefix, randPrefix)
 		nsB := GenerateE2EName("b", testPrefix, randPrefix)
@@ -113,6 +159,23 @@ var _ = Describe("Subnamespaces", func() {
 		FieldShouldContain("namespace", "", nsA, ".metadata.annotations", danav1.Role+":"+danav1.NoRole)
 	})
 
+	It("should keep the labels and annotations users add to a subnamespace", func() {
+		nsA := GenerateE2EName("a", testPrefix, randPrefix)
+		nsB := GenerateE2EName("b", testPrefix, randPrefix)
+
+		CreateSubnamespace(nsA, nsRoot, randPrefix, false, storage, "50Gi", cpu, "50", memory, "50Gi", pods, "50", gpu, "50")
+		MustRun("kubectl label --overwrite subnamespace -n", nsRoot, nsA, "team=test")
+		MustRun("kubectl annotate --overwrite subnamespace -n", nsRoot, nsA, "description=test")
+
+		// creating a child triggers the reconciliation of the subnamespace
+		CreateSubnamespace(nsB, nsA, randPrefix, false, storage, "25Gi", cpu, "25", memory, "25Gi", pods, "25", gpu, "25")
+		FieldShouldContain("namespace", "", nsA, ".metadata.labels", danav1.Role+":"+danav1.NoRole)
+
+		FieldShouldContain("subnamespace", nsRoot, nsA, ".metadata.labels", "team:test")
+		FieldShouldContain("subnamespace", nsRoot, nsA, ".metadata.labels", danav1.ResourcePool+":false")
+		FieldShouldContain("subnamespace", nsRoot, nsA, ".metadata.annotations", "description:test")
+	})
+
 	It("should update subnamespace with new resources", func() {
 		nsA := GenerateE2EName("a", testPrefix, randPrefix)
 		nsB := GenerateE2EName("b", testPrefix, randPrefix)
@@ -330,4 +393,31 @@ var _ = Describe("Subnamespaces", func() {
 		MustRun("kubectl delete namespace", nsC, "-n", nsB)
 	})
 
+	It("should inherit the LimitRange policy of an ancestor and only allow tightening it", func() {
+		nsA := GenerateE2EName("a", testPrefix, randPrefix)
+		nsB := GenerateE2EName("b", testPrefix, randPrefix)
+
+		CreateSubnamespace(nsA, nsRoot, randPrefix, false, storage, "50Gi", cpu, "50", memory, "50Gi", pods, "50", gpu, "50")
+		CreateSubnamespace(nsB, nsA, randPrefix, false, storage, "25Gi", cpu, "25", memory, "25Gi", pods, "25", gpu, "25")
+
+		By("setting a LimitRange policy on the parent subnamespace")
+		MustRun("kubectl patch subnamespace", nsA, "-n", nsRoot, "--type=merge",
+			"-p", `{"spec":{"limitrange":{"limits":[{"type":"Container","max":{"cpu":"2"}}]}}}`)
+
+		ComplexFieldShouldContain("limitrange", nsA, nsA,
+			"'{{range.spec.limits}}{{.type}}:{{.max.cpu}}{{\"\\n\"}}{{end}}'", "Container:2")
+		ComplexFieldShouldContain("limitrange", nsB, nsB,
+			"'{{range.spec.limits}}{{.type}}:{{.max.cpu}}{{\"\\n\"}}{{end}}'", "Container:2")
+
+		By("tightening the inherited LimitRange policy in the child subnamespace")
+		MustRun("kubectl patch subnamespace", nsB, "-n", nsA, "--type=merge",
+			"-p", `{"spec":{"limitrange":{"limits":[{"type":"Container","max":{"cpu":"1"}}]}}}`)
+		ComplexFieldShouldContain("limitrange", nsB, nsB,
+			"'{{range.spec.limits}}{{.type}}:{{.max.cpu}}{{\"\\n\"}}{{end}}'", "Container:1")
+
+		By("failing to loosen the inherited LimitRange policy in the child subnamespace")
+		MustNotRun("kubectl patch subnamespace", nsB, "-n", nsA, "--type=merge",
+			"-p", `{"spec":{"limitrange":{"limits":[{"type":"Container","max":{"cpu":"4"}}]}}}`)
+	})
+
 })
@@ -0,0 +1,92 @@
+package e2e_tests
+
+import (
+	danav1 "github.com/dana-team/hns/api/v1"
+	. "github.com/dana-team/hns/test/testutils"
+	. "github.com/onsi/ginkgo/v2"
+)
+
+var _ = Describe("SubnamespaceRename", func() {
+	testPrefix := "snsr-test"
+	var randPrefix string
+	var nsRoot string
+
+	BeforeEach(func() {
+		randPrefix = RandStr()
+
+		CleanupTestNamespaces(randPrefix)
+		CleanupTestSubnamespaceRenames(randPrefix)
+
+		nsRoot = GenerateE2EName("root", testPrefix, randPrefix)
+		CreateRootNS(nsRoot, randPrefix, rqDepth)
+		CreateResourceQuota(nsRoot, nsRoot, storage, "100Gi", cpu, "100", memory, "100Gi", pods, "100", gpu, "100")
+	})
+
+	AfterEach(func() {
+		CleanupTestNamespaces(randPrefix)
+		CleanupTestSubnamespaceRenames(randPrefix)
+	})
+
+	It("should rename a subnamespace and move its objects to the new namespace", func() {
+		nsA := GenerateE2EName("a", testPrefix, randPrefix)
+		nsZ := GenerateE2EName("z", testPrefix, randPrefix)
+
+		CreateSubnamespace(nsA, nsRoot, randPrefix, false, storage, "50Gi", cpu, "50", memory, "50Gi", pods, "50", gpu, "50")
+		MustRun("kubectl create configmap renamed-config -n", nsA, "--from-literal=key=value")
+		MustRun("kubectl create serviceaccount renamed-sa -n", nsA)
+		MustRun("kubectl create rolebinding renamed-rb -n", nsA, "--clusterrole=view --serviceaccount="+nsA+":renamed-sa")
+		MustRun("kubectl create service clusterip renamed-service -n", nsA, "--tcp=80:80")
+
+		snsrName := CreateSubnamespaceRename(nsA, nsZ)
+		FieldShouldContain("subnamespacerename", "", snsrName, ".status.phase", "Complete")
+		LabelTestingSubnamespaceRenames(snsrName, randPrefix)
+		LabelTestingNs(nsZ, randPrefix)
+
+		// make sure the subnamespace was renamed and kept its quota and objects
+		FieldShouldContain("subnamespace", nsRoot, nsZ, ".spec.resourcequota.hard", "50Gi")
+		FieldShouldContain("namespace", "", nsZ, ".metadata.annotations", danav1.PreviousNames+":"+nsA)
+		FieldShouldContain("configmap", nsZ, "renamed-config", ".data.key", "value")
+		FieldShouldContain("serviceaccount", nsZ, "renamed-sa", ".metadata.name", "renamed-sa")
+		FieldShouldContain("rolebinding", nsZ, "renamed-rb", ".subjects[0].namespace", nsZ)
+		FieldShouldContain("subnamespacerename", "", snsrName, ".status.notMoved", "Service/renamed-service")
+		RunShouldNotContain(nsA, propagationTime, "kubectl get ns")
+	})
+
+	It("should move the children of a renamed subnamespace to be under the new subnamespace", func() {
+		nsA := GenerateE2EName("a", testPrefix, randPrefix)
+		nsB := GenerateE2EName("b", testPrefix, randPrefix)
+		nsC := GenerateE2EName("c", testPrefix, randPrefix)
+		nsZ := GenerateE2EName("z", testPrefix, randPrefix)
+
+		CreateSubnamespace(nsA, nsRoot, randPrefix, false, storage, "50Gi", cpu, "50", memory, "50Gi", pods, "50", gpu, "50")
+		CreateSubnamespace(nsB, nsA, randPrefix, false, storage, "25Gi", cpu, "25", memory, "25Gi", pods, "25", gpu, "25")
+		CreateSubnamespace(nsC, nsB, randPrefix, false, storage, "10Gi", cpu, "10", memory, "10Gi", pods, "10", gpu, "10")
+
+		snsrName := CreateSubnamespaceRename(nsA, nsZ)
+		FieldShouldContain("subnamespacerename", "", snsrName, ".status.phase", "Complete")
+		LabelTestingSubnamespaceRenames(snsrName, randPrefix)
+		LabelTestingNs(nsZ, randPrefix)
+
+		// make sure the children were moved and the labels and annotations of the descendants were updated
+		FieldShouldContain("subnamespace", nsZ, nsB, ".metadata.namespace", nsZ)
+		FieldShouldContain("namespace", "", nsB, ".metadata.labels", danav1.Parent+":"+nsZ)
+		FieldShouldContain("namespace", "", nsC, ".metadata.annotations", nsZ+"/"+nsB+"/"+nsC)
+		FieldShouldNotContain("namespace", "", nsC, ".metadata.labels", nsA+":true")
+		RunShouldNotContain(nsA, propagationTime, "kubectl get ns")
+
+		// delete the descendants before the renamed namespace, which was created after them
+		MustRun("kubectl delete ns", nsC)
+		MustRun("kubectl delete ns", nsB)
+	})
+
+	It("should not rename a subnamespace to the name of an existing namespace", func() {
+		nsA := GenerateE2EName("a", testPrefix, randPrefix)
+		nsB := GenerateE2EName("b", testPrefix, randPrefix)
+
+		CreateSubnamespace(nsA, nsRoot, randPrefix, false, storage, "50Gi", cpu, "50", memory, "50Gi", pods, "50", gpu, "50")
+		CreateSubnamespace(nsB, nsRoot, randPrefix, false, storage, "25Gi", cpu, "25", memory, "25Gi", pods, "25", gpu, "25")
+
+		ShouldNotCreateSubnamespaceRename(nsA, nsB)
+		ShouldNotCreateSubnamespaceRename(nsRoot, GenerateE2EName("z", testPrefix, randPrefix))
+	})
+})
@@ -73,6 +73,15 @@ func GrantTestingServiceAccountAdmin(sa, ns string) {
 		"--clusterrole admin")
 }
 
+// GrantTestingUserClusterRole gives a rolebinding of the given clusterrole to a user on a namespace.
+func GrantTestingUserClusterRole(user, ns, clusterRole string) {
+	MustRun("kubectl create rolebinding",
+		"test-"+clusterRole+"-"+user+"-"+ns,
+		"--user", user,
+		"--namespace", ns,
+		"--clusterrole", clusterRole)
+}
+
 // GrantTestingUserClusterAdmin gives cluster-admin cluster-rolebinding to a user.
 func GrantTestingUserClusterAdmin(user string) {
 	MustRun("kubectl create clusterrolebinding", "test-cluster-admin-"+user, "--user", user, "--clusterrole cluster-admin")
@@ -105,6 +114,23 @@ func CreateRootNS(nm, randPrefix string, rqDepth int) {
 
 }
 
+// CreateHierarchyRoot creates/updates a HierarchyRoot with a given name, rqDepth,
+// secondary roots and resources, and labels the root namespace it creates.
+func CreateHierarchyRoot(nm, randPrefix string, rqDepth int, secondaryRoots []string, args ...string) {
+	hr := generateHierarchyRootManifest(nm, strconv.Itoa(rqDepth), secondaryRoots, args...)
+	MustApplyYAML(hr)
+	RunShouldContain(nm, propagationTime, "kubectl get hierarchyroot", nm)
+	LabelTestingHierarchyRoots(nm, randPrefix)
+	RunShouldContain(nm, propagationTime, "kubectl get ns", nm)
+	LabelTestingNs(nm, randPrefix)
+}
+
+// ShouldNotCreateHierarchyRoot should not be able to create/update the specified HierarchyRoot.
+func ShouldNotCreateHierarchyRoot(nm string, rqDepth int, secondaryRoots []string, args ...string) {
+	hr := generateHierarchyRootManifest(nm, strconv.Itoa(rqDepth), secondaryRoots, args...)
+	MustNotApplyYAML(hr)
+}
+
 // CreateResourceQuota creates/updates a ResourceQuota object in a given
 // namespace and with the given resources.
 func CreateResourceQuota(nm, nsnm string, args ...string) {
@@ -113,6 +139,13 @@ func CreateResourceQuota(nm, nsnm string, args ...string) {
 	RunShouldContain(nm, propagationTime, "kubectl get resourcequota -n", nsnm)
 }
 
+// ShouldNotUpdateResourceQuota should not be able to create/update a ResourceQuota object
+// in a given namespace and with the given resources.
+func ShouldNotUpdateResourceQuota(nm, nsnm string, args ...string) {
+	rq := generateRQManifest(nm, nsnm, args...)
+	MustNotApplyYAML(rq)
+}
+
 // CreateSubnamespace creates/updates the specified Subnamespace in the parent namespace with canned testing
 // labels making it easier to look up and delete later, and with the given resources.
 func CreateSubnamespace(nm, nsnm, randPrefix string, isRp bool, args ...string) {
@@ -135,6 +168,23 @@ func CreateSubnamespaceWithScope(nm, nsnm, randPrefix string, isRp bool, operato
 	LabelTestingNs(nm, randPrefix)
 }
 
+// CreateSubnamespaceAsUser creates the specified Subnamespace in the parent namespace as the given user, with
+// canned testing labels making it easier to look up and delete later, and with the given resources.
+func CreateSubnamespaceAsUser(nm, nsnm, randPrefix, user string, isRp bool, args ...string) {
+	sns := generateSNSManifest(nm, nsnm, strconv.FormatBool(isRp), args...)
+	MustApplyYAMLAsUser(sns, user)
+	RunShouldContain(nm, propagationTime, "kubectl get namespace")
+	LabelTestingNs(nm, randPrefix)
+}
+
+// ShouldNotCreateSubnamespaceAsUser should not be able to create the specified Subnamespace
+// in the parent namespace as the given user.
+func ShouldNotCreateSubnamespaceAsUser(nm, nsnm, user string, isRp bool, args ...string) {
+	sns := generateSNSManifest(nm, nsnm, strconv.FormatBool(isRp), args...)
+	MustNotApplyYAMLAsUser(sns, user)
+	RunShouldNotContain(nm, propagationTime, "kubectl get subnamespace -n", nsnm)
+}
+
 // ShouldNotCreateSubnamespace should not be able to create the specified Subnamespace
 // in the parent namespace and with the given resources.
 func ShouldNotCreateSubnamespace(nm, nsnm string, isRp bool, args ...string) {
@@ -177,14 +227,60 @@ func CreateMigrationHierarchy(currentns, tons string, user string) string {
 	return name
 }
 
+// CreateDryRunMigrationHierarchy creates the specified MigrationHierarchy in dry-run mode.
+func CreateDryRunMigrationHierarchy(currentns, tons string) string {
+	name := "preview" + currentns + "to" + tons
+	mh := generateMigrartionHierarchyManifest(name, currentns, tons) + `
+  dryRun: true`
+	MustApplyYAML(mh)
+	RunShouldContain(name, propagationTime, "kubectl get migrationhierarchy")
+	return name
+}
+
 // ShouldNotCreateMigrationHierarchy should not be able to create the specified MigrationHierarchy.
-func ShouldNotCreateMigrationHierarchy(currentns, tons string) {
+func ShouldNotCreateMigrationHierarchy(currentns, tons string, user string) {
 	name := "from" + currentns + "to" + tons
 	mh := generateMigrartionHierarchyManifest(name, currentns, tons)
-	MustNotApplyYAML(mh)
+	if user != "" {
+		MustNotApplyYAMLAsUser(mh, user)
+	} else {
+		MustNotApplyYAML(mh)
+	}
 	RunShouldNotContain(name, propagationTime, "kubectl get migrationhierarchy")
 }
 
+// CreateSubnamespaceRename creates the specified SubnamespaceRename.
+func CreateSubnamespaceRename(currentns, newns string) string {
+	name := "from" + currentns + "to" + newns
+	snsr := generateSubnamespaceRenameManifest(name, currentns, newns)
+	MustApplyYAML(snsr)
+	RunShouldContain(name, propagationTime, "kubectl get subnamespacerename")
+	return name
+}
+
+// ShouldNotCreateSubnamespaceRename should not be able to create the specified SubnamespaceRename.
+func ShouldNotCreateSubnamespaceRename(currentns, newns string) {
+	name := "from" + currentns + "to" + newns
+	snsr := generateSubnamespaceRenameManifest(name, currentns, newns)
+	MustNotApplyYAML(snsr)
+	RunShouldNotContain(name, propagationTime, "kubectl get subnamespacerename")
+}
+
+// CreateBatchMigration creates the specified BatchMigration. The migrations are given
+// as pairs of the subnamespace to migrate and its new parent.
+func CreateBatchMigration(nm string, migrations ...string) {
+	bm := generateBatchMigrationManifest(nm, migrations...)
+	MustApplyYAML(bm)
+	RunShouldContain(nm, propagationTime, "kubectl get batchmigration")
+}
+
+// ShouldNotCreateBatchMigration should not be able to create the specified BatchMigration.
+func ShouldNotCreateBatchMigration(nm string, migrations ...string) {
+	bm := generateBatchMigrationManifest(nm, migrations...)
+	MustNotApplyYAML(bm)
+	RunShouldNotContain(nm, propagationTime, "kubectl get batchmigration")
+}
+
 // ShouldNotCreateUpdateQuota should not be able to create the specified UpdateQuota
 // in the parent namespace and with the given resources.
 func ShouldNotCreateUpdateQuota(nm, nsnm, dsnm, user string, args ...string) {
@@ -225,6 +321,14 @@ func CreateGroup(g, u, randPrefix string) {
 	labelTestingGroup(g, randPrefix)
 }
 
+// CreateRoleBindingWithGroupAndUser creates a rolebinding of the given clusterrole whose first
+// subject is the given group and whose second subject is the given user.
+func CreateRoleBindingWithGroupAndUser(nm, ns, clusterRole, group, user string) {
+	roleBinding := generateRoleBindingManifest(nm, ns, clusterRole, group, user)
+	MustApplyYAML(roleBinding)
+	RunShouldContain(nm, propagationTime, "kubectl get rolebinding -n", ns)
+}
+
 // CreatePod creates a pod in the specified namespace with the required cpu and memory(Gi).
 func CreatePod(ns, name, randPrefix, cpu, memory string) {
 	pod := generatePodManifest(ns, name, cpu, memory)
@@ -252,6 +356,30 @@ metadata:
     ` + danav1.RqDepth + `: ` + `"` + rqDepth + `"`
 }
 
+// generateHierarchyRootManifest generates a HierarchyRoot manifest.
+func generateHierarchyRootManifest(nm, rqDepth string, secondaryRoots []string, args ...string) string {
+	manifest := `# temp file created by hierarchyroot_test.go
+apiVersion: dana.hns.io/v1
+kind: HierarchyRoot
+metadata:
+  name: ` + nm + `
+spec:
+  rqDepth: ` + rqDepth + `
+  resourcequota:
+    hard: ` + argsToResourceListString(3, args...)
+
+	if len(secondaryRoots) > 0 {
+		manifest += `
+  secondaryRoots:`
+		for _, secondaryRoot := range secondaryRoots {
+			manifest += `
+  - ` + secondaryRoot
+		}
+	}
+
+	return manifest
+}
+
 // generateSNSManifest generates a Subnamespace manifest.
 func generateSNSManifest(nm, nsnm, isRp string, args ...string) string {
 	return `# temp file created by sns_test.go
@@ -326,6 +454,36 @@ spec:
   tons: ` + tons
 }
 
+// generateSubnamespaceRenameManifest generates a SubnamespaceRename manifest.
+func generateSubnamespaceRenameManifest(nm, currentns, newns string) string {
+	return `# temp file created by subnamespacerename_test.go
+apiVersion: dana.hns.io/v1
+kind: SubnamespaceRename
+metadata:
+  name: ` + nm + `
+spec:
+  currentns: ` + currentns + `
+  newns: ` + newns
+}
+
+// generateBatchMigrationManifest generates a BatchMigration manifest.
+func generateBatchMigrationManifest(nm string, migrations ...string) string {
+	manifest := `# temp file created by batchmigration_test.go
+apiVersion: dana.hns.io/v1
+kind: BatchMigration
+metadata:
+  name: ` + nm + `
+spec:
+  migrations:`
+	for i := 0; i+1 < len(migrations); i += 2 {
+		manifest += `
+  - currentns: ` + migrations[i] + `
+    tons: ` + migrations[i+1]
+	}
+
+	return manifest
+}
+
 // generateUserManifest generates an User manifest.
 func generateUserManifest(nm string) string {
 	return `# temp file created by user_test.go
@@ -348,6 +506,27 @@ users:
   - ` + user
 }
 
+// generateRoleBindingManifest generates a RoleBinding manifest with a group subject and a user subject.
+func generateRoleBindingManifest(nm, ns, clusterRole, group, user string) string {
+	return `# temp file created by rolebindings_test.go
+apiVersion: rbac.authorization.k8s.io/v1
+kind: RoleBinding
+metadata:
+  name: ` + nm + `
+  namespace: ` + ns + `
+roleRef:
+  apiGroup: rbac.authorization.k8s.io
+  kind: ClusterRole
+  name: ` + clusterRole + `
+subjects:
+  - apiGroup: rbac.authorization.k8s.io
+    kind: Group
+    name: ` + group + `
+  - apiGroup: rbac.authorization.k8s.io
+    kind: User
+    name: ` + user
+}
+
 // generatePodManifest generates an Pod manifest.
 func generatePodManifest(ns, name, cpu, memory string) string {
 	return `# temp file created by pod_test.go
@@ -15,6 +15,9 @@ import (
 // The testing label marked on all namespaces created using the testing phase, offering ease when doing cleanups
 const testingNamespaceLabel = "dana.hns.io/testNamespace"
 const testingMigrationHierarchyLabel = "dana.hns.io/testMigrationHierarchy"
+const testingHierarchyRootLabel = "dana.hns.io/testHierarchyRoot"
+const testingSubnamespaceRenameLabel = "dana.hns.io/testSubnamespaceRename"
+const testingBatchMigrationLabel = "dana.hns.io/testBatchMigration"
 const testingUserLabel = "dana.hns.io/testUser"
 const testingGroupLabel = "dana.hns.io/testGroup"
 const testingServiceAccountLabel = "dana.hns.io/testServiceAccount"
@@ -227,6 +230,24 @@ func ShouldDelete(resource, ns, nm string) {
 	ShouldNotExist(resource, ns, nm)
 }
 
+// UserShouldBeAllowed checks that a user is eventually allowed to perform a verb on a resource in a namespace.
+func UserShouldBeAllowed(user, verb, resource, ns string) {
+	userShouldBeAllowed(1, "yes", user, verb, resource, ns)
+}
+
+// UserShouldNotBeAllowed checks that a user is eventually not allowed to perform a verb on a resource in a namespace.
+func UserShouldNotBeAllowed(user, verb, resource, ns string) {
+	userShouldBeAllowed(1, "no", user, verb, resource, ns)
+}
+
+func userShouldBeAllowed(offset int, want, user, verb, resource, ns string) {
+	EventuallyWithOffset(offset+1, func() string {
+		// kubectl exits with an error when the answer is no, so only the output is checked
+		stdout, _ := RunCommand("kubectl auth can-i", verb, resource, "-n", ns, "--as", user)
+		return strings.TrimSpace(stdout)
+	}, eventuallyTimeout).Should(Equal(want), "User %s %s %s in namespace %s", user, verb, resource, ns)
+}
+
 // RunCommand passes all arguments to the OS to execute, and returns the combined stdout/stderr
 // and error object. By default, each arg to this function may contain strings (e.g. "echo hello
 // world"), in which case we split the strings on the spaces (so this would be equivalent to calling
@@ -262,6 +283,21 @@ func LabelTestingMigrationHierarchies(mh, randPrefix string) {
 	MustRun("kubectl label --overwrite migrationhierarchy", mh, randPrefix+"-"+testingMigrationHierarchyLabel+"=true")
 }
 
+// LabelTestingSubnamespaceRenames marks testing subnamespacerenames with a label for future search and lookup.
+func LabelTestingSubnamespaceRenames(snsr, randPrefix string) {
+	MustRun("kubectl label --overwrite subnamespacerename", snsr, randPrefix+"-"+testingSubnamespaceRenameLabel+"=true")
+}
+
+// LabelTestingBatchMigrations marks testing batchmigrations with a label for future search and lookup.
+func LabelTestingBatchMigrations(bm, randPrefix string) {
+	MustRun("kubectl label --overwrite batchmigration", bm, randPrefix+"-"+testingBatchMigrationLabel+"=true")
+}
+
+// LabelTestingHierarchyRoots marks testing hierarchyroots with a label for future search and lookup.
+func LabelTestingHierarchyRoots(hr, randPrefix string) {
+	MustRun("kubectl label --overwrite hierarchyroot", hr, randPrefix+"-"+testingHierarchyRootLabel+"=true")
+}
+
 // labelTestingGroups marks testing groups with a label for future search and lookup.
 func labelTestingGroup(group, randPrefix string) {
 	MustRun("kubectl label --overwrite group", group, randPrefix+"-"+testingGroupLabel+"=true")
@@ -315,6 +351,60 @@ func CleanupTestMigrationHierarchies(randPrefix string) {
 	cleanupMigrationHierarchies(mh...)
 }
 
+// CleanupTestSubnamespaceRenames finds the list of subnamespacerenames labeled as test subnamespacerenames
+// and delegates to cleanupSubnamespaceRenames function.
+func CleanupTestSubnamespaceRenames(randPrefix string) {
+	var snsr []string
+	EventuallyWithOffset(1, func() error {
+		LabelQuery := randPrefix + "-" + testingSubnamespaceRenameLabel + "=true"
+		out, err := RunCommand(
+			"kubectl get subnamespacerenames -o custom-columns=:.metadata.name --no-headers=true",
+			"-l", LabelQuery)
+		if err != nil {
+			return err
+		}
+		snsr = strings.Split(out, "\n")
+		return nil
+	}).Should(Succeed(), "while getting list of subnamespacerenames to clean up")
+	cleanupSubnamespaceRenames(snsr...)
+}
+
+// CleanupTestBatchMigrations finds the list of batchmigrations labeled as test batchmigrations
+// and delegates to cleanupBatchMigrations function.
+func CleanupTestBatchMigrations(randPrefix string) {
+	var bm []string
+	EventuallyWithOffset(1, func() error {
+		LabelQuery := randPrefix + "-" + testingBatchMigrationLabel + "=true"
+		out, err := RunCommand(
+			"kubectl get batchmigrations -o custom-columns=:.metadata.name --no-headers=true",
+			"-l", LabelQuery)
+		if err != nil {
+			return err
+		}
+		bm = strings.Split(out, "\n")
+		return nil
+	}).Should(Succeed(), "while getting list of batchmigrations to clean up")
+	cleanupBatchMigrations(bm...)
+}
+
+// CleanupTestHierarchyRoots finds the list of hierarchyroots labeled as test hierarchyroots
+// and delegates to cleanupHierarchyRoots function.
+func CleanupTestHierarchyRoots(randPrefix string) {
+	var hr []string
+	EventuallyWithOffset(1, func() error {
+		LabelQuery := randPrefix + "-" + testingHierarchyRootLabel + "=true"
+		out, err := RunCommand(
+			"kubectl get hierarchyroots -o custom-columns=:.metadata.name --no-headers=true",
+			"-l", LabelQuery)
+		if err != nil {
+			return err
+		}
+		hr = strings.Split(out, "\n")
+		return nil
+	}).Should(Succeed(), "while getting list of hierarchyroots to clean up")
+	cleanupHierarchyRoots(hr...)
+}
+
 // CleanupTestUsers finds the list of users labeled as test namespaces and delegates
 // to cleanupUsers function
 func CleanupTestUsers(randPrefix string) {
@@ -386,6 +476,60 @@ func cleanupMigrationHierarchies(mhs ...string) {
 	}
 }
 
+// cleanupSubnamespaceRenames does everything it can to delete the passed-in subnamespacerenames
+func cleanupSubnamespaceRenames(snsrs ...string) {
+	var toDelete []string
+	for _, snsr := range snsrs {
+
+		if err := TryRunQuietly("kubectl get subnamespacerename", snsr); err != nil {
+			continue
+		}
+		toDelete = append(toDelete, snsr)
+	}
+
+	// Now, actually delete them
+	for _, snsr := range toDelete {
+		err := TryRun("kubectl delete subnamespacerename", snsr)
+		Expect(err).ShouldNot(HaveOccurred())
+	}
+}
+
+// cleanupBatchMigrations does everything it can to delete the passed-in batchmigrations
+func cleanupBatchMigrations(bms ...string) {
+	var toDelete []string
+	for _, bm := range bms {
+
+		if err := TryRunQuietly("kubectl get batchmigration", bm); err != nil {
+			continue
+		}
+		toDelete = append(toDelete, bm)
+	}
+
+	// Now, actually delete them
+	for _, bm := range toDelete {
+		err := TryRun("kubectl delete batchmigration", bm)
+		Expect(err).ShouldNot(HaveOccurred())
+	}
+}
+
+// cleanupHierarchyRoots does everything it can to delete the passed-in hierarchyroots
+func cleanupHierarchyRoots(hrs ...string) {
+	var toDelete []string
+	for _, hr := range hrs {
+
+		if err := TryRunQuietly("kubectl get hierarchyroot", hr); err != nil {
+			continue
+		}
+		toDelete = append(toDelete, hr)
+	}
+
+	// Now, actually delete them
+	for _, hr := range toDelete {
+		err := TryRun("kubectl delete hierarchyroot", hr)
+		Expect(err).ShouldNot(HaveOccurred())
+	}
+}
+
 // cleanupUsers does everything it can to delete the passed-in namespaces
 func cleanupUsers(users ...string) {
 	var toDelete []string // exclude missing namespaces